@@ -0,0 +1,5 @@
+alter table storages
+    drop column address,
+    drop column contact,
+    drop column storage_type,
+    drop column capacity;
@@ -0,0 +1,5 @@
+alter table storages
+    add column address text not null default '',
+    add column contact varchar(255) not null default '',
+    add column storage_type varchar(32) not null default 'warehouse',
+    add column capacity int check (capacity > 0);
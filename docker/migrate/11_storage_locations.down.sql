@@ -0,0 +1,2 @@
+drop table location_stock;
+drop table storage_locations;
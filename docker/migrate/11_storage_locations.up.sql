@@ -0,0 +1,20 @@
+create table storage_locations (
+    location_id serial primary key,
+    storage_id int not null references storages(storage_id),
+    parent_id int references storage_locations(location_id),
+    kind varchar(16) not null check (kind in ('zone', 'aisle', 'shelf', 'bin')),
+    code varchar(64) not null,
+    name varchar(255) not null default '',
+    path varchar(512) not null,
+    created_at timestamptz not null default now(),
+    unique (storage_id, path)
+);
+
+create table location_stock (
+    location_id int not null references storage_locations(location_id),
+    variant_id int not null references product_variants(variant_id),
+    quantity int not null default 0 check (quantity >= 0),
+    primary key (location_id, variant_id)
+);
+
+create index location_stock_variant_idx on location_stock (variant_id);
@@ -0,0 +1,3 @@
+drop table variant_barcodes;
+
+alter table product_variants drop column sku;
@@ -0,0 +1,9 @@
+alter table product_variants add column sku varchar(64) unique;
+
+create table variant_barcodes (
+    barcode varchar(13) primary key,
+    variant_id int not null references product_variants(variant_id),
+    added_at timestamptz not null default now()
+);
+
+create index variant_barcodes_variant_idx on variant_barcodes (variant_id);
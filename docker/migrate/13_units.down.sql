@@ -0,0 +1,6 @@
+alter table product_variants
+    drop constraint product_variants_weight_check,
+    drop constraint product_variants_unit_fkey,
+    alter column weight type int using round(weight);
+
+drop table units;
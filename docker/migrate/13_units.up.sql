@@ -0,0 +1,28 @@
+create table units (
+    code varchar(16) primary key,
+    name varchar(64) not null,
+    dimension varchar(16) not null check (dimension in ('mass', 'volume', 'count', 'length')),
+    factor numeric(20, 9) not null check (factor > 0)
+);
+
+-- factor переводит значение в базовую единицу измерения: кг, л, шт, м
+insert into units (code, name, dimension, factor)
+values
+    ('мг', 'миллиграмм', 'mass', 0.000001),
+    ('г', 'грамм', 'mass', 0.001),
+    ('кг', 'килограмм', 'mass', 1),
+    ('т', 'тонна', 'mass', 1000),
+    ('мл', 'миллилитр', 'volume', 0.001),
+    ('л', 'литр', 'volume', 1),
+    ('шт', 'штука', 'count', 1),
+    ('см', 'сантиметр', 'length', 0.01),
+    ('м', 'метр', 'length', 1);
+
+alter table product_variants
+    alter column weight type numeric(12, 3),
+    add constraint product_variants_unit_fkey foreign key (unit) references units(code),
+    add constraint product_variants_weight_check check (weight > 0);
+
+-- дробные значения начальных данных были округлены при вставке в целочисленную колонку
+update product_variants set weight = 1.5 where variant_id = 4 and weight = 2 and unit = 'л';
+update product_variants set weight = 0.5 where variant_id = 6 and weight = 1 and unit = 'мл';
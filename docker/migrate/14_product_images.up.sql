@@ -0,0 +1,16 @@
+create table product_images (
+    image_id serial primary key,
+    product_id int not null references products(product_id),
+    variant_id int references product_variants(variant_id),
+    blob_key varchar(255) not null unique,
+    thumb_key varchar(255) not null unique,
+    content_type varchar(32) not null check (content_type in ('image/jpeg', 'image/png')),
+    width int not null check (width > 0),
+    height int not null check (height > 0),
+    position int not null default 0 check (position >= 0),
+    is_primary boolean not null default false,
+    created_at timestamptz not null default now()
+);
+
+create index product_images_product_idx on product_images (product_id, position);
+create unique index product_images_primary_idx on product_images (product_id) where is_primary;
@@ -0,0 +1,6 @@
+alter table products
+    drop column attributes,
+    drop column category_id;
+
+drop table attribute_definitions;
+drop table categories;
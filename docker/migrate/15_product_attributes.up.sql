@@ -0,0 +1,22 @@
+create table categories (
+    category_id serial primary key,
+    name varchar(255) not null unique,
+    created_at timestamptz not null default now()
+);
+
+create table attribute_definitions (
+    category_id int not null references categories(category_id),
+    code varchar(64) not null,
+    name varchar(255) not null,
+    value_type varchar(16) not null check (value_type in ('string', 'number', 'bool', 'enum')),
+    required boolean not null default false,
+    options jsonb not null default '[]',
+    primary key (category_id, code)
+);
+
+alter table products
+    add column category_id int references categories(category_id),
+    add column attributes jsonb not null default '{}';
+
+create index products_category_idx on products (category_id);
+create index products_attributes_idx on products using gin (attributes);
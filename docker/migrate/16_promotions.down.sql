@@ -0,0 +1,5 @@
+alter table sales
+    drop column discount,
+    drop column promotion_id;
+
+drop table promotions;
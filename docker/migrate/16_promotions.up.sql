@@ -0,0 +1,24 @@
+create table promotions (
+    promotion_id serial primary key,
+    name varchar(255) not null,
+    kind varchar(16) not null check (kind in ('percent', 'fixed', 'buy_x_get_y')),
+    value numeric(12, 2) not null default 0 check (value >= 0),
+    buy_quantity int not null default 0 check (buy_quantity >= 0),
+    free_quantity int not null default 0 check (free_quantity >= 0),
+    variant_id int references product_variants(variant_id),
+    tag varchar(255),
+    category_id int references categories(category_id),
+    storage_id int references storages(storage_id),
+    min_amount numeric(12, 2) not null default 0 check (min_amount >= 0),
+    starts_at timestamptz not null default now(),
+    ends_at timestamptz,
+    created_at timestamptz not null default now(),
+    check (ends_at is null or ends_at > starts_at),
+    check (num_nonnulls(variant_id, tag, category_id) <= 1)
+);
+
+create index promotions_period_idx on promotions (starts_at, ends_at);
+
+alter table sales
+    add column promotion_id int references promotions(promotion_id),
+    add column discount numeric(12, 2) not null default 0;
@@ -0,0 +1,4 @@
+alter table stock_reservations drop column customer_id;
+alter table sales drop column customer_id;
+drop table customer_group_prices;
+drop table customers;
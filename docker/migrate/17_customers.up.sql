@@ -0,0 +1,28 @@
+create table customers (
+    customer_id serial primary key,
+    name varchar(255) not null,
+    phone varchar(64) not null default '',
+    customer_type varchar(32) not null default 'retail' check (customer_type in ('retail', 'wholesale')),
+    added_at timestamptz not null default now(),
+    removed_at timestamptz
+);
+
+create unique index customers_phone_idx on customers (phone) where phone <> '' and removed_at is null;
+create index customers_name_idx on customers (lower(name));
+
+create table customer_group_prices (
+    price_id serial primary key,
+    variant_id int not null references product_variants(variant_id),
+    customer_type varchar(32) not null check (customer_type in ('retail', 'wholesale')),
+    price numeric(10, 2) not null check (price > 0),
+    start_date timestamptz not null default now(),
+    end_date timestamptz,
+    check (end_date is null or end_date > start_date)
+);
+
+create unique index customer_group_prices_open_idx on customer_group_prices (variant_id, customer_type) where end_date is null;
+
+alter table sales add column customer_id int references customers(customer_id);
+create index sales_customer_idx on sales (customer_id, sold_at) where customer_id is not null;
+
+alter table stock_reservations add column customer_id int references customers(customer_id);
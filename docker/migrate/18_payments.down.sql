@@ -0,0 +1,2 @@
+drop index sales_sold_at_storage_idx;
+drop table sale_payments;
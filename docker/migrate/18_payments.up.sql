@@ -0,0 +1,10 @@
+create table sale_payments (
+    payment_id serial primary key,
+    sales_id int not null references sales(sales_id),
+    method varchar(32) not null check (method in ('cash', 'card', 'transfer')),
+    amount numeric(12, 2) not null check (amount > 0),
+    paid_at timestamptz not null default now()
+);
+
+create index sale_payments_sale_idx on sale_payments (sales_id);
+create index sales_sold_at_storage_idx on sales (sold_at, storage_id);
@@ -0,0 +1,13 @@
+create table outbox_events (
+    event_id bigserial primary key,
+    event_type varchar(255) not null,
+    aggregate_id int not null,
+    payload jsonb not null,
+    created_at timestamptz not null default now(),
+    published_at timestamptz,
+    attempts int not null default 0,
+    next_attempt_at timestamptz not null default now(),
+    last_error text
+);
+
+create index outbox_events_pending_idx on outbox_events (next_attempt_at, event_id) where published_at is null;
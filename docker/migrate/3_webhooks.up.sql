@@ -0,0 +1,27 @@
+create table webhook_subscriptions (
+    subscription_id serial primary key,
+    url text not null,
+    secret text not null,
+    event_types text[] not null,
+    added_at timestamptz not null default now(),
+    removed_at timestamptz
+);
+
+create table webhook_deliveries (
+    delivery_id bigserial primary key,
+    subscription_id int not null references webhook_subscriptions(subscription_id),
+    event_id bigint not null references outbox_events(event_id),
+    event_type varchar(255) not null,
+    payload jsonb not null,
+    occurred_at timestamptz not null,
+    status varchar(32) not null default 'pending',
+    attempts int not null default 0,
+    next_attempt_at timestamptz not null default now(),
+    last_status_code int,
+    last_error text,
+    delivered_at timestamptz,
+    unique (subscription_id, event_id)
+);
+
+create index webhook_deliveries_pending_idx on webhook_deliveries (next_attempt_at, delivery_id) where status = 'pending';
+create index webhook_deliveries_subscription_idx on webhook_deliveries (subscription_id, delivery_id);
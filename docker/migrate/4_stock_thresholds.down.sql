@@ -0,0 +1,2 @@
+drop table stock_write_offs;
+drop table stock_thresholds;
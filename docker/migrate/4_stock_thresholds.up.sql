@@ -0,0 +1,17 @@
+create table stock_thresholds (
+    variant_id int not null references product_variants(variant_id),
+    storage_id int not null references storages(storage_id),
+    min_quantity int not null check (min_quantity >= 0),
+    max_quantity int not null check (max_quantity >= min_quantity),
+    updated_at timestamptz not null default now(),
+    primary key (variant_id, storage_id)
+);
+
+create table stock_write_offs (
+    write_off_id serial primary key,
+    variant_id int not null references product_variants(variant_id),
+    storage_id int not null references storages(storage_id),
+    quantity int not null check (quantity > 0),
+    reason text not null,
+    written_off_at timestamptz not null default now()
+);
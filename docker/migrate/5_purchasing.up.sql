@@ -0,0 +1,49 @@
+create table suppliers (
+    supplier_id serial primary key,
+    name varchar(255) not null unique,
+    contact varchar(255) not null default '',
+    phone varchar(64) not null default '',
+    email varchar(255) not null default '',
+    added_at timestamptz not null default now(),
+    removed_at timestamptz
+);
+
+create table purchase_orders (
+    order_id serial primary key,
+    supplier_id int not null references suppliers(supplier_id),
+    storage_id int not null references storages(storage_id),
+    status varchar(32) not null default 'draft',
+    comment text not null default '',
+    created_at timestamptz not null default now(),
+    sent_at timestamptz,
+    closed_at timestamptz
+);
+
+create index purchase_orders_status_idx on purchase_orders (status, order_id);
+
+create table purchase_order_lines (
+    line_id serial primary key,
+    order_id int not null references purchase_orders(order_id),
+    variant_id int not null references product_variants(variant_id),
+    quantity int not null check (quantity > 0),
+    received_quantity int not null default 0 check (received_quantity <= quantity),
+    purchase_price decimal(10, 2) not null check (purchase_price >= 0),
+    closed_at timestamptz,
+    unique (order_id, variant_id)
+);
+
+create table goods_receipts (
+    receipt_id serial primary key,
+    order_id int not null references purchase_orders(order_id),
+    storage_id int not null references storages(storage_id),
+    received_at timestamptz not null default now()
+);
+
+create table goods_receipt_lines (
+    receipt_line_id serial primary key,
+    receipt_id int not null references goods_receipts(receipt_id),
+    line_id int not null references purchase_order_lines(line_id),
+    variant_id int not null references product_variants(variant_id),
+    quantity int not null check (quantity > 0),
+    purchase_price decimal(10, 2) not null
+);
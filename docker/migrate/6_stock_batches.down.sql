@@ -0,0 +1,2 @@
+alter table stock_write_offs drop column batch_id;
+drop table stock_batches;
@@ -0,0 +1,17 @@
+create table stock_batches (
+    batch_id serial primary key,
+    variant_id int not null references product_variants(variant_id),
+    storage_id int not null references storages(storage_id),
+    batch_number varchar(64) not null default '',
+    produced_at date,
+    expires_at date,
+    quantity int not null check (quantity >= 0),
+    receipt_id int references goods_receipts(receipt_id),
+    received_at timestamptz not null default now(),
+    check (expires_at is null or produced_at is null or expires_at >= produced_at)
+);
+
+create index stock_batches_variant_idx on stock_batches (variant_id, storage_id, expires_at) where quantity > 0;
+create index stock_batches_expires_idx on stock_batches (expires_at) where quantity > 0;
+
+alter table stock_write_offs add column batch_id int references stock_batches(batch_id);
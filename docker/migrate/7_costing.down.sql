@@ -0,0 +1,3 @@
+alter table sales drop column cost_of_goods;
+drop table stock_costs;
+drop table cost_layers;
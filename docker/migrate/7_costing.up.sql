@@ -0,0 +1,23 @@
+create table cost_layers (
+    layer_id serial primary key,
+    variant_id int not null references product_variants(variant_id),
+    storage_id int not null references storages(storage_id),
+    quantity int not null check (quantity > 0),
+    remaining int not null check (remaining >= 0 and remaining <= quantity),
+    unit_cost decimal(12, 4) not null check (unit_cost >= 0),
+    receipt_id int references goods_receipts(receipt_id),
+    received_at timestamptz not null default now()
+);
+
+create index cost_layers_variant_idx on cost_layers (variant_id, storage_id, layer_id) where remaining > 0;
+
+create table stock_costs (
+    variant_id int not null references product_variants(variant_id),
+    storage_id int not null references storages(storage_id),
+    quantity int not null default 0 check (quantity >= 0),
+    average_cost decimal(12, 4) not null default 0,
+    updated_at timestamptz not null default now(),
+    primary key (variant_id, storage_id)
+);
+
+alter table sales add column cost_of_goods decimal(10, 2) not null default 0;
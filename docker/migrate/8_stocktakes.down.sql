@@ -0,0 +1,3 @@
+alter table stock_write_offs drop column stocktake_id;
+drop table stocktake_lines;
+drop table stocktakes;
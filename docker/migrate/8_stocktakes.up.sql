@@ -0,0 +1,23 @@
+create table stocktakes (
+    stocktake_id serial primary key,
+    storage_id int not null references storages(storage_id),
+    status varchar(32) not null default 'open',
+    comment text not null default '',
+    created_at timestamptz not null default now(),
+    closed_at timestamptz
+);
+
+create unique index stocktakes_open_idx on stocktakes (storage_id) where status = 'open';
+
+create table stocktake_lines (
+    line_id serial primary key,
+    stocktake_id int not null references stocktakes(stocktake_id),
+    variant_id int not null references product_variants(variant_id),
+    expected_quantity int not null,
+    counted_quantity int check (counted_quantity >= 0),
+    counted_at timestamptz,
+    adjustment int,
+    unique (stocktake_id, variant_id)
+);
+
+alter table stock_write_offs add column stocktake_id int references stocktakes(stocktake_id);
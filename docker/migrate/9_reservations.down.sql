@@ -0,0 +1 @@
+drop table stock_reservations;
@@ -0,0 +1,15 @@
+create table stock_reservations (
+    reservation_id serial primary key,
+    variant_id int not null references product_variants(variant_id),
+    storage_id int not null references storages(storage_id),
+    quantity int not null check (quantity > 0),
+    status varchar(32) not null default 'active',
+    order_ref varchar(255) not null default '',
+    created_at timestamptz not null default now(),
+    expires_at timestamptz not null,
+    closed_at timestamptz,
+    sale_id int references sales(sales_id)
+);
+
+create index stock_reservations_active_idx on stock_reservations (variant_id, storage_id) where status = 'active';
+create index stock_reservations_expires_idx on stock_reservations (expires_at) where status = 'active';
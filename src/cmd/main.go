@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
 	"os"
 
+	"product_storage/config"
 	restapi "product_storage/external/restAPI"
+	"product_storage/internal/bridge"
 	"product_storage/internal/transaction"
 	"product_storage/rimport"
+	"product_storage/tools/amqpbroker"
 	"product_storage/tools/logger"
+	"product_storage/tools/memorybroker"
 	"product_storage/tools/pgdb"
+	"product_storage/tools/webhookclient"
 	"product_storage/uimport"
 )
 
@@ -33,6 +39,25 @@ func main() {
 
 	useCase := uimport.NewUsecaseImports(log, dbLog, repo, repo.SessionManager)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go useCase.Usecase.Product.RunCacheJanitor(ctx)
+	go useCase.Usecase.Reservation.RunExpiryRelease(ctx)
+
+	var publisher bridge.Publisher
+	switch repo.Config.Outbox.Publisher {
+	case config.PublisherMemory:
+		publisher = memorybroker.NewBroker()
+	default:
+		amqpPublisher := amqpbroker.NewPublisher(repo.Config.RabbitMQConnectURL(), repo.Config.Rabbit.Exchange)
+		defer amqpPublisher.Close()
+		publisher = amqpPublisher
+	}
+
+	go useCase.Usecase.Outbox.RunRelay(ctx, publisher)
+	go useCase.Usecase.Webhook.RunDispatcher(ctx, webhookclient.NewSender(repo.Config.WebhookTimeout()))
+
 	ginServer := restapi.NewGinServer(log, dbLog, useCase)
 	ginServer.Run()
 }
@@ -9,11 +9,106 @@ import (
 	"github.com/jinzhu/configor"
 )
 
+// defaultRequestTimeout таймаут запроса, если он не указан в конфиге
+const defaultRequestTimeout = 10 * time.Second
+
+// defaultBodyLimit максимальный размер тела запроса в байтах, если он не указан в конфиге
+const defaultBodyLimit = 1 << 20
+
+// defaultBatchSize кол-во записей, обрабатываемых фоновой задачей в одной транзакции, если оно не указано в конфиге
+const defaultBatchSize = 100
+
+// defaultWebhookMaxAttempts кол-во попыток доставки события, если оно не указано в конфиге
+const defaultWebhookMaxAttempts = 10
+
+// defaultWebhookTimeout таймаут запроса к получателю события, если он не указан в конфиге
+const defaultWebhookTimeout = 5 * time.Second
+
+const (
+	// CacheBackendMemory кэш в памяти процесса
+	CacheBackendMemory = "memory"
+	// CacheBackendRedis кэш в redis, общий для всех экземпляров сервиса
+	CacheBackendRedis = "redis"
+
+	// PublisherAMQP публикация событий в rabbitmq
+	PublisherAMQP = "amqp"
+	// PublisherMemory публикация событий в брокер в памяти процесса, для локального запуска
+	PublisherMemory = "memory"
+
+	// CostingFIFO себестоимость по первым поступившим партиям
+	CostingFIFO = "fifo"
+	// CostingAverage себестоимость по скользящей средневзвешенной цене
+	CostingAverage = "average"
+
+	// BlobStorageLocal файлы хранятся в каталоге локальной файловой системы и раздаются http сервером
+	BlobStorageLocal = "local"
+)
+
 // Config конфиг
 type Config struct {
 	Redis struct {
 		CacheTime time.Duration `yaml:"cacheTime"`
+		Prefix    string        `yaml:"prefix" default:"product_storage"` // префикс ключей кэша
 	} `yaml:"redis"`
+	Cache struct {
+		Backend string `yaml:"backend" default:"memory"` // хранилище кэша: memory или redis
+	} `yaml:"cache"`
+	Timeout struct {
+		Default  time.Duration            `yaml:"default" default:"10"` // таймаут запроса по умолчанию, в секундах
+		Endpoint map[string]time.Duration `yaml:"endpoint"`             // таймауты отдельных методов, в секундах
+	} `yaml:"timeout"`
+	Body struct {
+		Default  int64            `yaml:"default" default:"1024"` // максимальный размер тела запроса по умолчанию, в килобайтах
+		Endpoint map[string]int64 `yaml:"endpoint"`               // размеры тела запроса отдельных методов, в килобайтах
+	} `yaml:"body"`
+	Retry struct {
+		MaxAttempts int           `yaml:"maxAttempts" default:"5"` // максимальное кол-во попыток выполнения транзакции
+		BaseDelay   time.Duration `yaml:"baseDelay" default:"20"`  // задержка перед первым повтором, в миллисекундах
+		MaxDelay    time.Duration `yaml:"maxDelay" default:"1000"` // максимальная задержка между повторами, в миллисекундах
+	} `yaml:"retry"`
+	Outbox struct {
+		Publisher      string        `yaml:"publisher" default:"amqp"`    // брокер для публикации событий: amqp или memory
+		Interval       time.Duration `yaml:"interval" default:"1000"`     // период проверки неопубликованных событий, в миллисекундах
+		BatchSize      int           `yaml:"batchSize" default:"100"`     // кол-во событий, публикуемых в одной транзакции
+		RetryBaseDelay time.Duration `yaml:"retryBaseDelay" default:"1"`  // задержка перед первым повтором публикации, в секундах
+		RetryMaxDelay  time.Duration `yaml:"retryMaxDelay" default:"300"` // максимальная задержка между повторами публикации, в секундах
+	} `yaml:"outbox"`
+	Webhook struct {
+		Interval       time.Duration `yaml:"interval" default:"1000"`      // период проверки недоставленных событий, в миллисекундах
+		BatchSize      int           `yaml:"batchSize" default:"50"`       // кол-во доставок, забираемых на отправку за один раз
+		Timeout        time.Duration `yaml:"timeout" default:"5"`          // таймаут запроса к получателю, в секундах
+		MaxAttempts    int           `yaml:"maxAttempts" default:"10"`     // кол-во попыток доставки, после которых она считается неудачной
+		RetryBaseDelay time.Duration `yaml:"retryBaseDelay" default:"5"`   // задержка перед первым повтором доставки, в секундах
+		RetryMaxDelay  time.Duration `yaml:"retryMaxDelay" default:"3600"` // максимальная задержка между повторами доставки, в секундах
+	} `yaml:"webhook"`
+	Stream struct {
+		HistorySize int           `yaml:"historySize" default:"1000"` // кол-во последних изменений, хранимых для продолжения потока после переподключения
+		BufferSize  int           `yaml:"bufferSize" default:"64"`    // очередь изменений клиента, при переполнении клиент отключается
+		Heartbeat   time.Duration `yaml:"heartbeat" default:"15"`     // период отправки пустых сообщений, в секундах
+	} `yaml:"stream"`
+	Costing struct {
+		Method string `yaml:"method" default:"fifo"` // метод оценки себестоимости: fifo или average
+	} `yaml:"costing"`
+	Reservation struct {
+		DefaultTTL time.Duration `yaml:"defaultTTL" default:"30"` // срок резерва по умолчанию, в минутах
+		MaxTTL     time.Duration `yaml:"maxTTL" default:"10080"`  // максимальный срок резерва, в минутах
+		Interval   time.Duration `yaml:"interval" default:"60"`   // период снятия истекших резервов, в секундах
+		BatchSize  int           `yaml:"batchSize" default:"100"` // кол-во резервов, снимаемых в одной транзакции
+	} `yaml:"reservation"`
+	Image struct {
+		Storage   string `yaml:"storage" default:"local"`  // хранилище изображений: local
+		Root      string `yaml:"root" default:"./media"`   // каталог хранилища local
+		BaseURL   string `yaml:"baseURL" default:"/media"` // адрес, по которому раздаются файлы хранилища
+		MaxSize   int64  `yaml:"maxSize" default:"10"`     // максимальный размер загружаемого изображения, в мегабайтах
+		MaxPixels int64  `yaml:"maxPixels" default:"40"`   // максимальное кол-во пикселей изображения, в мегапикселях
+		ThumbSize int    `yaml:"thumbSize" default:"320"`  // большая сторона превью, в пикселях
+	} `yaml:"image"`
+	Payment struct {
+		DefaultMethod string `yaml:"defaultMethod" default:"cash"` // способ оплаты продажи, в которой оплаты не указаны
+	} `yaml:"payment"`
+	Rabbit struct {
+		Exchange string `yaml:"exchange" default:"product_storage.events"` // exchange доменных событий
+	} `yaml:"rabbit"`
 }
 
 // NewConfig init and return project config
@@ -62,6 +157,157 @@ func (c *Config) RedisCacheLifetime() time.Duration {
 	return c.Redis.CacheTime * time.Hour
 }
 
+// RedisCacheEnabled кэш хранится в redis
+func (c *Config) RedisCacheEnabled() bool {
+	return c.Cache.Backend == CacheBackendRedis
+}
+
+// RequestTimeout таймаут запроса для метода api, если для метода он не задан используется таймаут по умолчанию
+func (c *Config) RequestTimeout(method string) time.Duration {
+	if timeout, exists := c.Timeout.Endpoint[method]; exists && timeout > 0 {
+		return timeout * time.Second
+	}
+
+	if c.Timeout.Default > 0 {
+		return c.Timeout.Default * time.Second
+	}
+
+	return defaultRequestTimeout
+}
+
+// RequestBodyLimit максимальный размер тела запроса метода api в байтах,
+// если для метода он не задан используется размер по умолчанию
+func (c *Config) RequestBodyLimit(method string) int64 {
+	if limit, exists := c.Body.Endpoint[method]; exists && limit > 0 {
+		return limit << 10
+	}
+
+	if c.Body.Default > 0 {
+		return c.Body.Default << 10
+	}
+
+	return defaultBodyLimit
+}
+
+// RetryBaseDelay задержка перед первым повтором транзакции
+func (c *Config) RetryBaseDelay() time.Duration {
+	return c.Retry.BaseDelay * time.Millisecond
+}
+
+// RetryMaxDelay максимальная задержка между повторами транзакции
+func (c *Config) RetryMaxDelay() time.Duration {
+	return c.Retry.MaxDelay * time.Millisecond
+}
+
+// OutboxInterval период проверки неопубликованных событий
+func (c *Config) OutboxInterval() time.Duration {
+	return c.Outbox.Interval * time.Millisecond
+}
+
+// OutboxBatchSize кол-во событий, публикуемых в одной транзакции
+func (c *Config) OutboxBatchSize() int {
+	if c.Outbox.BatchSize > 0 {
+		return c.Outbox.BatchSize
+	}
+
+	return defaultBatchSize
+}
+
+// OutboxRetryBaseDelay задержка перед первым повтором публикации события
+func (c *Config) OutboxRetryBaseDelay() time.Duration {
+	return c.Outbox.RetryBaseDelay * time.Second
+}
+
+// OutboxRetryMaxDelay максимальная задержка между повторами публикации события
+func (c *Config) OutboxRetryMaxDelay() time.Duration {
+	return c.Outbox.RetryMaxDelay * time.Second
+}
+
+// WebhookInterval период проверки недоставленных событий
+func (c *Config) WebhookInterval() time.Duration {
+	return c.Webhook.Interval * time.Millisecond
+}
+
+// WebhookBatchSize кол-во доставок, выполняемых за один проход
+func (c *Config) WebhookBatchSize() int {
+	if c.Webhook.BatchSize > 0 {
+		return c.Webhook.BatchSize
+	}
+
+	return defaultBatchSize
+}
+
+// WebhookMaxAttempts кол-во попыток доставки, после которых она считается неудачной
+func (c *Config) WebhookMaxAttempts() int {
+	if c.Webhook.MaxAttempts > 0 {
+		return c.Webhook.MaxAttempts
+	}
+
+	return defaultWebhookMaxAttempts
+}
+
+// WebhookTimeout таймаут запроса к получателю события
+func (c *Config) WebhookTimeout() time.Duration {
+	if c.Webhook.Timeout > 0 {
+		return c.Webhook.Timeout * time.Second
+	}
+
+	return defaultWebhookTimeout
+}
+
+// WebhookRetryBaseDelay задержка перед первым повтором доставки события
+func (c *Config) WebhookRetryBaseDelay() time.Duration {
+	return c.Webhook.RetryBaseDelay * time.Second
+}
+
+// WebhookRetryMaxDelay максимальная задержка между повторами доставки события
+func (c *Config) WebhookRetryMaxDelay() time.Duration {
+	return c.Webhook.RetryMaxDelay * time.Second
+}
+
+// StreamHeartbeat период отправки пустых сообщений в поток
+func (c *Config) StreamHeartbeat() time.Duration {
+	return c.Stream.Heartbeat * time.Second
+}
+
+// ReservationTTL срок резерва в минутах, при ttl = 0 срок по умолчанию, срок ограничен максимальным
+func (c *Config) ReservationTTL(ttl int) time.Duration {
+	d := time.Duration(ttl)
+	if d <= 0 {
+		d = c.Reservation.DefaultTTL
+	}
+
+	if c.Reservation.MaxTTL > 0 && d > c.Reservation.MaxTTL {
+		d = c.Reservation.MaxTTL
+	}
+
+	return d * time.Minute
+}
+
+// ReservationInterval период снятия истекших резервов
+func (c *Config) ReservationInterval() time.Duration {
+	return c.Reservation.Interval * time.Second
+}
+
+// ReservationBatchSize кол-во резервов, снимаемых в одной транзакции
+func (c *Config) ReservationBatchSize() int {
+	if c.Reservation.BatchSize > 0 {
+		return c.Reservation.BatchSize
+	}
+
+	return defaultBatchSize
+}
+
+// ImageMaxSize максимальный размер загружаемого изображения в байтах
+func (c *Config) ImageMaxSize() int64 {
+	return c.Image.MaxSize << 20
+}
+
+// ImageMaxPixels максимальное кол-во пикселей загружаемого изображения, ограничивает память на его декодирование
+func (c *Config) ImageMaxPixels() int64 {
+	return c.Image.MaxPixels * 1_000_000
+}
+
 // RabbitMQConnectURL подключение к rabbitmq
 func (c *Config) RabbitMQConnectURL() string {
 	rabbitURL := os.Getenv("RABBIT_URL")
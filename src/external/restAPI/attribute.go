@@ -0,0 +1,54 @@
+package restapi
+
+import (
+	"product_storage/internal/entity/attribute"
+	"product_storage/internal/transaction"
+
+	"github.com/gin-gonic/gin"
+)
+
+// addCategory создает категорию продуктов с описаниями атрибутов
+func (e *GinServer) addCategory(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params attribute.CategoryParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Attribute.AddCategory(ts, params)
+}
+
+// addAttributeDefinition добавляет атрибут в категорию
+func (e *GinServer) addAttributeDefinition(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params attribute.Definition
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Attribute.AddDefinition(ts, params); err != nil {
+		return nil, err
+	}
+
+	return "успешно добавлено", nil
+}
+
+// findCategoryList выводит категории продуктов с атрибутами
+func (e *GinServer) findCategoryList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	return e.Usecase.Attribute.FindCategoryList(ts)
+}
+
+// updateProductAttributes меняет категорию и значения атрибутов продукта
+func (e *GinServer) updateProductAttributes(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params attribute.ProductParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Attribute.UpdateProductAttributes(ts, params); err != nil {
+		return nil, err
+	}
+
+	return "успешно изменено", nil
+}
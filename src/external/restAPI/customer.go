@@ -0,0 +1,103 @@
+package restapi
+
+import (
+	"product_storage/internal/entity/customer"
+	"product_storage/internal/transaction"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// addCustomer добавляет покупателя
+func (e *GinServer) addCustomer(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params customer.Params
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Customer.AddCustomer(ts, params)
+}
+
+// findCustomer выводит покупателя
+func (e *GinServer) findCustomer(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	customerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Customer.LoadCustomer(ts, customerID)
+}
+
+// findCustomerList ищет покупателей по части имени или телефона и типу
+func (e *GinServer) findCustomerList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	return e.Usecase.Customer.FindCustomerList(ts, c.Query("search"), c.Query("type"), limit)
+}
+
+// updateCustomer изменяет покупателя
+func (e *GinServer) updateCustomer(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params customer.UpdateParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Customer.UpdateCustomer(ts, params); err != nil {
+		return nil, err
+	}
+
+	return "успешно изменено", nil
+}
+
+// deleteCustomer удаляет покупателя
+func (e *GinServer) deleteCustomer(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params struct {
+		CustomerID int `json:"customer_id"`
+	}
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Customer.RemoveCustomer(ts, params.CustomerID); err != nil {
+		return nil, err
+	}
+
+	return "успешно удалено", nil
+}
+
+// findCustomerHistory выводит историю покупок покупателя
+func (e *GinServer) findCustomerHistory(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	customerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	return e.Usecase.Customer.FindHistory(ts, customerID, limit)
+}
+
+// setGroupPrice устанавливает цену варианта для группы покупателей
+func (e *GinServer) setGroupPrice(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params customer.GroupPriceParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Customer.SetGroupPrice(ts, params)
+}
+
+// findGroupPriceList выводит действующие цены групп покупателей
+func (e *GinServer) findGroupPriceList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	return e.Usecase.Customer.FindGroupPriceList(ts, c.Query("type"))
+}
@@ -1,6 +1,11 @@
 package restapi
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"product_storage/config"
+	"product_storage/internal/transaction"
 	"product_storage/uimport"
 
 	"github.com/gin-gonic/gin"
@@ -11,13 +16,22 @@ type GinServer struct {
 	server *gin.Engine
 	log    *logrus.Logger
 	dbLog  *logrus.Logger
+	// retrier выполнение транзакций запросов с повтором при конфликтах
+	retrier *transaction.Retrier
 	uimport.UsecaseImports
 }
 
 func NewGinServer(log, dblog *logrus.Logger, U uimport.UsecaseImports) *GinServer {
+	retryPolicy := transaction.RetryPolicy{
+		MaxAttempts: U.Config.Retry.MaxAttempts,
+		BaseDelay:   U.Config.RetryBaseDelay(),
+		MaxDelay:    U.Config.RetryMaxDelay(),
+	}
+
 	return &GinServer{
 		log:            log,
 		dbLog:          dblog,
+		retrier:        transaction.NewRetrier(U.SessionManager, retryPolicy, log),
 		UsecaseImports: U,
 	}
 }
@@ -39,17 +53,125 @@ func (e *GinServer) Run() {
 		c.Next()
 	})
 
-	e.server.POST("/product/add", e.addProduct)
-	e.server.POST("/product/price", e.addProductPrice)
-	e.server.POST("/product/add/stock", e.addProductInStock)
-	e.server.GET("/product/:id", e.findProductInfoById)
-	e.server.GET("/product_list", e.findProductList)
-	e.server.GET("/stock", e.findProductListInStock)
-	e.server.POST("/buy", e.SaveSale)
-	e.server.POST("/sales", e.FindSaleList)
-	e.server.GET("/stock_list", e.LoadStockList)
-	e.server.POST("/stock/add", e.AddStock)
-	e.server.DELETE("/stock/delete", e.DeleteStock)
+	e.server.POST("/product/add", e.inSession("product_add", "product_id", e.addProduct))
+	e.server.POST("/product/price", e.inSession("product_price", "price_id", e.addProductPrice))
+	e.server.POST("/product/price/bulk/preview", e.inSession("price_bulk_preview", "preview", e.previewReprice, transaction.ReadOnly()))
+	e.server.POST("/product/price/bulk/apply", e.inSession("price_bulk_apply", "result", e.applyReprice, transaction.Serializable()))
+	e.server.POST("/product/add/stock", e.inSession("product_add_stock", "product_stock_ID", e.addProductInStock, transaction.Serializable()))
+	e.server.GET("/product/:id", e.inSession("product_info", "product_info", e.findProductInfoById, transaction.ReadOnly()))
+	e.server.GET("/product_list", e.inSession("product_list", "product_list", e.findProductList, transaction.ReadOnly()))
+	e.server.GET("/stock", e.inSession("stock", "stock_list", e.findProductListInStock, transaction.ReadOnly()))
+	e.server.GET("/variant/by-barcode/:code", e.inSession("variant_by_barcode", "variant", e.findVariantByBarcode, transaction.ReadOnly()))
+	e.server.POST("/variant/barcode/add", e.inSession("variant_barcode_add", "status", e.addVariantBarcode, transaction.Serializable()))
+	e.server.POST("/buy", e.inSession("buy", "sale_id", e.SaveSale, transaction.Serializable()))
+	e.server.POST("/sales", e.inSession("sales", "sale_list", e.FindSaleList, transaction.ReadOnly()))
+	e.server.GET("/sale/:id/payments", e.inSession("sale_payments", "payment_list", e.findSalePaymentList, transaction.ReadOnly()))
+	e.server.GET("/report/valuation", e.inSession("report_valuation", "valuation", e.valuationReport, transaction.ReadOnly()))
+	e.server.POST("/report/margin", e.inSession("report_margin", "margin", e.marginReport, transaction.ReadOnly()))
+	e.server.POST("/report/z", e.inSession("report_z", "z_report", e.zReport, transaction.ReadOnly()))
+	e.server.GET("/stock/stream", e.stockStream)
+	e.server.POST("/stock/threshold", e.inSession("stock_threshold", "status", e.saveStockThreshold))
+	e.server.GET("/stock/low", e.inSession("stock_low", "low_stock_list", e.findLowStockList, transaction.ReadOnly()))
+	e.server.POST("/stock/write_off", e.inSession("stock_write_off", "write_off_id", e.writeOff, transaction.Serializable()))
+	e.server.GET("/stock/batch_list", e.inSession("stock_batch_list", "batch_list", e.findBatchList, transaction.ReadOnly()))
+	e.server.GET("/stock/batch/expiring", e.inSession("stock_batch_expiring", "batch_list", e.findExpiringBatchList, transaction.ReadOnly()))
+	e.server.POST("/stock/batch/write_off_expired", e.inSession("stock_batch_write_off_expired", "write_off_id_list", e.writeOffExpired, transaction.Serializable()))
+	e.server.GET("/stock_list", e.inSession("stock_list", "stock_list", e.LoadStockList, transaction.ReadOnly()))
+	e.server.POST("/stock/add", e.inSession("stock_add", "stockID", e.AddStock))
+	e.server.POST("/stock/update", e.inSession("stock_update", "status", e.UpdateStock, transaction.Serializable()))
+	e.server.DELETE("/stock/delete", e.inSession("stock_delete", "status", e.DeleteStock, transaction.Serializable()))
+
+	e.server.POST("/product/:id/image", e.inSession(imageUploadMethod, "image_id", e.uploadImage))
+	e.server.GET("/product/:id/images", e.inSession("product_image_list", "image_list", e.findImageList, transaction.ReadOnly()))
+	e.server.POST("/product/image/primary", e.inSession("product_image_primary", "status", e.setPrimaryImage, transaction.Serializable()))
+	e.server.POST("/product/image/order", e.inSession("product_image_order", "status", e.reorderImages, transaction.Serializable()))
+	e.server.DELETE("/product/image/delete", e.inSession("product_image_delete", "status", e.deleteImage, transaction.Serializable()))
+	if e.Config.Image.Storage == config.BlobStorageLocal {
+		e.server.Static(e.Config.Image.BaseURL, e.Config.Image.Root)
+	}
+
+	e.server.POST("/category/add", e.inSession("category_add", "category_id", e.addCategory, transaction.Serializable()))
+	e.server.POST("/category/attribute/add", e.inSession("category_attribute_add", "status", e.addAttributeDefinition, transaction.Serializable()))
+	e.server.GET("/category_list", e.inSession("category_list", "category_list", e.findCategoryList, transaction.ReadOnly()))
+	e.server.POST("/product/attributes", e.inSession("product_attributes", "status", e.updateProductAttributes, transaction.Serializable()))
+
+	e.server.POST("/promotion/add", e.inSession("promotion_add", "promotion_id", e.addPromotion))
+	e.server.GET("/promotion_list", e.inSession("promotion_list", "promotion_list", e.findPromotionList, transaction.ReadOnly()))
+	e.server.POST("/promotion/end", e.inSession("promotion_end", "status", e.endPromotion, transaction.Serializable()))
+	e.server.POST("/sale/quote", e.inSession("sale_quote", "quote", e.quoteSale, transaction.ReadOnly()))
+
+	e.server.POST("/customer/add", e.inSession("customer_add", "customer_id", e.addCustomer, transaction.Serializable()))
+	e.server.GET("/customer/:id", e.inSession("customer", "customer", e.findCustomer, transaction.ReadOnly()))
+	e.server.GET("/customer_list", e.inSession("customer_list", "customer_list", e.findCustomerList, transaction.ReadOnly()))
+	e.server.POST("/customer/update", e.inSession("customer_update", "status", e.updateCustomer, transaction.Serializable()))
+	e.server.DELETE("/customer/delete", e.inSession("customer_delete", "status", e.deleteCustomer))
+	e.server.GET("/customer/:id/history", e.inSession("customer_history", "history", e.findCustomerHistory, transaction.ReadOnly()))
+	e.server.POST("/customer/price/set", e.inSession("customer_price_set", "price_id", e.setGroupPrice, transaction.Serializable()))
+	e.server.GET("/customer_price_list", e.inSession("customer_price_list", "price_list", e.findGroupPriceList, transaction.ReadOnly()))
+
+	e.server.POST("/unit/add", e.inSession("unit_add", "status", e.addUnit))
+	e.server.GET("/unit_list", e.inSession("unit_list", "unit_list", e.findUnitList, transaction.ReadOnly()))
+	e.server.GET("/unit/convert", e.inSession("unit_convert", "value", e.convertUnit, transaction.ReadOnly()))
+
+	e.server.POST("/location/add", e.inSession("location_add", "location_id", e.addLocation))
+	e.server.GET("/location_list", e.inSession("location_list", "location_list", e.findLocationList, transaction.ReadOnly()))
+	e.server.POST("/location/putaway", e.inSession("location_putaway", "status", e.putaway, transaction.Serializable()))
+	e.server.POST("/location/move", e.inSession("location_move", "status", e.moveBetweenLocations, transaction.Serializable()))
+
+	e.server.POST("/supplier/add", e.inSession("supplier_add", "supplier_id", e.addSupplier))
+	e.server.GET("/supplier_list", e.inSession("supplier_list", "supplier_list", e.findSupplierList, transaction.ReadOnly()))
+	e.server.POST("/purchase_order/add", e.inSession("purchase_order_add", "order_id", e.addPurchaseOrder))
+	e.server.GET("/purchase_order/:id", e.inSession("purchase_order", "order", e.findPurchaseOrder, transaction.ReadOnly()))
+	e.server.GET("/purchase_order_list", e.inSession("purchase_order_list", "order_list", e.findPurchaseOrderList, transaction.ReadOnly()))
+	e.server.POST("/purchase_order/send", e.inSession("purchase_order_send", "status", e.sendPurchaseOrder, transaction.Serializable()))
+	e.server.POST("/purchase_order/cancel", e.inSession("purchase_order_cancel", "status", e.cancelPurchaseOrder, transaction.Serializable()))
+	e.server.POST("/purchase_order/receive", e.inSession("purchase_order_receive", "receipt_id", e.receiveGoods, transaction.Serializable()))
+
+	e.server.POST("/stocktake/start", e.inSession("stocktake_start", "stocktake_id", e.startStocktake, transaction.Serializable()))
+	e.server.POST("/stocktake/count", e.inSession("stocktake_count", "status", e.saveStocktakeCount))
+	e.server.GET("/stocktake_list", e.inSession("stocktake_list", "stocktake_list", e.findStocktakeList, transaction.ReadOnly()))
+	e.server.GET("/stocktake/:id/variance", e.inSession("stocktake_variance", "variance", e.stocktakeVarianceReport, transaction.ReadOnly()))
+	e.server.POST("/stocktake/post", e.inSession("stocktake_post", "status", e.postStocktake, transaction.Serializable()))
+	e.server.POST("/stocktake/cancel", e.inSession("stocktake_cancel", "status", e.cancelStocktake, transaction.Serializable()))
+
+	e.server.POST("/reservation/add", e.inSession("reservation_add", "reservation_id", e.reserve, transaction.Serializable()))
+	e.server.POST("/reservation/release", e.inSession("reservation_release", "status", e.releaseReservation, transaction.Serializable()))
+	e.server.POST("/reservation/convert", e.inSession("reservation_convert", "sale_id", e.convertReservation, transaction.Serializable()))
+	e.server.GET("/reservation_list", e.inSession("reservation_list", "reservation_list", e.findReservationList, transaction.ReadOnly()))
+
+	e.server.POST("/webhook/add", e.inSession("webhook_add", "subscription_id", e.addWebhook))
+	e.server.GET("/webhook_list", e.inSession("webhook_list", "subscription_list", e.findWebhookList, transaction.ReadOnly()))
+	e.server.DELETE("/webhook/delete", e.inSession("webhook_delete", "status", e.deleteWebhook))
+	e.server.GET("/webhook/:id/deliveries", e.inSession("webhook_deliveries", "delivery_list", e.findWebhookDeliveryList, transaction.ReadOnly()))
+
+	e.server.GET("/stats/transactions", e.transactionStats)
+	e.server.GET("/stats/cache", e.cacheStats)
+	e.server.GET("/stats/outbox", e.outboxStats)
+	e.server.GET("/stats/webhooks", e.webhookStats)
 
 	e.server.Run(":9000")
 }
+
+// requestContext контекст запроса с таймаутом, заданным в конфиге для метода;
+// контекст отменяется и при разрыве соединения клиентом
+func (e *GinServer) requestContext(c *gin.Context, method string) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.Request.Context(), e.Config.RequestTimeout(method))
+}
+
+// bodyLimit максимальный размер тела запроса метода, для загрузки изображения он определяется размером изображения
+func (e *GinServer) bodyLimit(method string) int64 {
+	if method == imageUploadMethod {
+		return e.Config.ImageMaxSize() + multipartOverhead
+	}
+
+	return e.Config.RequestBodyLimit(method)
+}
+
+// errorStatus http статус ошибки с учетом истечения таймаута запроса
+func errorStatus(ctx context.Context) int {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+
+	return http.StatusInternalServerError
+}
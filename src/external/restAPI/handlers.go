@@ -1,10 +1,11 @@
 package restapi
 
 import (
-	"log"
 	"net/http"
+	"product_storage/internal/entity/attribute"
 	"product_storage/internal/entity/product"
 	"product_storage/internal/entity/stock"
+	"product_storage/internal/transaction"
 	"product_storage/tools/response"
 	"strconv"
 	"time"
@@ -13,132 +14,51 @@ import (
 )
 
 // addProduct добавление товара
-func (e *GinServer) addProduct(c *gin.Context) {
-	ts := e.SessionManager.CreateSession()
-	err := ts.Start()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
-	}
-	defer ts.Rollback()
+func (e *GinServer) addProduct(c *gin.Context, ts transaction.Session) (interface{}, error) {
 	var product product.ProductParams
 
 	if err := c.ShouldBindJSON(&product); err != nil {
-		c.JSON(http.StatusBadRequest, response.NewErrorResponse(err))
-		return
-	}
-
-	productID, err := e.Usecase.Product.AddProduct(ts, product)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
+		return nil, badRequest(err)
 	}
 
-	if err := ts.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
-	}
-
-	c.JSON(http.StatusOK, response.NewSuccessResponse(productID, "product_id"))
+	return e.Usecase.Product.AddProduct(ts, product)
 }
 
 // addProductPrice добавляет цену продукта
-func (e *GinServer) addProductPrice(c *gin.Context) {
-	ts := e.SessionManager.CreateSession()
-	err := ts.Start()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
-	}
-	defer ts.Rollback()
-
+func (e *GinServer) addProductPrice(c *gin.Context, ts transaction.Session) (interface{}, error) {
 	var productPrice product.ProductPriceParams
 
 	if err := c.ShouldBindJSON(&productPrice); err != nil {
-		c.JSON(http.StatusBadRequest, response.NewErrorResponse(err))
-		return
+		return nil, badRequest(err)
 	}
 
-	priceID, err := e.Usecase.Product.AddProductPrice(ts, productPrice)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
-	}
-
-	if err := ts.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
-	}
-
-	c.JSON(http.StatusOK, response.NewSuccessResponse(priceID, "price_id"))
+	return e.Usecase.Product.AddProductPrice(ts, productPrice)
 }
 
 // addProductInStock добавляет продукт в склад
-func (e *GinServer) addProductInStock(c *gin.Context) {
-	ts := e.SessionManager.CreateSession()
-	err := ts.Start()
-	if err != nil {
-		c.JSON(http.StatusOK, response.NewErrorResponse(err))
-		return
-	}
-	defer ts.Rollback()
-
+func (e *GinServer) addProductInStock(c *gin.Context, ts transaction.Session) (interface{}, error) {
 	var addProduct stock.ProductInStockParams
 
 	if err := c.ShouldBindJSON(&addProduct); err != nil {
-		c.JSON(http.StatusBadRequest, response.NewErrorResponse(err))
-		return
-	}
-
-	productStockID, err := e.Usecase.Product.AddProductInStock(ts, addProduct)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
+		return nil, badRequest(err)
 	}
 
-	if err := ts.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
-	}
-
-	c.JSON(http.StatusOK, response.NewSuccessResponse(productStockID, "product_stock_ID"))
+	return e.Usecase.Product.AddProductInStock(ts, addProduct)
 }
 
 // findProductInfoById выводит данные о продукте по его id
-func (e *GinServer) findProductInfoById(c *gin.Context) {
-	ts := e.SessionManager.CreateSession()
-	err := ts.Start()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-	}
-	defer ts.Rollback()
-
+func (e *GinServer) findProductInfoById(c *gin.Context, ts transaction.Session) (interface{}, error) {
 	id := c.Param("id")
 	productID, err := strconv.Atoi(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, response.NewErrorResponse(err))
-		return
-	}
-
-	productInfo, err := e.Usecase.Product.FindProductInfoById(ts, productID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
+		return nil, badRequest(err)
 	}
 
-	c.JSON(http.StatusOK, response.NewSuccessResponse(productInfo, "product_info"))
+	return e.Usecase.Product.FindProductInfoById(ts, productID)
 }
 
-// findProductList выводит список продуктов по тегам и лимитам
-func (e *GinServer) findProductList(c *gin.Context) {
-	ts := e.SessionManager.CreateSession()
-	err := ts.Start()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
-	}
-	defer ts.Rollback()
-
+// findProductList выводит список продуктов по тегам, категории, атрибутам и лимитам
+func (e *GinServer) findProductList(c *gin.Context, ts transaction.Session) (interface{}, error) {
 	tag := c.Query("tag")
 	productName := c.Query("name")
 	limitstr := c.Query("limit")
@@ -147,25 +67,19 @@ func (e *GinServer) findProductList(c *gin.Context) {
 		limit = 3
 	}
 
-	productList, err := e.Usecase.Product.FindProductList(ts, tag, productName, limit)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
+	// отбор по атрибутам передается параметрами вида attr[fat]=3.2
+	filter := attribute.Filter{Values: c.QueryMap("attr")}
+	if value := c.Query("category_id"); value != "" {
+		if filter.CategoryID, err = strconv.Atoi(value); err != nil {
+			return nil, badRequest(err)
+		}
 	}
 
-	c.JSON(http.StatusOK, response.NewSuccessResponse(productList, "product_list"))
+	return e.Usecase.Product.FindProductList(ts, tag, productName, limit, filter)
 }
 
 // findProductListInStock выводит информацию о складах и продуктах в них
-func (e *GinServer) findProductListInStock(c *gin.Context) {
-	ts := e.SessionManager.CreateSession()
-	err := ts.Start()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
-	}
-	defer ts.Rollback()
-
+func (e *GinServer) findProductListInStock(c *gin.Context, ts transaction.Session) (interface{}, error) {
 	id := c.Query("product_id")
 	if id == "" {
 		id = "0"
@@ -173,160 +87,122 @@ func (e *GinServer) findProductListInStock(c *gin.Context) {
 
 	productId, err := strconv.Atoi(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, response.NewErrorResponse(err))
-		return
+		return nil, badRequest(err)
 	}
 
-	stockList, err := e.Usecase.Product.FindProductsInStock(ts, productId)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
+	byLocation := false
+	if value := c.Query("by_location"); value != "" {
+		if byLocation, err = strconv.ParseBool(value); err != nil {
+			return nil, badRequest(err)
+		}
 	}
 
-	c.JSON(http.StatusOK, response.NewSuccessResponse(stockList, "stock_list"))
+	return e.Usecase.Product.FindProductsInStock(ts, productId, byLocation)
 }
 
-// buy запись сделанной продажи в базу
-func (e *GinServer) SaveSale(c *gin.Context) {
-	ts := e.SessionManager.CreateSession()
-	err := ts.Start()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
+// findVariantByBarcode выводит вариант по штрихкоду с ценой и доступным кол-вом
+func (e *GinServer) findVariantByBarcode(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	return e.Usecase.Product.FindVariantByBarcode(ts, c.Param("code"))
+}
+
+// addVariantBarcode привязывает штрихкод к варианту
+func (e *GinServer) addVariantBarcode(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params product.BarcodeParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
 	}
-	defer ts.Rollback()
 
+	if err := e.Usecase.Product.AddVariantBarcode(ts, params); err != nil {
+		return nil, err
+	}
+
+	return "успешно добавлено", nil
+}
+
+// SaveSale запись сделанной продажи в базу
+func (e *GinServer) SaveSale(c *gin.Context, ts transaction.Session) (interface{}, error) {
 	var sale product.SaleParams
 
 	if err := c.ShouldBindJSON(&sale); err != nil {
-		c.JSON(http.StatusBadRequest, response.NewErrorResponse(err))
-		return
+		return nil, badRequest(err)
 	}
 	sale.SoldAt = time.Now()
 
-	saleID, err := e.Usecase.Product.SaveSale(ts, sale)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
-	}
-
-	if err := ts.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
-	}
-
-	c.JSON(http.StatusOK, response.NewSuccessResponse(saleID, "sale_id"))
+	return e.Usecase.Product.SaveSale(ts, sale)
 }
 
-// findSales выводит информацию о продажах по фильтрам или без них
-func (e *GinServer) FindSaleList(c *gin.Context) {
-	ts := e.SessionManager.CreateSession()
-	err := ts.Start()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
-	}
-	defer ts.Rollback()
-
+// FindSaleList выводит информацию о продажах по фильтрам или без них
+func (e *GinServer) FindSaleList(c *gin.Context, ts transaction.Session) (interface{}, error) {
 	var saleQuery product.SaleQueryParam
 
 	if err := c.ShouldBindJSON(&saleQuery); err != nil {
-		log.Println(err)
-		c.JSON(http.StatusBadRequest, response.NewErrorResponse(err))
-		return
+		return nil, badRequest(err)
 	}
-	log.Println(saleQuery)
 
 	if saleQuery.ProductName.String == "" {
 		saleQuery.ProductName.Valid = false
 	}
 
-	saleList, err := e.Usecase.Product.FindSaleList(ts, saleQuery)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
-	}
-
-	c.JSON(http.StatusOK, response.NewSuccessResponse(saleList, "sale_list"))
+	return e.Usecase.Product.FindSaleList(ts, saleQuery)
 }
 
-func (e *GinServer) LoadStockList(c *gin.Context) {
-	ts := e.SessionManager.CreateSession()
-	err := ts.Start()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
+// LoadStockList выводит список складов, удаленные склады выводятся при removed=true
+func (e *GinServer) LoadStockList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	withRemoved := false
+	if value := c.Query("removed"); value != "" {
+		var err error
+		if withRemoved, err = strconv.ParseBool(value); err != nil {
+			return nil, badRequest(err)
+		}
 	}
-	defer ts.Rollback()
 
-	stockList, err := e.Usecase.Product.LoadStockList(ts)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
-	}
+	return e.Usecase.Product.LoadStockList(ts, withRemoved)
+}
 
-	if err := ts.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
+// AddStock добавляет склад
+func (e *GinServer) AddStock(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var stockParams stock.StockParams
+	if err := c.ShouldBindJSON(&stockParams); err != nil {
+		return nil, badRequest(err)
 	}
 
-	c.JSON(http.StatusOK, response.NewSuccessResponse(stockList, "stock_list"))
+	return e.Usecase.Product.AddStock(ts, stockParams)
 }
 
-func (e *GinServer) AddStock(c *gin.Context) {
-	ts := e.SessionManager.CreateSession()
-	err := ts.Start()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
-	}
-	defer ts.Rollback()
-
+// UpdateStock изменяет склад
+func (e *GinServer) UpdateStock(c *gin.Context, ts transaction.Session) (interface{}, error) {
 	var stockParams stock.StockParams
 	if err := c.ShouldBindJSON(&stockParams); err != nil {
-		c.JSON(http.StatusBadRequest, response.NewErrorResponse(err))
-		return
-	}
-
-	stockID, err := e.Usecase.Product.AddStock(ts, stockParams)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
+		return nil, badRequest(err)
 	}
 
-	if err := ts.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
+	if err := e.Usecase.Product.UpdateStock(ts, stockParams); err != nil {
+		return nil, err
 	}
 
-	c.JSON(http.StatusOK, response.NewSuccessResponse(stockID, "stockID"))
+	return "успешно изменено", nil
 }
 
-func (e *GinServer) DeleteStock(c *gin.Context) {
-	ts := e.SessionManager.CreateSession()
-	err := ts.Start()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
-	}
-	defer ts.Rollback()
-
+// DeleteStock удаляет склад
+func (e *GinServer) DeleteStock(c *gin.Context, ts transaction.Session) (interface{}, error) {
 	var stockParams stock.StockParams
 	if err := c.ShouldBindJSON(&stockParams); err != nil {
-		c.JSON(http.StatusBadRequest, response.NewErrorResponse(err))
-		return
+		return nil, badRequest(err)
 	}
 
-	err = e.Usecase.Product.DeleteStock(ts, stockParams)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
+	if err := e.Usecase.Product.DeleteStock(ts, stockParams); err != nil {
+		return nil, err
 	}
 
-	if err := ts.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(err))
-		return
-	}
+	return "успешно удалено", nil
+}
+
+// outboxStats статистика публикации событий
+func (e *GinServer) outboxStats(c *gin.Context) {
+	c.JSON(http.StatusOK, response.NewSuccessResponse(e.Usecase.Outbox.Stats(), "outbox_stats"))
+}
 
-	c.JSON(http.StatusOK, response.NewSuccessResponse("успешно удалено", "status"))
+// cacheStats статистика кэша продуктов
+func (e *GinServer) cacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, response.NewSuccessResponse(e.Usecase.Product.CacheStats(), "cache_stats"))
 }
@@ -0,0 +1,116 @@
+package restapi
+
+import (
+	"errors"
+	"io"
+	"product_storage/internal/entity/image"
+	"product_storage/internal/entity/rpc"
+	"product_storage/internal/transaction"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// imageUploadMethod метод загрузки изображения, размер его тела ограничивается размером изображения
+const imageUploadMethod = "product_image_upload"
+
+// multipartOverhead запас на заголовки и поля multipart формы сверх размера файла
+const multipartOverhead = 64 << 10
+
+// uploadImage загружает изображение продукта из поля file multipart формы
+func (e *GinServer) uploadImage(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	var params image.UploadParams
+	if err = c.ShouldBind(&params); err != nil {
+		return nil, badRequest(err)
+	}
+	params.ProductID = productID
+
+	rc := rpc.Context{GinContext: c}
+	fileHeader, err := rc.GetGinFile()
+	if err != nil {
+		return nil, badRequest(errors.New("не передан файл изображения в поле file"))
+	}
+
+	maxSize := e.Config.ImageMaxSize()
+	if fileHeader.Size > maxSize {
+		return nil, image.ErrTooLarge
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	defer file.Close()
+
+	// читается на байт больше допустимого, чтобы usecase отклонил слишком большой файл
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Image.Upload(ts, params, data)
+}
+
+// findImageList выводит изображения продукта
+func (e *GinServer) findImageList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Image.FindImageList(ts, productID)
+}
+
+// setPrimaryImage назначает основное изображение продукта
+func (e *GinServer) setPrimaryImage(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params struct {
+		ImageID int `json:"image_id"`
+	}
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Image.SetPrimary(ts, params.ImageID); err != nil {
+		return nil, err
+	}
+
+	return "успешно назначено", nil
+}
+
+// reorderImages меняет порядок изображений продукта
+func (e *GinServer) reorderImages(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params image.OrderParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Image.Reorder(ts, params); err != nil {
+		return nil, err
+	}
+
+	return "порядок изменен", nil
+}
+
+// deleteImage удаляет изображение продукта
+func (e *GinServer) deleteImage(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params struct {
+		ImageID int `json:"image_id"`
+	}
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Image.Delete(ts, params.ImageID); err != nil {
+		return nil, err
+	}
+
+	return "успешно удалено", nil
+}
@@ -0,0 +1,60 @@
+package restapi
+
+import (
+	"product_storage/internal/entity/location"
+	"product_storage/internal/transaction"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// addLocation создает место хранения на складе
+func (e *GinServer) addLocation(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params location.Params
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Location.AddLocation(ts, params)
+}
+
+// findLocationList выводит места хранения склада
+func (e *GinServer) findLocationList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	storageID, err := strconv.Atoi(c.Query("storage_id"))
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Location.FindLocationList(ts, storageID)
+}
+
+// putaway размещает продукт склада в место хранения
+func (e *GinServer) putaway(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params location.PutawayParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Location.Putaway(ts, params); err != nil {
+		return nil, err
+	}
+
+	return "успешно размещено", nil
+}
+
+// moveBetweenLocations перемещает продукт между местами хранения
+func (e *GinServer) moveBetweenLocations(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params location.MoveParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Location.Move(ts, params); err != nil {
+		return nil, err
+	}
+
+	return "успешно перемещено", nil
+}
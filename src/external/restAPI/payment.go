@@ -0,0 +1,30 @@
+package restapi
+
+import (
+	"product_storage/internal/entity/payment"
+	"product_storage/internal/transaction"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// findSalePaymentList выводит оплаты продажи
+func (e *GinServer) findSalePaymentList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	saleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Payment.FindPaymentList(ts, saleID)
+}
+
+// zReport выводит кассовый отчет за день по складам и способам оплаты
+func (e *GinServer) zReport(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var query payment.ZQuery
+
+	if err := c.ShouldBindJSON(&query); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Payment.ZReport(ts, query)
+}
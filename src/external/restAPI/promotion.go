@@ -0,0 +1,65 @@
+package restapi
+
+import (
+	"product_storage/internal/entity/product"
+	"product_storage/internal/entity/promotion"
+	"product_storage/internal/transaction"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// promotionIDParams тело запроса завершения акции
+type promotionIDParams struct {
+	PromotionID int `json:"promotion_id"`
+}
+
+// addPromotion создает акцию
+func (e *GinServer) addPromotion(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params promotion.Params
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Promotion.AddPromotion(ts, params)
+}
+
+// findPromotionList выводит акции, с active=true только действующие сейчас
+func (e *GinServer) findPromotionList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var activeOnly bool
+	if value := c.Query("active"); value != "" {
+		var err error
+		if activeOnly, err = strconv.ParseBool(value); err != nil {
+			return nil, badRequest(err)
+		}
+	}
+
+	return e.Usecase.Promotion.FindPromotionList(ts, activeOnly)
+}
+
+// endPromotion досрочно завершает акцию
+func (e *GinServer) endPromotion(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params promotionIDParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Promotion.EndPromotion(ts, params.PromotionID); err != nil {
+		return nil, err
+	}
+
+	return "акция завершена", nil
+}
+
+// quoteSale рассчитывает сумму продажи с учетом акций без ее проведения
+func (e *GinServer) quoteSale(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params product.SaleParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Promotion.Quote(ts, params)
+}
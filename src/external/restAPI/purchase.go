@@ -0,0 +1,107 @@
+package restapi
+
+import (
+	"product_storage/internal/entity/purchase"
+	"product_storage/internal/transaction"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// purchaseOrderIDParams тело запроса смены статуса заказа поставщику
+type purchaseOrderIDParams struct {
+	OrderID int `json:"order_id"`
+}
+
+// addSupplier добавляет поставщика
+func (e *GinServer) addSupplier(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params purchase.SupplierParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Purchase.AddSupplier(ts, params)
+}
+
+// findSupplierList выводит действующих поставщиков
+func (e *GinServer) findSupplierList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	return e.Usecase.Purchase.FindSupplierList(ts)
+}
+
+// addPurchaseOrder создает заказ поставщику
+func (e *GinServer) addPurchaseOrder(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params purchase.OrderParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Purchase.AddPurchaseOrder(ts, params)
+}
+
+// findPurchaseOrder выводит заказ поставщику со строками
+func (e *GinServer) findPurchaseOrder(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Purchase.FindPurchaseOrder(ts, orderID)
+}
+
+// findPurchaseOrderList выводит заказы поставщикам с отбором по статусу и поставщику
+func (e *GinServer) findPurchaseOrderList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	id := c.Query("supplier_id")
+	if id == "" {
+		id = "0"
+	}
+
+	supplierID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Purchase.FindPurchaseOrderList(ts, c.Query("status"), supplierID)
+}
+
+// sendPurchaseOrder отправляет заказ поставщику
+func (e *GinServer) sendPurchaseOrder(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params purchaseOrderIDParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Purchase.SendPurchaseOrder(ts, params.OrderID); err != nil {
+		return nil, err
+	}
+
+	return "успешно отправлено", nil
+}
+
+// cancelPurchaseOrder отменяет заказ поставщику
+func (e *GinServer) cancelPurchaseOrder(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params purchaseOrderIDParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Purchase.CancelPurchaseOrder(ts, params.OrderID); err != nil {
+		return nil, err
+	}
+
+	return "успешно отменено", nil
+}
+
+// receiveGoods принимает товар по заказу поставщику
+func (e *GinServer) receiveGoods(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params purchase.ReceiptParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Purchase.ReceiveGoods(ts, params)
+}
@@ -0,0 +1,35 @@
+package restapi
+
+import (
+	"product_storage/internal/entity/valuation"
+	"product_storage/internal/transaction"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// valuationReport выводит оценку остатков склада по методу себестоимости из конфига
+func (e *GinServer) valuationReport(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	id := c.Query("storage_id")
+	if id == "" {
+		id = "0"
+	}
+
+	storageID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Product.ValuationReport(ts, storageID)
+}
+
+// marginReport выводит выручку, себестоимость и валовую маржу продаж за период
+func (e *GinServer) marginReport(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var query valuation.MarginQuery
+
+	if err := c.ShouldBindJSON(&query); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Product.MarginReport(ts, query)
+}
@@ -0,0 +1,30 @@
+package restapi
+
+import (
+	"product_storage/internal/entity/reprice"
+	"product_storage/internal/transaction"
+
+	"github.com/gin-gonic/gin"
+)
+
+// previewReprice показывает старые и новые цены массового изменения без его проведения
+func (e *GinServer) previewReprice(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params reprice.Params
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Reprice.Preview(ts, params)
+}
+
+// applyReprice проводит массовое изменение цен
+func (e *GinServer) applyReprice(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params reprice.Params
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Reprice.Apply(ts, params)
+}
@@ -0,0 +1,71 @@
+package restapi
+
+import (
+	"product_storage/internal/entity/payment"
+	"product_storage/internal/entity/reservation"
+	"product_storage/internal/transaction"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reserve резервирует продукт на складе под заказ
+func (e *GinServer) reserve(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params reservation.Params
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Reservation.Reserve(ts, params)
+}
+
+// releaseReservation снимает резерв
+func (e *GinServer) releaseReservation(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params struct {
+		ReservationID int `json:"reservation_id"`
+	}
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Reservation.Release(ts, params.ReservationID); err != nil {
+		return nil, err
+	}
+
+	return "успешно снят", nil
+}
+
+// convertReservation превращает резерв в продажу
+func (e *GinServer) convertReservation(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params struct {
+		ReservationID int              `json:"reservation_id"`
+		Payments      []payment.Params `json:"payments"`
+	}
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Reservation.Convert(ts, params.ReservationID, params.Payments)
+}
+
+// findReservationList выводит резервы с отбором по варианту, складу и статусу
+func (e *GinServer) findReservationList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var idList [2]int
+	for i, name := range []string{"variant_id", "storage_id"} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, badRequest(err)
+		}
+		idList[i] = id
+	}
+
+	return e.Usecase.Reservation.FindReservationList(ts, idList[0], idList[1], c.Query("status"))
+}
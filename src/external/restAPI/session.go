@@ -0,0 +1,82 @@
+package restapi
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"product_storage/internal/transaction"
+	"product_storage/tools/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sessionHandlerFunc обработчик, выполняемый в рамках транзакции запроса,
+// возвращает данные для ответа
+type sessionHandlerFunc func(c *gin.Context, ts transaction.Session) (interface{}, error)
+
+// requestError ошибка в параметрах запроса
+type requestError struct {
+	err error
+}
+
+func (e requestError) Error() string {
+	return e.err.Error()
+}
+
+// badRequest помечает ошибку как ошибку параметров запроса, на нее отвечается статусом 400
+func badRequest(err error) error {
+	return requestError{err: err}
+}
+
+// inSession оборачивает обработчик в транзакцию: открывает сессию с таймаутом метода и параметрами opts,
+// фиксирует ее при успешном выполнении обработчика и откатывает при ошибке,
+// при конфликте сериализации или deadlock обработчик выполняется повторно в новой транзакции,
+// ответ отправляется только после фиксации транзакции
+func (e *GinServer) inSession(method, resultName string, h sessionHandlerFunc, opts ...transaction.Option) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := e.requestContext(c, method)
+		defer cancel()
+
+		// тело запроса сохраняется, чтобы обработчик мог прочитать его при повторе,
+		// размер тела ограничивается, чтобы не держать в памяти произвольно большой запрос
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			if body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, e.bodyLimit(method))); err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					c.JSON(http.StatusRequestEntityTooLarge, response.NewErrorResponse(err))
+					return
+				}
+
+				c.JSON(http.StatusBadRequest, response.NewErrorResponse(err))
+				return
+			}
+		}
+
+		var data interface{}
+		err := e.retrier.Run(ctx, func(ts transaction.Session) (err error) {
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			data, err = h(c, ts)
+			return err
+		}, opts...)
+		if err != nil {
+			var reqErr requestError
+			if errors.As(err, &reqErr) {
+				c.JSON(http.StatusBadRequest, response.NewErrorResponse(err))
+				return
+			}
+
+			c.JSON(errorStatus(ctx), response.NewErrorResponse(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, response.NewSuccessResponse(data, resultName))
+	}
+}
+
+// transactionStats статистика повторов транзакций
+func (e *GinServer) transactionStats(c *gin.Context) {
+	c.JSON(http.StatusOK, response.NewSuccessResponse(e.retrier.Stats(), "transaction_stats"))
+}
@@ -0,0 +1,108 @@
+package restapi
+
+import (
+	"product_storage/internal/entity/stock"
+	"product_storage/internal/transaction"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// saveStockThreshold устанавливает пороги остатка варианта продукта на складе
+func (e *GinServer) saveStockThreshold(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var threshold stock.ThresholdParams
+
+	if err := c.ShouldBindJSON(&threshold); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Product.SaveThreshold(ts, threshold); err != nil {
+		return nil, err
+	}
+
+	return "успешно сохранено", nil
+}
+
+// findLowStockList выводит варианты продуктов с остатком ниже минимального и рекомендуемый дозаказ
+func (e *GinServer) findLowStockList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	id := c.Query("storage_id")
+	if id == "" {
+		id = "0"
+	}
+
+	storageID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Product.FindLowStockList(ts, storageID)
+}
+
+// writeOff списывает продукт со склада
+func (e *GinServer) writeOff(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var writeOff stock.WriteOffParams
+
+	if err := c.ShouldBindJSON(&writeOff); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Product.WriteOff(ts, writeOff)
+}
+
+// findBatchList выводит партии варианта продукта с остатком
+func (e *GinServer) findBatchList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	variantID, err := strconv.Atoi(c.Query("variant_id"))
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	id := c.Query("storage_id")
+	if id == "" {
+		id = "0"
+	}
+
+	storageID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Product.FindBatchList(ts, variantID, storageID)
+}
+
+// findExpiringBatchList выводит партии, срок годности которых истекает в ближайшие days дней или уже истек
+func (e *GinServer) findExpiringBatchList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	id := c.Query("storage_id")
+	if id == "" {
+		id = "0"
+	}
+
+	storageID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	d := c.Query("days")
+	if d == "" {
+		d = "0"
+	}
+
+	days, err := strconv.Atoi(d)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Product.FindExpiringBatchList(ts, storageID, days)
+}
+
+// writeOffExpired списывает партии с истекшим сроком годности
+func (e *GinServer) writeOffExpired(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params struct {
+		StorageID int `json:"storage_id"`
+	}
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Product.WriteOffExpired(ts, params.StorageID)
+}
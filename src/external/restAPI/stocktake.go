@@ -0,0 +1,92 @@
+package restapi
+
+import (
+	"product_storage/internal/entity/stocktake"
+	"product_storage/internal/transaction"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// startStocktake начинает инвентаризацию склада
+func (e *GinServer) startStocktake(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params stocktake.Params
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Stocktake.StartStocktake(ts, params)
+}
+
+// saveStocktakeCount записывает результаты пересчета
+func (e *GinServer) saveStocktakeCount(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params stocktake.CountListParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Stocktake.SaveCount(ts, params); err != nil {
+		return nil, err
+	}
+
+	return "успешно сохранено", nil
+}
+
+// findStocktakeList выводит инвентаризации с отбором по складу и статусу
+func (e *GinServer) findStocktakeList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	id := c.Query("storage_id")
+	if id == "" {
+		id = "0"
+	}
+
+	storageID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Stocktake.FindStocktakeList(ts, storageID, c.Query("status"))
+}
+
+// stocktakeVarianceReport выводит расхождения инвентаризации
+func (e *GinServer) stocktakeVarianceReport(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	stocktakeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Stocktake.VarianceReport(ts, stocktakeID)
+}
+
+// postStocktake проводит расхождения инвентаризации по складу
+func (e *GinServer) postStocktake(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params stocktake.PostParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Stocktake.PostStocktake(ts, params); err != nil {
+		return nil, err
+	}
+
+	return "успешно проведено", nil
+}
+
+// cancelStocktake отменяет инвентаризацию
+func (e *GinServer) cancelStocktake(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params struct {
+		StocktakeID int `json:"stocktake_id"`
+	}
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Stocktake.CancelStocktake(ts, params.StocktakeID); err != nil {
+		return nil, err
+	}
+
+	return "успешно отменено", nil
+}
@@ -0,0 +1,137 @@
+package restapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"product_storage/internal/entity/stock"
+	"product_storage/internal/transaction"
+	"product_storage/tools/response"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// события потока изменений склада
+const (
+	streamEventSnapshot = "snapshot" // текущее кол-во продуктов на складах, клиент заменяет им свое состояние
+	streamEventStock    = "stock"    // изменение кол-ва варианта продукта на складе
+)
+
+// parseIDList список id из параметров вида ?id=1&id=2 или ?id=1,2
+func parseIDList(values []string) ([]int, error) {
+	idList := make([]int, 0, len(values))
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+
+			id, err := strconv.Atoi(part)
+			if err != nil || id <= 0 {
+				return nil, errors.New("id склада должен быть положительным числом")
+			}
+			idList = append(idList, id)
+		}
+	}
+
+	return idList, nil
+}
+
+// lastEventID id последнего полученного клиентом события: заголовок Last-Event-ID,
+// который браузер отправляет при переподключении, или параметр last_event_id
+func lastEventID(c *gin.Context) (id int64, exists bool) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	return id, err == nil
+}
+
+// stockStream поток изменений кол-ва продуктов на складах (Server-Sent Events).
+// Новому клиенту сначала отправляется текущее состояние, переподключившийся клиент
+// получает пропущенные изменения, а если они уже не хранятся - снова текущее состояние
+func (e *GinServer) stockStream(c *gin.Context) {
+	storageIDList, err := parseIDList(c.QueryArray("storage_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(err))
+		return
+	}
+
+	storages := make(map[int]struct{}, len(storageIDList))
+	for _, id := range storageIDList {
+		storages[id] = struct{}{}
+	}
+	matches := func(level stock.StockLevel) bool {
+		_, exists := storages[level.StorageID]
+		return len(storages) == 0 || exists
+	}
+
+	// подписка оформляется до загрузки состояния, чтобы не пропустить изменения между ними
+	lastID, hasLastID := lastEventID(c)
+	sub := e.Usecase.Product.SubscribeStockChanges(lastID, hasLastID)
+	defer sub.Close()
+
+	var snapshot []stock.StockLevel
+	if !sub.Resumed {
+		ctx, cancel := e.requestContext(c, "stock_stream")
+		err = e.retrier.Run(ctx, func(ts transaction.Session) (err error) {
+			snapshot, err = e.Usecase.Product.FindStockLevelList(ts, storageIDList)
+			return err
+		}, transaction.ReadOnly())
+		cancel()
+
+		if err != nil {
+			c.JSON(errorStatus(ctx), response.NewErrorResponse(err))
+			return
+		}
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	if !sub.Resumed {
+		snapshotEvent := sse.Event{Event: streamEventSnapshot, Data: snapshot}
+		if sub.LastID != 0 {
+			snapshotEvent.Id = strconv.FormatInt(sub.LastID, 10)
+		}
+		c.Render(-1, snapshotEvent)
+	}
+
+	for _, item := range sub.Backlog {
+		if matches(item.Data) {
+			c.Render(-1, sse.Event{Id: strconv.FormatInt(item.ID, 10), Event: streamEventStock, Data: item.Data})
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(e.Config.StreamHeartbeat())
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case item, ok := <-sub.C:
+			// канал закрывается, если клиент не успевает читать; он переподключится с последнего id
+			if !ok {
+				return false
+			}
+
+			if matches(item.Data) {
+				c.Render(-1, sse.Event{Id: strconv.FormatInt(item.ID, 10), Event: streamEventStock, Data: item.Data})
+			}
+			return true
+		case <-heartbeat.C:
+			// комментарий не обрабатывается клиентом и не дает прокси закрыть соединение
+			io.WriteString(w, ": ping\n\n")
+			return true
+		}
+	})
+}
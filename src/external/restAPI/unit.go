@@ -0,0 +1,39 @@
+package restapi
+
+import (
+	"product_storage/internal/entity/unit"
+	"product_storage/internal/transaction"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// addUnit добавляет единицу измерения в справочник
+func (e *GinServer) addUnit(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params unit.Unit
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Unit.AddUnit(ts, params); err != nil {
+		return nil, err
+	}
+
+	return "успешно добавлено", nil
+}
+
+// findUnitList выводит справочник единиц измерения
+func (e *GinServer) findUnitList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	return e.Usecase.Unit.FindUnitList(ts)
+}
+
+// convertUnit переводит значение value из единицы from в единицу to
+func (e *GinServer) convertUnit(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	value, err := strconv.ParseFloat(c.Query("value"), 64)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Unit.Convert(ts, value, c.Query("from"), c.Query("to"))
+}
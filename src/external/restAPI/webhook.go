@@ -0,0 +1,64 @@
+package restapi
+
+import (
+	"net/http"
+	"product_storage/internal/entity/webhook"
+	"product_storage/internal/transaction"
+	"product_storage/tools/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// addWebhook добавляет подписку на события
+func (e *GinServer) addWebhook(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params webhook.SubscriptionParams
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return e.Usecase.Webhook.AddSubscription(ts, params)
+}
+
+// findWebhookList выводит действующие подписки
+func (e *GinServer) findWebhookList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	return e.Usecase.Webhook.FindSubscriptionList(ts)
+}
+
+// deleteWebhook отключает подписку
+func (e *GinServer) deleteWebhook(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	var params struct {
+		SubscriptionID int `json:"subscription_id"`
+	}
+
+	if err := c.ShouldBindJSON(&params); err != nil {
+		return nil, badRequest(err)
+	}
+
+	if err := e.Usecase.Webhook.RemoveSubscription(ts, params.SubscriptionID); err != nil {
+		return nil, err
+	}
+
+	return "успешно удалено", nil
+}
+
+// findWebhookDeliveryList выводит журнал доставок подписки
+func (e *GinServer) findWebhookDeliveryList(c *gin.Context, ts transaction.Session) (interface{}, error) {
+	subscriptionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	return e.Usecase.Webhook.FindDeliveryList(ts, subscriptionID, limit)
+}
+
+// webhookStats статистика доставки событий подписчикам
+func (e *GinServer) webhookStats(c *gin.Context) {
+	c.JSON(http.StatusOK, response.NewSuccessResponse(e.Usecase.Webhook.Stats(), "webhook_stats"))
+}
@@ -1,8 +1,39 @@
 package bridge
 
-import "time"
+import (
+	"context"
+	"io"
+	"product_storage/internal/entity/event"
+	"product_storage/internal/entity/stock"
+	"product_storage/internal/entity/webhook"
+	"time"
+)
 
 type Date interface {
 	Today() time.Time
 	Now() time.Time
 }
+
+// Publisher публикация доменных событий в брокер сообщений
+type Publisher interface {
+	Publish(ctx context.Context, e event.Event) error
+}
+
+// WebhookSender отправка события получателю подписки, возвращает http статус ответа
+type WebhookSender interface {
+	Send(ctx context.Context, d webhook.Delivery) (statusCode int, err error)
+}
+
+// LowStockNotifier оповещение ответственных о снижении остатка ниже минимального
+type LowStockNotifier interface {
+	NotifyLowStock(ctx context.Context, alert stock.LowStockAlert) error
+}
+
+// BlobStorage хранилище файлов по ключу, например изображений продуктов
+type BlobStorage interface {
+	Put(ctx context.Context, key, contentType string, r io.Reader) error
+	// Delete удаляет файл, отсутствие файла не считается ошибкой
+	Delete(ctx context.Context, key string) error
+	// URL адрес, по которому файл доступен клиентам
+	URL(key string) string
+}
@@ -5,6 +5,11 @@
 package bridge
 
 import (
+	context "context"
+	io "io"
+	event "product_storage/internal/entity/event"
+	stock "product_storage/internal/entity/stock"
+	webhook "product_storage/internal/entity/webhook"
 	reflect "reflect"
 	time "time"
 
@@ -61,3 +66,180 @@ func (mr *MockDateMockRecorder) Today() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Today", reflect.TypeOf((*MockDate)(nil).Today))
 }
+
+// MockPublisher is a mock of Publisher interface.
+type MockPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockPublisherMockRecorder
+}
+
+// MockPublisherMockRecorder is the mock recorder for MockPublisher.
+type MockPublisherMockRecorder struct {
+	mock *MockPublisher
+}
+
+// NewMockPublisher creates a new mock instance.
+func NewMockPublisher(ctrl *gomock.Controller) *MockPublisher {
+	mock := &MockPublisher{ctrl: ctrl}
+	mock.recorder = &MockPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPublisher) EXPECT() *MockPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockPublisher) Publish(ctx context.Context, e event.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockPublisherMockRecorder) Publish(ctx, e interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockPublisher)(nil).Publish), ctx, e)
+}
+
+// MockWebhookSender is a mock of WebhookSender interface.
+type MockWebhookSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookSenderMockRecorder
+}
+
+// MockWebhookSenderMockRecorder is the mock recorder for MockWebhookSender.
+type MockWebhookSenderMockRecorder struct {
+	mock *MockWebhookSender
+}
+
+// NewMockWebhookSender creates a new mock instance.
+func NewMockWebhookSender(ctrl *gomock.Controller) *MockWebhookSender {
+	mock := &MockWebhookSender{ctrl: ctrl}
+	mock.recorder = &MockWebhookSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookSender) EXPECT() *MockWebhookSenderMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockWebhookSender) Send(ctx context.Context, d webhook.Delivery) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, d)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockWebhookSenderMockRecorder) Send(ctx, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockWebhookSender)(nil).Send), ctx, d)
+}
+
+// MockLowStockNotifier is a mock of LowStockNotifier interface.
+type MockLowStockNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockLowStockNotifierMockRecorder
+}
+
+// MockLowStockNotifierMockRecorder is the mock recorder for MockLowStockNotifier.
+type MockLowStockNotifierMockRecorder struct {
+	mock *MockLowStockNotifier
+}
+
+// NewMockLowStockNotifier creates a new mock instance.
+func NewMockLowStockNotifier(ctrl *gomock.Controller) *MockLowStockNotifier {
+	mock := &MockLowStockNotifier{ctrl: ctrl}
+	mock.recorder = &MockLowStockNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLowStockNotifier) EXPECT() *MockLowStockNotifierMockRecorder {
+	return m.recorder
+}
+
+// NotifyLowStock mocks base method.
+func (m *MockLowStockNotifier) NotifyLowStock(ctx context.Context, alert stock.LowStockAlert) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyLowStock", ctx, alert)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyLowStock indicates an expected call of NotifyLowStock.
+func (mr *MockLowStockNotifierMockRecorder) NotifyLowStock(ctx, alert interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyLowStock", reflect.TypeOf((*MockLowStockNotifier)(nil).NotifyLowStock), ctx, alert)
+}
+
+// MockBlobStorage is a mock of BlobStorage interface.
+type MockBlobStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlobStorageMockRecorder
+}
+
+// MockBlobStorageMockRecorder is the mock recorder for MockBlobStorage.
+type MockBlobStorageMockRecorder struct {
+	mock *MockBlobStorage
+}
+
+// NewMockBlobStorage creates a new mock instance.
+func NewMockBlobStorage(ctrl *gomock.Controller) *MockBlobStorage {
+	mock := &MockBlobStorage{ctrl: ctrl}
+	mock.recorder = &MockBlobStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlobStorage) EXPECT() *MockBlobStorageMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockBlobStorage) Delete(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockBlobStorageMockRecorder) Delete(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockBlobStorage)(nil).Delete), ctx, key)
+}
+
+// Put mocks base method.
+func (m *MockBlobStorage) Put(ctx context.Context, key, contentType string, r io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, key, contentType, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockBlobStorageMockRecorder) Put(ctx, key, contentType, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockBlobStorage)(nil).Put), ctx, key, contentType, r)
+}
+
+// URL mocks base method.
+func (m *MockBlobStorage) URL(key string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "URL", key)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// URL indicates an expected call of URL.
+func (mr *MockBlobStorageMockRecorder) URL(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "URL", reflect.TypeOf((*MockBlobStorage)(nil).URL), key)
+}
@@ -0,0 +1,206 @@
+package attribute
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+	"github.com/sirupsen/logrus"
+)
+
+// типы значений атрибутов
+const (
+	TypeString = "string" // произвольная строка
+	TypeNumber = "number" // число, может быть дробным
+	TypeBool   = "bool"   // да или нет
+	TypeEnum   = "enum"   // одно из значений списка options
+)
+
+// codePattern допустимый код атрибута, используется как ключ в jsonb
+var codePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+var (
+	// ErrCategoryNotFound категория не найдена
+	ErrCategoryNotFound = errors.New("категория не найдена")
+	// ErrNoCategory атрибуты указаны для продукта без категории
+	ErrNoCategory = errors.New("атрибуты можно задать только продукту с категорией")
+)
+
+// Values значения атрибутов продукта по коду атрибута
+type Values map[string]any
+
+// Definition описание атрибута продуктов категории
+type Definition struct {
+	CategoryID int            `json:"category_id" db:"category_id"` // id категории
+	Code       string         `json:"code" db:"code"`               // код атрибута, ключ значения, например fat
+	Name       string         `json:"name" db:"name"`               // название, например Жирность, %
+	Type       string         `json:"type" db:"value_type"`         // тип значения
+	Required   bool           `json:"required" db:"required"`       // значение обязательно для продуктов категории
+	Options    []string       `json:"options" db:"-"`               // допустимые значения атрибута типа enum
+	RawOptions types.JSONText `json:"-" db:"options"`               // допустимые значения в виде jsonb
+}
+
+// Validate проверка описания атрибута
+func (d Definition) Validate() error {
+	if !codePattern.MatchString(d.Code) {
+		return errors.New("код атрибута должен начинаться с латинской буквы и содержать только строчные латинские буквы, цифры и _")
+	}
+
+	if d.Name == "" {
+		return errors.New("название атрибута не может быть пустым")
+	}
+
+	switch d.Type {
+	case TypeString, TypeNumber, TypeBool:
+		if len(d.Options) > 0 {
+			return errors.New("список значений задается только для атрибута типа enum")
+		}
+	case TypeEnum:
+		if len(d.Options) == 0 {
+			return errors.New("для атрибута типа enum должен быть задан список значений")
+		}
+	default:
+		return errors.New("тип атрибута должен быть одним из: string, number, bool, enum")
+	}
+
+	return nil
+}
+
+// check проверка значения атрибута
+func (d Definition) check(value any) error {
+	switch d.Type {
+	case TypeNumber:
+		if _, ok := value.(float64); ok {
+			return nil
+		}
+	case TypeBool:
+		if _, ok := value.(bool); ok {
+			return nil
+		}
+	case TypeString:
+		if s, ok := value.(string); ok && s != "" {
+			return nil
+		}
+	case TypeEnum:
+		if s, ok := value.(string); ok {
+			for _, option := range d.Options {
+				if s == option {
+					return nil
+				}
+			}
+			return fmt.Errorf("значение атрибута %s должно быть одним из: %s", d.Code, strings.Join(d.Options, ", "))
+		}
+	}
+
+	return fmt.Errorf("значение атрибута %s должно иметь тип %s", d.Code, d.Type)
+}
+
+// Validate проверка значений атрибутов продукта по описаниям атрибутов его категории
+func Validate(definitionList []Definition, values Values) error {
+	byCode := make(map[string]Definition, len(definitionList))
+	for _, d := range definitionList {
+		byCode[d.Code] = d
+
+		if _, exists := values[d.Code]; d.Required && !exists {
+			return fmt.Errorf("не указано обязательное значение атрибута %s", d.Code)
+		}
+	}
+
+	for code, value := range values {
+		d, exists := byCode[code]
+		if !exists {
+			return fmt.Errorf("атрибут %s не определен для категории продукта", code)
+		}
+
+		if err := d.check(value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CategoryParams параметры создания категории
+type CategoryParams struct {
+	Name        string       `json:"name"`        // название категории
+	Definitions []Definition `json:"definitions"` // атрибуты продуктов категории
+}
+
+func (p CategoryParams) Log() logrus.Fields {
+	return logrus.Fields{"category_name": p.Name}
+}
+
+// Validate проверка параметров категории
+func (p CategoryParams) Validate() error {
+	if p.Name == "" {
+		return errors.New("название категории не может быть пустым")
+	}
+
+	seen := make(map[string]bool, len(p.Definitions))
+	for _, d := range p.Definitions {
+		if err := d.Validate(); err != nil {
+			return err
+		}
+
+		if seen[d.Code] {
+			return fmt.Errorf("атрибут %s указан несколько раз", d.Code)
+		}
+		seen[d.Code] = true
+	}
+
+	return nil
+}
+
+// Category категория продуктов с описаниями атрибутов
+type Category struct {
+	CategoryID  int          `json:"category_id" db:"category_id"` // id категории
+	Name        string       `json:"name" db:"name"`               // название
+	CreatedAt   time.Time    `json:"created_at" db:"created_at"`   // дата создания
+	Definitions []Definition `json:"definitions" db:"-"`           // атрибуты продуктов категории
+}
+
+// ProductParams параметры изменения категории и атрибутов продукта
+type ProductParams struct {
+	ProductID  int    `json:"product_id"`  // id продукта
+	CategoryID int    `json:"category_id"` // id категории, 0 убирает продукт из категории
+	Attributes Values `json:"attributes"`  // значения атрибутов, заменяют прежние значения
+}
+
+func (p ProductParams) Log() logrus.Fields {
+	return logrus.Fields{
+		"product_ID":  p.ProductID,
+		"category_ID": p.CategoryID,
+	}
+}
+
+// Filter отбор продуктов по категории и значениям атрибутов
+type Filter struct {
+	CategoryID int               // id категории, 0 не ограничивает выборку
+	Values     map[string]string // значения атрибутов в текстовом виде по коду атрибута
+}
+
+// IsEmpty отбор не задан
+func (f Filter) IsEmpty() bool {
+	return f.CategoryID == 0 && len(f.Values) == 0
+}
+
+// String отбор в виде строки с атрибутами в порядке кодов, используется в ключе кэша
+func (f Filter) String() string {
+	codeList := make([]string, 0, len(f.Values))
+	for code := range f.Values {
+		codeList = append(codeList, code)
+	}
+	sort.Strings(codeList)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d", f.CategoryID)
+	for _, code := range codeList {
+		fmt.Fprintf(&b, "|%s=%s", code, f.Values[code])
+	}
+
+	return b.String()
+}
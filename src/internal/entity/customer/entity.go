@@ -0,0 +1,152 @@
+package customer
+
+import (
+	"errors"
+	"product_storage/internal/entity/product"
+	"product_storage/tools/sqlnull"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+)
+
+// типы покупателей, тип определяет группу прайс-листа покупателя
+const (
+	TypeRetail    = "retail"    // розничный покупатель
+	TypeWholesale = "wholesale" // оптовый покупатель
+)
+
+var (
+	// ErrNotFound покупатель не найден или удален
+	ErrNotFound = errors.New("покупатель не найден")
+	// ErrPhoneTaken телефон уже указан у другого покупателя
+	ErrPhoneTaken = errors.New("покупатель с таким телефоном уже существует")
+)
+
+// IsValidType известен ли тип покупателя
+func IsValidType(customerType string) bool {
+	return customerType == TypeRetail || customerType == TypeWholesale
+}
+
+// NormalizePhone телефон без пробелов, скобок и дефисов, с ведущим + если он был указан
+func NormalizePhone(phone string) string {
+	phone = strings.TrimSpace(phone)
+
+	var b strings.Builder
+	for i, r := range phone {
+		if unicode.IsDigit(r) || (r == '+' && i == 0) {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// Params параметры покупателя
+type Params struct {
+	Name  string `json:"name" db:"name"`          // имя или название покупателя
+	Phone string `json:"phone" db:"phone"`        // телефон, необязательный
+	Type  string `json:"type" db:"customer_type"` // тип покупателя, по умолчанию розничный
+}
+
+func (p Params) Log() logrus.Fields {
+	return logrus.Fields{"name": p.Name, "type": p.Type}
+}
+
+// Normalize параметры с приведенным телефоном и типом по умолчанию
+func (p Params) Normalize() Params {
+	p.Name = strings.TrimSpace(p.Name)
+	p.Phone = NormalizePhone(p.Phone)
+	if p.Type == "" {
+		p.Type = TypeRetail
+	}
+
+	return p
+}
+
+// Validate проверка нормализованных параметров покупателя
+func (p Params) Validate() error {
+	if p.Name == "" {
+		return errors.New("имя покупателя не может быть пустым")
+	}
+
+	if digits := strings.TrimPrefix(p.Phone, "+"); p.Phone != "" && (len(digits) < 10 || len(digits) > 15) {
+		return errors.New("телефон должен содержать от 10 до 15 цифр")
+	}
+
+	if !IsValidType(p.Type) {
+		return errors.New("тип покупателя должен быть одним из: retail, wholesale")
+	}
+
+	return nil
+}
+
+// Customer покупатель
+type Customer struct {
+	CustomerID int              `json:"customer_id" db:"customer_id"` // id покупателя
+	AddedAt    time.Time        `json:"added_at" db:"added_at"`       // дата добавления
+	RemovedAt  sqlnull.NullTime `json:"removed_at" db:"removed_at"`   // дата удаления
+	Params
+}
+
+// UpdateParams параметры изменения покупателя
+type UpdateParams struct {
+	CustomerID int `json:"customer_id"` // id покупателя
+	Params
+}
+
+// GroupPriceParams параметры цены варианта для группы покупателей
+type GroupPriceParams struct {
+	VariantID    int     `json:"variant_id"`    // id варианта продукта
+	CustomerType string  `json:"customer_type"` // группа покупателей
+	Price        float64 `json:"price"`         // цена единицы для группы
+}
+
+func (p GroupPriceParams) Log() logrus.Fields {
+	return logrus.Fields{
+		"variant_ID":    p.VariantID,
+		"customer_type": p.CustomerType,
+		"price":         p.Price,
+	}
+}
+
+// Validate проверка параметров цены группы
+func (p GroupPriceParams) Validate() error {
+	if p.VariantID <= 0 {
+		return errors.New("id варианта не может быть меньше или равен 0")
+	}
+
+	if !IsValidType(p.CustomerType) {
+		return errors.New("группа покупателей должна быть одной из: retail, wholesale")
+	}
+
+	if p.Price <= 0 {
+		return errors.New("цена должна быть больше 0")
+	}
+
+	return nil
+}
+
+// GroupPrice цена варианта для группы покупателей, действующая в интервале дат
+type GroupPrice struct {
+	PriceID      int              `json:"price_id" db:"price_id"`           // id цены
+	VariantID    int              `json:"variant_id" db:"variant_id"`       // id варианта продукта
+	CustomerType string           `json:"customer_type" db:"customer_type"` // группа покупателей
+	Price        float64          `json:"price" db:"price"`                 // цена единицы
+	StartDate    time.Time        `json:"start_date" db:"start_date"`       // начало действия
+	EndDate      sqlnull.NullTime `json:"end_date" db:"end_date"`           // окончание действия
+}
+
+// History история покупок покупателя
+type History struct {
+	Customer Customer `json:"customer"` // покупатель
+	SaleTotal
+	Sales []product.Sale `json:"sales"` // последние продажи, последние первыми
+}
+
+// SaleTotal итоги всех продаж покупателя
+type SaleTotal struct {
+	SaleCount  int     `json:"sale_count" db:"sale_count"`   // кол-во продаж
+	TotalSpent float64 `json:"total_spent" db:"total_spent"` // сумма продаж с учетом скидок
+}
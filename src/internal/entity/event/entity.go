@@ -0,0 +1,100 @@
+package event
+
+import (
+	"product_storage/tools/jsonb"
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+	"github.com/sirupsen/logrus"
+)
+
+// типы доменных событий
+const (
+	ProductCreated = "ProductCreated" // добавлен продукт
+	PriceChanged   = "PriceChanged"   // изменена цена варианта продукта
+	StockChanged   = "StockChanged"   // изменено кол-во варианта продукта на складе
+	SaleRecorded   = "SaleRecorded"   // записана продажа
+	LowStock       = "LowStock"       // остаток варианта продукта на складе опустился ниже минимального
+)
+
+// TypeList все типы доменных событий
+var TypeList = []string{ProductCreated, PriceChanged, StockChanged, SaleRecorded, LowStock}
+
+// IsKnownType проверка типа события
+func IsKnownType(eventType string) bool {
+	for _, t := range TypeList {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Event доменное событие, сохраняется в outbox в одной транзакции с изменением
+// и публикуется в брокер сообщений отдельным процессом
+type Event struct {
+	EventID     int64          `json:"event_id" db:"event_id"`         // id события, используется получателями для дедупликации
+	EventType   string         `json:"event_type" db:"event_type"`     // тип события
+	AggregateID int            `json:"aggregate_id" db:"aggregate_id"` // id измененной сущности
+	Payload     types.JSONText `json:"payload" db:"payload"`           // данные события
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`     // дата возникновения события
+	Attempts    int            `json:"-" db:"attempts"`                // кол-во неудачных попыток публикации
+}
+
+// NewEvent событие с данными payload
+func NewEvent(eventType string, aggregateID int, payload any) (Event, error) {
+	data, err := jsonb.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		EventType:   eventType,
+		AggregateID: aggregateID,
+		Payload:     data,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+func (e Event) Log() logrus.Fields {
+	return logrus.Fields{
+		"event_ID":     e.EventID,
+		"event_type":   e.EventType,
+		"aggregate_ID": e.AggregateID,
+		"attempts":     e.Attempts,
+	}
+}
+
+// ProductCreatedPayload данные события ProductCreated
+type ProductCreatedPayload struct {
+	ProductID int    `json:"product_id"`
+	Name      string `json:"name"`
+	Tags      string `json:"tags"`
+}
+
+// PriceChangedPayload данные события PriceChanged
+type PriceChangedPayload struct {
+	PriceID   int       `json:"price_id"`
+	VariantID int       `json:"variant_id"`
+	Price     float64   `json:"price"`
+	StartDate time.Time `json:"start_date"`
+}
+
+// StockChangedPayload данные события StockChanged
+type StockChangedPayload struct {
+	VariantID int `json:"variant_id"`
+	StorageID int `json:"storage_id"`
+	Quantity  int `json:"quantity"`
+}
+
+// SaleRecordedPayload данные события SaleRecorded
+type SaleRecordedPayload struct {
+	SaleID     int       `json:"sale_id"`
+	VariantID  int       `json:"variant_id"`
+	StorageID  int       `json:"storage_id"`
+	Quantity   int       `json:"quantity"`
+	TotalPrice float64   `json:"total_price"`
+	SoldAt     time.Time `json:"sold_at"`
+	CustomerID int       `json:"customer_id,omitempty"`
+}
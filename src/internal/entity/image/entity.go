@@ -0,0 +1,121 @@
+package image
+
+import (
+	"errors"
+	"product_storage/tools/sqlnull"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// поддерживаемые форматы изображений
+const (
+	ContentTypeJPEG = "image/jpeg"
+	ContentTypePNG  = "image/png"
+)
+
+// formatContentType тип содержимого по формату, определенному при декодировании изображения
+var formatContentType = map[string]string{
+	"jpeg": ContentTypeJPEG,
+	"png":  ContentTypePNG,
+}
+
+// contentTypeExt расширение файла изображения в хранилище
+var contentTypeExt = map[string]string{
+	ContentTypeJPEG: ".jpg",
+	ContentTypePNG:  ".png",
+}
+
+var (
+	// ErrUnsupportedImage файл не является изображением в поддерживаемом формате
+	ErrUnsupportedImage = errors.New("файл должен быть изображением в формате jpeg или png")
+	// ErrTooLarge размер файла превышает допустимый
+	ErrTooLarge = errors.New("размер изображения превышает допустимый")
+	// ErrNotFound изображение не найдено
+	ErrNotFound = errors.New("изображение не найдено")
+)
+
+// ContentType тип содержимого изображения формата format, пустая строка для неподдерживаемого формата
+func ContentType(format string) string {
+	return formatContentType[format]
+}
+
+// Ext расширение файла изображения с типом содержимого contentType
+func Ext(contentType string) string {
+	return contentTypeExt[contentType]
+}
+
+// UploadParams параметры загрузки изображения
+type UploadParams struct {
+	ProductID int `form:"product_id"` // id продукта
+	VariantID int `form:"variant_id"` // id варианта, 0 если изображение относится ко всему продукту
+}
+
+func (p UploadParams) Log() logrus.Fields {
+	return logrus.Fields{
+		"product_ID": p.ProductID,
+		"variant_ID": p.VariantID,
+	}
+}
+
+// Validate проверка параметров загрузки
+func (p UploadParams) Validate() error {
+	if p.ProductID <= 0 || p.VariantID < 0 {
+		return errors.New("поле product_id должно быть больше 0, variant_id не может быть отрицательным")
+	}
+
+	return nil
+}
+
+// OrderParams новый порядок изображений продукта
+type OrderParams struct {
+	ProductID   int   `json:"product_id"`    // id продукта
+	ImageIDList []int `json:"image_id_list"` // id всех изображений продукта в новом порядке
+}
+
+// Validate проверка нового порядка изображений
+func (p OrderParams) Validate() error {
+	if p.ProductID <= 0 {
+		return errors.New("id продукта не может быть меньше или равен 0")
+	}
+
+	if len(p.ImageIDList) == 0 {
+		return errors.New("список изображений не может быть пустым")
+	}
+
+	seen := make(map[int]bool, len(p.ImageIDList))
+	for _, imageID := range p.ImageIDList {
+		if seen[imageID] {
+			return errors.New("изображение указано в списке несколько раз")
+		}
+		seen[imageID] = true
+	}
+
+	return nil
+}
+
+// Image изображение продукта или его варианта
+type Image struct {
+	ImageID     int               `json:"image_id" db:"image_id"`         // id изображения
+	ProductID   int               `json:"product_id" db:"product_id"`     // id продукта
+	VariantID   sqlnull.NullInt64 `json:"variant_id" db:"variant_id"`     // id варианта
+	BlobKey     string            `json:"-" db:"blob_key"`                // ключ исходного файла в хранилище
+	ThumbKey    string            `json:"-" db:"thumb_key"`               // ключ превью в хранилище
+	ContentType string            `json:"content_type" db:"content_type"` // тип содержимого
+	Width       int               `json:"width" db:"width"`               // ширина исходного изображения в пикселях
+	Height      int               `json:"height" db:"height"`             // высота исходного изображения в пикселях
+	Position    int               `json:"position" db:"position"`         // порядковый номер в галерее продукта
+	IsPrimary   bool              `json:"is_primary" db:"is_primary"`     // основное изображение продукта
+	CreatedAt   time.Time         `json:"created_at" db:"created_at"`     // дата загрузки
+	URL         string            `json:"url" db:"-"`                     // адрес исходного изображения
+	ThumbURL    string            `json:"thumb_url" db:"-"`               // адрес превью
+}
+
+func (i Image) Log() logrus.Fields {
+	return logrus.Fields{
+		"image_ID":   i.ImageID,
+		"product_ID": i.ProductID,
+		"variant_ID": i.VariantID,
+		"blob_key":   i.BlobKey,
+	}
+}
@@ -0,0 +1,155 @@
+package location
+
+import (
+	"errors"
+	"product_storage/tools/sqlnull"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// уровни мест хранения от крупного к мелкому
+const (
+	KindZone  = "zone"  // зона склада
+	KindAisle = "aisle" // ряд
+	KindShelf = "shelf" // стеллаж, полка
+	KindBin   = "bin"   // ячейка
+)
+
+// kindLevel уровень места хранения в иерархии
+var kindLevel = map[string]int{
+	KindZone:  1,
+	KindAisle: 2,
+	KindShelf: 3,
+	KindBin:   4,
+}
+
+// PathSeparator разделитель кодов в адресе места хранения
+const PathSeparator = "/"
+
+var (
+	// ErrNotEnoughUnplaced на складе недостаточно продукта, не разложенного по местам хранения
+	ErrNotEnoughUnplaced = errors.New("недостаточно неразмещенного продукта на складе")
+	// ErrNotEnoughInLocation в месте хранения недостаточно продукта
+	ErrNotEnoughInLocation = errors.New("недостаточно продукта в месте хранения")
+)
+
+// CanContain может ли место хранения вида parent содержать место вида child: вложенное место мельче родителя
+func CanContain(parent, child string) bool {
+	return kindLevel[parent] > 0 && kindLevel[child] > kindLevel[parent]
+}
+
+// Params параметры создания места хранения
+type Params struct {
+	StorageID int    `json:"storage_id"` // id склада
+	ParentID  int    `json:"parent_id"`  // id родительского места, 0 для места верхнего уровня
+	Kind      string `json:"kind"`       // вид места: zone, aisle, shelf или bin
+	Code      string `json:"code"`       // код места внутри родителя, например A или 03
+	Name      string `json:"name"`       // название
+}
+
+func (p Params) Log() logrus.Fields {
+	return logrus.Fields{
+		"storage_ID": p.StorageID,
+		"parent_ID":  p.ParentID,
+		"kind":       p.Kind,
+		"code":       p.Code,
+	}
+}
+
+// Validate проверка параметров места хранения
+func (p Params) Validate() error {
+	if p.StorageID <= 0 || p.ParentID < 0 {
+		return errors.New("поле storage_id должно быть больше 0, parent_id не может быть отрицательным")
+	}
+
+	if kindLevel[p.Kind] == 0 {
+		return errors.New("вид места хранения должен быть одним из: zone, aisle, shelf, bin")
+	}
+
+	if p.Code == "" || strings.Contains(p.Code, PathSeparator) {
+		return errors.New("код места хранения не может быть пустым или содержать " + PathSeparator)
+	}
+
+	return nil
+}
+
+// Location место хранения на складе
+type Location struct {
+	LocationID int               `json:"location_id" db:"location_id"` // id места хранения
+	StorageID  int               `json:"storage_id" db:"storage_id"`   // id склада
+	ParentID   sqlnull.NullInt64 `json:"parent_id" db:"parent_id"`     // id родительского места
+	Kind       string            `json:"kind" db:"kind"`               // вид места
+	Code       string            `json:"code" db:"code"`               // код места внутри родителя
+	Name       string            `json:"name" db:"name"`               // название
+	Path       string            `json:"path" db:"path"`               // полный адрес места, например A/03/2/B
+	CreatedAt  time.Time         `json:"created_at" db:"created_at"`   // дата создания
+}
+
+// ChildPath адрес вложенного места с кодом code
+func (l Location) ChildPath(code string) string {
+	return l.Path + PathSeparator + code
+}
+
+// Stock кол-во варианта продукта в месте хранения
+type Stock struct {
+	LocationID int    `json:"location_id" db:"location_id"` // id места хранения
+	Path       string `json:"path" db:"path"`               // адрес места
+	VariantID  int    `json:"variant_id" db:"variant_id"`   // id варианта продукта
+	Quantity   int    `json:"quantity" db:"quantity"`       // кол-во
+}
+
+// PutawayParams размещение неразмещенного продукта склада в место хранения
+type PutawayParams struct {
+	VariantID  int `json:"variant_id"`  // id варианта продукта
+	LocationID int `json:"location_id"` // id места хранения
+	Quantity   int `json:"quantity"`    // размещаемое кол-во
+}
+
+func (p PutawayParams) Log() logrus.Fields {
+	return logrus.Fields{
+		"variant_ID":  p.VariantID,
+		"location_ID": p.LocationID,
+		"quantity":    p.Quantity,
+	}
+}
+
+// Validate проверка параметров размещения
+func (p PutawayParams) Validate() error {
+	if p.VariantID <= 0 || p.LocationID <= 0 || p.Quantity <= 0 {
+		return errors.New("поля variant_id, location_id и quantity должны быть больше 0")
+	}
+
+	return nil
+}
+
+// MoveParams перемещение продукта между местами хранения одного склада
+type MoveParams struct {
+	VariantID      int `json:"variant_id"`       // id варианта продукта
+	FromLocationID int `json:"from_location_id"` // id места, откуда перемещается продукт
+	ToLocationID   int `json:"to_location_id"`   // id места, куда перемещается продукт
+	Quantity       int `json:"quantity"`         // перемещаемое кол-во
+}
+
+func (p MoveParams) Log() logrus.Fields {
+	return logrus.Fields{
+		"variant_ID":       p.VariantID,
+		"from_location_ID": p.FromLocationID,
+		"to_location_ID":   p.ToLocationID,
+		"quantity":         p.Quantity,
+	}
+}
+
+// Validate проверка параметров перемещения
+func (p MoveParams) Validate() error {
+	if p.VariantID <= 0 || p.FromLocationID <= 0 || p.ToLocationID <= 0 || p.Quantity <= 0 {
+		return errors.New("поля variant_id, from_location_id, to_location_id и quantity должны быть больше 0")
+	}
+
+	if p.FromLocationID == p.ToLocationID {
+		return errors.New("место хранения назначения совпадает с исходным")
+	}
+
+	return nil
+}
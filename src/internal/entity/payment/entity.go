@@ -0,0 +1,188 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// способы оплаты
+const (
+	MethodCash     = "cash"     // наличные
+	MethodCard     = "card"     // банковская карта
+	MethodTransfer = "transfer" // перевод
+)
+
+// MethodList все способы оплаты в порядке вывода в отчетах
+var MethodList = []string{MethodCash, MethodCard, MethodTransfer}
+
+// IsValidMethod известен ли способ оплаты
+func IsValidMethod(method string) bool {
+	for _, m := range MethodList {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+// toCents сумма в копейках, суммы оплат сравниваются в копейках без погрешности float64
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// Params оплата части или всей суммы продажи
+type Params struct {
+	Method string  `json:"method"` // способ оплаты
+	Amount float64 `json:"amount"` // сумма
+}
+
+// ValidateList проверка оплат продажи: способы известны, суммы положительны и в сумме равны total
+func ValidateList(list []Params, total float64) error {
+	if len(list) == 0 {
+		return errors.New("у продажи должна быть хотя бы одна оплата")
+	}
+
+	var paid int64
+	for _, p := range list {
+		if !IsValidMethod(p.Method) {
+			return errors.New("способ оплаты должен быть одним из: cash, card, transfer")
+		}
+
+		if toCents(p.Amount) <= 0 {
+			return errors.New("сумма оплаты должна быть больше 0")
+		}
+
+		paid += toCents(p.Amount)
+	}
+
+	if paid != toCents(total) {
+		return fmt.Errorf("сумма оплат %.2f не равна сумме продажи %.2f", float64(paid)/100, total)
+	}
+
+	return nil
+}
+
+// Payment оплата продажи
+type Payment struct {
+	PaymentID int       `json:"payment_id" db:"payment_id"` // id оплаты
+	SaleID    int       `json:"sale_id" db:"sales_id"`      // id продажи
+	Method    string    `json:"method" db:"method"`         // способ оплаты
+	Amount    float64   `json:"amount" db:"amount"`         // сумма
+	PaidAt    time.Time `json:"paid_at" db:"paid_at"`       // дата оплаты
+}
+
+// ZQuery параметры кассового отчета за день
+type ZQuery struct {
+	Date      time.Time `json:"date"`       // день отчета, границы дня берутся в часовом поясе даты
+	StorageID int       `json:"storage_id"` // id склада, 0 если по всем складам
+}
+
+func (q ZQuery) Log() logrus.Fields {
+	return logrus.Fields{"date": q.Date, "storage_ID": q.StorageID}
+}
+
+// Validate проверка параметров отчета
+func (q ZQuery) Validate() error {
+	if q.Date.IsZero() {
+		return errors.New("нужно указать день отчета")
+	}
+
+	if q.StorageID < 0 {
+		return errors.New("id склада не может быть меньше нуля")
+	}
+
+	return nil
+}
+
+// Period начало дня отчета и начало следующего дня
+func (q ZQuery) Period() (start, end time.Time) {
+	y, m, d := q.Date.Date()
+	start = time.Date(y, m, d, 0, 0, 0, 0, q.Date.Location())
+	return start, start.AddDate(0, 0, 1)
+}
+
+// ZStorageTotal итоги продаж склада за день
+type ZStorageTotal struct {
+	StorageID   int     `json:"storage_id" db:"storage_id"`     // id склада
+	StorageName string  `json:"storage_name" db:"storage_name"` // название склада
+	SaleCount   int     `json:"sale_count" db:"sale_count"`     // кол-во продаж
+	Revenue     float64 `json:"revenue" db:"revenue"`           // сумма продаж с учетом скидок
+	Discount    float64 `json:"discount" db:"discount"`         // сумма скидок
+}
+
+// ZMethodTotal итоги оплат склада за день одним способом
+type ZMethodTotal struct {
+	StorageID    int     `json:"-" db:"storage_id"`                // id склада
+	Method       string  `json:"method" db:"method"`               // способ оплаты
+	PaymentCount int     `json:"payment_count" db:"payment_count"` // кол-во оплат
+	Amount       float64 `json:"amount" db:"amount"`               // сумма оплат
+}
+
+// ZStorage кассовый отчет склада
+type ZStorage struct {
+	ZStorageTotal
+	Methods []ZMethodTotal `json:"methods"` // оплаты по способам
+	// WithoutPayments сумма продаж без записанных оплат, проведенных до учета оплат
+	WithoutPayments float64 `json:"without_payments"`
+}
+
+// ZReport кассовый отчет за день по складам и способам оплаты
+type ZReport struct {
+	Start     time.Time          `json:"start"`      // начало дня
+	End       time.Time          `json:"end"`        // начало следующего дня
+	SaleCount int                `json:"sale_count"` // кол-во продаж по всем складам
+	Revenue   float64            `json:"revenue"`    // сумма продаж по всем складам
+	Discount  float64            `json:"discount"`   // сумма скидок по всем складам
+	Methods   map[string]float64 `json:"methods"`    // суммы оплат по способам по всем складам
+	Storages  []ZStorage         `json:"storages"`   // отчеты складов
+}
+
+// NewZReport отчет из итогов складов и оплат, у каждого склада выводятся все способы оплаты
+func NewZReport(start, end time.Time, storageList []ZStorageTotal, methodList []ZMethodTotal) ZReport {
+	r := ZReport{
+		Start:    start,
+		End:      end,
+		Methods:  make(map[string]float64, len(MethodList)),
+		Storages: make([]ZStorage, 0, len(storageList)),
+	}
+	for _, m := range MethodList {
+		r.Methods[m] = 0
+	}
+
+	byStorage := make(map[int]map[string]ZMethodTotal)
+	for _, m := range methodList {
+		if byStorage[m.StorageID] == nil {
+			byStorage[m.StorageID] = make(map[string]ZMethodTotal)
+		}
+		byStorage[m.StorageID][m.Method] = m
+	}
+
+	for _, st := range storageList {
+		s := ZStorage{ZStorageTotal: st, Methods: make([]ZMethodTotal, 0, len(MethodList))}
+
+		var paid int64
+		for _, method := range MethodList {
+			m, ok := byStorage[st.StorageID][method]
+			if !ok {
+				m = ZMethodTotal{StorageID: st.StorageID, Method: method}
+			}
+
+			s.Methods = append(s.Methods, m)
+			paid += toCents(m.Amount)
+			r.Methods[method] = float64(toCents(r.Methods[method])+toCents(m.Amount)) / 100
+		}
+		s.WithoutPayments = float64(toCents(st.Revenue)-paid) / 100
+
+		r.SaleCount += st.SaleCount
+		r.Revenue = float64(toCents(r.Revenue)+toCents(st.Revenue)) / 100
+		r.Discount = float64(toCents(r.Discount)+toCents(st.Discount)) / 100
+		r.Storages = append(r.Storages, s)
+	}
+
+	return r
+}
@@ -0,0 +1,60 @@
+package product
+
+import (
+	"errors"
+)
+
+// ean13Length длина штрихкода EAN-13
+const ean13Length = 13
+
+var (
+	// ErrInvalidBarcode штрихкод не является корректным EAN-13
+	ErrInvalidBarcode = errors.New("штрихкод должен состоять из 13 цифр с верной контрольной цифрой EAN-13")
+	// ErrBarcodeNotFound вариант с таким штрихкодом не найден
+	ErrBarcodeNotFound = errors.New("вариант с таким штрихкодом не найден")
+	// ErrBarcodeExists штрихкод уже привязан к варианту
+	ErrBarcodeExists = errors.New("штрихкод уже привязан к варианту")
+	// ErrSKUExists артикул уже используется другим вариантом
+	ErrSKUExists = errors.New("артикул уже используется другим вариантом")
+)
+
+// ValidateEAN13 проверка штрихкода EAN-13: 13 цифр, последняя из которых контрольная.
+// Цифры на нечетных позициях берутся с весом 1, на четных с весом 3, контрольная дополняет сумму до кратной 10
+func ValidateEAN13(code string) error {
+	if len(code) != ean13Length {
+		return ErrInvalidBarcode
+	}
+
+	sum := 0
+	for i := 0; i < ean13Length-1; i++ {
+		d := code[i]
+		if d < '0' || d > '9' {
+			return ErrInvalidBarcode
+		}
+
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(d-'0') * weight
+	}
+
+	check := code[ean13Length-1]
+	if check < '0' || check > '9' || int(check-'0') != (10-sum%10)%10 {
+		return ErrInvalidBarcode
+	}
+
+	return nil
+}
+
+// BarcodeParams привязка штрихкода к варианту
+type BarcodeParams struct {
+	VariantID int    `json:"variant_id"` // id варианта продукта
+	Barcode   string `json:"barcode"`    // штрихкод EAN-13
+}
+
+// BarcodeVariant вариант, найденный по штрихкоду, с названием продукта, ценой и доступным кол-вом на складах
+type BarcodeVariant struct {
+	ProductName string `json:"product_name" db:"product_name"` // название продукта
+	Variant
+}
@@ -1,31 +1,72 @@
 package product
 
 import (
+	"product_storage/internal/entity/attribute"
+	"product_storage/internal/entity/image"
+	"product_storage/internal/entity/unit"
 	"product_storage/tools/sqlnull"
 	"time"
+
+	"github.com/jmoiron/sqlx/types"
 )
 
 // Variant структура варианта, продукта представляем с собой информацию о продукте который нужно внести в базу
 type Variant struct {
-	ProductID    int          `json:"product_id" db:"product_id"` // id продука
-	VariantID    int          `json:"variant_id" db:"variant_id"` // id конкретного варианта продукта
-	Weight       int          `json:"weight" db:"weight"`         // масса или вес продукта
-	Unit         string       `json:"unit" db:"unit"`             // единица измерения
-	AddedAt      time.Time    `json:"added_at" db:"added_at"`     // дата добавления определенного варианта
-	CurrentPrice float64      `json:"price" db:"price"`           // актуальная цена
-	InStorages   []VarStorage `json:"in_storages"`                // список названий складов в которых есть этот вариант
+	ProductID        int                `json:"product_id" db:"product_id"`           // id продука
+	VariantID        int                `json:"variant_id" db:"variant_id"`           // id конкретного варианта продукта
+	Weight           float64            `json:"weight" db:"weight"`                   // мера варианта в единицах unit, может быть дробной
+	Unit             string             `json:"unit" db:"unit"`                       // единица измерения
+	AddedAt          time.Time          `json:"added_at" db:"added_at"`               // дата добавления определенного варианта
+	CurrentPrice     float64            `json:"price" db:"price"`                     // актуальная цена
+	InStorages       []VarStorage       `json:"in_storages"`                          // список названий складов в которых есть этот вариант
+	SKU              sqlnull.NullString `json:"sku" db:"sku"`                         // артикул варианта
+	Barcodes         []string           `json:"barcodes,omitempty" db:"-"`            // штрихкоды EAN-13 варианта
+	UnitDimension    string             `json:"-" db:"unit_dimension"`                // измеряемая величина единицы варианта
+	UnitFactor       float64            `json:"-" db:"unit_factor"`                   // множитель перевода единицы варианта в базовую
+	PricePerBaseUnit float64            `json:"price_per_base_unit,omitempty" db:"-"` // цена за кг, л, шт или м
+	BaseUnit         string             `json:"base_unit,omitempty" db:"-"`           // базовая единица цены за единицу
+}
+
+// SetPricePerBaseUnit расчет цены за базовую единицу по актуальной цене, мере и единице варианта
+func (v *Variant) SetPricePerBaseUnit() {
+	v.PricePerBaseUnit = unit.PricePerBaseUnit(v.CurrentPrice, v.Weight, v.UnitFactor)
+	v.BaseUnit = ""
+	if v.PricePerBaseUnit > 0 {
+		v.BaseUnit = unit.BaseUnit(v.UnitDimension)
+	}
 }
+
 type VarStorage struct {
 	StorageID   int    `db:"storage_id"`
 	StorageName string `db:"name"`
+	Quantity    int    `db:"quantity"`  // кол-во варианта на складе
+	Reserved    int    `db:"reserved"`  // кол-во, зарезервированное под заказы
+	Available   int    `db:"available"` // кол-во, доступное для продажи
+}
+
+// VariantStorage склад, в котором находится вариант продукта
+type VariantStorage struct {
+	VariantID int `db:"variant_id"` // id варианта продукта
+	VarStorage
+}
+
+// VariantPrice актуальная цена варианта продукта
+type VariantPrice struct {
+	VariantID int     `db:"variant_id"` // id варианта продукта
+	Price     float64 `db:"price"`      // актуальная цена
 }
 
 // ProductInfo структура информации о продукте о котором нужно получить информацию
 type ProductInfo struct {
-	ProductID   int       `db:"product_id"`       // id продукта
-	Name        string    `db:"name"`             // название продукта
-	Descr       string    `db:"description"`      // описание продукта
-	VariantList []Variant `db:"product_variants"` // список вариантов продукта
+	ProductID   int               `db:"product_id"`       // id продукта
+	Name        string            `db:"name"`             // название продукта
+	Descr       string            `db:"description"`      // описание продукта
+	VariantList []Variant         `db:"product_variants"` // список вариантов продукта
+	Images      []image.Image     `db:"-"`                // изображения продукта и его вариантов, основное первым
+	CategoryID  sqlnull.NullInt64 `db:"category_id"`      // id категории продукта
+	Attributes  attribute.Values  `db:"-"`                // значения атрибутов категории
+	// RawAttributes значения атрибутов в виде jsonb
+	RawAttributes types.JSONText `json:"-" db:"attributes"`
 }
 
 // Sale структура продажи
@@ -37,4 +78,8 @@ type Sale struct {
 	SoldAt      time.Time          `db:"sold_at"`                      // дата продажи
 	Quantity    int                `json:"quantity" db:"quantity"`     // кол-во проданного продукта
 	TotalPrice  float64            `db:"total_price"`                  // общая стоимость с учетом кол-ва продукта
+	CostOfGoods float64            `db:"cost_of_goods"`                // себестоимость проданного продукта
+	PromotionID sqlnull.NullInt64  `db:"promotion_id"`                 // id примененной акции
+	Discount    float64            `db:"discount"`                     // скидка по акции
+	CustomerID  sqlnull.NullInt64  `db:"customer_id"`                  // id покупателя
 }
@@ -2,6 +2,8 @@ package product
 
 import (
 	"errors"
+	"product_storage/internal/entity/attribute"
+	"product_storage/internal/entity/payment"
 	"product_storage/tools/sqlnull"
 	"time"
 
@@ -17,6 +19,8 @@ type ProductParams struct {
 	RemovedAt   sqlnull.NullTime `json:"removed_at"`  // дата удаления продукта
 	Tags        string           `json:"tags"`        // теги продукта
 	VariantList []Variant        `json:"variants"`    // cписок вариантов продукта
+	CategoryID  int              `json:"category_id"` // id категории продукта, 0 если продукт без категории
+	Attributes  attribute.Values `json:"attributes"`  // значения атрибутов категории
 }
 
 func (p ProductParams) Log() logrus.Fields {
@@ -52,12 +56,18 @@ type SaleParams struct {
 	SoldAt      time.Time          `db:"sold_at"`                      // дата продажи
 	Quantity    int                `json:"quantity" db:"quantity"`     // кол-во проданного продукта
 	TotalPrice  float64            `db:"total_price"`                  // общая стоимость с учетом кол-ва продукта
+	CostOfGoods float64            `json:"-" db:"cost_of_goods"`       // себестоимость проданного продукта
+	Barcode     string             `json:"barcode" db:"-"`             // штрихкод варианта, если variant_id не указан
+	PromotionID sqlnull.NullInt64  `json:"-" db:"promotion_id"`        // id примененной к продаже акции
+	Discount    float64            `json:"-" db:"discount"`            // скидка по акции, уже вычтена из total_price
+	CustomerID  int                `json:"customer_id" db:"-"`         // id покупателя, необязательный
+	Payments    []payment.Params   `json:"payments" db:"-"`            // оплаты, без них вся сумма оплачивается способом по умолчанию
 }
 
-// IsNullFields проверка полей нва нулевые значения
+// IsNullFields проверка полей нва нулевые значения, вариант задается variant_id или штрихкодом
 func (s SaleParams) IsNullFields() error {
-	if s.VariantID == 0 || s.StorageID == 0 || s.Quantity == 0 {
-		return errors.New("variant_id, storage_id или quantity являются пустыми полями")
+	if (s.VariantID == 0 && s.Barcode == "") || s.StorageID == 0 || s.Quantity == 0 {
+		return errors.New("variant_id или barcode, storage_id или quantity являются пустыми полями")
 	}
 	return nil
 }
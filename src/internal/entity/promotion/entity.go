@@ -0,0 +1,162 @@
+package promotion
+
+import (
+	"errors"
+	"math"
+	"product_storage/tools/sqlnull"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// виды скидок акции
+const (
+	KindPercent  = "percent"     // процент от цены каждой единицы
+	KindFixed    = "fixed"       // фиксированная сумма с каждой единицы, не больше ее цены
+	KindBuyXGetY = "buy_x_get_y" // из каждых buy_quantity + free_quantity единиц free_quantity бесплатно
+)
+
+// ErrNotFound акция не найдена
+var ErrNotFound = errors.New("акция не найдена")
+
+// Params параметры создания акции. Акция действует на вариант, тег или категорию продуктов,
+// без них на все продукты; без storage_id на всех складах
+type Params struct {
+	Name         string           `json:"name"`          // название акции
+	Kind         string           `json:"kind"`          // вид скидки
+	Value        float64          `json:"value"`         // процент или сумма скидки
+	BuyQuantity  int              `json:"buy_quantity"`  // кол-во оплачиваемых единиц для buy_x_get_y
+	FreeQuantity int              `json:"free_quantity"` // кол-во бесплатных единиц для buy_x_get_y
+	VariantID    int              `json:"variant_id"`    // id варианта
+	Tag          string           `json:"tag"`           // тег продуктов
+	CategoryID   int              `json:"category_id"`   // id категории продуктов
+	StorageID    int              `json:"storage_id"`    // id склада
+	MinAmount    float64          `json:"min_amount"`    // минимальная сумма продажи по цене без скидки
+	StartsAt     time.Time        `json:"starts_at"`     // начало действия, по умолчанию сейчас
+	EndsAt       sqlnull.NullTime `json:"ends_at"`       // окончание действия, без него акция бессрочная
+}
+
+func (p Params) Log() logrus.Fields {
+	return logrus.Fields{
+		"name":        p.Name,
+		"kind":        p.Kind,
+		"variant_ID":  p.VariantID,
+		"tag":         p.Tag,
+		"category_ID": p.CategoryID,
+		"storage_ID":  p.StorageID,
+	}
+}
+
+// Validate проверка параметров акции
+func (p Params) Validate() error {
+	if p.Name == "" {
+		return errors.New("название акции не может быть пустым")
+	}
+
+	switch p.Kind {
+	case KindPercent:
+		if p.Value <= 0 || p.Value >= 100 {
+			return errors.New("процент скидки должен быть больше 0 и меньше 100")
+		}
+	case KindFixed:
+		if p.Value <= 0 {
+			return errors.New("сумма скидки должна быть больше 0")
+		}
+	case KindBuyXGetY:
+		if p.BuyQuantity <= 0 || p.FreeQuantity <= 0 {
+			return errors.New("поля buy_quantity и free_quantity должны быть больше 0")
+		}
+	default:
+		return errors.New("вид скидки должен быть одним из: percent, fixed, buy_x_get_y")
+	}
+
+	if p.VariantID < 0 || p.CategoryID < 0 || p.StorageID < 0 || p.MinAmount < 0 {
+		return errors.New("поля variant_id, category_id, storage_id и min_amount не могут быть отрицательными")
+	}
+
+	targets := 0
+	for _, set := range []bool{p.VariantID > 0, p.Tag != "", p.CategoryID > 0} {
+		if set {
+			targets++
+		}
+	}
+	if targets > 1 {
+		return errors.New("акция действует только на одно из: вариант, тег или категорию")
+	}
+
+	if p.EndsAt.Valid && !p.StartsAt.IsZero() && !p.EndsAt.Time.After(p.StartsAt) {
+		return errors.New("окончание акции должно быть позже ее начала")
+	}
+
+	return nil
+}
+
+// Promotion акция
+type Promotion struct {
+	PromotionID  int                `json:"promotion_id" db:"promotion_id"`   // id акции
+	Name         string             `json:"name" db:"name"`                   // название
+	Kind         string             `json:"kind" db:"kind"`                   // вид скидки
+	Value        float64            `json:"value" db:"value"`                 // процент или сумма скидки
+	BuyQuantity  int                `json:"buy_quantity" db:"buy_quantity"`   // кол-во оплачиваемых единиц
+	FreeQuantity int                `json:"free_quantity" db:"free_quantity"` // кол-во бесплатных единиц
+	VariantID    sqlnull.NullInt64  `json:"variant_id" db:"variant_id"`       // id варианта
+	Tag          sqlnull.NullString `json:"tag" db:"tag"`                     // тег продуктов
+	CategoryID   sqlnull.NullInt64  `json:"category_id" db:"category_id"`     // id категории
+	StorageID    sqlnull.NullInt64  `json:"storage_id" db:"storage_id"`       // id склада
+	MinAmount    float64            `json:"min_amount" db:"min_amount"`       // минимальная сумма продажи
+	StartsAt     time.Time          `json:"starts_at" db:"starts_at"`         // начало действия
+	EndsAt       sqlnull.NullTime   `json:"ends_at" db:"ends_at"`             // окончание действия
+	CreatedAt    time.Time          `json:"created_at" db:"created_at"`       // дата создания
+}
+
+// roundMoney округление суммы до копеек
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// Discount скидка акции на quantity единиц по цене price, 0 если не набрана минимальная сумма.
+// Скидка не превышает сумму продажи
+func (p Promotion) Discount(price float64, quantity int) float64 {
+	gross := price * float64(quantity)
+	if gross <= 0 || gross < p.MinAmount {
+		return 0
+	}
+
+	var discount float64
+	switch p.Kind {
+	case KindPercent:
+		discount = gross * p.Value / 100
+	case KindFixed:
+		discount = math.Min(p.Value, price) * float64(quantity)
+	case KindBuyXGetY:
+		if set := p.BuyQuantity + p.FreeQuantity; set > 0 {
+			discount = price * float64(quantity/set*p.FreeQuantity)
+		}
+	}
+
+	return roundMoney(math.Min(discount, gross))
+}
+
+// Best акция с наибольшей скидкой на продажу, акции не суммируются
+func Best(promotionList []Promotion, price float64, quantity int) (best Promotion, discount float64, found bool) {
+	for _, p := range promotionList {
+		if d := p.Discount(price, quantity); d > discount {
+			best, discount, found = p, d, true
+		}
+	}
+
+	return best, discount, found
+}
+
+// Quote расчет цены продажи с учетом акций
+type Quote struct {
+	VariantID     int                `json:"variant_id"`     // id варианта
+	StorageID     int                `json:"storage_id"`     // id склада
+	Quantity      int                `json:"quantity"`       // кол-во
+	Price         float64            `json:"price"`          // цена единицы без скидки
+	Gross         float64            `json:"gross"`          // сумма без скидки
+	Discount      float64            `json:"discount"`       // скидка
+	Total         float64            `json:"total"`          // сумма к оплате
+	PromotionID   sqlnull.NullInt64  `json:"promotion_id"`   // id примененной акции
+	PromotionName sqlnull.NullString `json:"promotion_name"` // название примененной акции
+}
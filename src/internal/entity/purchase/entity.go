@@ -0,0 +1,185 @@
+package purchase
+
+import (
+	"errors"
+	"fmt"
+	"product_storage/internal/entity/stock"
+	"product_storage/tools/sqlnull"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// статусы заказа поставщику
+const (
+	StatusDraft             = "draft"              // черновик, заказ можно отменить или отправить
+	StatusSent              = "sent"               // отправлен поставщику, ожидает поставки
+	StatusPartiallyReceived = "partially_received" // часть заказа принята на склад
+	StatusReceived          = "received"           // заказ принят полностью
+	StatusCancelled         = "cancelled"          // заказ отменен
+)
+
+// transitions допустимые переходы между статусами заказа
+var transitions = map[string][]string{
+	StatusDraft:             {StatusSent, StatusCancelled},
+	StatusSent:              {StatusPartiallyReceived, StatusReceived, StatusCancelled},
+	StatusPartiallyReceived: {StatusPartiallyReceived, StatusReceived},
+}
+
+// CanTransition можно ли перевести заказ из статуса from в статус to
+func CanTransition(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ErrStatusTransition недопустимый переход статуса заказа
+func ErrStatusTransition(from, to string) error {
+	return fmt.Errorf("заказ в статусе %s нельзя перевести в статус %s", from, to)
+}
+
+// SupplierParams параметры поставщика
+type SupplierParams struct {
+	Name    string `json:"name" db:"name"`       // название поставщика
+	Contact string `json:"contact" db:"contact"` // контактное лицо
+	Phone   string `json:"phone" db:"phone"`     // телефон
+	Email   string `json:"email" db:"email"`     // электронная почта
+}
+
+func (p SupplierParams) Log() logrus.Fields {
+	return logrus.Fields{"name": p.Name}
+}
+
+// Validate проверка параметров поставщика
+func (p SupplierParams) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("название поставщика не может быть пустым")
+	}
+
+	return nil
+}
+
+// Supplier поставщик
+type Supplier struct {
+	SupplierID int       `json:"supplier_id" db:"supplier_id"` // id поставщика
+	AddedAt    time.Time `json:"added_at" db:"added_at"`       // дата добавления
+	SupplierParams
+}
+
+// OrderLineParams строка заказа поставщику
+type OrderLineParams struct {
+	VariantID     int     `json:"variant_id" db:"variant_id"`         // id варианта продукта
+	Quantity      int     `json:"quantity" db:"quantity"`             // заказанное кол-во
+	PurchasePrice float64 `json:"purchase_price" db:"purchase_price"` // закупочная цена за единицу
+}
+
+// OrderParams параметры заказа поставщику
+type OrderParams struct {
+	SupplierID int               `json:"supplier_id"` // id поставщика
+	StorageID  int               `json:"storage_id"`  // id склада, на который ожидается поставка
+	Comment    string            `json:"comment"`     // комментарий
+	Lines      []OrderLineParams `json:"lines"`       // строки заказа
+}
+
+func (p OrderParams) Log() logrus.Fields {
+	return logrus.Fields{
+		"supplier_ID": p.SupplierID,
+		"storage_ID":  p.StorageID,
+		"lines":       len(p.Lines),
+	}
+}
+
+// Validate проверка заказа и его строк
+func (p OrderParams) Validate() error {
+	if p.SupplierID <= 0 || p.StorageID <= 0 {
+		return errors.New("поля supplier_id и storage_id не должны быть пустыми")
+	}
+
+	if len(p.Lines) == 0 {
+		return errors.New("заказ должен содержать хотя бы одну строку")
+	}
+
+	variants := make(map[int]struct{}, len(p.Lines))
+	for _, l := range p.Lines {
+		if l.VariantID <= 0 || l.Quantity <= 0 || l.PurchasePrice < 0 {
+			return errors.New("в строке заказа должны быть указаны variant_id, quantity больше 0 и неотрицательная purchase_price")
+		}
+
+		if _, exists := variants[l.VariantID]; exists {
+			return fmt.Errorf("вариант %d указан в заказе несколько раз", l.VariantID)
+		}
+		variants[l.VariantID] = struct{}{}
+	}
+
+	return nil
+}
+
+// Order заказ поставщику
+type Order struct {
+	OrderID    int              `json:"order_id" db:"order_id"`       // id заказа
+	SupplierID int              `json:"supplier_id" db:"supplier_id"` // id поставщика
+	StorageID  int              `json:"storage_id" db:"storage_id"`   // id склада поставки
+	Status     string           `json:"status" db:"status"`           // статус заказа
+	Comment    string           `json:"comment" db:"comment"`         // комментарий
+	CreatedAt  time.Time        `json:"created_at" db:"created_at"`   // дата создания
+	SentAt     sqlnull.NullTime `json:"sent_at" db:"sent_at"`         // дата отправки поставщику
+	ClosedAt   sqlnull.NullTime `json:"closed_at" db:"closed_at"`     // дата полного приема или отмены
+	Lines      []OrderLine      `json:"lines" db:"-"`                 // строки заказа
+}
+
+func (o Order) Log() logrus.Fields {
+	return logrus.Fields{
+		"order_ID": o.OrderID,
+		"status":   o.Status,
+	}
+}
+
+// OrderLine строка заказа поставщику
+type OrderLine struct {
+	LineID           int              `json:"line_id" db:"line_id"`                     // id строки
+	OrderID          int              `json:"order_id" db:"order_id"`                   // id заказа
+	ReceivedQuantity int              `json:"received_quantity" db:"received_quantity"` // принятое кол-во
+	ClosedAt         sqlnull.NullTime `json:"closed_at" db:"closed_at"`                 // дата полного приема строки
+	OrderLineParams
+}
+
+// Remaining кол-во, которое еще ожидается к приему
+func (l OrderLine) Remaining() int {
+	return l.Quantity - l.ReceivedQuantity
+}
+
+// ReceiptLineParams принимаемое кол-во по строке заказа
+type ReceiptLineParams struct {
+	LineID   int                `json:"line_id"`         // id строки заказа
+	Quantity int                `json:"quantity"`        // принятое кол-во
+	Batch    *stock.BatchParams `json:"batch,omitempty"` // партия принятого товара, если ведется учет партий
+	UnitCost float64            `json:"unit_cost"`       // фактическая закупочная цена, если отличается от цены заказа
+}
+
+// ReceiptParams прием товара по заказу, если строки не указаны принимается весь оставшийся товар
+type ReceiptParams struct {
+	OrderID int                 `json:"order_id"` // id заказа
+	Lines   []ReceiptLineParams `json:"lines"`    // принятые строки
+}
+
+func (p ReceiptParams) Log() logrus.Fields {
+	return logrus.Fields{
+		"order_ID": p.OrderID,
+		"lines":    len(p.Lines),
+	}
+}
+
+// ReceiptLine строка приема товара
+type ReceiptLine struct {
+	ReceiptID     int                `db:"receipt_id"`     // id приема
+	LineID        int                `db:"line_id"`        // id строки заказа
+	VariantID     int                `db:"variant_id"`     // id варианта продукта
+	Quantity      int                `db:"quantity"`       // принятое кол-во
+	PurchasePrice float64            `db:"purchase_price"` // закупочная цена за единицу
+	Batch         *stock.BatchParams `db:"-"`              // партия принятого товара
+}
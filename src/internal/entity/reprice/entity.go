@@ -0,0 +1,190 @@
+package reprice
+
+import (
+	"errors"
+	"math"
+	"product_storage/tools/sqlnull"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// операции массового изменения цен
+const (
+	OpSet     = "set"     // установка одной цены
+	OpPercent = "percent" // изменение на процент, отрицательный процент снижает цену
+	OpAmount  = "amount"  // изменение на сумму, отрицательная сумма снижает цену
+)
+
+// режимы округления новой цены
+const (
+	RoundNearest = "nearest" // до ближайшего кратного шагу
+	RoundUp      = "up"      // вверх до кратного шагу
+	RoundDown    = "down"    // вниз до кратного шагу
+)
+
+// defaultStep шаг округления по умолчанию, копейки
+const defaultStep = 0.01
+
+// ErrNoVariants под условия не попал ни один вариант с действующей ценой
+var ErrNoVariants = errors.New("не найдено вариантов с действующей ценой для изменения")
+
+// Rounding правило округления новой цены
+type Rounding struct {
+	Step   float64 `json:"step"`   // шаг округления, по умолчанию 0.01
+	Mode   string  `json:"mode"`   // режим округления, по умолчанию nearest
+	Ending float64 `json:"ending"` // окончание цены внутри шага: при шаге 1 и окончании 0.99 цена 200 станет 199.99
+}
+
+// Validate проверка правила округления
+func (r Rounding) Validate() error {
+	if r.Step < 0 {
+		return errors.New("шаг округления не может быть отрицательным")
+	}
+
+	switch r.Mode {
+	case "", RoundNearest, RoundUp, RoundDown:
+	default:
+		return errors.New("режим округления должен быть одним из: nearest, up, down")
+	}
+
+	if r.Ending < 0 || (r.Ending > 0 && r.Ending >= r.step()) {
+		return errors.New("окончание цены должно быть не меньше 0 и меньше шага округления")
+	}
+
+	return nil
+}
+
+// step шаг округления с учетом значения по умолчанию
+func (r Rounding) step() float64 {
+	if r.Step == 0 {
+		return defaultStep
+	}
+
+	return r.Step
+}
+
+// Apply округление цены до шага и замена окончания
+func (r Rounding) Apply(price float64) float64 {
+	step := r.step()
+	// погрешность float64 не должна переносить цену, уже кратную шагу, на соседний шаг
+	units := price / step
+	switch r.Mode {
+	case RoundUp:
+		units = math.Ceil(units - 1e-9)
+	case RoundDown:
+		units = math.Floor(units + 1e-9)
+	default:
+		units = math.Round(units)
+	}
+
+	price = units * step
+	if r.Ending > 0 {
+		price = price - step + r.Ending
+	}
+
+	return math.Round(price*100) / 100
+}
+
+// Params параметры массового изменения цен. Варианты выбираются по одному из: тегу продукта,
+// категории или списку продуктов
+type Params struct {
+	Tag           string   `json:"tag"`             // тег продуктов
+	CategoryID    int      `json:"category_id"`     // id категории продуктов
+	ProductIDList []int    `json:"product_id_list"` // id продуктов
+	Operation     string   `json:"operation"`       // операция
+	Value         float64  `json:"value"`           // новая цена, процент или сумма изменения
+	Rounding      Rounding `json:"rounding"`        // правило округления
+}
+
+func (p Params) Log() logrus.Fields {
+	return logrus.Fields{
+		"tag":             p.Tag,
+		"category_ID":     p.CategoryID,
+		"product_ID_list": p.ProductIDList,
+		"operation":       p.Operation,
+		"value":           p.Value,
+	}
+}
+
+// Validate проверка параметров изменения цен
+func (p Params) Validate() error {
+	targets := 0
+	for _, set := range []bool{strings.TrimSpace(p.Tag) != "", p.CategoryID > 0, len(p.ProductIDList) > 0} {
+		if set {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return errors.New("варианты выбираются ровно по одному из: tag, category_id или product_id_list")
+	}
+
+	if p.CategoryID < 0 {
+		return errors.New("id категории не может быть отрицательным")
+	}
+
+	for _, productID := range p.ProductIDList {
+		if productID <= 0 {
+			return errors.New("id продукта не может быть меньше или равен 0")
+		}
+	}
+
+	switch p.Operation {
+	case OpSet:
+		if p.Value <= 0 {
+			return errors.New("новая цена должна быть больше 0")
+		}
+	case OpPercent:
+		if p.Value == 0 || p.Value <= -100 {
+			return errors.New("процент изменения не может быть равен 0 и должен быть больше -100")
+		}
+	case OpAmount:
+		if p.Value == 0 {
+			return errors.New("сумма изменения не может быть равна 0")
+		}
+	default:
+		return errors.New("операция должна быть одной из: set, percent, amount")
+	}
+
+	return p.Rounding.Validate()
+}
+
+// NewPrice новая цена вместо цены old, не положительная цена означает, что операция к варианту неприменима
+func (p Params) NewPrice(old float64) float64 {
+	price := old
+	switch p.Operation {
+	case OpSet:
+		price = p.Value
+	case OpPercent:
+		price = old * (100 + p.Value) / 100
+	case OpAmount:
+		price = old + p.Value
+	}
+
+	// округление вниз и окончание цены могут довести малую цену до нуля
+	if price = p.Rounding.Apply(price); price <= 0 {
+		return 0
+	}
+
+	return price
+}
+
+// Line изменение цены одного варианта
+type Line struct {
+	VariantID   int     `json:"variant_id" db:"variant_id"`     // id варианта
+	ProductID   int     `json:"product_id" db:"product_id"`     // id продукта
+	ProductName string  `json:"product_name" db:"product_name"` // название продукта
+	OldPrice    float64 `json:"old_price" db:"price"`           // действующая цена
+	NewPrice    float64 `json:"new_price" db:"-"`               // новая цена
+	// ScheduledAt начало уже запланированной цены варианта, новая цена действует только до него
+	ScheduledAt sqlnull.NullTime `json:"scheduled_at" db:"scheduled_at"`
+}
+
+// Preview результат расчета изменения цен
+type Preview struct {
+	Lines     []Line `json:"lines"`     // варианты, цена которых изменится
+	Unchanged []Line `json:"unchanged"` // варианты, новая цена которых совпадает с действующей
+	Rejected  []Line `json:"rejected"`  // варианты, новая цена которых получилась не больше 0
+	NoPrice   []int  `json:"no_price"`  // id вариантов без действующей цены, они не изменяются
+	Scheduled []int  `json:"scheduled"` // id изменяемых вариантов с запланированной ценой, новая цена действует до ее начала
+}
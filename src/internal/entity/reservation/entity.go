@@ -0,0 +1,91 @@
+package reservation
+
+import (
+	"errors"
+	"product_storage/tools/sqlnull"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// статусы резерва
+const (
+	StatusActive    = "active"    // продукт зарезервирован
+	StatusConverted = "converted" // резерв превращен в продажу
+	StatusReleased  = "released"  // резерв снят вручную
+	StatusExpired   = "expired"   // резерв снят по истечении срока
+)
+
+var (
+	// ErrNotActive резерв уже превращен в продажу или снят
+	ErrNotActive = errors.New("резерв уже превращен в продажу или снят")
+	// ErrExpired срок резерва истек
+	ErrExpired = errors.New("срок резерва истек")
+)
+
+// Params параметры резервирования продукта на складе
+type Params struct {
+	VariantID  int    `json:"variant_id"`  // id варианта продукта
+	StorageID  int    `json:"storage_id"`  // id склада
+	Quantity   int    `json:"quantity"`    // резервируемое кол-во
+	OrderRef   string `json:"order_ref"`   // номер заказа, под который резервируется продукт
+	TTL        int    `json:"ttl"`         // срок резерва в минутах, 0 срок по умолчанию
+	CustomerID int    `json:"customer_id"` // id покупателя заказа, необязательный
+}
+
+func (p Params) Log() logrus.Fields {
+	return logrus.Fields{
+		"variant_ID":  p.VariantID,
+		"storage_ID":  p.StorageID,
+		"quantity":    p.Quantity,
+		"order_ref":   p.OrderRef,
+		"customer_ID": p.CustomerID,
+	}
+}
+
+// Validate проверка параметров резерва
+func (p Params) Validate() error {
+	if p.VariantID <= 0 || p.StorageID <= 0 || p.Quantity <= 0 {
+		return errors.New("поля variant_id, storage_id и quantity должны быть больше 0")
+	}
+
+	if p.CustomerID < 0 {
+		return errors.New("id покупателя не может быть отрицательным")
+	}
+
+	if p.TTL < 0 {
+		return errors.New("срок резерва не может быть отрицательным")
+	}
+
+	return nil
+}
+
+// Reservation резерв продукта на складе
+type Reservation struct {
+	ReservationID int               `json:"reservation_id" db:"reservation_id"` // id резерва
+	VariantID     int               `json:"variant_id" db:"variant_id"`         // id варианта продукта
+	StorageID     int               `json:"storage_id" db:"storage_id"`         // id склада
+	Quantity      int               `json:"quantity" db:"quantity"`             // зарезервированное кол-во
+	Status        string            `json:"status" db:"status"`                 // статус
+	OrderRef      string            `json:"order_ref" db:"order_ref"`           // номер заказа
+	CreatedAt     time.Time         `json:"created_at" db:"created_at"`         // дата резервирования
+	ExpiresAt     time.Time         `json:"expires_at" db:"expires_at"`         // дата истечения резерва
+	ClosedAt      sqlnull.NullTime  `json:"closed_at" db:"closed_at"`           // дата продажи или снятия
+	SaleID        sqlnull.NullInt64 `json:"sale_id" db:"sale_id"`               // id продажи, в которую превращен резерв
+	CustomerID    sqlnull.NullInt64 `json:"customer_id" db:"customer_id"`       // id покупателя заказа
+}
+
+func (r Reservation) Log() logrus.Fields {
+	return logrus.Fields{
+		"reservation_ID": r.ReservationID,
+		"variant_ID":     r.VariantID,
+		"storage_ID":     r.StorageID,
+		"quantity":       r.Quantity,
+		"status":         r.Status,
+	}
+}
+
+// IsExpired истек ли срок резерва на момент now
+func (r Reservation) IsExpired(now time.Time) bool {
+	return !r.ExpiresAt.After(now)
+}
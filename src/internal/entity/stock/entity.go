@@ -1,14 +1,161 @@
 package stock
 
 import (
-	
+	"errors"
+	"product_storage/tools/sqlnull"
+	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
 // Stock структура склада
 type Stock struct {
 	StorageID          int                    `db:"storage_id"`          // id склада
 	StorageName        string                 `db:"name"`                // название склада
+	Address            string                 `db:"address"`             // адрес склада
+	Contact            string                 `db:"contact"`             // контакт ответственного
+	StorageType        string                 `db:"storage_type"`        // тип склада
+	Capacity           sqlnull.NullInt64      `db:"capacity"`            // вместимость, null без ограничения
+	Occupied           int                    `db:"occupied"`            // общее кол-во продукта на складе
+	RemovedAt          sqlnull.NullTime       `db:"removed_at"`          // дата удаления склада
 	ProductVariantList []ProductInStockParams `db:"products_in_storage"` // список продуктов на данном складе
 }
 
+// ErrStorageNotEmpty склад нельзя удалить, пока на нем есть продукт
+var ErrStorageNotEmpty = errors.New("на складе есть продукт, склад не может быть удален")
+
+// ErrStorageRemoved склад удален
+var ErrStorageRemoved = errors.New("склад удален")
+
+// StockLevel кол-во варианта продукта на складе
+type StockLevel struct {
+	VariantID int `json:"variant_id" db:"variant_id"` // id варианта продукта
+	StorageID int `json:"storage_id" db:"storage_id"` // id склада
+	Quantity  int `json:"quantity" db:"quantity"`     // кол-во продукта на складе
+}
+
+// ErrNotEnoughStock на складе недостаточно продукта для продажи или списания
+var ErrNotEnoughStock = errors.New("недостаточно продукта на складе")
+
+// ErrNotEnoughAvailable продукта на складе достаточно, но часть его зарезервирована под заказы
+var ErrNotEnoughAvailable = errors.New("недостаточно свободного от резерва продукта на складе")
+
+// ErrBelowReserved кол-во продукта на складе нельзя установить меньше зарезервированного под заказы
+var ErrBelowReserved = errors.New("кол-во продукта на складе не может быть меньше зарезервированного")
+
+// ErrNotEnoughFreshStock продукта на складе достаточно, но часть его в партиях с истекшим сроком годности
+var ErrNotEnoughFreshStock = errors.New("недостаточно продукта с неистекшим сроком годности")
+
+// LowStock вариант продукта, остаток которого на складе ниже минимального
+type LowStock struct {
+	VariantID       int     `json:"variant_id" db:"variant_id"`             // id варианта продукта
+	StorageID       int     `json:"storage_id" db:"storage_id"`             // id склада
+	ProductName     string  `json:"product_name" db:"product_name"`         // название продукта
+	Weight          float64 `json:"weight" db:"weight"`                     // вес варианта
+	Unit            string  `json:"unit" db:"unit"`                         // единица измерения варианта
+	Quantity        int     `json:"quantity" db:"quantity"`                 // текущий остаток
+	MinQuantity     int     `json:"min_quantity" db:"min_quantity"`         // минимальный остаток
+	MaxQuantity     int     `json:"max_quantity" db:"max_quantity"`         // максимальный остаток
+	ReorderQuantity int     `json:"reorder_quantity" db:"reorder_quantity"` // рекомендуемое кол-во дозаказа до максимального остатка
+}
+
+// LowStockAlert оповещение о снижении остатка ниже минимального
+type LowStockAlert struct {
+	VariantID       int `json:"variant_id"`
+	StorageID       int `json:"storage_id"`
+	Quantity        int `json:"quantity"`
+	MinQuantity     int `json:"min_quantity"`
+	MaxQuantity     int `json:"max_quantity"`
+	ReorderQuantity int `json:"reorder_quantity"`
+}
+
+// NewLowStockAlert оповещение для остатка quantity и порога threshold
+func NewLowStockAlert(threshold ThresholdParams, quantity int) LowStockAlert {
+	return LowStockAlert{
+		VariantID:       threshold.VariantID,
+		StorageID:       threshold.StorageID,
+		Quantity:        quantity,
+		MinQuantity:     threshold.MinQuantity,
+		MaxQuantity:     threshold.MaxQuantity,
+		ReorderQuantity: threshold.MaxQuantity - quantity,
+	}
+}
+
+func (a LowStockAlert) Log() logrus.Fields {
+	return logrus.Fields{
+		"variant_ID":       a.VariantID,
+		"storage_ID":       a.StorageID,
+		"quantity":         a.Quantity,
+		"min_quantity":     a.MinQuantity,
+		"reorder_quantity": a.ReorderQuantity,
+	}
+}
+
+// Batch партия варианта продукта на складе.
+// Остаток на складе может быть больше суммы остатков партий: продукт, добавленный без партии, не отслеживается
+type Batch struct {
+	BatchID    int       `json:"batch_id" db:"batch_id"`               // id партии
+	VariantID  int       `json:"variant_id" db:"variant_id"`           // id варианта продукта
+	StorageID  int       `json:"storage_id" db:"storage_id"`           // id склада
+	Quantity   int       `json:"quantity" db:"quantity"`               // остаток партии
+	ReceiptID  int       `json:"receipt_id,omitempty" db:"receipt_id"` // id приема товара по заказу, 0 если партия добавлена вручную
+	ReceivedAt time.Time `json:"received_at" db:"received_at"`         // дата поступления партии
+	BatchParams
+}
+
+func (b Batch) Log() logrus.Fields {
+	return logrus.Fields{
+		"batch_ID":   b.BatchID,
+		"variant_ID": b.VariantID,
+		"storage_ID": b.StorageID,
+		"quantity":   b.Quantity,
+	}
+}
+
+// IsExpired истек ли срок годности партии на момент now, партия годна весь день окончания срока
+func (b Batch) IsExpired(now time.Time) bool {
+	return b.ExpiresAt.Valid && b.ExpiresAt.Time.Before(truncateDay(now))
+}
+
+// ExpiringBatch партия с истекающим или истекшим сроком годности
+type ExpiringBatch struct {
+	Batch
+	ProductName string  `json:"product_name" db:"product_name"` // название продукта
+	Weight      float64 `json:"weight" db:"weight"`             // вес варианта
+	Unit        string  `json:"unit" db:"unit"`                 // единица измерения варианта
+	DaysLeft    int     `json:"days_left" db:"days_left"`       // дней до окончания срока, отрицательное значение у истекших партий
+}
+
+// BatchConsumption кол-во, забираемое из партии
+type BatchConsumption struct {
+	BatchID  int
+	Quantity int
+}
+
+// AllocateFEFO распределение кол-ва по партиям: первыми забираются партии с ближайшим сроком годности.
+// Партии должны быть упорядочены по сроку годности. При freshOnly партии с истекшим на момент now сроком пропускаются.
+// rest кол-во, которое не удалось распределить по партиям
+func AllocateFEFO(batchList []Batch, quantity int, now time.Time, freshOnly bool) (allocation []BatchConsumption, rest int) {
+	rest = quantity
+	for _, b := range batchList {
+		if rest == 0 {
+			break
+		}
+
+		if b.Quantity <= 0 || freshOnly && b.IsExpired(now) {
+			continue
+		}
 
+		take := min(b.Quantity, rest)
+		allocation = append(allocation, BatchConsumption{BatchID: b.BatchID, Quantity: take})
+		rest -= take
+	}
+
+	return allocation, rest
+}
+
+// truncateDay начало дня t
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
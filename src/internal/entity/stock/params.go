@@ -2,6 +2,7 @@ package stock
 
 import (
 	"errors"
+	"product_storage/internal/entity/location"
 	"product_storage/tools/sqlnull"
 	"time"
 
@@ -11,10 +12,16 @@ import (
 // AddProductInStock структура для вставки продукта на склад
 type ProductInStockParams struct {
 	ProductInStorageID int
-	VariantID          int       `json:"variant_id" db:"variant_id"` // id варианта продукта
-	StorageID          int       `json:"storage_id" db:"storage_id"` // id склада куда будет помещен этот продукт
-	AddedAt            time.Time `json:"added_at" db:"added_at" `    // дата добавления продукта на склад
-	Quantity           int       `json:"quantity" db:"quantity"`     // кол-во продукта добавленного на склад
+	VariantID          int              `json:"variant_id" db:"variant_id"` // id варианта продукта
+	StorageID          int              `json:"storage_id" db:"storage_id"` // id склада куда будет помещен этот продукт
+	AddedAt            time.Time        `json:"added_at" db:"added_at" `    // дата добавления продукта на склад
+	Quantity           int              `json:"quantity" db:"quantity"`     // кол-во продукта добавленного на склад
+	Batch              *BatchParams     `json:"batch,omitempty" db:"-"`     // партия поступившего продукта, если ведется учет партий
+	UnitCost           float64          `json:"unit_cost" db:"-"`           // себестоимость единицы, 0 если неизвестна
+	Reserved           int              `json:"reserved" db:"reserved"`     // кол-во, зарезервированное под заказы
+	Available          int              `json:"available" db:"available"`   // кол-во, доступное для продажи
+	Locations          []location.Stock `json:"locations,omitempty" db:"-"` // кол-во по местам хранения
+	Unplaced           int              `json:"unplaced,omitempty" db:"-"`  // кол-во, не разложенное по местам хранения
 }
 
 func (p ProductInStockParams) Log() logrus.Fields {
@@ -32,13 +39,27 @@ func (p ProductInStockParams) IsNullFields() error {
 	if p.StorageID == 0 || p.VariantID == 0 || p.Quantity == 0 {
 		return errors.New("поля: variant_id, storage_id, added_at, quantity не должны быть пустыми")
 	}
+	if p.UnitCost < 0 {
+		return errors.New("себестоимость не может быть отрицательной")
+	}
 	return nil
 }
 
+// типы складов
+const (
+	StorageTypeWarehouse = "warehouse" // склад
+	StorageTypeStore     = "store"     // торговая точка
+	StorageTypeCold      = "cold"      // склад с охлаждением
+)
+
 type StockParams struct {
-	StorageID   int              `db:"storage_id" json:"storage_id"`
-	StorageName string           `db:"name" json:"storage_name"`
-	Added_at    sqlnull.NullTime `db:"added_at" json:"added_at"`
+	StorageID   int               `db:"storage_id" json:"storage_id"`
+	StorageName string            `db:"name" json:"storage_name"`
+	Added_at    sqlnull.NullTime  `db:"added_at" json:"added_at"`
+	Address     string            `db:"address" json:"address"`           // адрес склада
+	Contact     string            `db:"contact" json:"contact"`           // контакт ответственного
+	StorageType string            `db:"storage_type" json:"storage_type"` // тип склада, по умолчанию warehouse
+	Capacity    sqlnull.NullInt64 `db:"capacity" json:"capacity"`         // вместимость в единицах продукта, null без ограничения
 }
 
 func (s StockParams) Log() logrus.Fields {
@@ -46,5 +67,104 @@ func (s StockParams) Log() logrus.Fields {
 		"Storage_ID":  s.StorageID,
 		"StorageName": s.StorageName,
 		"Added_at":    s.Added_at,
+		"type":        s.StorageType,
+	}
+}
+
+// Validate проверка параметров склада, пустой тип заменяется типом по умолчанию
+func (s *StockParams) Validate() error {
+	if s.StorageName == "" {
+		return errors.New("название склада не может быть пустым")
+	}
+
+	switch s.StorageType {
+	case "":
+		s.StorageType = StorageTypeWarehouse
+	case StorageTypeWarehouse, StorageTypeStore, StorageTypeCold:
+	default:
+		return errors.New("неизвестный тип склада")
+	}
+
+	if s.Capacity.Valid && s.Capacity.Int64 <= 0 {
+		return errors.New("вместимость склада должна быть больше 0")
+	}
+
+	return nil
+}
+
+// ThresholdParams порог остатка варианта продукта на складе
+type ThresholdParams struct {
+	VariantID   int `json:"variant_id" db:"variant_id"`     // id варианта продукта
+	StorageID   int `json:"storage_id" db:"storage_id"`     // id склада
+	MinQuantity int `json:"min_quantity" db:"min_quantity"` // минимальный остаток, ниже которого нужен дозаказ
+	MaxQuantity int `json:"max_quantity" db:"max_quantity"` // максимальный остаток, до которого выполняется дозаказ
+}
+
+func (p ThresholdParams) Log() logrus.Fields {
+	return logrus.Fields{
+		"variant_ID":   p.VariantID,
+		"storage_ID":   p.StorageID,
+		"min_quantity": p.MinQuantity,
+		"max_quantity": p.MaxQuantity,
+	}
+}
+
+// Validate проверка порогов
+func (p ThresholdParams) Validate() error {
+	if p.VariantID <= 0 || p.StorageID <= 0 {
+		return errors.New("поля variant_id и storage_id не должны быть пустыми")
+	}
+
+	if p.MinQuantity < 0 || p.MaxQuantity <= 0 || p.MaxQuantity < p.MinQuantity {
+		return errors.New("порог должен удовлетворять условию 0 <= min_quantity <= max_quantity, max_quantity > 0")
+	}
+
+	return nil
+}
+
+// ReasonExpired причина списания партии с истекшим сроком годности
+const ReasonExpired = "истек срок годности"
+
+// WriteOffParams списание продукта со склада
+type WriteOffParams struct {
+	VariantID    int       `json:"variant_id" db:"variant_id"`         // id варианта продукта
+	StorageID    int       `json:"storage_id" db:"storage_id"`         // id склада
+	Quantity     int       `json:"quantity" db:"quantity"`             // кол-во списываемого продукта
+	Reason       string    `json:"reason" db:"reason"`                 // причина списания
+	WrittenOffAt time.Time `json:"written_off_at" db:"written_off_at"` // дата списания
+	BatchID      int       `json:"-" db:"batch_id"`                    // id списанной партии, 0 если партия не указана
+	StocktakeID  int       `json:"-" db:"stocktake_id"`                // id инвентаризации, выявившей недостачу
+}
+
+func (p WriteOffParams) Log() logrus.Fields {
+	return logrus.Fields{
+		"variant_ID": p.VariantID,
+		"storage_ID": p.StorageID,
+		"quantity":   p.Quantity,
+		"reason":     p.Reason,
+	}
+}
+
+// IsNullFields проверка полей на нулевые значения
+func (p WriteOffParams) IsNullFields() error {
+	if p.VariantID == 0 || p.StorageID == 0 || p.Quantity <= 0 || p.Reason == "" {
+		return errors.New("поля: variant_id, storage_id, quantity, reason не должны быть пустыми")
 	}
+	return nil
+}
+
+// BatchParams параметры партии продукта
+type BatchParams struct {
+	BatchNumber string           `json:"batch_number" db:"batch_number"` // номер партии производителя
+	ProducedAt  sqlnull.NullTime `json:"produced_at" db:"produced_at"`   // дата производства
+	ExpiresAt   sqlnull.NullTime `json:"expires_at" db:"expires_at"`     // дата окончания срока годности
+}
+
+// Validate проверка дат партии
+func (p BatchParams) Validate() error {
+	if p.ProducedAt.Valid && p.ExpiresAt.Valid && p.ExpiresAt.Time.Before(p.ProducedAt.Time) {
+		return errors.New("срок годности партии не может истекать раньше даты производства")
+	}
+
+	return nil
 }
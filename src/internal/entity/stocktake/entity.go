@@ -0,0 +1,165 @@
+package stocktake
+
+import (
+	"errors"
+	"product_storage/tools/sqlnull"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// статусы инвентаризации
+const (
+	StatusOpen      = "open"      // идет пересчет
+	StatusPosted    = "posted"    // расхождения проведены по складу
+	StatusCancelled = "cancelled" // инвентаризация отменена без изменения остатков
+)
+
+// ReasonShortage причина списания недостачи, выявленной инвентаризацией
+const ReasonShortage = "недостача по инвентаризации"
+
+// ErrNotOpen инвентаризация уже проведена или отменена
+var ErrNotOpen = errors.New("инвентаризация уже проведена или отменена")
+
+// Params параметры начала инвентаризации
+type Params struct {
+	StorageID int    `json:"storage_id"` // id склада
+	Comment   string `json:"comment"`    // комментарий
+}
+
+func (p Params) Log() logrus.Fields {
+	return logrus.Fields{"storage_ID": p.StorageID}
+}
+
+// Stocktake документ инвентаризации склада
+type Stocktake struct {
+	StocktakeID int              `json:"stocktake_id" db:"stocktake_id"` // id инвентаризации
+	StorageID   int              `json:"storage_id" db:"storage_id"`     // id склада
+	Status      string           `json:"status" db:"status"`             // статус
+	Comment     string           `json:"comment" db:"comment"`           // комментарий
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`     // дата начала
+	ClosedAt    sqlnull.NullTime `json:"closed_at" db:"closed_at"`       // дата проведения или отмены
+}
+
+// Line строка инвентаризации: ожидаемое на момент начала и фактически посчитанное кол-во
+type Line struct {
+	LineID           int               `json:"line_id" db:"line_id"`                     // id строки
+	StocktakeID      int               `json:"stocktake_id" db:"stocktake_id"`           // id инвентаризации
+	VariantID        int               `json:"variant_id" db:"variant_id"`               // id варианта продукта
+	ProductName      string            `json:"product_name" db:"product_name"`           // название продукта
+	ExpectedQuantity int               `json:"expected_quantity" db:"expected_quantity"` // остаток по учету на момент начала
+	CountedQuantity  sqlnull.NullInt64 `json:"counted_quantity" db:"counted_quantity"`   // посчитанное кол-во, null если вариант не считали
+	CountedAt        sqlnull.NullTime  `json:"counted_at" db:"counted_at"`               // дата последнего пересчета
+	Adjustment       sqlnull.NullInt64 `json:"adjustment" db:"adjustment"`               // проведенная корректировка остатка
+}
+
+// Counted посчитан ли вариант
+func (l Line) Counted() bool {
+	return l.CountedQuantity.Valid
+}
+
+// Variance расхождение фактического кол-ва с учетным, у непосчитанного варианта расхождения нет
+func (l Line) Variance() int {
+	if !l.Counted() {
+		return 0
+	}
+
+	return int(l.CountedQuantity.Int64) - l.ExpectedQuantity
+}
+
+// CountParams посчитанное кол-во варианта
+type CountParams struct {
+	VariantID       int `json:"variant_id"`       // id варианта продукта
+	CountedQuantity int `json:"counted_quantity"` // посчитанное кол-во
+}
+
+// CountListParams результаты одного пересчета. При accumulate посчитанное кол-во прибавляется
+// к результатам прошлых пересчетов, например когда вариант лежит в нескольких местах склада,
+// иначе заменяет их
+type CountListParams struct {
+	StocktakeID int           `json:"stocktake_id"` // id инвентаризации
+	Accumulate  bool          `json:"accumulate"`   // прибавить к прошлым пересчетам
+	Counts      []CountParams `json:"counts"`       // посчитанные варианты
+}
+
+func (p CountListParams) Log() logrus.Fields {
+	return logrus.Fields{
+		"stocktake_ID": p.StocktakeID,
+		"accumulate":   p.Accumulate,
+		"counts":       len(p.Counts),
+	}
+}
+
+// Validate проверка результатов пересчета
+func (p CountListParams) Validate() error {
+	if p.StocktakeID <= 0 || len(p.Counts) == 0 {
+		return errors.New("нужно указать stocktake_id и хотя бы один посчитанный вариант")
+	}
+
+	variants := make(map[int]struct{}, len(p.Counts))
+	for _, c := range p.Counts {
+		if c.VariantID <= 0 || c.CountedQuantity < 0 {
+			return errors.New("в пересчете должны быть указаны variant_id и неотрицательное counted_quantity")
+		}
+
+		if _, exists := variants[c.VariantID]; exists {
+			return errors.New("вариант указан в пересчете несколько раз")
+		}
+		variants[c.VariantID] = struct{}{}
+	}
+
+	return nil
+}
+
+// PostParams параметры проведения инвентаризации
+type PostParams struct {
+	StocktakeID   int  `json:"stocktake_id"`   // id инвентаризации
+	ZeroUncounted bool `json:"zero_uncounted"` // считать непосчитанные варианты отсутствующими, иначе их остаток не меняется
+}
+
+func (p PostParams) Log() logrus.Fields {
+	return logrus.Fields{
+		"stocktake_ID":   p.StocktakeID,
+		"zero_uncounted": p.ZeroUncounted,
+	}
+}
+
+// VarianceLine строка отчета о расхождениях
+type VarianceLine struct {
+	Line
+	Variance int `json:"variance"` // расхождение: излишек больше 0, недостача меньше 0
+}
+
+// VarianceReport отчет о расхождениях инвентаризации
+type VarianceReport struct {
+	Stocktake
+	Counted   int            `json:"counted"`   // кол-во посчитанных вариантов
+	Uncounted int            `json:"uncounted"` // кол-во непосчитанных вариантов
+	Surplus   int            `json:"surplus"`   // общий излишек
+	Shortage  int            `json:"shortage"`  // общая недостача
+	Lines     []VarianceLine `json:"lines"`     // строки с расхождениями
+}
+
+// NewVarianceReport отчет о расхождениях по строкам инвентаризации
+func NewVarianceReport(s Stocktake, lineList []Line) VarianceReport {
+	r := VarianceReport{Stocktake: s, Lines: make([]VarianceLine, 0, len(lineList))}
+	for _, l := range lineList {
+		if !l.Counted() {
+			r.Uncounted++
+		} else {
+			r.Counted++
+		}
+
+		v := l.Variance()
+		switch {
+		case v > 0:
+			r.Surplus += v
+		case v < 0:
+			r.Shortage -= v
+		}
+
+		r.Lines = append(r.Lines, VarianceLine{Line: l, Variance: v})
+	}
+
+	return r
+}
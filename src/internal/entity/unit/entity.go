@@ -0,0 +1,89 @@
+package unit
+
+import (
+	"errors"
+	"math"
+
+	"github.com/sirupsen/logrus"
+)
+
+// измерения единиц
+const (
+	DimensionMass   = "mass"   // масса, базовая единица кг
+	DimensionVolume = "volume" // объем, базовая единица л
+	DimensionCount  = "count"  // количество, базовая единица шт
+	DimensionLength = "length" // длина, базовая единица м
+)
+
+// baseUnit базовая единица измерения, в которую переводит Factor
+var baseUnit = map[string]string{
+	DimensionMass:   "кг",
+	DimensionVolume: "л",
+	DimensionCount:  "шт",
+	DimensionLength: "м",
+}
+
+var (
+	// ErrUnknownUnit единица измерения отсутствует в справочнике
+	ErrUnknownUnit = errors.New("единица измерения отсутствует в справочнике")
+	// ErrDimensionMismatch единицы измеряют разные величины
+	ErrDimensionMismatch = errors.New("единицы измерения относятся к разным величинам и не переводятся друг в друга")
+)
+
+// Unit единица измерения
+type Unit struct {
+	Code      string  `json:"code" db:"code"`           // обозначение, например кг
+	Name      string  `json:"name" db:"name"`           // название
+	Dimension string  `json:"dimension" db:"dimension"` // измеряемая величина
+	Factor    float64 `json:"factor" db:"factor"`       // множитель перевода в базовую единицу
+}
+
+func (u Unit) Log() logrus.Fields {
+	return logrus.Fields{
+		"code":      u.Code,
+		"dimension": u.Dimension,
+		"factor":    u.Factor,
+	}
+}
+
+// Validate проверка единицы измерения перед добавлением в справочник
+func (u Unit) Validate() error {
+	if u.Code == "" || u.Name == "" {
+		return errors.New("обозначение и название единицы измерения не могут быть пустыми")
+	}
+
+	if _, exists := baseUnit[u.Dimension]; !exists {
+		return errors.New("измерение должно быть одним из: mass, volume, count, length")
+	}
+
+	if u.Factor <= 0 {
+		return errors.New("множитель перевода в базовую единицу должен быть больше 0")
+	}
+
+	return nil
+}
+
+// BaseUnit базовая единица измерения величины dimension
+func BaseUnit(dimension string) string {
+	return baseUnit[dimension]
+}
+
+// Convert перевод значения value из единицы from в единицу to
+func Convert(value float64, from, to Unit) (float64, error) {
+	if from.Dimension != to.Dimension {
+		return 0, ErrDimensionMismatch
+	}
+
+	return value * from.Factor / to.Factor, nil
+}
+
+// PricePerBaseUnit цена за базовую единицу (кг, л, шт, м) варианта с ценой price и мерой amount в единицах с множителем factor.
+// 0 если мера или множитель неизвестны
+func PricePerBaseUnit(price, amount, factor float64) float64 {
+	base := amount * factor
+	if price <= 0 || base <= 0 {
+		return 0
+	}
+
+	return math.Round(price/base*100) / 100
+}
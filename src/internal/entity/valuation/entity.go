@@ -0,0 +1,166 @@
+package valuation
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// группировка отчета о валовой марже
+const (
+	GroupByProduct = "product" // по продуктам
+	GroupByVariant = "variant" // по вариантам продуктов
+)
+
+// CostLayer слой себестоимости: продукт, поступивший на склад по одной цене
+type CostLayer struct {
+	LayerID   int     `db:"layer_id"`   // id слоя
+	VariantID int     `db:"variant_id"` // id варианта продукта
+	StorageID int     `db:"storage_id"` // id склада
+	Quantity  int     `db:"quantity"`   // поступившее кол-во
+	Remaining int     `db:"remaining"`  // еще не списанное кол-во
+	UnitCost  float64 `db:"unit_cost"`  // себестоимость единицы
+	ReceiptID int     `db:"receipt_id"` // id приема товара по заказу, 0 если продукт добавлен вручную
+}
+
+// AverageCost скользящая средневзвешенная себестоимость варианта продукта на складе
+type AverageCost struct {
+	VariantID   int     `db:"variant_id"`   // id варианта продукта
+	StorageID   int     `db:"storage_id"`   // id склада
+	Quantity    int     `db:"quantity"`     // кол-во продукта с известной себестоимостью
+	AverageCost float64 `db:"average_cost"` // средняя себестоимость единицы
+}
+
+// LayerConsumption кол-во, списываемое со слоя себестоимости
+type LayerConsumption struct {
+	LayerID  int
+	Quantity int
+}
+
+// AllocateFIFO распределение кол-ва по слоям себестоимости, начиная с первого поступившего.
+// Слои должны быть упорядочены по времени поступления.
+// rest кол-во, для которого не нашлось слоев, его себестоимость вычисляется вызывающим
+func AllocateFIFO(layerList []CostLayer, quantity int) (allocation []LayerConsumption, cost float64, rest int) {
+	rest = quantity
+	for _, l := range layerList {
+		if rest == 0 {
+			break
+		}
+
+		take := min(l.Remaining, rest)
+		if take <= 0 {
+			continue
+		}
+
+		allocation = append(allocation, LayerConsumption{LayerID: l.LayerID, Quantity: take})
+		cost += float64(take) * l.UnitCost
+		rest -= take
+	}
+
+	return allocation, cost, rest
+}
+
+// RoundMoney округление суммы до копеек
+func RoundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Valuation стоимость остатка варианта продукта на складе
+type Valuation struct {
+	VariantID   int     `json:"variant_id" db:"variant_id"`     // id варианта продукта
+	StorageID   int     `json:"storage_id" db:"storage_id"`     // id склада
+	ProductName string  `json:"product_name" db:"product_name"` // название продукта
+	Weight      float64 `json:"weight" db:"weight"`             // вес варианта
+	Unit        string  `json:"unit" db:"unit"`                 // единица измерения варианта
+	Quantity    int     `json:"quantity" db:"quantity"`         // кол-во продукта с известной себестоимостью
+	UnitCost    float64 `json:"unit_cost" db:"unit_cost"`       // средняя себестоимость единицы остатка
+	Value       float64 `json:"value" db:"value"`               // стоимость остатка
+}
+
+// ValuationReport оценка остатков склада
+type ValuationReport struct {
+	Method    string      `json:"method"`      // метод оценки себестоимости
+	StorageID int         `json:"storage_id"`  // id склада, 0 если по всем складам
+	Total     float64     `json:"total_value"` // общая стоимость остатков
+	Items     []Valuation `json:"items"`       // стоимость остатков по вариантам
+}
+
+// MarginQuery параметры отчета о валовой марже
+type MarginQuery struct {
+	StartDate time.Time `json:"start_date"` // начало периода продаж
+	EndDate   time.Time `json:"end_date"`   // конец периода продаж
+	StorageID int       `json:"storage_id"` // id склада, 0 если по всем складам
+	GroupBy   string    `json:"group_by"`   // группировка: product или variant, по умолчанию product
+}
+
+func (q MarginQuery) Log() logrus.Fields {
+	return logrus.Fields{
+		"start_date": q.StartDate,
+		"end_date":   q.EndDate,
+		"storage_ID": q.StorageID,
+		"group_by":   q.GroupBy,
+	}
+}
+
+// Validate проверка параметров отчета, пустая группировка заменяется группировкой по продуктам
+func (q *MarginQuery) Validate() error {
+	if q.StartDate.IsZero() || q.EndDate.IsZero() || q.EndDate.Before(q.StartDate) {
+		return errors.New("нужно указать период продаж: start_date не позже end_date")
+	}
+
+	if q.StorageID < 0 {
+		return errors.New("id склада не может быть меньше нуля")
+	}
+
+	switch q.GroupBy {
+	case "":
+		q.GroupBy = GroupByProduct
+	case GroupByProduct, GroupByVariant:
+	default:
+		return errors.New("группировка должна быть product или variant")
+	}
+
+	return nil
+}
+
+// Margin валовая маржа продукта или варианта за период
+type Margin struct {
+	ProductID     int     `json:"product_id" db:"product_id"`           // id продукта
+	ProductName   string  `json:"product_name" db:"product_name"`       // название продукта
+	VariantID     int     `json:"variant_id,omitempty" db:"variant_id"` // id варианта, 0 при группировке по продуктам
+	Quantity      int     `json:"quantity" db:"quantity"`               // проданное кол-во
+	Revenue       float64 `json:"revenue" db:"revenue"`                 // выручка
+	CostOfGoods   float64 `json:"cost_of_goods" db:"cost_of_goods"`     // себестоимость проданного
+	GrossMargin   float64 `json:"gross_margin" db:"gross_margin"`       // валовая маржа
+	MarginPercent float64 `json:"margin_percent" db:"margin_percent"`   // доля маржи в выручке, в процентах
+}
+
+// MarginReport отчет о валовой марже за период
+type MarginReport struct {
+	MarginQuery
+	Revenue       float64  `json:"revenue"`        // общая выручка
+	CostOfGoods   float64  `json:"cost_of_goods"`  // общая себестоимость
+	GrossMargin   float64  `json:"gross_margin"`   // общая валовая маржа
+	MarginPercent float64  `json:"margin_percent"` // доля маржи в выручке, в процентах
+	Items         []Margin `json:"items"`          // маржа по продуктам или вариантам
+}
+
+// NewMarginReport отчет с итогами по строкам
+func NewMarginReport(q MarginQuery, items []Margin) MarginReport {
+	r := MarginReport{MarginQuery: q, Items: items}
+	for _, m := range items {
+		r.Revenue += m.Revenue
+		r.CostOfGoods += m.CostOfGoods
+	}
+
+	r.Revenue = RoundMoney(r.Revenue)
+	r.CostOfGoods = RoundMoney(r.CostOfGoods)
+	r.GrossMargin = RoundMoney(r.Revenue - r.CostOfGoods)
+	if r.Revenue > 0 {
+		r.MarginPercent = RoundMoney(r.GrossMargin / r.Revenue * 100)
+	}
+
+	return r
+}
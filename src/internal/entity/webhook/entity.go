@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"product_storage/internal/entity/event"
+	"product_storage/tools/sqlnull"
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// статусы доставки
+const (
+	StatusPending   = "pending"   // ожидает доставки или повтора
+	StatusDelivered = "delivered" // получатель ответил статусом 2xx
+	StatusFailed    = "failed"    // исчерпаны попытки доставки
+)
+
+// minSecretLength минимальная длина секрета подписи
+const minSecretLength = 16
+
+// SubscriptionParams параметры подписки на события
+type SubscriptionParams struct {
+	URL        string   `json:"url"`         // адрес, на который отправляются события
+	Secret     string   `json:"secret"`      // секрет для подписи тела запроса HMAC-SHA256
+	EventTypes []string `json:"event_types"` // типы событий, на которые оформлена подписка
+}
+
+func (p SubscriptionParams) Log() logrus.Fields {
+	return logrus.Fields{
+		"url":         p.URL,
+		"event_types": p.EventTypes,
+	}
+}
+
+// Validate проверка адреса, секрета и типов событий
+func (p SubscriptionParams) Validate() error {
+	u, err := url.Parse(p.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url должен быть абсолютным адресом http или https")
+	}
+
+	if len(p.Secret) < minSecretLength {
+		return fmt.Errorf("секрет должен быть не короче %d символов", minSecretLength)
+	}
+
+	if len(p.EventTypes) == 0 {
+		return errors.New("не указаны типы событий")
+	}
+
+	for _, t := range p.EventTypes {
+		if !event.IsKnownType(t) {
+			return fmt.Errorf("неизвестный тип события %s", t)
+		}
+	}
+
+	return nil
+}
+
+// Subscription подписка на события, секрет в ответах не выводится
+type Subscription struct {
+	SubscriptionID int            `json:"subscription_id" db:"subscription_id"` // id подписки
+	URL            string         `json:"url" db:"url"`                         // адрес получателя
+	EventTypes     pq.StringArray `json:"event_types" db:"event_types"`         // типы событий
+	AddedAt        time.Time      `json:"added_at" db:"added_at"`               // дата создания подписки
+}
+
+// Delivery доставка события получателю, записи доставок образуют журнал доставок подписки
+type Delivery struct {
+	DeliveryID     int64              `json:"delivery_id" db:"delivery_id"`           // id доставки
+	SubscriptionID int                `json:"subscription_id" db:"subscription_id"`   // id подписки
+	EventID        int64              `json:"event_id" db:"event_id"`                 // id события
+	EventType      string             `json:"event_type" db:"event_type"`             // тип события
+	Payload        types.JSONText     `json:"payload" db:"payload"`                   // данные события
+	OccurredAt     time.Time          `json:"occurred_at" db:"occurred_at"`           // дата возникновения события
+	Status         string             `json:"status" db:"status"`                     // статус доставки
+	Attempts       int                `json:"attempts" db:"attempts"`                 // кол-во выполненных попыток
+	NextAttemptAt  time.Time          `json:"next_attempt_at" db:"next_attempt_at"`   // время следующей попытки
+	LastStatusCode sqlnull.NullInt64  `json:"last_status_code" db:"last_status_code"` // http статус последней попытки
+	LastError      sqlnull.NullString `json:"last_error" db:"last_error"`             // ошибка последней попытки
+	DeliveredAt    sqlnull.NullTime   `json:"delivered_at" db:"delivered_at"`         // дата успешной доставки
+	URL            string             `json:"-" db:"url"`                             // адрес получателя
+	Secret         string             `json:"-" db:"secret"`                          // секрет подписи
+}
+
+func (d Delivery) Log() logrus.Fields {
+	return logrus.Fields{
+		"delivery_ID":     d.DeliveryID,
+		"subscription_ID": d.SubscriptionID,
+		"event_ID":        d.EventID,
+		"event_type":      d.EventType,
+		"attempts":        d.Attempts,
+	}
+}
+
+// Message тело запроса, отправляемого получателю
+type Message struct {
+	DeliveryID int64          `json:"delivery_id"`
+	EventID    int64          `json:"event_id"`
+	EventType  string         `json:"event_type"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	Payload    types.JSONText `json:"payload"`
+}
+
+// Message тело запроса доставки
+func (d Delivery) Message() Message {
+	return Message{
+		DeliveryID: d.DeliveryID,
+		EventID:    d.EventID,
+		EventType:  d.EventType,
+		OccurredAt: d.OccurredAt,
+		Payload:    d.Payload,
+	}
+}
@@ -1,10 +1,25 @@
 package repository
 
 import (
+	"product_storage/internal/entity/attribute"
+	"product_storage/internal/entity/customer"
+	"product_storage/internal/entity/event"
+	"product_storage/internal/entity/image"
+	"product_storage/internal/entity/location"
 	"product_storage/internal/entity/log"
+	"product_storage/internal/entity/payment"
 	"product_storage/internal/entity/product"
+	"product_storage/internal/entity/promotion"
+	"product_storage/internal/entity/purchase"
+	"product_storage/internal/entity/reprice"
+	"product_storage/internal/entity/reservation"
 	"product_storage/internal/entity/stock"
+	"product_storage/internal/entity/stocktake"
+	"product_storage/internal/entity/unit"
+	"product_storage/internal/entity/valuation"
+	"product_storage/internal/entity/webhook"
 	"product_storage/internal/transaction"
+	"time"
 )
 
 type Logger interface {
@@ -17,7 +32,12 @@ type Logger interface {
 
 type Product interface {
 	AddProduct(ts transaction.Session, product product.ProductParams) (productID int, err error)
-	AddProductVariantList(ts transaction.Session, productID int, variant product.Variant) error
+	AddProductVariantList(ts transaction.Session, productID int, variant product.Variant) (variantID int, err error)
+	AddVariantBarcode(ts transaction.Session, variantID int, barcode string) error
+	FindVariantIDByBarcode(ts transaction.Session, barcode string) (int, error)
+	FindVariantIDBySKU(ts transaction.Session, sku string) (int, error)
+	FindVariantByBarcode(ts transaction.Session, barcode string) (product.BarcodeVariant, error)
+	FindVariantBarcodeList(ts transaction.Session, variantID int) ([]string, error)
 
 	CheckExists(ts transaction.Session, p product.ProductPriceParams) (int, error)
 	UpdateProductPrice(ts transaction.Session, p product.ProductPriceParams, id int) error
@@ -25,21 +45,31 @@ type Product interface {
 
 	CheckProductInStock(ts transaction.Session, p stock.ProductInStockParams) (bool, error)
 	UpdateProductInstock(ts transaction.Session, p stock.ProductInStockParams) (int, error)
+	IncreaseProductInstock(ts transaction.Session, p stock.ProductInStockParams) (productStockID, total int, err error)
 	AddProductInStock(ts transaction.Session, p stock.ProductInStockParams) (int, error)
 
 	LoadProductInfo(ts transaction.Session, productID int) (product.ProductInfo, error)
 	FindProductVariantList(ts transaction.Session, productID int) ([]product.Variant, error)
 	FindCurrentPrice(ts transaction.Session, variantID int) (float64, error)
 	InStorages(ts transaction.Session, variantID int) ([]product.VarStorage, error)
+	FindVariantListByProductIDList(ts transaction.Session, productIDList []int) ([]product.Variant, error)
+	FindVariantIDListByIDList(ts transaction.Session, variantIDList []int) ([]int, error)
+	FindCurrentPriceListByVariantIDList(ts transaction.Session, variantIDList []int) ([]product.VariantPrice, error)
+	FindStorageListByVariantIDList(ts transaction.Session, variantIDList []int) ([]product.VariantStorage, error)
 
 	FindProductListByTag(ts transaction.Session, tag string, limit int) ([]product.ProductInfo, error)
 	FindProductListByName(ts transaction.Session, name string, limit int) ([]product.ProductInfo, error)
 	FindProductListByTagAndName(ts transaction.Session, tag, name string, limit int) ([]product.ProductInfo, error)
 	LoadProductList(ts transaction.Session, limit int) ([]product.ProductInfo, error)
+	FindProductListByAttributes(ts transaction.Session, f attribute.Filter, tag, name string, limit int) ([]product.ProductInfo, error)
+	UpdateProductAttributes(ts transaction.Session, productID, categoryID int, values attribute.Values) error
 
-	LoadStockList(ts transaction.Session) ([]stock.Stock, error)
+	LoadStockList(ts transaction.Session, withRemoved bool) ([]stock.Stock, error)
+	LoadStorage(ts transaction.Session, storageID int) (stock.Stock, error)
 	FindStockListByProductId(ts transaction.Session, productID int) ([]stock.Stock, error)
 	FindStocksVariantList(ts transaction.Session, storageID int) ([]stock.ProductInStockParams, error)
+	FindStocksVariantListByStorageIDList(ts transaction.Session, storageIDList []int) ([]stock.ProductInStockParams, error)
+	FindStockLevelList(ts transaction.Session, storageIDList []int) ([]stock.StockLevel, error)
 
 	SaveSale(ts transaction.Session, s product.SaleParams) (int, error)
 	FindPrice(ts transaction.Session, variantID int) (float64, error)
@@ -48,5 +78,171 @@ type Product interface {
 	FindSaleListByFilters(ts transaction.Session, sq product.SaleQueryParam) ([]product.Sale, error)
 
 	AddStock(ts transaction.Session, storage stock.StockParams) (stockID int, err error)
-	DeleteStock(ts transaction.Session, storage stock.StockParams) error
+	UpdateStock(ts transaction.Session, storage stock.StockParams) error
+	DeleteStock(ts transaction.Session, storageID int) error
+}
+
+type Outbox interface {
+	SaveEvent(ts transaction.Session, e event.Event) (eventID int64, err error)
+	LoadPendingEventList(ts transaction.Session, limit int) ([]event.Event, error)
+	MarkPublished(ts transaction.Session, eventID int64) error
+	MarkFailed(ts transaction.Session, eventID int64, nextAttemptAt time.Time, lastError string) error
+}
+
+type Webhook interface {
+	AddSubscription(ts transaction.Session, p webhook.SubscriptionParams) (subscriptionID int, err error)
+	FindSubscriptionList(ts transaction.Session) ([]webhook.Subscription, error)
+	RemoveSubscription(ts transaction.Session, subscriptionID int) (removed bool, err error)
+
+	CreateDeliveryList(ts transaction.Session, e event.Event) error
+	FindDeliveryList(ts transaction.Session, subscriptionID, limit int) ([]webhook.Delivery, error)
+	LoadPendingDeliveryList(ts transaction.Session, limit int) ([]webhook.Delivery, error)
+	ClaimDeliveryList(ts transaction.Session, deliveryIDList []int64, until time.Time) error
+	MarkDelivered(ts transaction.Session, deliveryID int64, statusCode int) error
+	MarkFailed(ts transaction.Session, deliveryID int64, status string, nextAttemptAt time.Time, statusCode int, lastError string) error
+}
+
+type Stock interface {
+	IncreaseProductInStock(ts transaction.Session, variantID, storageID, quantity int) (total int, err error)
+	DecreaseProductInStock(ts transaction.Session, variantID, storageID, quantity int) (remaining int, err error)
+	SaveWriteOff(ts transaction.Session, w stock.WriteOffParams) (writeOffID int, err error)
+
+	SaveThreshold(ts transaction.Session, t stock.ThresholdParams) error
+	FindThreshold(ts transaction.Session, variantID, storageID int) (stock.ThresholdParams, error)
+	FindLowStockList(ts transaction.Session, storageID int) ([]stock.LowStock, error)
+
+	AddBatch(ts transaction.Session, b stock.Batch) (batchID int, err error)
+	LoadBatchList(ts transaction.Session, variantID, storageID int) ([]stock.Batch, error)
+	FindBatchList(ts transaction.Session, variantID, storageID int) ([]stock.Batch, error)
+	ConsumeBatch(ts transaction.Session, batchID, quantity int) error
+	TrimBatches(ts transaction.Session, variantID, storageID, total int) error
+	FindExpiringBatchList(ts transaction.Session, storageID, days int) ([]stock.ExpiringBatch, error)
+	LoadExpiredBatchList(ts transaction.Session, storageID int) ([]stock.Batch, error)
+}
+
+type Purchase interface {
+	AddSupplier(ts transaction.Session, p purchase.SupplierParams) (supplierID int, err error)
+	FindSupplierList(ts transaction.Session) ([]purchase.Supplier, error)
+	LoadSupplier(ts transaction.Session, supplierID int) (purchase.Supplier, error)
+
+	AddPurchaseOrder(ts transaction.Session, p purchase.OrderParams) (orderID int, err error)
+	AddPurchaseOrderLine(ts transaction.Session, orderID int, l purchase.OrderLineParams) (lineID int, err error)
+	LoadPurchaseOrder(ts transaction.Session, orderID int) (purchase.Order, error)
+	FindPurchaseOrderLineList(ts transaction.Session, orderID int) ([]purchase.OrderLine, error)
+	FindPurchaseOrderList(ts transaction.Session, status string, supplierID int) ([]purchase.Order, error)
+	UpdatePurchaseOrderStatus(ts transaction.Session, orderID int, status string) error
+
+	AddGoodsReceipt(ts transaction.Session, orderID, storageID int) (receiptID int, err error)
+	AddGoodsReceiptLine(ts transaction.Session, l purchase.ReceiptLine) error
+	ReceivePurchaseOrderLine(ts transaction.Session, lineID, quantity int) error
+}
+
+type Valuation interface {
+	AddCostLayer(ts transaction.Session, l valuation.CostLayer) error
+	LoadCostLayerList(ts transaction.Session, variantID, storageID int) ([]valuation.CostLayer, error)
+	ConsumeCostLayer(ts transaction.Session, layerID, quantity int) error
+
+	AddAverageCost(ts transaction.Session, variantID, storageID, quantity int, unitCost float64) error
+	LoadAverageCost(ts transaction.Session, variantID, storageID int) (valuation.AverageCost, error)
+	ConsumeAverageCost(ts transaction.Session, variantID, storageID, quantity int) error
+
+	FindFIFOValuationList(ts transaction.Session, storageID int) ([]valuation.Valuation, error)
+	FindAverageValuationList(ts transaction.Session, storageID int) ([]valuation.Valuation, error)
+	FindMarginList(ts transaction.Session, q valuation.MarginQuery) ([]valuation.Margin, error)
+}
+
+type Stocktake interface {
+	AddStocktake(ts transaction.Session, p stocktake.Params) (stocktakeID int, err error)
+	SnapshotStocktakeLines(ts transaction.Session, stocktakeID, storageID int) error
+	LoadStocktake(ts transaction.Session, stocktakeID int) (stocktake.Stocktake, error)
+	FindStocktakeList(ts transaction.Session, storageID int, status string) ([]stocktake.Stocktake, error)
+	FindStocktakeLineList(ts transaction.Session, stocktakeID int) ([]stocktake.Line, error)
+	SaveCount(ts transaction.Session, stocktakeID int, c stocktake.CountParams, accumulate bool) error
+	SetLineAdjustment(ts transaction.Session, lineID, adjustment int) error
+	UpdateStocktakeStatus(ts transaction.Session, stocktakeID int, status string) error
+}
+
+type Reservation interface {
+	AddReservation(ts transaction.Session, r reservation.Reservation) (reservationID int, err error)
+	LoadStockQuantity(ts transaction.Session, variantID, storageID int) (int, error)
+	FindReservedQuantity(ts transaction.Session, variantID, storageID int) (int, error)
+	LoadReservation(ts transaction.Session, reservationID int) (reservation.Reservation, error)
+	FindReservationList(ts transaction.Session, variantID, storageID int, status string) ([]reservation.Reservation, error)
+	CloseReservation(ts transaction.Session, reservationID int, status string, saleID int) error
+	ExpireReservationList(ts transaction.Session, limit int) ([]reservation.Reservation, error)
+}
+
+type Location interface {
+	AddLocation(ts transaction.Session, l location.Location) (locationID int, err error)
+	LoadLocation(ts transaction.Session, locationID int) (location.Location, error)
+	FindLocationList(ts transaction.Session, storageID int) ([]location.Location, error)
+	FindLocationStockList(ts transaction.Session, storageID int) ([]location.Stock, error)
+	LoadUnplacedQuantity(ts transaction.Session, variantID, storageID int) (int, error)
+	PutLocationStock(ts transaction.Session, locationID, variantID, quantity int) error
+	TakeLocationStock(ts transaction.Session, locationID, variantID, quantity int) error
+	TrimLocationStock(ts transaction.Session, variantID, storageID, total int) error
+}
+
+type Unit interface {
+	AddUnit(ts transaction.Session, u unit.Unit) error
+	LoadUnit(ts transaction.Session, code string) (unit.Unit, error)
+	FindUnitList(ts transaction.Session) ([]unit.Unit, error)
+}
+
+type Image interface {
+	AddImage(ts transaction.Session, img image.Image) (imageID int, err error)
+	LoadImage(ts transaction.Session, imageID int) (image.Image, error)
+	FindImageList(ts transaction.Session, productID int) ([]image.Image, error)
+	FindImageListByProductIDList(ts transaction.Session, productIDList []int) ([]image.Image, error)
+	LockProductImages(ts transaction.Session, productID int) error
+	NextImagePosition(ts transaction.Session, productID int) (int, error)
+	SetImagePosition(ts transaction.Session, imageID, position int) error
+	SetPrimaryImage(ts transaction.Session, productID, imageID int) error
+	PromoteFirstImage(ts transaction.Session, productID int) error
+	DeleteImage(ts transaction.Session, imageID int) error
+}
+
+type Attribute interface {
+	AddCategory(ts transaction.Session, name string) (categoryID int, err error)
+	LoadCategory(ts transaction.Session, categoryID int) (attribute.Category, error)
+	FindCategoryIDByName(ts transaction.Session, name string) (int, error)
+	FindCategoryList(ts transaction.Session) ([]attribute.Category, error)
+	AddDefinition(ts transaction.Session, d attribute.Definition) error
+	FindDefinitionList(ts transaction.Session, categoryID int) ([]attribute.Definition, error)
+	CountCategoryProducts(ts transaction.Session, categoryID int) (int, error)
+}
+
+type Promotion interface {
+	AddPromotion(ts transaction.Session, p promotion.Promotion) (promotionID int, err error)
+	FindPromotionList(ts transaction.Session, activeAt time.Time) ([]promotion.Promotion, error)
+	FindApplicablePromotionList(ts transaction.Session, variantID, storageID int, at time.Time) ([]promotion.Promotion, error)
+	EndPromotion(ts transaction.Session, promotionID int, at time.Time) error
+}
+
+type Customer interface {
+	AddCustomer(ts transaction.Session, p customer.Params) (customerID int, err error)
+	LoadCustomer(ts transaction.Session, customerID int) (customer.Customer, error)
+	FindCustomerIDByPhone(ts transaction.Session, phone string) (int, error)
+	FindCustomerList(ts transaction.Session, search, customerType string, limit int) ([]customer.Customer, error)
+	UpdateCustomer(ts transaction.Session, customerID int, p customer.Params) error
+	RemoveCustomer(ts transaction.Session, customerID int) error
+
+	SetGroupPrice(ts transaction.Session, p customer.GroupPriceParams, at time.Time) (priceID int, err error)
+	FindGroupPrice(ts transaction.Session, variantID int, customerType string, at time.Time) (float64, error)
+	FindGroupPriceList(ts transaction.Session, customerType string) ([]customer.GroupPrice, error)
+
+	FindCustomerSaleList(ts transaction.Session, customerID, limit int) ([]product.Sale, error)
+	LoadCustomerSaleTotal(ts transaction.Session, customerID int) (customer.SaleTotal, error)
+}
+
+type Reprice interface {
+	FindLineList(ts transaction.Session, p reprice.Params, at time.Time) ([]reprice.Line, error)
+	ReplacePrice(ts transaction.Session, variantID int, price float64, at time.Time) (priceID int, err error)
+}
+
+type Payment interface {
+	AddPayment(ts transaction.Session, saleID int, p payment.Params) (paymentID int, err error)
+	FindPaymentList(ts transaction.Session, saleID int) ([]payment.Payment, error)
+	FindZStorageTotalList(ts transaction.Session, start, end time.Time, storageID int) ([]payment.ZStorageTotal, error)
+	FindZMethodTotalList(ts transaction.Session, start, end time.Time, storageID int) ([]payment.ZMethodTotal, error)
 }
@@ -5,11 +5,26 @@
 package repository
 
 import (
+	attribute "product_storage/internal/entity/attribute"
+	customer "product_storage/internal/entity/customer"
+	event "product_storage/internal/entity/event"
+	image "product_storage/internal/entity/image"
+	location "product_storage/internal/entity/location"
 	log "product_storage/internal/entity/log"
+	payment "product_storage/internal/entity/payment"
 	product "product_storage/internal/entity/product"
+	promotion "product_storage/internal/entity/promotion"
+	purchase "product_storage/internal/entity/purchase"
+	reprice "product_storage/internal/entity/reprice"
+	reservation "product_storage/internal/entity/reservation"
 	stock "product_storage/internal/entity/stock"
+	stocktake "product_storage/internal/entity/stocktake"
+	unit "product_storage/internal/entity/unit"
+	valuation "product_storage/internal/entity/valuation"
+	webhook "product_storage/internal/entity/webhook"
 	transaction "product_storage/internal/transaction"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -149,11 +164,12 @@ func (mr *MockProductMockRecorder) AddProductPrice(ts, p interface{}) *gomock.Ca
 }
 
 // AddProductVariantList mocks base method.
-func (m *MockProduct) AddProductVariantList(ts transaction.Session, productID int, variant product.Variant) error {
+func (m *MockProduct) AddProductVariantList(ts transaction.Session, productID int, variant product.Variant) (int, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "AddProductVariantList", ts, productID, variant)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // AddProductVariantList indicates an expected call of AddProductVariantList.
@@ -177,6 +193,20 @@ func (mr *MockProductMockRecorder) AddStock(ts, storage interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddStock", reflect.TypeOf((*MockProduct)(nil).AddStock), ts, storage)
 }
 
+// AddVariantBarcode mocks base method.
+func (m *MockProduct) AddVariantBarcode(ts transaction.Session, variantID int, barcode string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddVariantBarcode", ts, variantID, barcode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddVariantBarcode indicates an expected call of AddVariantBarcode.
+func (mr *MockProductMockRecorder) AddVariantBarcode(ts, variantID, barcode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddVariantBarcode", reflect.TypeOf((*MockProduct)(nil).AddVariantBarcode), ts, variantID, barcode)
+}
+
 // CheckExists mocks base method.
 func (m *MockProduct) CheckExists(ts transaction.Session, p product.ProductPriceParams) (int, error) {
 	m.ctrl.T.Helper()
@@ -208,17 +238,17 @@ func (mr *MockProductMockRecorder) CheckProductInStock(ts, p interface{}) *gomoc
 }
 
 // DeleteStock mocks base method.
-func (m *MockProduct) DeleteStock(ts transaction.Session, storage stock.StockParams) error {
+func (m *MockProduct) DeleteStock(ts transaction.Session, storageID int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteStock", ts, storage)
+	ret := m.ctrl.Call(m, "DeleteStock", ts, storageID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteStock indicates an expected call of DeleteStock.
-func (mr *MockProductMockRecorder) DeleteStock(ts, storage interface{}) *gomock.Call {
+func (mr *MockProductMockRecorder) DeleteStock(ts, storageID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStock", reflect.TypeOf((*MockProduct)(nil).DeleteStock), ts, storage)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStock", reflect.TypeOf((*MockProduct)(nil).DeleteStock), ts, storageID)
 }
 
 // FindCurrentPrice mocks base method.
@@ -236,6 +266,21 @@ func (mr *MockProductMockRecorder) FindCurrentPrice(ts, variantID interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCurrentPrice", reflect.TypeOf((*MockProduct)(nil).FindCurrentPrice), ts, variantID)
 }
 
+// FindCurrentPriceListByVariantIDList mocks base method.
+func (m *MockProduct) FindCurrentPriceListByVariantIDList(ts transaction.Session, variantIDList []int) ([]product.VariantPrice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCurrentPriceListByVariantIDList", ts, variantIDList)
+	ret0, _ := ret[0].([]product.VariantPrice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCurrentPriceListByVariantIDList indicates an expected call of FindCurrentPriceListByVariantIDList.
+func (mr *MockProductMockRecorder) FindCurrentPriceListByVariantIDList(ts, variantIDList interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCurrentPriceListByVariantIDList", reflect.TypeOf((*MockProduct)(nil).FindCurrentPriceListByVariantIDList), ts, variantIDList)
+}
+
 // FindPrice mocks base method.
 func (m *MockProduct) FindPrice(ts transaction.Session, variantID int) (float64, error) {
 	m.ctrl.T.Helper()
@@ -251,6 +296,21 @@ func (mr *MockProductMockRecorder) FindPrice(ts, variantID interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPrice", reflect.TypeOf((*MockProduct)(nil).FindPrice), ts, variantID)
 }
 
+// FindProductListByAttributes mocks base method.
+func (m *MockProduct) FindProductListByAttributes(ts transaction.Session, f attribute.Filter, tag, name string, limit int) ([]product.ProductInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProductListByAttributes", ts, f, tag, name, limit)
+	ret0, _ := ret[0].([]product.ProductInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindProductListByAttributes indicates an expected call of FindProductListByAttributes.
+func (mr *MockProductMockRecorder) FindProductListByAttributes(ts, f, tag, name, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProductListByAttributes", reflect.TypeOf((*MockProduct)(nil).FindProductListByAttributes), ts, f, tag, name, limit)
+}
+
 // FindProductListByName mocks base method.
 func (m *MockProduct) FindProductListByName(ts transaction.Session, name string, limit int) ([]product.ProductInfo, error) {
 	m.ctrl.T.Helper()
@@ -341,6 +401,21 @@ func (mr *MockProductMockRecorder) FindSaleListOnlyBySoldDate(ts, sq interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSaleListOnlyBySoldDate", reflect.TypeOf((*MockProduct)(nil).FindSaleListOnlyBySoldDate), ts, sq)
 }
 
+// FindStockLevelList mocks base method.
+func (m *MockProduct) FindStockLevelList(ts transaction.Session, storageIDList []int) ([]stock.StockLevel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindStockLevelList", ts, storageIDList)
+	ret0, _ := ret[0].([]stock.StockLevel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindStockLevelList indicates an expected call of FindStockLevelList.
+func (mr *MockProductMockRecorder) FindStockLevelList(ts, storageIDList interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindStockLevelList", reflect.TypeOf((*MockProduct)(nil).FindStockLevelList), ts, storageIDList)
+}
+
 // FindStockListByProductId mocks base method.
 func (m *MockProduct) FindStockListByProductId(ts transaction.Session, productID int) ([]stock.Stock, error) {
 	m.ctrl.T.Helper()
@@ -371,6 +446,126 @@ func (mr *MockProductMockRecorder) FindStocksVariantList(ts, storageID interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindStocksVariantList", reflect.TypeOf((*MockProduct)(nil).FindStocksVariantList), ts, storageID)
 }
 
+// FindStocksVariantListByStorageIDList mocks base method.
+func (m *MockProduct) FindStocksVariantListByStorageIDList(ts transaction.Session, storageIDList []int) ([]stock.ProductInStockParams, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindStocksVariantListByStorageIDList", ts, storageIDList)
+	ret0, _ := ret[0].([]stock.ProductInStockParams)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindStocksVariantListByStorageIDList indicates an expected call of FindStocksVariantListByStorageIDList.
+func (mr *MockProductMockRecorder) FindStocksVariantListByStorageIDList(ts, storageIDList interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindStocksVariantListByStorageIDList", reflect.TypeOf((*MockProduct)(nil).FindStocksVariantListByStorageIDList), ts, storageIDList)
+}
+
+// FindStorageListByVariantIDList mocks base method.
+func (m *MockProduct) FindStorageListByVariantIDList(ts transaction.Session, variantIDList []int) ([]product.VariantStorage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindStorageListByVariantIDList", ts, variantIDList)
+	ret0, _ := ret[0].([]product.VariantStorage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindStorageListByVariantIDList indicates an expected call of FindStorageListByVariantIDList.
+func (mr *MockProductMockRecorder) FindStorageListByVariantIDList(ts, variantIDList interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindStorageListByVariantIDList", reflect.TypeOf((*MockProduct)(nil).FindStorageListByVariantIDList), ts, variantIDList)
+}
+
+// FindVariantBarcodeList mocks base method.
+func (m *MockProduct) FindVariantBarcodeList(ts transaction.Session, variantID int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindVariantBarcodeList", ts, variantID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindVariantBarcodeList indicates an expected call of FindVariantBarcodeList.
+func (mr *MockProductMockRecorder) FindVariantBarcodeList(ts, variantID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindVariantBarcodeList", reflect.TypeOf((*MockProduct)(nil).FindVariantBarcodeList), ts, variantID)
+}
+
+// FindVariantByBarcode mocks base method.
+func (m *MockProduct) FindVariantByBarcode(ts transaction.Session, barcode string) (product.BarcodeVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindVariantByBarcode", ts, barcode)
+	ret0, _ := ret[0].(product.BarcodeVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindVariantByBarcode indicates an expected call of FindVariantByBarcode.
+func (mr *MockProductMockRecorder) FindVariantByBarcode(ts, barcode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindVariantByBarcode", reflect.TypeOf((*MockProduct)(nil).FindVariantByBarcode), ts, barcode)
+}
+
+// FindVariantIDByBarcode mocks base method.
+func (m *MockProduct) FindVariantIDByBarcode(ts transaction.Session, barcode string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindVariantIDByBarcode", ts, barcode)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindVariantIDByBarcode indicates an expected call of FindVariantIDByBarcode.
+func (mr *MockProductMockRecorder) FindVariantIDByBarcode(ts, barcode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindVariantIDByBarcode", reflect.TypeOf((*MockProduct)(nil).FindVariantIDByBarcode), ts, barcode)
+}
+
+// FindVariantIDBySKU mocks base method.
+func (m *MockProduct) FindVariantIDBySKU(ts transaction.Session, sku string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindVariantIDBySKU", ts, sku)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindVariantIDBySKU indicates an expected call of FindVariantIDBySKU.
+func (mr *MockProductMockRecorder) FindVariantIDBySKU(ts, sku interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindVariantIDBySKU", reflect.TypeOf((*MockProduct)(nil).FindVariantIDBySKU), ts, sku)
+}
+
+// FindVariantIDListByIDList mocks base method.
+func (m *MockProduct) FindVariantIDListByIDList(ts transaction.Session, variantIDList []int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindVariantIDListByIDList", ts, variantIDList)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindVariantIDListByIDList indicates an expected call of FindVariantIDListByIDList.
+func (mr *MockProductMockRecorder) FindVariantIDListByIDList(ts, variantIDList interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindVariantIDListByIDList", reflect.TypeOf((*MockProduct)(nil).FindVariantIDListByIDList), ts, variantIDList)
+}
+
+// FindVariantListByProductIDList mocks base method.
+func (m *MockProduct) FindVariantListByProductIDList(ts transaction.Session, productIDList []int) ([]product.Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindVariantListByProductIDList", ts, productIDList)
+	ret0, _ := ret[0].([]product.Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindVariantListByProductIDList indicates an expected call of FindVariantListByProductIDList.
+func (mr *MockProductMockRecorder) FindVariantListByProductIDList(ts, productIDList interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindVariantListByProductIDList", reflect.TypeOf((*MockProduct)(nil).FindVariantListByProductIDList), ts, productIDList)
+}
+
 // InStorages mocks base method.
 func (m *MockProduct) InStorages(ts transaction.Session, variantID int) ([]product.VarStorage, error) {
 	m.ctrl.T.Helper()
@@ -386,6 +581,22 @@ func (mr *MockProductMockRecorder) InStorages(ts, variantID interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InStorages", reflect.TypeOf((*MockProduct)(nil).InStorages), ts, variantID)
 }
 
+// IncreaseProductInstock mocks base method.
+func (m *MockProduct) IncreaseProductInstock(ts transaction.Session, p stock.ProductInStockParams) (int, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncreaseProductInstock", ts, p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// IncreaseProductInstock indicates an expected call of IncreaseProductInstock.
+func (mr *MockProductMockRecorder) IncreaseProductInstock(ts, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseProductInstock", reflect.TypeOf((*MockProduct)(nil).IncreaseProductInstock), ts, p)
+}
+
 // LoadProductInfo mocks base method.
 func (m *MockProduct) LoadProductInfo(ts transaction.Session, productID int) (product.ProductInfo, error) {
 	m.ctrl.T.Helper()
@@ -417,18 +628,33 @@ func (mr *MockProductMockRecorder) LoadProductList(ts, limit interface{}) *gomoc
 }
 
 // LoadStockList mocks base method.
-func (m *MockProduct) LoadStockList(ts transaction.Session) ([]stock.Stock, error) {
+func (m *MockProduct) LoadStockList(ts transaction.Session, withRemoved bool) ([]stock.Stock, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LoadStockList", ts)
+	ret := m.ctrl.Call(m, "LoadStockList", ts, withRemoved)
 	ret0, _ := ret[0].([]stock.Stock)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // LoadStockList indicates an expected call of LoadStockList.
-func (mr *MockProductMockRecorder) LoadStockList(ts interface{}) *gomock.Call {
+func (mr *MockProductMockRecorder) LoadStockList(ts, withRemoved interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadStockList", reflect.TypeOf((*MockProduct)(nil).LoadStockList), ts, withRemoved)
+}
+
+// LoadStorage mocks base method.
+func (m *MockProduct) LoadStorage(ts transaction.Session, storageID int) (stock.Stock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadStorage", ts, storageID)
+	ret0, _ := ret[0].(stock.Stock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadStorage indicates an expected call of LoadStorage.
+func (mr *MockProductMockRecorder) LoadStorage(ts, storageID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadStockList", reflect.TypeOf((*MockProduct)(nil).LoadStockList), ts)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadStorage", reflect.TypeOf((*MockProduct)(nil).LoadStorage), ts, storageID)
 }
 
 // SaveSale mocks base method.
@@ -446,6 +672,20 @@ func (mr *MockProductMockRecorder) SaveSale(ts, s interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSale", reflect.TypeOf((*MockProduct)(nil).SaveSale), ts, s)
 }
 
+// UpdateProductAttributes mocks base method.
+func (m *MockProduct) UpdateProductAttributes(ts transaction.Session, productID, categoryID int, values attribute.Values) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProductAttributes", ts, productID, categoryID, values)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProductAttributes indicates an expected call of UpdateProductAttributes.
+func (mr *MockProductMockRecorder) UpdateProductAttributes(ts, productID, categoryID, values interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProductAttributes", reflect.TypeOf((*MockProduct)(nil).UpdateProductAttributes), ts, productID, categoryID, values)
+}
+
 // UpdateProductInstock mocks base method.
 func (m *MockProduct) UpdateProductInstock(ts transaction.Session, p stock.ProductInStockParams) (int, error) {
 	m.ctrl.T.Helper()
@@ -474,3 +714,1993 @@ func (mr *MockProductMockRecorder) UpdateProductPrice(ts, p, id interface{}) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProductPrice", reflect.TypeOf((*MockProduct)(nil).UpdateProductPrice), ts, p, id)
 }
+
+// UpdateStock mocks base method.
+func (m *MockProduct) UpdateStock(ts transaction.Session, storage stock.StockParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStock", ts, storage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStock indicates an expected call of UpdateStock.
+func (mr *MockProductMockRecorder) UpdateStock(ts, storage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStock", reflect.TypeOf((*MockProduct)(nil).UpdateStock), ts, storage)
+}
+
+// MockOutbox is a mock of Outbox interface.
+type MockOutbox struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxMockRecorder
+}
+
+// MockOutboxMockRecorder is the mock recorder for MockOutbox.
+type MockOutboxMockRecorder struct {
+	mock *MockOutbox
+}
+
+// NewMockOutbox creates a new mock instance.
+func NewMockOutbox(ctrl *gomock.Controller) *MockOutbox {
+	mock := &MockOutbox{ctrl: ctrl}
+	mock.recorder = &MockOutboxMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutbox) EXPECT() *MockOutboxMockRecorder {
+	return m.recorder
+}
+
+// LoadPendingEventList mocks base method.
+func (m *MockOutbox) LoadPendingEventList(ts transaction.Session, limit int) ([]event.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadPendingEventList", ts, limit)
+	ret0, _ := ret[0].([]event.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadPendingEventList indicates an expected call of LoadPendingEventList.
+func (mr *MockOutboxMockRecorder) LoadPendingEventList(ts, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPendingEventList", reflect.TypeOf((*MockOutbox)(nil).LoadPendingEventList), ts, limit)
+}
+
+// MarkFailed mocks base method.
+func (m *MockOutbox) MarkFailed(ts transaction.Session, eventID int64, nextAttemptAt time.Time, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFailed", ts, eventID, nextAttemptAt, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFailed indicates an expected call of MarkFailed.
+func (mr *MockOutboxMockRecorder) MarkFailed(ts, eventID, nextAttemptAt, lastError interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockOutbox)(nil).MarkFailed), ts, eventID, nextAttemptAt, lastError)
+}
+
+// MarkPublished mocks base method.
+func (m *MockOutbox) MarkPublished(ts transaction.Session, eventID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPublished", ts, eventID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkPublished indicates an expected call of MarkPublished.
+func (mr *MockOutboxMockRecorder) MarkPublished(ts, eventID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPublished", reflect.TypeOf((*MockOutbox)(nil).MarkPublished), ts, eventID)
+}
+
+// SaveEvent mocks base method.
+func (m *MockOutbox) SaveEvent(ts transaction.Session, e event.Event) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveEvent", ts, e)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveEvent indicates an expected call of SaveEvent.
+func (mr *MockOutboxMockRecorder) SaveEvent(ts, e interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEvent", reflect.TypeOf((*MockOutbox)(nil).SaveEvent), ts, e)
+}
+
+// MockWebhook is a mock of Webhook interface.
+type MockWebhook struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookMockRecorder
+}
+
+// MockWebhookMockRecorder is the mock recorder for MockWebhook.
+type MockWebhookMockRecorder struct {
+	mock *MockWebhook
+}
+
+// NewMockWebhook creates a new mock instance.
+func NewMockWebhook(ctrl *gomock.Controller) *MockWebhook {
+	mock := &MockWebhook{ctrl: ctrl}
+	mock.recorder = &MockWebhookMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhook) EXPECT() *MockWebhookMockRecorder {
+	return m.recorder
+}
+
+// AddSubscription mocks base method.
+func (m *MockWebhook) AddSubscription(ts transaction.Session, p webhook.SubscriptionParams) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSubscription", ts, p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSubscription indicates an expected call of AddSubscription.
+func (mr *MockWebhookMockRecorder) AddSubscription(ts, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSubscription", reflect.TypeOf((*MockWebhook)(nil).AddSubscription), ts, p)
+}
+
+// ClaimDeliveryList mocks base method.
+func (m *MockWebhook) ClaimDeliveryList(ts transaction.Session, deliveryIDList []int64, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDeliveryList", ts, deliveryIDList, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimDeliveryList indicates an expected call of ClaimDeliveryList.
+func (mr *MockWebhookMockRecorder) ClaimDeliveryList(ts, deliveryIDList, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDeliveryList", reflect.TypeOf((*MockWebhook)(nil).ClaimDeliveryList), ts, deliveryIDList, until)
+}
+
+// CreateDeliveryList mocks base method.
+func (m *MockWebhook) CreateDeliveryList(ts transaction.Session, e event.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeliveryList", ts, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDeliveryList indicates an expected call of CreateDeliveryList.
+func (mr *MockWebhookMockRecorder) CreateDeliveryList(ts, e interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeliveryList", reflect.TypeOf((*MockWebhook)(nil).CreateDeliveryList), ts, e)
+}
+
+// FindDeliveryList mocks base method.
+func (m *MockWebhook) FindDeliveryList(ts transaction.Session, subscriptionID, limit int) ([]webhook.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDeliveryList", ts, subscriptionID, limit)
+	ret0, _ := ret[0].([]webhook.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDeliveryList indicates an expected call of FindDeliveryList.
+func (mr *MockWebhookMockRecorder) FindDeliveryList(ts, subscriptionID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDeliveryList", reflect.TypeOf((*MockWebhook)(nil).FindDeliveryList), ts, subscriptionID, limit)
+}
+
+// FindSubscriptionList mocks base method.
+func (m *MockWebhook) FindSubscriptionList(ts transaction.Session) ([]webhook.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSubscriptionList", ts)
+	ret0, _ := ret[0].([]webhook.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSubscriptionList indicates an expected call of FindSubscriptionList.
+func (mr *MockWebhookMockRecorder) FindSubscriptionList(ts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSubscriptionList", reflect.TypeOf((*MockWebhook)(nil).FindSubscriptionList), ts)
+}
+
+// LoadPendingDeliveryList mocks base method.
+func (m *MockWebhook) LoadPendingDeliveryList(ts transaction.Session, limit int) ([]webhook.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadPendingDeliveryList", ts, limit)
+	ret0, _ := ret[0].([]webhook.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadPendingDeliveryList indicates an expected call of LoadPendingDeliveryList.
+func (mr *MockWebhookMockRecorder) LoadPendingDeliveryList(ts, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPendingDeliveryList", reflect.TypeOf((*MockWebhook)(nil).LoadPendingDeliveryList), ts, limit)
+}
+
+// MarkDelivered mocks base method.
+func (m *MockWebhook) MarkDelivered(ts transaction.Session, deliveryID int64, statusCode int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDelivered", ts, deliveryID, statusCode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDelivered indicates an expected call of MarkDelivered.
+func (mr *MockWebhookMockRecorder) MarkDelivered(ts, deliveryID, statusCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDelivered", reflect.TypeOf((*MockWebhook)(nil).MarkDelivered), ts, deliveryID, statusCode)
+}
+
+// MarkFailed mocks base method.
+func (m *MockWebhook) MarkFailed(ts transaction.Session, deliveryID int64, status string, nextAttemptAt time.Time, statusCode int, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFailed", ts, deliveryID, status, nextAttemptAt, statusCode, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFailed indicates an expected call of MarkFailed.
+func (mr *MockWebhookMockRecorder) MarkFailed(ts, deliveryID, status, nextAttemptAt, statusCode, lastError interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockWebhook)(nil).MarkFailed), ts, deliveryID, status, nextAttemptAt, statusCode, lastError)
+}
+
+// RemoveSubscription mocks base method.
+func (m *MockWebhook) RemoveSubscription(ts transaction.Session, subscriptionID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveSubscription", ts, subscriptionID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveSubscription indicates an expected call of RemoveSubscription.
+func (mr *MockWebhookMockRecorder) RemoveSubscription(ts, subscriptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSubscription", reflect.TypeOf((*MockWebhook)(nil).RemoveSubscription), ts, subscriptionID)
+}
+
+// MockStock is a mock of Stock interface.
+type MockStock struct {
+	ctrl     *gomock.Controller
+	recorder *MockStockMockRecorder
+}
+
+// MockStockMockRecorder is the mock recorder for MockStock.
+type MockStockMockRecorder struct {
+	mock *MockStock
+}
+
+// NewMockStock creates a new mock instance.
+func NewMockStock(ctrl *gomock.Controller) *MockStock {
+	mock := &MockStock{ctrl: ctrl}
+	mock.recorder = &MockStockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStock) EXPECT() *MockStockMockRecorder {
+	return m.recorder
+}
+
+// AddBatch mocks base method.
+func (m *MockStock) AddBatch(ts transaction.Session, b stock.Batch) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddBatch", ts, b)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddBatch indicates an expected call of AddBatch.
+func (mr *MockStockMockRecorder) AddBatch(ts, b interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBatch", reflect.TypeOf((*MockStock)(nil).AddBatch), ts, b)
+}
+
+// ConsumeBatch mocks base method.
+func (m *MockStock) ConsumeBatch(ts transaction.Session, batchID, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeBatch", ts, batchID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeBatch indicates an expected call of ConsumeBatch.
+func (mr *MockStockMockRecorder) ConsumeBatch(ts, batchID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeBatch", reflect.TypeOf((*MockStock)(nil).ConsumeBatch), ts, batchID, quantity)
+}
+
+// DecreaseProductInStock mocks base method.
+func (m *MockStock) DecreaseProductInStock(ts transaction.Session, variantID, storageID, quantity int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecreaseProductInStock", ts, variantID, storageID, quantity)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecreaseProductInStock indicates an expected call of DecreaseProductInStock.
+func (mr *MockStockMockRecorder) DecreaseProductInStock(ts, variantID, storageID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecreaseProductInStock", reflect.TypeOf((*MockStock)(nil).DecreaseProductInStock), ts, variantID, storageID, quantity)
+}
+
+// FindBatchList mocks base method.
+func (m *MockStock) FindBatchList(ts transaction.Session, variantID, storageID int) ([]stock.Batch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBatchList", ts, variantID, storageID)
+	ret0, _ := ret[0].([]stock.Batch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBatchList indicates an expected call of FindBatchList.
+func (mr *MockStockMockRecorder) FindBatchList(ts, variantID, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBatchList", reflect.TypeOf((*MockStock)(nil).FindBatchList), ts, variantID, storageID)
+}
+
+// FindExpiringBatchList mocks base method.
+func (m *MockStock) FindExpiringBatchList(ts transaction.Session, storageID, days int) ([]stock.ExpiringBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindExpiringBatchList", ts, storageID, days)
+	ret0, _ := ret[0].([]stock.ExpiringBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindExpiringBatchList indicates an expected call of FindExpiringBatchList.
+func (mr *MockStockMockRecorder) FindExpiringBatchList(ts, storageID, days interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindExpiringBatchList", reflect.TypeOf((*MockStock)(nil).FindExpiringBatchList), ts, storageID, days)
+}
+
+// FindLowStockList mocks base method.
+func (m *MockStock) FindLowStockList(ts transaction.Session, storageID int) ([]stock.LowStock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLowStockList", ts, storageID)
+	ret0, _ := ret[0].([]stock.LowStock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLowStockList indicates an expected call of FindLowStockList.
+func (mr *MockStockMockRecorder) FindLowStockList(ts, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLowStockList", reflect.TypeOf((*MockStock)(nil).FindLowStockList), ts, storageID)
+}
+
+// FindThreshold mocks base method.
+func (m *MockStock) FindThreshold(ts transaction.Session, variantID, storageID int) (stock.ThresholdParams, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindThreshold", ts, variantID, storageID)
+	ret0, _ := ret[0].(stock.ThresholdParams)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindThreshold indicates an expected call of FindThreshold.
+func (mr *MockStockMockRecorder) FindThreshold(ts, variantID, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindThreshold", reflect.TypeOf((*MockStock)(nil).FindThreshold), ts, variantID, storageID)
+}
+
+// IncreaseProductInStock mocks base method.
+func (m *MockStock) IncreaseProductInStock(ts transaction.Session, variantID, storageID, quantity int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncreaseProductInStock", ts, variantID, storageID, quantity)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncreaseProductInStock indicates an expected call of IncreaseProductInStock.
+func (mr *MockStockMockRecorder) IncreaseProductInStock(ts, variantID, storageID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseProductInStock", reflect.TypeOf((*MockStock)(nil).IncreaseProductInStock), ts, variantID, storageID, quantity)
+}
+
+// LoadBatchList mocks base method.
+func (m *MockStock) LoadBatchList(ts transaction.Session, variantID, storageID int) ([]stock.Batch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadBatchList", ts, variantID, storageID)
+	ret0, _ := ret[0].([]stock.Batch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadBatchList indicates an expected call of LoadBatchList.
+func (mr *MockStockMockRecorder) LoadBatchList(ts, variantID, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadBatchList", reflect.TypeOf((*MockStock)(nil).LoadBatchList), ts, variantID, storageID)
+}
+
+// LoadExpiredBatchList mocks base method.
+func (m *MockStock) LoadExpiredBatchList(ts transaction.Session, storageID int) ([]stock.Batch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadExpiredBatchList", ts, storageID)
+	ret0, _ := ret[0].([]stock.Batch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadExpiredBatchList indicates an expected call of LoadExpiredBatchList.
+func (mr *MockStockMockRecorder) LoadExpiredBatchList(ts, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadExpiredBatchList", reflect.TypeOf((*MockStock)(nil).LoadExpiredBatchList), ts, storageID)
+}
+
+// SaveThreshold mocks base method.
+func (m *MockStock) SaveThreshold(ts transaction.Session, t stock.ThresholdParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveThreshold", ts, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveThreshold indicates an expected call of SaveThreshold.
+func (mr *MockStockMockRecorder) SaveThreshold(ts, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveThreshold", reflect.TypeOf((*MockStock)(nil).SaveThreshold), ts, t)
+}
+
+// SaveWriteOff mocks base method.
+func (m *MockStock) SaveWriteOff(ts transaction.Session, w stock.WriteOffParams) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWriteOff", ts, w)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveWriteOff indicates an expected call of SaveWriteOff.
+func (mr *MockStockMockRecorder) SaveWriteOff(ts, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWriteOff", reflect.TypeOf((*MockStock)(nil).SaveWriteOff), ts, w)
+}
+
+// TrimBatches mocks base method.
+func (m *MockStock) TrimBatches(ts transaction.Session, variantID, storageID, total int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrimBatches", ts, variantID, storageID, total)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TrimBatches indicates an expected call of TrimBatches.
+func (mr *MockStockMockRecorder) TrimBatches(ts, variantID, storageID, total interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrimBatches", reflect.TypeOf((*MockStock)(nil).TrimBatches), ts, variantID, storageID, total)
+}
+
+// MockPurchase is a mock of Purchase interface.
+type MockPurchase struct {
+	ctrl     *gomock.Controller
+	recorder *MockPurchaseMockRecorder
+}
+
+// MockPurchaseMockRecorder is the mock recorder for MockPurchase.
+type MockPurchaseMockRecorder struct {
+	mock *MockPurchase
+}
+
+// NewMockPurchase creates a new mock instance.
+func NewMockPurchase(ctrl *gomock.Controller) *MockPurchase {
+	mock := &MockPurchase{ctrl: ctrl}
+	mock.recorder = &MockPurchaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPurchase) EXPECT() *MockPurchaseMockRecorder {
+	return m.recorder
+}
+
+// AddGoodsReceipt mocks base method.
+func (m *MockPurchase) AddGoodsReceipt(ts transaction.Session, orderID, storageID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddGoodsReceipt", ts, orderID, storageID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddGoodsReceipt indicates an expected call of AddGoodsReceipt.
+func (mr *MockPurchaseMockRecorder) AddGoodsReceipt(ts, orderID, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddGoodsReceipt", reflect.TypeOf((*MockPurchase)(nil).AddGoodsReceipt), ts, orderID, storageID)
+}
+
+// AddGoodsReceiptLine mocks base method.
+func (m *MockPurchase) AddGoodsReceiptLine(ts transaction.Session, l purchase.ReceiptLine) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddGoodsReceiptLine", ts, l)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddGoodsReceiptLine indicates an expected call of AddGoodsReceiptLine.
+func (mr *MockPurchaseMockRecorder) AddGoodsReceiptLine(ts, l interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddGoodsReceiptLine", reflect.TypeOf((*MockPurchase)(nil).AddGoodsReceiptLine), ts, l)
+}
+
+// AddPurchaseOrder mocks base method.
+func (m *MockPurchase) AddPurchaseOrder(ts transaction.Session, p purchase.OrderParams) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPurchaseOrder", ts, p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPurchaseOrder indicates an expected call of AddPurchaseOrder.
+func (mr *MockPurchaseMockRecorder) AddPurchaseOrder(ts, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPurchaseOrder", reflect.TypeOf((*MockPurchase)(nil).AddPurchaseOrder), ts, p)
+}
+
+// AddPurchaseOrderLine mocks base method.
+func (m *MockPurchase) AddPurchaseOrderLine(ts transaction.Session, orderID int, l purchase.OrderLineParams) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPurchaseOrderLine", ts, orderID, l)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPurchaseOrderLine indicates an expected call of AddPurchaseOrderLine.
+func (mr *MockPurchaseMockRecorder) AddPurchaseOrderLine(ts, orderID, l interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPurchaseOrderLine", reflect.TypeOf((*MockPurchase)(nil).AddPurchaseOrderLine), ts, orderID, l)
+}
+
+// AddSupplier mocks base method.
+func (m *MockPurchase) AddSupplier(ts transaction.Session, p purchase.SupplierParams) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSupplier", ts, p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSupplier indicates an expected call of AddSupplier.
+func (mr *MockPurchaseMockRecorder) AddSupplier(ts, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSupplier", reflect.TypeOf((*MockPurchase)(nil).AddSupplier), ts, p)
+}
+
+// FindPurchaseOrderLineList mocks base method.
+func (m *MockPurchase) FindPurchaseOrderLineList(ts transaction.Session, orderID int) ([]purchase.OrderLine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPurchaseOrderLineList", ts, orderID)
+	ret0, _ := ret[0].([]purchase.OrderLine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPurchaseOrderLineList indicates an expected call of FindPurchaseOrderLineList.
+func (mr *MockPurchaseMockRecorder) FindPurchaseOrderLineList(ts, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPurchaseOrderLineList", reflect.TypeOf((*MockPurchase)(nil).FindPurchaseOrderLineList), ts, orderID)
+}
+
+// FindPurchaseOrderList mocks base method.
+func (m *MockPurchase) FindPurchaseOrderList(ts transaction.Session, status string, supplierID int) ([]purchase.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPurchaseOrderList", ts, status, supplierID)
+	ret0, _ := ret[0].([]purchase.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPurchaseOrderList indicates an expected call of FindPurchaseOrderList.
+func (mr *MockPurchaseMockRecorder) FindPurchaseOrderList(ts, status, supplierID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPurchaseOrderList", reflect.TypeOf((*MockPurchase)(nil).FindPurchaseOrderList), ts, status, supplierID)
+}
+
+// FindSupplierList mocks base method.
+func (m *MockPurchase) FindSupplierList(ts transaction.Session) ([]purchase.Supplier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSupplierList", ts)
+	ret0, _ := ret[0].([]purchase.Supplier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSupplierList indicates an expected call of FindSupplierList.
+func (mr *MockPurchaseMockRecorder) FindSupplierList(ts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSupplierList", reflect.TypeOf((*MockPurchase)(nil).FindSupplierList), ts)
+}
+
+// LoadPurchaseOrder mocks base method.
+func (m *MockPurchase) LoadPurchaseOrder(ts transaction.Session, orderID int) (purchase.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadPurchaseOrder", ts, orderID)
+	ret0, _ := ret[0].(purchase.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadPurchaseOrder indicates an expected call of LoadPurchaseOrder.
+func (mr *MockPurchaseMockRecorder) LoadPurchaseOrder(ts, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPurchaseOrder", reflect.TypeOf((*MockPurchase)(nil).LoadPurchaseOrder), ts, orderID)
+}
+
+// LoadSupplier mocks base method.
+func (m *MockPurchase) LoadSupplier(ts transaction.Session, supplierID int) (purchase.Supplier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadSupplier", ts, supplierID)
+	ret0, _ := ret[0].(purchase.Supplier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadSupplier indicates an expected call of LoadSupplier.
+func (mr *MockPurchaseMockRecorder) LoadSupplier(ts, supplierID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadSupplier", reflect.TypeOf((*MockPurchase)(nil).LoadSupplier), ts, supplierID)
+}
+
+// ReceivePurchaseOrderLine mocks base method.
+func (m *MockPurchase) ReceivePurchaseOrderLine(ts transaction.Session, lineID, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceivePurchaseOrderLine", ts, lineID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReceivePurchaseOrderLine indicates an expected call of ReceivePurchaseOrderLine.
+func (mr *MockPurchaseMockRecorder) ReceivePurchaseOrderLine(ts, lineID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceivePurchaseOrderLine", reflect.TypeOf((*MockPurchase)(nil).ReceivePurchaseOrderLine), ts, lineID, quantity)
+}
+
+// UpdatePurchaseOrderStatus mocks base method.
+func (m *MockPurchase) UpdatePurchaseOrderStatus(ts transaction.Session, orderID int, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePurchaseOrderStatus", ts, orderID, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePurchaseOrderStatus indicates an expected call of UpdatePurchaseOrderStatus.
+func (mr *MockPurchaseMockRecorder) UpdatePurchaseOrderStatus(ts, orderID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePurchaseOrderStatus", reflect.TypeOf((*MockPurchase)(nil).UpdatePurchaseOrderStatus), ts, orderID, status)
+}
+
+// MockValuation is a mock of Valuation interface.
+type MockValuation struct {
+	ctrl     *gomock.Controller
+	recorder *MockValuationMockRecorder
+}
+
+// MockValuationMockRecorder is the mock recorder for MockValuation.
+type MockValuationMockRecorder struct {
+	mock *MockValuation
+}
+
+// NewMockValuation creates a new mock instance.
+func NewMockValuation(ctrl *gomock.Controller) *MockValuation {
+	mock := &MockValuation{ctrl: ctrl}
+	mock.recorder = &MockValuationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockValuation) EXPECT() *MockValuationMockRecorder {
+	return m.recorder
+}
+
+// AddAverageCost mocks base method.
+func (m *MockValuation) AddAverageCost(ts transaction.Session, variantID, storageID, quantity int, unitCost float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAverageCost", ts, variantID, storageID, quantity, unitCost)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAverageCost indicates an expected call of AddAverageCost.
+func (mr *MockValuationMockRecorder) AddAverageCost(ts, variantID, storageID, quantity, unitCost interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAverageCost", reflect.TypeOf((*MockValuation)(nil).AddAverageCost), ts, variantID, storageID, quantity, unitCost)
+}
+
+// AddCostLayer mocks base method.
+func (m *MockValuation) AddCostLayer(ts transaction.Session, l valuation.CostLayer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCostLayer", ts, l)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddCostLayer indicates an expected call of AddCostLayer.
+func (mr *MockValuationMockRecorder) AddCostLayer(ts, l interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCostLayer", reflect.TypeOf((*MockValuation)(nil).AddCostLayer), ts, l)
+}
+
+// ConsumeAverageCost mocks base method.
+func (m *MockValuation) ConsumeAverageCost(ts transaction.Session, variantID, storageID, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeAverageCost", ts, variantID, storageID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeAverageCost indicates an expected call of ConsumeAverageCost.
+func (mr *MockValuationMockRecorder) ConsumeAverageCost(ts, variantID, storageID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeAverageCost", reflect.TypeOf((*MockValuation)(nil).ConsumeAverageCost), ts, variantID, storageID, quantity)
+}
+
+// ConsumeCostLayer mocks base method.
+func (m *MockValuation) ConsumeCostLayer(ts transaction.Session, layerID, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeCostLayer", ts, layerID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeCostLayer indicates an expected call of ConsumeCostLayer.
+func (mr *MockValuationMockRecorder) ConsumeCostLayer(ts, layerID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeCostLayer", reflect.TypeOf((*MockValuation)(nil).ConsumeCostLayer), ts, layerID, quantity)
+}
+
+// FindAverageValuationList mocks base method.
+func (m *MockValuation) FindAverageValuationList(ts transaction.Session, storageID int) ([]valuation.Valuation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAverageValuationList", ts, storageID)
+	ret0, _ := ret[0].([]valuation.Valuation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAverageValuationList indicates an expected call of FindAverageValuationList.
+func (mr *MockValuationMockRecorder) FindAverageValuationList(ts, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAverageValuationList", reflect.TypeOf((*MockValuation)(nil).FindAverageValuationList), ts, storageID)
+}
+
+// FindFIFOValuationList mocks base method.
+func (m *MockValuation) FindFIFOValuationList(ts transaction.Session, storageID int) ([]valuation.Valuation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindFIFOValuationList", ts, storageID)
+	ret0, _ := ret[0].([]valuation.Valuation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindFIFOValuationList indicates an expected call of FindFIFOValuationList.
+func (mr *MockValuationMockRecorder) FindFIFOValuationList(ts, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindFIFOValuationList", reflect.TypeOf((*MockValuation)(nil).FindFIFOValuationList), ts, storageID)
+}
+
+// FindMarginList mocks base method.
+func (m *MockValuation) FindMarginList(ts transaction.Session, q valuation.MarginQuery) ([]valuation.Margin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindMarginList", ts, q)
+	ret0, _ := ret[0].([]valuation.Margin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindMarginList indicates an expected call of FindMarginList.
+func (mr *MockValuationMockRecorder) FindMarginList(ts, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindMarginList", reflect.TypeOf((*MockValuation)(nil).FindMarginList), ts, q)
+}
+
+// LoadAverageCost mocks base method.
+func (m *MockValuation) LoadAverageCost(ts transaction.Session, variantID, storageID int) (valuation.AverageCost, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadAverageCost", ts, variantID, storageID)
+	ret0, _ := ret[0].(valuation.AverageCost)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadAverageCost indicates an expected call of LoadAverageCost.
+func (mr *MockValuationMockRecorder) LoadAverageCost(ts, variantID, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadAverageCost", reflect.TypeOf((*MockValuation)(nil).LoadAverageCost), ts, variantID, storageID)
+}
+
+// LoadCostLayerList mocks base method.
+func (m *MockValuation) LoadCostLayerList(ts transaction.Session, variantID, storageID int) ([]valuation.CostLayer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadCostLayerList", ts, variantID, storageID)
+	ret0, _ := ret[0].([]valuation.CostLayer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadCostLayerList indicates an expected call of LoadCostLayerList.
+func (mr *MockValuationMockRecorder) LoadCostLayerList(ts, variantID, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadCostLayerList", reflect.TypeOf((*MockValuation)(nil).LoadCostLayerList), ts, variantID, storageID)
+}
+
+// MockStocktake is a mock of Stocktake interface.
+type MockStocktake struct {
+	ctrl     *gomock.Controller
+	recorder *MockStocktakeMockRecorder
+}
+
+// MockStocktakeMockRecorder is the mock recorder for MockStocktake.
+type MockStocktakeMockRecorder struct {
+	mock *MockStocktake
+}
+
+// NewMockStocktake creates a new mock instance.
+func NewMockStocktake(ctrl *gomock.Controller) *MockStocktake {
+	mock := &MockStocktake{ctrl: ctrl}
+	mock.recorder = &MockStocktakeMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStocktake) EXPECT() *MockStocktakeMockRecorder {
+	return m.recorder
+}
+
+// AddStocktake mocks base method.
+func (m *MockStocktake) AddStocktake(ts transaction.Session, p stocktake.Params) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddStocktake", ts, p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddStocktake indicates an expected call of AddStocktake.
+func (mr *MockStocktakeMockRecorder) AddStocktake(ts, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddStocktake", reflect.TypeOf((*MockStocktake)(nil).AddStocktake), ts, p)
+}
+
+// FindStocktakeLineList mocks base method.
+func (m *MockStocktake) FindStocktakeLineList(ts transaction.Session, stocktakeID int) ([]stocktake.Line, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindStocktakeLineList", ts, stocktakeID)
+	ret0, _ := ret[0].([]stocktake.Line)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindStocktakeLineList indicates an expected call of FindStocktakeLineList.
+func (mr *MockStocktakeMockRecorder) FindStocktakeLineList(ts, stocktakeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindStocktakeLineList", reflect.TypeOf((*MockStocktake)(nil).FindStocktakeLineList), ts, stocktakeID)
+}
+
+// FindStocktakeList mocks base method.
+func (m *MockStocktake) FindStocktakeList(ts transaction.Session, storageID int, status string) ([]stocktake.Stocktake, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindStocktakeList", ts, storageID, status)
+	ret0, _ := ret[0].([]stocktake.Stocktake)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindStocktakeList indicates an expected call of FindStocktakeList.
+func (mr *MockStocktakeMockRecorder) FindStocktakeList(ts, storageID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindStocktakeList", reflect.TypeOf((*MockStocktake)(nil).FindStocktakeList), ts, storageID, status)
+}
+
+// LoadStocktake mocks base method.
+func (m *MockStocktake) LoadStocktake(ts transaction.Session, stocktakeID int) (stocktake.Stocktake, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadStocktake", ts, stocktakeID)
+	ret0, _ := ret[0].(stocktake.Stocktake)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadStocktake indicates an expected call of LoadStocktake.
+func (mr *MockStocktakeMockRecorder) LoadStocktake(ts, stocktakeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadStocktake", reflect.TypeOf((*MockStocktake)(nil).LoadStocktake), ts, stocktakeID)
+}
+
+// SaveCount mocks base method.
+func (m *MockStocktake) SaveCount(ts transaction.Session, stocktakeID int, c stocktake.CountParams, accumulate bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCount", ts, stocktakeID, c, accumulate)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCount indicates an expected call of SaveCount.
+func (mr *MockStocktakeMockRecorder) SaveCount(ts, stocktakeID, c, accumulate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCount", reflect.TypeOf((*MockStocktake)(nil).SaveCount), ts, stocktakeID, c, accumulate)
+}
+
+// SetLineAdjustment mocks base method.
+func (m *MockStocktake) SetLineAdjustment(ts transaction.Session, lineID, adjustment int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLineAdjustment", ts, lineID, adjustment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLineAdjustment indicates an expected call of SetLineAdjustment.
+func (mr *MockStocktakeMockRecorder) SetLineAdjustment(ts, lineID, adjustment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLineAdjustment", reflect.TypeOf((*MockStocktake)(nil).SetLineAdjustment), ts, lineID, adjustment)
+}
+
+// SnapshotStocktakeLines mocks base method.
+func (m *MockStocktake) SnapshotStocktakeLines(ts transaction.Session, stocktakeID, storageID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnapshotStocktakeLines", ts, stocktakeID, storageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SnapshotStocktakeLines indicates an expected call of SnapshotStocktakeLines.
+func (mr *MockStocktakeMockRecorder) SnapshotStocktakeLines(ts, stocktakeID, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotStocktakeLines", reflect.TypeOf((*MockStocktake)(nil).SnapshotStocktakeLines), ts, stocktakeID, storageID)
+}
+
+// UpdateStocktakeStatus mocks base method.
+func (m *MockStocktake) UpdateStocktakeStatus(ts transaction.Session, stocktakeID int, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStocktakeStatus", ts, stocktakeID, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStocktakeStatus indicates an expected call of UpdateStocktakeStatus.
+func (mr *MockStocktakeMockRecorder) UpdateStocktakeStatus(ts, stocktakeID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStocktakeStatus", reflect.TypeOf((*MockStocktake)(nil).UpdateStocktakeStatus), ts, stocktakeID, status)
+}
+
+// MockReservation is a mock of Reservation interface.
+type MockReservation struct {
+	ctrl     *gomock.Controller
+	recorder *MockReservationMockRecorder
+}
+
+// MockReservationMockRecorder is the mock recorder for MockReservation.
+type MockReservationMockRecorder struct {
+	mock *MockReservation
+}
+
+// NewMockReservation creates a new mock instance.
+func NewMockReservation(ctrl *gomock.Controller) *MockReservation {
+	mock := &MockReservation{ctrl: ctrl}
+	mock.recorder = &MockReservationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReservation) EXPECT() *MockReservationMockRecorder {
+	return m.recorder
+}
+
+// AddReservation mocks base method.
+func (m *MockReservation) AddReservation(ts transaction.Session, r reservation.Reservation) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReservation", ts, r)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddReservation indicates an expected call of AddReservation.
+func (mr *MockReservationMockRecorder) AddReservation(ts, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReservation", reflect.TypeOf((*MockReservation)(nil).AddReservation), ts, r)
+}
+
+// CloseReservation mocks base method.
+func (m *MockReservation) CloseReservation(ts transaction.Session, reservationID int, status string, saleID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseReservation", ts, reservationID, status, saleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseReservation indicates an expected call of CloseReservation.
+func (mr *MockReservationMockRecorder) CloseReservation(ts, reservationID, status, saleID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseReservation", reflect.TypeOf((*MockReservation)(nil).CloseReservation), ts, reservationID, status, saleID)
+}
+
+// ExpireReservationList mocks base method.
+func (m *MockReservation) ExpireReservationList(ts transaction.Session, limit int) ([]reservation.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireReservationList", ts, limit)
+	ret0, _ := ret[0].([]reservation.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireReservationList indicates an expected call of ExpireReservationList.
+func (mr *MockReservationMockRecorder) ExpireReservationList(ts, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireReservationList", reflect.TypeOf((*MockReservation)(nil).ExpireReservationList), ts, limit)
+}
+
+// FindReservationList mocks base method.
+func (m *MockReservation) FindReservationList(ts transaction.Session, variantID, storageID int, status string) ([]reservation.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindReservationList", ts, variantID, storageID, status)
+	ret0, _ := ret[0].([]reservation.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindReservationList indicates an expected call of FindReservationList.
+func (mr *MockReservationMockRecorder) FindReservationList(ts, variantID, storageID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindReservationList", reflect.TypeOf((*MockReservation)(nil).FindReservationList), ts, variantID, storageID, status)
+}
+
+// FindReservedQuantity mocks base method.
+func (m *MockReservation) FindReservedQuantity(ts transaction.Session, variantID, storageID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindReservedQuantity", ts, variantID, storageID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindReservedQuantity indicates an expected call of FindReservedQuantity.
+func (mr *MockReservationMockRecorder) FindReservedQuantity(ts, variantID, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindReservedQuantity", reflect.TypeOf((*MockReservation)(nil).FindReservedQuantity), ts, variantID, storageID)
+}
+
+// LoadReservation mocks base method.
+func (m *MockReservation) LoadReservation(ts transaction.Session, reservationID int) (reservation.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadReservation", ts, reservationID)
+	ret0, _ := ret[0].(reservation.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadReservation indicates an expected call of LoadReservation.
+func (mr *MockReservationMockRecorder) LoadReservation(ts, reservationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadReservation", reflect.TypeOf((*MockReservation)(nil).LoadReservation), ts, reservationID)
+}
+
+// LoadStockQuantity mocks base method.
+func (m *MockReservation) LoadStockQuantity(ts transaction.Session, variantID, storageID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadStockQuantity", ts, variantID, storageID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadStockQuantity indicates an expected call of LoadStockQuantity.
+func (mr *MockReservationMockRecorder) LoadStockQuantity(ts, variantID, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadStockQuantity", reflect.TypeOf((*MockReservation)(nil).LoadStockQuantity), ts, variantID, storageID)
+}
+
+// MockLocation is a mock of Location interface.
+type MockLocation struct {
+	ctrl     *gomock.Controller
+	recorder *MockLocationMockRecorder
+}
+
+// MockLocationMockRecorder is the mock recorder for MockLocation.
+type MockLocationMockRecorder struct {
+	mock *MockLocation
+}
+
+// NewMockLocation creates a new mock instance.
+func NewMockLocation(ctrl *gomock.Controller) *MockLocation {
+	mock := &MockLocation{ctrl: ctrl}
+	mock.recorder = &MockLocationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLocation) EXPECT() *MockLocationMockRecorder {
+	return m.recorder
+}
+
+// AddLocation mocks base method.
+func (m *MockLocation) AddLocation(ts transaction.Session, l location.Location) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddLocation", ts, l)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddLocation indicates an expected call of AddLocation.
+func (mr *MockLocationMockRecorder) AddLocation(ts, l interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLocation", reflect.TypeOf((*MockLocation)(nil).AddLocation), ts, l)
+}
+
+// FindLocationList mocks base method.
+func (m *MockLocation) FindLocationList(ts transaction.Session, storageID int) ([]location.Location, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLocationList", ts, storageID)
+	ret0, _ := ret[0].([]location.Location)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLocationList indicates an expected call of FindLocationList.
+func (mr *MockLocationMockRecorder) FindLocationList(ts, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLocationList", reflect.TypeOf((*MockLocation)(nil).FindLocationList), ts, storageID)
+}
+
+// FindLocationStockList mocks base method.
+func (m *MockLocation) FindLocationStockList(ts transaction.Session, storageID int) ([]location.Stock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLocationStockList", ts, storageID)
+	ret0, _ := ret[0].([]location.Stock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLocationStockList indicates an expected call of FindLocationStockList.
+func (mr *MockLocationMockRecorder) FindLocationStockList(ts, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLocationStockList", reflect.TypeOf((*MockLocation)(nil).FindLocationStockList), ts, storageID)
+}
+
+// LoadLocation mocks base method.
+func (m *MockLocation) LoadLocation(ts transaction.Session, locationID int) (location.Location, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadLocation", ts, locationID)
+	ret0, _ := ret[0].(location.Location)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadLocation indicates an expected call of LoadLocation.
+func (mr *MockLocationMockRecorder) LoadLocation(ts, locationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadLocation", reflect.TypeOf((*MockLocation)(nil).LoadLocation), ts, locationID)
+}
+
+// LoadUnplacedQuantity mocks base method.
+func (m *MockLocation) LoadUnplacedQuantity(ts transaction.Session, variantID, storageID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadUnplacedQuantity", ts, variantID, storageID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadUnplacedQuantity indicates an expected call of LoadUnplacedQuantity.
+func (mr *MockLocationMockRecorder) LoadUnplacedQuantity(ts, variantID, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadUnplacedQuantity", reflect.TypeOf((*MockLocation)(nil).LoadUnplacedQuantity), ts, variantID, storageID)
+}
+
+// PutLocationStock mocks base method.
+func (m *MockLocation) PutLocationStock(ts transaction.Session, locationID, variantID, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutLocationStock", ts, locationID, variantID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutLocationStock indicates an expected call of PutLocationStock.
+func (mr *MockLocationMockRecorder) PutLocationStock(ts, locationID, variantID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutLocationStock", reflect.TypeOf((*MockLocation)(nil).PutLocationStock), ts, locationID, variantID, quantity)
+}
+
+// TakeLocationStock mocks base method.
+func (m *MockLocation) TakeLocationStock(ts transaction.Session, locationID, variantID, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeLocationStock", ts, locationID, variantID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TakeLocationStock indicates an expected call of TakeLocationStock.
+func (mr *MockLocationMockRecorder) TakeLocationStock(ts, locationID, variantID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeLocationStock", reflect.TypeOf((*MockLocation)(nil).TakeLocationStock), ts, locationID, variantID, quantity)
+}
+
+// TrimLocationStock mocks base method.
+func (m *MockLocation) TrimLocationStock(ts transaction.Session, variantID, storageID, total int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrimLocationStock", ts, variantID, storageID, total)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TrimLocationStock indicates an expected call of TrimLocationStock.
+func (mr *MockLocationMockRecorder) TrimLocationStock(ts, variantID, storageID, total interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrimLocationStock", reflect.TypeOf((*MockLocation)(nil).TrimLocationStock), ts, variantID, storageID, total)
+}
+
+// MockUnit is a mock of Unit interface.
+type MockUnit struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnitMockRecorder
+}
+
+// MockUnitMockRecorder is the mock recorder for MockUnit.
+type MockUnitMockRecorder struct {
+	mock *MockUnit
+}
+
+// NewMockUnit creates a new mock instance.
+func NewMockUnit(ctrl *gomock.Controller) *MockUnit {
+	mock := &MockUnit{ctrl: ctrl}
+	mock.recorder = &MockUnitMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUnit) EXPECT() *MockUnitMockRecorder {
+	return m.recorder
+}
+
+// AddUnit mocks base method.
+func (m *MockUnit) AddUnit(ts transaction.Session, u unit.Unit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUnit", ts, u)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddUnit indicates an expected call of AddUnit.
+func (mr *MockUnitMockRecorder) AddUnit(ts, u interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUnit", reflect.TypeOf((*MockUnit)(nil).AddUnit), ts, u)
+}
+
+// FindUnitList mocks base method.
+func (m *MockUnit) FindUnitList(ts transaction.Session) ([]unit.Unit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUnitList", ts)
+	ret0, _ := ret[0].([]unit.Unit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUnitList indicates an expected call of FindUnitList.
+func (mr *MockUnitMockRecorder) FindUnitList(ts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUnitList", reflect.TypeOf((*MockUnit)(nil).FindUnitList), ts)
+}
+
+// LoadUnit mocks base method.
+func (m *MockUnit) LoadUnit(ts transaction.Session, code string) (unit.Unit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadUnit", ts, code)
+	ret0, _ := ret[0].(unit.Unit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadUnit indicates an expected call of LoadUnit.
+func (mr *MockUnitMockRecorder) LoadUnit(ts, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadUnit", reflect.TypeOf((*MockUnit)(nil).LoadUnit), ts, code)
+}
+
+// MockImage is a mock of Image interface.
+type MockImage struct {
+	ctrl     *gomock.Controller
+	recorder *MockImageMockRecorder
+}
+
+// MockImageMockRecorder is the mock recorder for MockImage.
+type MockImageMockRecorder struct {
+	mock *MockImage
+}
+
+// NewMockImage creates a new mock instance.
+func NewMockImage(ctrl *gomock.Controller) *MockImage {
+	mock := &MockImage{ctrl: ctrl}
+	mock.recorder = &MockImageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImage) EXPECT() *MockImageMockRecorder {
+	return m.recorder
+}
+
+// AddImage mocks base method.
+func (m *MockImage) AddImage(ts transaction.Session, img image.Image) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddImage", ts, img)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddImage indicates an expected call of AddImage.
+func (mr *MockImageMockRecorder) AddImage(ts, img interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddImage", reflect.TypeOf((*MockImage)(nil).AddImage), ts, img)
+}
+
+// DeleteImage mocks base method.
+func (m *MockImage) DeleteImage(ts transaction.Session, imageID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteImage", ts, imageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteImage indicates an expected call of DeleteImage.
+func (mr *MockImageMockRecorder) DeleteImage(ts, imageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteImage", reflect.TypeOf((*MockImage)(nil).DeleteImage), ts, imageID)
+}
+
+// FindImageList mocks base method.
+func (m *MockImage) FindImageList(ts transaction.Session, productID int) ([]image.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindImageList", ts, productID)
+	ret0, _ := ret[0].([]image.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindImageList indicates an expected call of FindImageList.
+func (mr *MockImageMockRecorder) FindImageList(ts, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindImageList", reflect.TypeOf((*MockImage)(nil).FindImageList), ts, productID)
+}
+
+// FindImageListByProductIDList mocks base method.
+func (m *MockImage) FindImageListByProductIDList(ts transaction.Session, productIDList []int) ([]image.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindImageListByProductIDList", ts, productIDList)
+	ret0, _ := ret[0].([]image.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindImageListByProductIDList indicates an expected call of FindImageListByProductIDList.
+func (mr *MockImageMockRecorder) FindImageListByProductIDList(ts, productIDList interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindImageListByProductIDList", reflect.TypeOf((*MockImage)(nil).FindImageListByProductIDList), ts, productIDList)
+}
+
+// LoadImage mocks base method.
+func (m *MockImage) LoadImage(ts transaction.Session, imageID int) (image.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadImage", ts, imageID)
+	ret0, _ := ret[0].(image.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadImage indicates an expected call of LoadImage.
+func (mr *MockImageMockRecorder) LoadImage(ts, imageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadImage", reflect.TypeOf((*MockImage)(nil).LoadImage), ts, imageID)
+}
+
+// LockProductImages mocks base method.
+func (m *MockImage) LockProductImages(ts transaction.Session, productID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockProductImages", ts, productID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockProductImages indicates an expected call of LockProductImages.
+func (mr *MockImageMockRecorder) LockProductImages(ts, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockProductImages", reflect.TypeOf((*MockImage)(nil).LockProductImages), ts, productID)
+}
+
+// NextImagePosition mocks base method.
+func (m *MockImage) NextImagePosition(ts transaction.Session, productID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NextImagePosition", ts, productID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NextImagePosition indicates an expected call of NextImagePosition.
+func (mr *MockImageMockRecorder) NextImagePosition(ts, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextImagePosition", reflect.TypeOf((*MockImage)(nil).NextImagePosition), ts, productID)
+}
+
+// PromoteFirstImage mocks base method.
+func (m *MockImage) PromoteFirstImage(ts transaction.Session, productID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PromoteFirstImage", ts, productID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PromoteFirstImage indicates an expected call of PromoteFirstImage.
+func (mr *MockImageMockRecorder) PromoteFirstImage(ts, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromoteFirstImage", reflect.TypeOf((*MockImage)(nil).PromoteFirstImage), ts, productID)
+}
+
+// SetImagePosition mocks base method.
+func (m *MockImage) SetImagePosition(ts transaction.Session, imageID, position int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetImagePosition", ts, imageID, position)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetImagePosition indicates an expected call of SetImagePosition.
+func (mr *MockImageMockRecorder) SetImagePosition(ts, imageID, position interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetImagePosition", reflect.TypeOf((*MockImage)(nil).SetImagePosition), ts, imageID, position)
+}
+
+// SetPrimaryImage mocks base method.
+func (m *MockImage) SetPrimaryImage(ts transaction.Session, productID, imageID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPrimaryImage", ts, productID, imageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPrimaryImage indicates an expected call of SetPrimaryImage.
+func (mr *MockImageMockRecorder) SetPrimaryImage(ts, productID, imageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPrimaryImage", reflect.TypeOf((*MockImage)(nil).SetPrimaryImage), ts, productID, imageID)
+}
+
+// MockAttribute is a mock of Attribute interface.
+type MockAttribute struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttributeMockRecorder
+}
+
+// MockAttributeMockRecorder is the mock recorder for MockAttribute.
+type MockAttributeMockRecorder struct {
+	mock *MockAttribute
+}
+
+// NewMockAttribute creates a new mock instance.
+func NewMockAttribute(ctrl *gomock.Controller) *MockAttribute {
+	mock := &MockAttribute{ctrl: ctrl}
+	mock.recorder = &MockAttributeMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttribute) EXPECT() *MockAttributeMockRecorder {
+	return m.recorder
+}
+
+// AddCategory mocks base method.
+func (m *MockAttribute) AddCategory(ts transaction.Session, name string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCategory", ts, name)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCategory indicates an expected call of AddCategory.
+func (mr *MockAttributeMockRecorder) AddCategory(ts, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCategory", reflect.TypeOf((*MockAttribute)(nil).AddCategory), ts, name)
+}
+
+// AddDefinition mocks base method.
+func (m *MockAttribute) AddDefinition(ts transaction.Session, d attribute.Definition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddDefinition", ts, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddDefinition indicates an expected call of AddDefinition.
+func (mr *MockAttributeMockRecorder) AddDefinition(ts, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDefinition", reflect.TypeOf((*MockAttribute)(nil).AddDefinition), ts, d)
+}
+
+// CountCategoryProducts mocks base method.
+func (m *MockAttribute) CountCategoryProducts(ts transaction.Session, categoryID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCategoryProducts", ts, categoryID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountCategoryProducts indicates an expected call of CountCategoryProducts.
+func (mr *MockAttributeMockRecorder) CountCategoryProducts(ts, categoryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCategoryProducts", reflect.TypeOf((*MockAttribute)(nil).CountCategoryProducts), ts, categoryID)
+}
+
+// FindCategoryIDByName mocks base method.
+func (m *MockAttribute) FindCategoryIDByName(ts transaction.Session, name string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCategoryIDByName", ts, name)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCategoryIDByName indicates an expected call of FindCategoryIDByName.
+func (mr *MockAttributeMockRecorder) FindCategoryIDByName(ts, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCategoryIDByName", reflect.TypeOf((*MockAttribute)(nil).FindCategoryIDByName), ts, name)
+}
+
+// FindCategoryList mocks base method.
+func (m *MockAttribute) FindCategoryList(ts transaction.Session) ([]attribute.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCategoryList", ts)
+	ret0, _ := ret[0].([]attribute.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCategoryList indicates an expected call of FindCategoryList.
+func (mr *MockAttributeMockRecorder) FindCategoryList(ts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCategoryList", reflect.TypeOf((*MockAttribute)(nil).FindCategoryList), ts)
+}
+
+// FindDefinitionList mocks base method.
+func (m *MockAttribute) FindDefinitionList(ts transaction.Session, categoryID int) ([]attribute.Definition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDefinitionList", ts, categoryID)
+	ret0, _ := ret[0].([]attribute.Definition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDefinitionList indicates an expected call of FindDefinitionList.
+func (mr *MockAttributeMockRecorder) FindDefinitionList(ts, categoryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDefinitionList", reflect.TypeOf((*MockAttribute)(nil).FindDefinitionList), ts, categoryID)
+}
+
+// LoadCategory mocks base method.
+func (m *MockAttribute) LoadCategory(ts transaction.Session, categoryID int) (attribute.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadCategory", ts, categoryID)
+	ret0, _ := ret[0].(attribute.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadCategory indicates an expected call of LoadCategory.
+func (mr *MockAttributeMockRecorder) LoadCategory(ts, categoryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadCategory", reflect.TypeOf((*MockAttribute)(nil).LoadCategory), ts, categoryID)
+}
+
+// MockPromotion is a mock of Promotion interface.
+type MockPromotion struct {
+	ctrl     *gomock.Controller
+	recorder *MockPromotionMockRecorder
+}
+
+// MockPromotionMockRecorder is the mock recorder for MockPromotion.
+type MockPromotionMockRecorder struct {
+	mock *MockPromotion
+}
+
+// NewMockPromotion creates a new mock instance.
+func NewMockPromotion(ctrl *gomock.Controller) *MockPromotion {
+	mock := &MockPromotion{ctrl: ctrl}
+	mock.recorder = &MockPromotionMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPromotion) EXPECT() *MockPromotionMockRecorder {
+	return m.recorder
+}
+
+// AddPromotion mocks base method.
+func (m *MockPromotion) AddPromotion(ts transaction.Session, p promotion.Promotion) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPromotion", ts, p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPromotion indicates an expected call of AddPromotion.
+func (mr *MockPromotionMockRecorder) AddPromotion(ts, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPromotion", reflect.TypeOf((*MockPromotion)(nil).AddPromotion), ts, p)
+}
+
+// EndPromotion mocks base method.
+func (m *MockPromotion) EndPromotion(ts transaction.Session, promotionID int, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndPromotion", ts, promotionID, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EndPromotion indicates an expected call of EndPromotion.
+func (mr *MockPromotionMockRecorder) EndPromotion(ts, promotionID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndPromotion", reflect.TypeOf((*MockPromotion)(nil).EndPromotion), ts, promotionID, at)
+}
+
+// FindApplicablePromotionList mocks base method.
+func (m *MockPromotion) FindApplicablePromotionList(ts transaction.Session, variantID, storageID int, at time.Time) ([]promotion.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindApplicablePromotionList", ts, variantID, storageID, at)
+	ret0, _ := ret[0].([]promotion.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindApplicablePromotionList indicates an expected call of FindApplicablePromotionList.
+func (mr *MockPromotionMockRecorder) FindApplicablePromotionList(ts, variantID, storageID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindApplicablePromotionList", reflect.TypeOf((*MockPromotion)(nil).FindApplicablePromotionList), ts, variantID, storageID, at)
+}
+
+// FindPromotionList mocks base method.
+func (m *MockPromotion) FindPromotionList(ts transaction.Session, activeAt time.Time) ([]promotion.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPromotionList", ts, activeAt)
+	ret0, _ := ret[0].([]promotion.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPromotionList indicates an expected call of FindPromotionList.
+func (mr *MockPromotionMockRecorder) FindPromotionList(ts, activeAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPromotionList", reflect.TypeOf((*MockPromotion)(nil).FindPromotionList), ts, activeAt)
+}
+
+// MockCustomer is a mock of Customer interface.
+type MockCustomer struct {
+	ctrl     *gomock.Controller
+	recorder *MockCustomerMockRecorder
+}
+
+// MockCustomerMockRecorder is the mock recorder for MockCustomer.
+type MockCustomerMockRecorder struct {
+	mock *MockCustomer
+}
+
+// NewMockCustomer creates a new mock instance.
+func NewMockCustomer(ctrl *gomock.Controller) *MockCustomer {
+	mock := &MockCustomer{ctrl: ctrl}
+	mock.recorder = &MockCustomerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCustomer) EXPECT() *MockCustomerMockRecorder {
+	return m.recorder
+}
+
+// AddCustomer mocks base method.
+func (m *MockCustomer) AddCustomer(ts transaction.Session, p customer.Params) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCustomer", ts, p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCustomer indicates an expected call of AddCustomer.
+func (mr *MockCustomerMockRecorder) AddCustomer(ts, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCustomer", reflect.TypeOf((*MockCustomer)(nil).AddCustomer), ts, p)
+}
+
+// FindCustomerIDByPhone mocks base method.
+func (m *MockCustomer) FindCustomerIDByPhone(ts transaction.Session, phone string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCustomerIDByPhone", ts, phone)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCustomerIDByPhone indicates an expected call of FindCustomerIDByPhone.
+func (mr *MockCustomerMockRecorder) FindCustomerIDByPhone(ts, phone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCustomerIDByPhone", reflect.TypeOf((*MockCustomer)(nil).FindCustomerIDByPhone), ts, phone)
+}
+
+// FindCustomerList mocks base method.
+func (m *MockCustomer) FindCustomerList(ts transaction.Session, search, customerType string, limit int) ([]customer.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCustomerList", ts, search, customerType, limit)
+	ret0, _ := ret[0].([]customer.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCustomerList indicates an expected call of FindCustomerList.
+func (mr *MockCustomerMockRecorder) FindCustomerList(ts, search, customerType, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCustomerList", reflect.TypeOf((*MockCustomer)(nil).FindCustomerList), ts, search, customerType, limit)
+}
+
+// FindCustomerSaleList mocks base method.
+func (m *MockCustomer) FindCustomerSaleList(ts transaction.Session, customerID, limit int) ([]product.Sale, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCustomerSaleList", ts, customerID, limit)
+	ret0, _ := ret[0].([]product.Sale)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCustomerSaleList indicates an expected call of FindCustomerSaleList.
+func (mr *MockCustomerMockRecorder) FindCustomerSaleList(ts, customerID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCustomerSaleList", reflect.TypeOf((*MockCustomer)(nil).FindCustomerSaleList), ts, customerID, limit)
+}
+
+// FindGroupPrice mocks base method.
+func (m *MockCustomer) FindGroupPrice(ts transaction.Session, variantID int, customerType string, at time.Time) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindGroupPrice", ts, variantID, customerType, at)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindGroupPrice indicates an expected call of FindGroupPrice.
+func (mr *MockCustomerMockRecorder) FindGroupPrice(ts, variantID, customerType, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindGroupPrice", reflect.TypeOf((*MockCustomer)(nil).FindGroupPrice), ts, variantID, customerType, at)
+}
+
+// FindGroupPriceList mocks base method.
+func (m *MockCustomer) FindGroupPriceList(ts transaction.Session, customerType string) ([]customer.GroupPrice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindGroupPriceList", ts, customerType)
+	ret0, _ := ret[0].([]customer.GroupPrice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindGroupPriceList indicates an expected call of FindGroupPriceList.
+func (mr *MockCustomerMockRecorder) FindGroupPriceList(ts, customerType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindGroupPriceList", reflect.TypeOf((*MockCustomer)(nil).FindGroupPriceList), ts, customerType)
+}
+
+// LoadCustomer mocks base method.
+func (m *MockCustomer) LoadCustomer(ts transaction.Session, customerID int) (customer.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadCustomer", ts, customerID)
+	ret0, _ := ret[0].(customer.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadCustomer indicates an expected call of LoadCustomer.
+func (mr *MockCustomerMockRecorder) LoadCustomer(ts, customerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadCustomer", reflect.TypeOf((*MockCustomer)(nil).LoadCustomer), ts, customerID)
+}
+
+// LoadCustomerSaleTotal mocks base method.
+func (m *MockCustomer) LoadCustomerSaleTotal(ts transaction.Session, customerID int) (customer.SaleTotal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadCustomerSaleTotal", ts, customerID)
+	ret0, _ := ret[0].(customer.SaleTotal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadCustomerSaleTotal indicates an expected call of LoadCustomerSaleTotal.
+func (mr *MockCustomerMockRecorder) LoadCustomerSaleTotal(ts, customerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadCustomerSaleTotal", reflect.TypeOf((*MockCustomer)(nil).LoadCustomerSaleTotal), ts, customerID)
+}
+
+// RemoveCustomer mocks base method.
+func (m *MockCustomer) RemoveCustomer(ts transaction.Session, customerID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveCustomer", ts, customerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveCustomer indicates an expected call of RemoveCustomer.
+func (mr *MockCustomerMockRecorder) RemoveCustomer(ts, customerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCustomer", reflect.TypeOf((*MockCustomer)(nil).RemoveCustomer), ts, customerID)
+}
+
+// SetGroupPrice mocks base method.
+func (m *MockCustomer) SetGroupPrice(ts transaction.Session, p customer.GroupPriceParams, at time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetGroupPrice", ts, p, at)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetGroupPrice indicates an expected call of SetGroupPrice.
+func (mr *MockCustomerMockRecorder) SetGroupPrice(ts, p, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetGroupPrice", reflect.TypeOf((*MockCustomer)(nil).SetGroupPrice), ts, p, at)
+}
+
+// UpdateCustomer mocks base method.
+func (m *MockCustomer) UpdateCustomer(ts transaction.Session, customerID int, p customer.Params) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCustomer", ts, customerID, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCustomer indicates an expected call of UpdateCustomer.
+func (mr *MockCustomerMockRecorder) UpdateCustomer(ts, customerID, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCustomer", reflect.TypeOf((*MockCustomer)(nil).UpdateCustomer), ts, customerID, p)
+}
+
+// MockReprice is a mock of Reprice interface.
+type MockReprice struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepriceMockRecorder
+}
+
+// MockRepriceMockRecorder is the mock recorder for MockReprice.
+type MockRepriceMockRecorder struct {
+	mock *MockReprice
+}
+
+// NewMockReprice creates a new mock instance.
+func NewMockReprice(ctrl *gomock.Controller) *MockReprice {
+	mock := &MockReprice{ctrl: ctrl}
+	mock.recorder = &MockRepriceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReprice) EXPECT() *MockRepriceMockRecorder {
+	return m.recorder
+}
+
+// FindLineList mocks base method.
+func (m *MockReprice) FindLineList(ts transaction.Session, p reprice.Params, at time.Time) ([]reprice.Line, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLineList", ts, p, at)
+	ret0, _ := ret[0].([]reprice.Line)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLineList indicates an expected call of FindLineList.
+func (mr *MockRepriceMockRecorder) FindLineList(ts, p, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLineList", reflect.TypeOf((*MockReprice)(nil).FindLineList), ts, p, at)
+}
+
+// ReplacePrice mocks base method.
+func (m *MockReprice) ReplacePrice(ts transaction.Session, variantID int, price float64, at time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplacePrice", ts, variantID, price, at)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplacePrice indicates an expected call of ReplacePrice.
+func (mr *MockRepriceMockRecorder) ReplacePrice(ts, variantID, price, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplacePrice", reflect.TypeOf((*MockReprice)(nil).ReplacePrice), ts, variantID, price, at)
+}
+
+// MockPayment is a mock of Payment interface.
+type MockPayment struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentMockRecorder
+}
+
+// MockPaymentMockRecorder is the mock recorder for MockPayment.
+type MockPaymentMockRecorder struct {
+	mock *MockPayment
+}
+
+// NewMockPayment creates a new mock instance.
+func NewMockPayment(ctrl *gomock.Controller) *MockPayment {
+	mock := &MockPayment{ctrl: ctrl}
+	mock.recorder = &MockPaymentMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPayment) EXPECT() *MockPaymentMockRecorder {
+	return m.recorder
+}
+
+// AddPayment mocks base method.
+func (m *MockPayment) AddPayment(ts transaction.Session, saleID int, p payment.Params) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPayment", ts, saleID, p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPayment indicates an expected call of AddPayment.
+func (mr *MockPaymentMockRecorder) AddPayment(ts, saleID, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPayment", reflect.TypeOf((*MockPayment)(nil).AddPayment), ts, saleID, p)
+}
+
+// FindPaymentList mocks base method.
+func (m *MockPayment) FindPaymentList(ts transaction.Session, saleID int) ([]payment.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPaymentList", ts, saleID)
+	ret0, _ := ret[0].([]payment.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPaymentList indicates an expected call of FindPaymentList.
+func (mr *MockPaymentMockRecorder) FindPaymentList(ts, saleID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPaymentList", reflect.TypeOf((*MockPayment)(nil).FindPaymentList), ts, saleID)
+}
+
+// FindZMethodTotalList mocks base method.
+func (m *MockPayment) FindZMethodTotalList(ts transaction.Session, start, end time.Time, storageID int) ([]payment.ZMethodTotal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindZMethodTotalList", ts, start, end, storageID)
+	ret0, _ := ret[0].([]payment.ZMethodTotal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindZMethodTotalList indicates an expected call of FindZMethodTotalList.
+func (mr *MockPaymentMockRecorder) FindZMethodTotalList(ts, start, end, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindZMethodTotalList", reflect.TypeOf((*MockPayment)(nil).FindZMethodTotalList), ts, start, end, storageID)
+}
+
+// FindZStorageTotalList mocks base method.
+func (m *MockPayment) FindZStorageTotalList(ts transaction.Session, start, end time.Time, storageID int) ([]payment.ZStorageTotal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindZStorageTotalList", ts, start, end, storageID)
+	ret0, _ := ret[0].([]payment.ZStorageTotal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindZStorageTotalList indicates an expected call of FindZStorageTotalList.
+func (mr *MockPaymentMockRecorder) FindZStorageTotalList(ts, start, end, storageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindZStorageTotalList", reflect.TypeOf((*MockPayment)(nil).FindZStorageTotalList), ts, start, end, storageID)
+}
@@ -0,0 +1,110 @@
+package postgresql
+
+import (
+	"product_storage/internal/entity/attribute"
+	"product_storage/internal/repository"
+	"product_storage/internal/transaction"
+	"product_storage/tools/gensql"
+	"product_storage/tools/jsonb"
+)
+
+type attributeRepository struct{}
+
+func NewAttribute() repository.Attribute {
+	return &attributeRepository{}
+}
+
+// AddCategory создание категории продуктов
+func (r *attributeRepository) AddCategory(ts transaction.Session, name string) (categoryID int, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into categories
+	( name )
+	values ( $1 )
+	returning category_id`,
+		name).Scan(&categoryID)
+
+	return categoryID, err
+}
+
+// LoadCategory категория продуктов без описаний атрибутов
+func (r *attributeRepository) LoadCategory(ts transaction.Session, categoryID int) (attribute.Category, error) {
+	query := `
+	select category_id, name, created_at
+	from categories
+	where category_id = $1`
+
+	return gensql.Get[attribute.Category](ts.Context(), SqlxTx(ts), query, categoryID)
+}
+
+// FindCategoryIDByName id категории по названию
+func (r *attributeRepository) FindCategoryIDByName(ts transaction.Session, name string) (int, error) {
+	query := `
+	select category_id
+	from categories
+	where name = $1`
+
+	return gensql.Get[int](ts.Context(), SqlxTx(ts), query, name)
+}
+
+// FindCategoryList категории продуктов в порядке названий
+func (r *attributeRepository) FindCategoryList(ts transaction.Session) ([]attribute.Category, error) {
+	query := `
+	select category_id, name, created_at
+	from categories
+	order by name`
+
+	return gensql.Select[attribute.Category](ts.Context(), SqlxTx(ts), query)
+}
+
+// AddDefinition добавление описания атрибута в категорию
+func (r *attributeRepository) AddDefinition(ts transaction.Session, d attribute.Definition) error {
+	options := d.Options
+	if options == nil {
+		options = []string{}
+	}
+
+	rawOptions, err := jsonb.Marshal(options)
+	if err != nil {
+		return err
+	}
+
+	_, err = SqlxTx(ts).ExecContext(ts.Context(), `
+	insert into attribute_definitions
+	( category_id, code, name, value_type, required, options )
+	values ( $1, $2, $3, $4, $5, $6 )`,
+		d.CategoryID, d.Code, d.Name, d.Type, d.Required, rawOptions)
+
+	return err
+}
+
+// FindDefinitionList описания атрибутов категории, нулевой categoryID выбирает атрибуты всех категорий
+func (r *attributeRepository) FindDefinitionList(ts transaction.Session, categoryID int) ([]attribute.Definition, error) {
+	query := `
+	select category_id, code, name, value_type, required, options
+	from attribute_definitions
+	where $1 = 0 or category_id = $1
+	order by category_id, code`
+
+	definitionList, err := gensql.Select[attribute.Definition](ts.Context(), SqlxTx(ts), query, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range definitionList {
+		if definitionList[i].Options, err = jsonb.Unmarshal[[]string](definitionList[i].RawOptions); err != nil {
+			return nil, err
+		}
+	}
+
+	return definitionList, nil
+}
+
+// CountCategoryProducts кол-во продуктов в категории
+func (r *attributeRepository) CountCategoryProducts(ts transaction.Session, categoryID int) (int, error) {
+	query := `
+	select count(*)
+	from products
+	where category_id = $1`
+
+	return gensql.Get[int](ts.Context(), SqlxTx(ts), query, categoryID)
+}
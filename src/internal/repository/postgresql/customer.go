@@ -0,0 +1,159 @@
+package postgresql
+
+import (
+	"product_storage/internal/entity/customer"
+	"product_storage/internal/entity/product"
+	"product_storage/internal/repository"
+	"product_storage/internal/transaction"
+	"product_storage/tools/gensql"
+	"time"
+)
+
+type customerRepository struct{}
+
+func NewCustomer() repository.Customer {
+	return &customerRepository{}
+}
+
+// AddCustomer добавление покупателя
+func (r *customerRepository) AddCustomer(ts transaction.Session, p customer.Params) (customerID int, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into customers
+	( name, phone, customer_type )
+	values ( $1, $2, $3 )
+	returning customer_id`,
+		p.Name, p.Phone, p.Type).Scan(&customerID)
+
+	return customerID, err
+}
+
+// LoadCustomer неудаленный покупатель
+func (r *customerRepository) LoadCustomer(ts transaction.Session, customerID int) (customer.Customer, error) {
+	query := `
+	select customer_id, name, phone, customer_type, added_at, removed_at
+	from customers
+	where customer_id = $1 and removed_at is null`
+
+	return gensql.Get[customer.Customer](ts.Context(), SqlxTx(ts), query, customerID)
+}
+
+// FindCustomerIDByPhone id неудаленного покупателя по телефону
+func (r *customerRepository) FindCustomerIDByPhone(ts transaction.Session, phone string) (int, error) {
+	query := `
+	select customer_id
+	from customers
+	where phone = $1 and removed_at is null`
+
+	return gensql.Get[int](ts.Context(), SqlxTx(ts), query, phone)
+}
+
+// FindCustomerList поиск неудаленных покупателей по части имени или телефона,
+// пустые search и customerType не ограничивают выборку
+func (r *customerRepository) FindCustomerList(ts transaction.Session, search, customerType string, limit int) ([]customer.Customer, error) {
+	query := `
+	select customer_id, name, phone, customer_type, added_at, removed_at
+	from customers
+	where removed_at is null
+	and ( $1 = '' or name ilike '%' || $1 || '%' or ( $2 <> '' and phone like '%' || $2 || '%' ) )
+	and ( $3 = '' or customer_type = $3 )
+	order by name, customer_id
+	limit $4`
+
+	return gensql.Select[customer.Customer](ts.Context(), SqlxTx(ts), query,
+		search, customer.NormalizePhone(search), customerType, limit)
+}
+
+// UpdateCustomer изменение неудаленного покупателя
+func (r *customerRepository) UpdateCustomer(ts transaction.Session, customerID int, p customer.Params) error {
+	query := `
+	update customers
+	set name = $2, phone = $3, customer_type = $4
+	where customer_id = $1 and removed_at is null
+	returning customer_id`
+
+	_, err := gensql.Get[int](ts.Context(), SqlxTx(ts), query, customerID, p.Name, p.Phone, p.Type)
+	return err
+}
+
+// RemoveCustomer удаление покупателя с сохранением истории покупок: покупатель помечается удаленным
+func (r *customerRepository) RemoveCustomer(ts transaction.Session, customerID int) error {
+	query := `
+	update customers
+	set removed_at = now()
+	where customer_id = $1 and removed_at is null
+	returning customer_id`
+
+	_, err := gensql.Get[int](ts.Context(), SqlxTx(ts), query, customerID)
+	return err
+}
+
+// SetGroupPrice новая цена варианта для группы покупателей с момента at, действующая цена закрывается
+func (r *customerRepository) SetGroupPrice(ts transaction.Session, p customer.GroupPriceParams, at time.Time) (priceID int, err error) {
+	_, err = SqlxTx(ts).ExecContext(ts.Context(), `
+	update customer_group_prices
+	set end_date = $3
+	where variant_id = $1 and customer_type = $2 and end_date is null`,
+		p.VariantID, p.CustomerType, at)
+	if err != nil {
+		return 0, err
+	}
+
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into customer_group_prices
+	( variant_id, customer_type, price, start_date )
+	values ( $1, $2, $3, $4 )
+	returning price_id`,
+		p.VariantID, p.CustomerType, p.Price, at).Scan(&priceID)
+
+	return priceID, err
+}
+
+// FindGroupPrice цена варианта для группы покупателей, действующая в момент at
+func (r *customerRepository) FindGroupPrice(ts transaction.Session, variantID int, customerType string, at time.Time) (float64, error) {
+	query := `
+	select price
+	from customer_group_prices
+	where variant_id = $1 and customer_type = $2
+	and start_date <= $3 and ( end_date is null or end_date > $3 )
+	order by start_date desc
+	limit 1`
+
+	return gensql.Get[float64](ts.Context(), SqlxTx(ts), query, variantID, customerType, at)
+}
+
+// FindGroupPriceList действующие цены группы покупателей, пустой customerType выбирает все группы
+func (r *customerRepository) FindGroupPriceList(ts transaction.Session, customerType string) ([]customer.GroupPrice, error) {
+	query := `
+	select price_id, variant_id, customer_type, price, start_date, end_date
+	from customer_group_prices
+	where end_date is null
+	and ( $1 = '' or customer_type = $1 )
+	order by customer_type, variant_id`
+
+	return gensql.Select[customer.GroupPrice](ts.Context(), SqlxTx(ts), query, customerType)
+}
+
+// FindCustomerSaleList последние продажи покупателя
+func (r *customerRepository) FindCustomerSaleList(ts transaction.Session, customerID, limit int) ([]product.Sale, error) {
+	query := `
+	select s.sales_id, s.variant_id, s.storage_id, s.sold_at, s.quantity, s.total_price, s.cost_of_goods,
+		s.promotion_id, s.discount, s.customer_id, p.name
+	from sales s
+	join product_variants pv on pv.variant_id = s.variant_id
+	join products p on p.product_id = pv.product_id
+	where s.customer_id = $1
+	order by s.sold_at desc, s.sales_id desc
+	limit $2`
+
+	return gensql.Select[product.Sale](ts.Context(), SqlxTx(ts), query, customerID, limit)
+}
+
+// LoadCustomerSaleTotal кол-во и сумма всех продаж покупателя
+func (r *customerRepository) LoadCustomerSaleTotal(ts transaction.Session, customerID int) (customer.SaleTotal, error) {
+	query := `
+	select count(*) as sale_count, coalesce(sum(total_price), 0) as total_spent
+	from sales
+	where customer_id = $1`
+
+	return gensql.Get[customer.SaleTotal](ts.Context(), SqlxTx(ts), query, customerID)
+}
@@ -0,0 +1,152 @@
+package postgresql
+
+import (
+	"product_storage/internal/entity/image"
+	"product_storage/internal/repository"
+	"product_storage/internal/transaction"
+	"product_storage/tools/gensql"
+)
+
+type imageRepository struct{}
+
+func NewImage() repository.Image {
+	return &imageRepository{}
+}
+
+// imageColumns поля изображения
+const imageColumns = `image_id, product_id, variant_id, blob_key, thumb_key, content_type,
+	width, height, position, is_primary, created_at`
+
+// AddImage сохранение загруженного изображения
+func (r *imageRepository) AddImage(ts transaction.Session, img image.Image) (imageID int, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into product_images
+	( product_id, variant_id, blob_key, thumb_key, content_type, width, height, position, is_primary )
+	values ( $1, $2, $3, $4, $5, $6, $7, $8, $9 )
+	returning image_id`,
+		img.ProductID, img.VariantID, img.BlobKey, img.ThumbKey, img.ContentType,
+		img.Width, img.Height, img.Position, img.IsPrimary).Scan(&imageID)
+
+	return imageID, err
+}
+
+// LoadImage изображение, блокируется до конца транзакции
+func (r *imageRepository) LoadImage(ts transaction.Session, imageID int) (image.Image, error) {
+	query := `
+	select ` + imageColumns + `
+	from product_images
+	where image_id = $1
+	for update`
+
+	return gensql.Get[image.Image](ts.Context(), SqlxTx(ts), query, imageID)
+}
+
+// FindImageList изображения продукта, основное первым, остальные по порядку
+func (r *imageRepository) FindImageList(ts transaction.Session, productID int) ([]image.Image, error) {
+	query := `
+	select ` + imageColumns + `
+	from product_images
+	where product_id = $1
+	order by is_primary desc, position, image_id`
+
+	return gensql.Select[image.Image](ts.Context(), SqlxTx(ts), query, productID)
+}
+
+// FindImageListByProductIDList изображения списка продуктов одним запросом
+func (r *imageRepository) FindImageListByProductIDList(ts transaction.Session, productIDList []int) ([]image.Image, error) {
+	query := `
+	select ` + imageColumns + `
+	from product_images
+	where product_id in (?)
+	order by product_id, is_primary desc, position, image_id`
+
+	return gensql.SelectInOverLimit(productIDList, func(list []int) ([]image.Image, error) {
+		return gensql.SelectIn[image.Image](ts.Context(), SqlxTx(ts), query, list)
+	})
+}
+
+// LockProductImages блокировка продукта до конца транзакции, чтобы изображения продукта добавлялись по очереди
+func (r *imageRepository) LockProductImages(ts transaction.Session, productID int) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	select 1
+	from products
+	where product_id = $1
+	for update`,
+		productID)
+
+	return err
+}
+
+// NextImagePosition порядковый номер следующего изображения продукта
+func (r *imageRepository) NextImagePosition(ts transaction.Session, productID int) (int, error) {
+	query := `
+	select coalesce(max(position) + 1, 0)
+	from product_images
+	where product_id = $1`
+
+	return gensql.Get[int](ts.Context(), SqlxTx(ts), query, productID)
+}
+
+// SetImagePosition изменение порядкового номера изображения
+func (r *imageRepository) SetImagePosition(ts transaction.Session, imageID, position int) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	update product_images
+	set position = $2
+	where image_id = $1`,
+		imageID, position)
+
+	return err
+}
+
+// SetPrimaryImage назначение основного изображения продукта, прежнее основное изображение снимается
+// отдельным запросом, чтобы не нарушить уникальность основного изображения
+func (r *imageRepository) SetPrimaryImage(ts transaction.Session, productID, imageID int) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	update product_images
+	set is_primary = false
+	where product_id = $1 and is_primary and image_id <> $2`,
+		productID, imageID)
+	if err != nil {
+		return err
+	}
+
+	_, err = SqlxTx(ts).ExecContext(ts.Context(), `
+	update product_images
+	set is_primary = true
+	where product_id = $1 and image_id = $2`,
+		productID, imageID)
+
+	return err
+}
+
+// PromoteFirstImage назначение основным первого по порядку изображения продукта без основного изображения
+func (r *imageRepository) PromoteFirstImage(ts transaction.Session, productID int) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	update product_images
+	set is_primary = true
+	where image_id = (
+		select image_id
+		from product_images
+		where product_id = $1
+		order by position, image_id
+		limit 1
+	)
+	and not exists (
+		select 1
+		from product_images
+		where product_id = $1 and is_primary
+	)`,
+		productID)
+
+	return err
+}
+
+// DeleteImage удаление изображения
+func (r *imageRepository) DeleteImage(ts transaction.Session, imageID int) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	delete from product_images
+	where image_id = $1`,
+		imageID)
+
+	return err
+}
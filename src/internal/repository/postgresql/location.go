@@ -0,0 +1,123 @@
+package postgresql
+
+import (
+	"product_storage/internal/entity/location"
+	"product_storage/internal/repository"
+	"product_storage/internal/transaction"
+	"product_storage/tools/gensql"
+)
+
+type locationRepository struct{}
+
+func NewLocation() repository.Location {
+	return &locationRepository{}
+}
+
+// AddLocation создание места хранения
+func (r *locationRepository) AddLocation(ts transaction.Session, l location.Location) (locationID int, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into storage_locations
+	( storage_id, parent_id, kind, code, name, path )
+	values ( $1, $2, $3, $4, $5, $6 )
+	returning location_id`,
+		l.StorageID, l.ParentID, l.Kind, l.Code, l.Name, l.Path).Scan(&locationID)
+
+	return locationID, err
+}
+
+// LoadLocation место хранения
+func (r *locationRepository) LoadLocation(ts transaction.Session, locationID int) (location.Location, error) {
+	query := `
+	select location_id, storage_id, parent_id, kind, code, name, path, created_at
+	from storage_locations
+	where location_id = $1`
+
+	return gensql.Get[location.Location](ts.Context(), SqlxTx(ts), query, locationID)
+}
+
+// FindLocationList места хранения склада в порядке адресов
+func (r *locationRepository) FindLocationList(ts transaction.Session, storageID int) ([]location.Location, error) {
+	query := `
+	select location_id, storage_id, parent_id, kind, code, name, path, created_at
+	from storage_locations
+	where storage_id = $1
+	order by path`
+
+	return gensql.Select[location.Location](ts.Context(), SqlxTx(ts), query, storageID)
+}
+
+// FindLocationStockList ненулевые остатки вариантов по местам хранения склада
+func (r *locationRepository) FindLocationStockList(ts transaction.Session, storageID int) ([]location.Stock, error) {
+	query := `
+	select ls.location_id, l.path, ls.variant_id, ls.quantity
+	from location_stock ls
+	join storage_locations l on l.location_id = ls.location_id
+	where l.storage_id = $1 and ls.quantity > 0
+	order by ls.variant_id, l.path`
+
+	return gensql.Select[location.Stock](ts.Context(), SqlxTx(ts), query, storageID)
+}
+
+// LoadUnplacedQuantity кол-во варианта на складе, не разложенное по местам хранения.
+// Остаток склада блокируется до конца транзакции
+func (r *locationRepository) LoadUnplacedQuantity(ts transaction.Session, variantID, storageID int) (int, error) {
+	query := `
+	select pis.quantity - coalesce((
+		select sum(ls.quantity)
+		from location_stock ls
+		join storage_locations l on l.location_id = ls.location_id
+		where ls.variant_id = pis.variant_id and l.storage_id = pis.storage_id
+	), 0)
+	from products_in_storage pis
+	where pis.variant_id = $1 and pis.storage_id = $2
+	for update`
+
+	return gensql.Get[int](ts.Context(), SqlxTx(ts), query, variantID, storageID)
+}
+
+// PutLocationStock добавление кол-ва варианта в место хранения
+func (r *locationRepository) PutLocationStock(ts transaction.Session, locationID, variantID, quantity int) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	insert into location_stock
+	( location_id, variant_id, quantity )
+	values ( $1, $2, $3 )
+	on conflict (location_id, variant_id) do update
+	set quantity = location_stock.quantity + excluded.quantity`,
+		locationID, variantID, quantity)
+
+	return err
+}
+
+// TakeLocationStock уменьшение кол-ва варианта в месте хранения, global.ErrNoData если его недостаточно
+func (r *locationRepository) TakeLocationStock(ts transaction.Session, locationID, variantID, quantity int) error {
+	query := `
+	update location_stock
+	set quantity = quantity - $3
+	where location_id = $1 and variant_id = $2 and quantity >= $3
+	returning quantity`
+
+	_, err := gensql.Get[int](ts.Context(), SqlxTx(ts), query, locationID, variantID, quantity)
+	return err
+}
+
+// TrimLocationStock уменьшение кол-ва варианта в местах хранения склада так, чтобы в сумме оно
+// не превышало общий остаток total. Продукт забирается из мест в порядке адресов
+func (r *locationRepository) TrimLocationStock(ts transaction.Session, variantID, storageID, total int) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	with located as (
+		select ls.location_id, ls.quantity,
+			sum(ls.quantity) over (order by l.path) - ls.quantity as before,
+			sum(ls.quantity) over () - $3 as excess
+		from location_stock ls
+		join storage_locations l on l.location_id = ls.location_id
+		where ls.variant_id = $1 and l.storage_id = $2 and ls.quantity > 0
+	)
+	update location_stock ls
+	set quantity = ls.quantity - least(x.quantity, x.excess - x.before)
+	from located x
+	where ls.location_id = x.location_id and ls.variant_id = $1
+	and x.excess > x.before`,
+		variantID, storageID, total)
+
+	return err
+}
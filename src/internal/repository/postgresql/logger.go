@@ -20,7 +20,7 @@ func (l *loggerRepository) SaveLog(
 	( logtime, flag, msg, module, fl, ln ) 
 	values ( $1, $2, $3, $4, $5, $6)`
 
-	_, err := SqlxTx(ts).Exec(sqlQuery,
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), sqlQuery,
 		row.Time,
 		row.Flag,
 		row.Message,
@@ -33,7 +33,7 @@ func (l *loggerRepository) SaveLog(
 
 func (l *loggerRepository) SaveLogWithReturnID(
 	ts transaction.Session,
-	row log.Row,) (logID int, err error) {
+	row log.Row) (logID int, err error) {
 
 	sqlQuery := `
 	insert into log_table
@@ -41,7 +41,7 @@ func (l *loggerRepository) SaveLogWithReturnID(
 	values ( $1, $2, $3, $4, $5, $6 )
 	returning log_id
 	`
-	err = SqlxTx(ts).QueryRow(sqlQuery,
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), sqlQuery,
 		row.Time,
 		row.Flag,
 		row.Message,
@@ -58,14 +58,14 @@ func (l *loggerRepository) SaveLogDetails(ts transaction.Session, logID int, det
 	(LOG_ID, NAME, VALUE) 
 	VALUES ($1, $2, $3)`
 
-	stmt, err := SqlxTx(ts).Prepare(sqlQuery)
+	stmt, err := SqlxTx(ts).PrepareContext(ts.Context(), sqlQuery)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
 	for k, v := range details {
-		if _, err := stmt.Exec(logID, k, v); err != nil {
+		if _, err := stmt.ExecContext(ts.Context(), logID, k, v); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,67 @@
+package postgresql
+
+import (
+	"product_storage/internal/entity/event"
+	"product_storage/internal/repository"
+	"product_storage/internal/transaction"
+	"product_storage/tools/gensql"
+	"time"
+)
+
+type outboxRepository struct{}
+
+func NewOutbox() repository.Outbox {
+	return &outboxRepository{}
+}
+
+// SaveEvent запись события в outbox, выполняется в транзакции изменения, породившего событие
+func (r *outboxRepository) SaveEvent(ts transaction.Session, e event.Event) (eventID int64, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into outbox_events
+	( event_type, aggregate_id, payload, created_at )
+	values ( $1, $2, $3, $4 )
+	returning event_id`,
+		e.EventType, e.AggregateID, e.Payload, e.CreatedAt).Scan(&eventID)
+
+	return eventID, err
+}
+
+// LoadPendingEventList неопубликованные события, время повтора которых наступило, в порядке возникновения.
+// Строки блокируются до конца транзакции, параллельные обработчики пропускают их
+func (r *outboxRepository) LoadPendingEventList(ts transaction.Session, limit int) ([]event.Event, error) {
+	query := `
+	select event_id, event_type, aggregate_id, payload, created_at, attempts
+	from outbox_events
+	where published_at is null
+	and next_attempt_at <= now()
+	order by event_id
+	limit $1
+	for update skip locked`
+
+	return gensql.Select[event.Event](ts.Context(), SqlxTx(ts), query, limit)
+}
+
+// MarkPublished отметка о публикации события
+func (r *outboxRepository) MarkPublished(ts transaction.Session, eventID int64) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	update outbox_events
+	set published_at = now(),
+		last_error = null
+	where event_id = $1`,
+		eventID)
+
+	return err
+}
+
+// MarkFailed отметка о неудачной публикации и время следующей попытки
+func (r *outboxRepository) MarkFailed(ts transaction.Session, eventID int64, nextAttemptAt time.Time, lastError string) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	update outbox_events
+	set attempts = attempts + 1,
+		next_attempt_at = $2,
+		last_error = $3
+	where event_id = $1`,
+		eventID, nextAttemptAt, lastError)
+
+	return err
+}
@@ -0,0 +1,67 @@
+package postgresql
+
+import (
+	"product_storage/internal/entity/payment"
+	"product_storage/internal/repository"
+	"product_storage/internal/transaction"
+	"product_storage/tools/gensql"
+	"time"
+)
+
+type paymentRepository struct{}
+
+func NewPayment() repository.Payment {
+	return &paymentRepository{}
+}
+
+// AddPayment запись оплаты продажи
+func (r *paymentRepository) AddPayment(ts transaction.Session, saleID int, p payment.Params) (paymentID int, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into sale_payments
+	( sales_id, method, amount )
+	values ( $1, $2, $3 )
+	returning payment_id`,
+		saleID, p.Method, p.Amount).Scan(&paymentID)
+
+	return paymentID, err
+}
+
+// FindPaymentList оплаты продажи
+func (r *paymentRepository) FindPaymentList(ts transaction.Session, saleID int) ([]payment.Payment, error) {
+	query := `
+	select payment_id, sales_id, method, amount, paid_at
+	from sale_payments
+	where sales_id = $1
+	order by payment_id`
+
+	return gensql.Select[payment.Payment](ts.Context(), SqlxTx(ts), query, saleID)
+}
+
+// FindZStorageTotalList итоги продаж по складам за период [start, end), нулевой storageID выбирает все склады
+func (r *paymentRepository) FindZStorageTotalList(ts transaction.Session, start, end time.Time, storageID int) ([]payment.ZStorageTotal, error) {
+	query := `
+	select s.storage_id, st.name as storage_name, count(*) as sale_count,
+		sum(s.total_price) as revenue, sum(s.discount) as discount
+	from sales s
+	join storages st on st.storage_id = s.storage_id
+	where s.sold_at >= $1 and s.sold_at < $2
+	and ( $3 = 0 or s.storage_id = $3 )
+	group by s.storage_id, st.name
+	order by s.storage_id`
+
+	return gensql.Select[payment.ZStorageTotal](ts.Context(), SqlxTx(ts), query, start, end, storageID)
+}
+
+// FindZMethodTotalList итоги оплат по складам и способам оплаты за период продаж [start, end)
+func (r *paymentRepository) FindZMethodTotalList(ts transaction.Session, start, end time.Time, storageID int) ([]payment.ZMethodTotal, error) {
+	query := `
+	select s.storage_id, sp.method, count(*) as payment_count, sum(sp.amount) as amount
+	from sale_payments sp
+	join sales s on s.sales_id = sp.sales_id
+	where s.sold_at >= $1 and s.sold_at < $2
+	and ( $3 = 0 or s.storage_id = $3 )
+	group by s.storage_id, sp.method
+	order by s.storage_id, sp.method`
+
+	return gensql.Select[payment.ZMethodTotal](ts.Context(), SqlxTx(ts), query, start, end, storageID)
+}
@@ -1,12 +1,16 @@
 package postgresql
 
 import (
-	"log"
+	"product_storage/internal/entity/attribute"
 	"product_storage/internal/entity/product"
 	"product_storage/internal/entity/stock"
 	"product_storage/internal/repository"
 	"product_storage/internal/transaction"
 	"product_storage/tools/gensql"
+	"product_storage/tools/jsonb"
+
+	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
 )
 
 type productRepository struct {
@@ -19,27 +23,116 @@ func NewProduct() repository.Product {
 
 // AddProduct вставка названия,описания,времени добавления и тегов в базу
 func (r *productRepository) AddProduct(ts transaction.Session, product product.ProductParams) (productID int, err error) {
+	attributes, err := marshalAttributes(product.Attributes)
+	if err != nil {
+		return 0, err
+	}
+
 	query := `insert into products
-	(name, description, added_at, tags)
-	values ($1, $2, $3, $4) 
+	(name, description, added_at, tags, category_id, attributes)
+	values ($1, $2, $3, $4, nullif($5, 0), $6) 
 	returning product_id`
 
-	err = SqlxTx(ts).QueryRow(query, product.Name, product.Descr, product.AddetAt, product.Tags).Scan(&productID)
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), query, product.Name, product.Descr, product.AddetAt, product.Tags,
+		product.CategoryID, attributes).Scan(&productID)
 
 	return productID, err
 }
 
+// marshalAttributes значения атрибутов продукта в виде jsonb, отсутствие значений сохраняется пустым объектом
+func marshalAttributes(values attribute.Values) (types.JSONText, error) {
+	if values == nil {
+		values = attribute.Values{}
+	}
+
+	return jsonb.Marshal(values)
+}
+
+// UpdateProductAttributes замена категории и значений атрибутов продукта, categoryID = 0 убирает продукт из категории
+func (r *productRepository) UpdateProductAttributes(ts transaction.Session, productID, categoryID int, values attribute.Values) error {
+	attributes, err := marshalAttributes(values)
+	if err != nil {
+		return err
+	}
+
+	_, err = SqlxTx(ts).ExecContext(ts.Context(), `
+	update products
+	set category_id = nullif($2, 0),
+		attributes = $3
+	where product_id = $1`,
+		productID, categoryID, attributes)
+
+	return err
+}
+
 // AddProductVariantList добавление вариантов продукта в продукт по его id
-func (r *productRepository) AddProductVariantList(ts transaction.Session, productID int, variant product.Variant) error {
+func (r *productRepository) AddProductVariantList(ts transaction.Session, productID int, variant product.Variant) (variantID int, err error) {
 	query := `
 	insert into product_variants 
-	(product_id, weight, unit) 
-	values ($1, $2, $3)`
+	(product_id, weight, unit, sku) 
+	values ($1, $2, $3, $4)
+	returning variant_id`
+
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), query, productID, variant.Weight, variant.Unit, variant.SKU).Scan(&variantID)
+	return variantID, err
+}
 
-	_, err := SqlxTx(ts).Exec(query, productID, variant.Weight, variant.Unit)
+// AddVariantBarcode привязка штрихкода к варианту
+func (r *productRepository) AddVariantBarcode(ts transaction.Session, variantID int, barcode string) error {
+	query := `
+	insert into variant_barcodes
+	(barcode, variant_id)
+	values ($1, $2)`
+
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), query, barcode, variantID)
 	return err
 }
 
+// FindVariantIDByBarcode id варианта по штрихкоду
+func (r *productRepository) FindVariantIDByBarcode(ts transaction.Session, barcode string) (int, error) {
+	query := `
+	select variant_id
+	from variant_barcodes
+	where barcode = $1`
+
+	return gensql.Get[int](ts.Context(), SqlxTx(ts), query, barcode)
+}
+
+// FindVariantIDBySKU id варианта по артикулу
+func (r *productRepository) FindVariantIDBySKU(ts transaction.Session, sku string) (int, error) {
+	query := `
+	select variant_id
+	from product_variants
+	where sku = $1`
+
+	return gensql.Get[int](ts.Context(), SqlxTx(ts), query, sku)
+}
+
+// FindVariantByBarcode вариант с названием продукта по штрихкоду
+func (r *productRepository) FindVariantByBarcode(ts transaction.Session, barcode string) (product.BarcodeVariant, error) {
+	query := `
+	select p.name as product_name, v.product_id, v.variant_id, v.weight, v.unit, v.added_at, v.sku,
+		coalesce(u.dimension, '') as unit_dimension, coalesce(u.factor, 0) as unit_factor
+	from variant_barcodes b
+	join product_variants v on v.variant_id = b.variant_id
+	join products p on p.product_id = v.product_id
+	left join units u on u.code = v.unit
+	where b.barcode = $1`
+
+	return gensql.Get[product.BarcodeVariant](ts.Context(), SqlxTx(ts), query, barcode)
+}
+
+// FindVariantBarcodeList штрихкоды варианта
+func (r *productRepository) FindVariantBarcodeList(ts transaction.Session, variantID int) ([]string, error) {
+	query := `
+	select barcode
+	from variant_barcodes
+	where variant_id = $1
+	order by added_at, barcode`
+
+	return gensql.Select[string](ts.Context(), SqlxTx(ts), query, variantID)
+}
+
 // CheckExists проверка наличия цен варианта продукта в указаный диапазон времени
 func (r *productRepository) CheckExists(ts transaction.Session, p product.ProductPriceParams) (isExistsID int, err error) {
 	query := `
@@ -49,12 +142,12 @@ func (r *productRepository) CheckExists(ts transaction.Session, p product.Produc
 	and start_date = $2 
 	and( end_date = $3 or end_date is null )`
 
-	return gensql.Get[int](SqlxTx(ts), query, p.VariantID, p.StartDate, p.EndDate)
+	return gensql.Get[int](ts.Context(), SqlxTx(ts), query, p.VariantID, p.StartDate, p.EndDate)
 }
 
 // UpdateProductPrice обновление цены варианта продукта
 func (r *productRepository) UpdateProductPrice(ts transaction.Session, price product.ProductPriceParams, priceID int) error {
-	_, err := SqlxTx(ts).Exec(`
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
 	update product_prices
 	set end_date = $1 
 	where price_id = $2`,
@@ -65,7 +158,7 @@ func (r *productRepository) UpdateProductPrice(ts transaction.Session, price pro
 
 // AddProductPrice вставка цены варианта продукта в базу
 func (r *productRepository) AddProductPrice(ts transaction.Session, price product.ProductPriceParams) (priceID int, err error) {
-	err = SqlxTx(ts).QueryRow(`
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
 	insert into product_prices
 	( variant_id, price, start_date, end_date )
 	values( $1, $2, $3, $4 )
@@ -83,12 +176,12 @@ func (r *productRepository) CheckProductInStock(ts transaction.Session, productI
 	where variant_id = $1 
 	and storage_id = $2)`
 
-	return gensql.Get[bool](SqlxTx(ts), query, productInStock.VariantID, productInStock.StorageID)
+	return gensql.Get[bool](ts.Context(), SqlxTx(ts), query, productInStock.VariantID, productInStock.StorageID)
 }
 
 // UpdateProductInstock обновление колличества продукта
 func (r *productRepository) UpdateProductInstock(ts transaction.Session, productInStock stock.ProductInStockParams) (productStockID int, err error) {
-	err = SqlxTx(ts).QueryRow(`
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
 	update products_in_storage 
 	set quantity = $1
 	where variant_id = $2 
@@ -99,9 +192,22 @@ func (r *productRepository) UpdateProductInstock(ts transaction.Session, product
 	return productStockID, err
 }
 
+// IncreaseProductInstock увеличение колличества продукта, уже имеющегося на складе
+func (r *productRepository) IncreaseProductInstock(ts transaction.Session, productInStock stock.ProductInStockParams) (productStockID, total int, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	update products_in_storage
+	set quantity = quantity + $1
+	where variant_id = $2
+	and storage_id = $3
+	returning pis_id, quantity`,
+		productInStock.Quantity, productInStock.VariantID, productInStock.StorageID).Scan(&productStockID, &total)
+
+	return productStockID, total, err
+}
+
 // AddProductInStock добавление продукта на склад
 func (r *productRepository) AddProductInStock(ts transaction.Session, productInStock stock.ProductInStockParams) (productStockID int, err error) {
-	err = SqlxTx(ts).QueryRow(`
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
 	 insert into products_in_storage
 	 ( variant_id, storage_id, added_at, quantity )
 	 values ($1, $2, $3, $4)
@@ -114,21 +220,23 @@ func (r *productRepository) AddProductInStock(ts transaction.Session, productInS
 // LoadProductInfo получение информации о продукте
 func (r *productRepository) LoadProductInfo(ts transaction.Session, productId int) (productInfo product.ProductInfo, err error) {
 	query := `
-	select product_id, name, description  
+	select product_id, name, description, category_id, attributes
 	from products 
     where product_id = $1`
 
-	return gensql.Get[product.ProductInfo](SqlxTx(ts), query, productId)
+	return gensql.Get[product.ProductInfo](ts.Context(), SqlxTx(ts), query, productId)
 }
 
 // FindProductVariantList получение вариантов продукта по его id
 func (r *productRepository) FindProductVariantList(ts transaction.Session, productID int) (variantList []product.Variant, err error) {
 	query := `
-	select product_id, variant_id, weight, unit, added_at
-	from product_variants	
-	where product_id = $1`
+	select v.product_id, v.variant_id, v.weight, v.unit, v.added_at, v.sku,
+		coalesce(u.dimension, '') as unit_dimension, coalesce(u.factor, 0) as unit_factor
+	from product_variants v
+	left join units u on u.code = v.unit
+	where v.product_id = $1`
 
-	return gensql.Select[product.Variant](SqlxTx(ts), query, productID)
+	return gensql.Select[product.Variant](ts.Context(), SqlxTx(ts), query, productID)
 }
 
 // FindCurrentPrice получение актуальной цены
@@ -140,70 +248,186 @@ func (r *productRepository) FindCurrentPrice(ts transaction.Session, variantID i
 	and start_date < now() 
 	and ( end_date is null or end_date > now() )`
 
-	return gensql.Get[float64](SqlxTx(ts), query, variantID)
+	return gensql.Get[float64](ts.Context(), SqlxTx(ts), query, variantID)
 }
 
-// InStorages нахождение id складов в которых находится продукт
+// InStorages нахождение неудаленных складов, в которых находится продукт
 func (r *productRepository) InStorages(ts transaction.Session, varantID int) (inStorages []product.VarStorage, err error) {
 	query := `
-	SELECT s.storage_id, s.name
-	FROM products_in_storage pis
-	JOIN storages s ON pis.storage_id = s.storage_id
-    WHERE pis.variant_id = $1`
+	select s.storage_id, s.name, pis.quantity,
+		coalesce(r.reserved, 0) as reserved,
+		pis.quantity - coalesce(r.reserved, 0) as available
+	from products_in_storage pis
+	join storages s on pis.storage_id = s.storage_id
+	left join (
+		select variant_id, storage_id, sum(quantity) as reserved
+		from stock_reservations
+		where status = 'active' and expires_at > now()
+		group by variant_id, storage_id
+	) r on r.variant_id = pis.variant_id and r.storage_id = pis.storage_id
+	where pis.variant_id = $1 and s.removed_at is null`
+
+	return gensql.Select[product.VarStorage](ts.Context(), SqlxTx(ts), query, varantID)
+}
 
-	return gensql.Select[product.VarStorage](SqlxTx(ts), query, varantID)
+// FindVariantListByProductIDList получение вариантов списка продуктов одним запросом
+func (r *productRepository) FindVariantListByProductIDList(ts transaction.Session, productIDList []int) (variantList []product.Variant, err error) {
+	query := `
+	select v.product_id, v.variant_id, v.weight, v.unit, v.added_at, v.sku,
+		coalesce(u.dimension, '') as unit_dimension, coalesce(u.factor, 0) as unit_factor
+	from product_variants v
+	left join units u on u.code = v.unit
+	where v.product_id in (?)
+	order by v.product_id, v.variant_id`
+
+	return gensql.SelectInOverLimit(productIDList, func(list []int) ([]product.Variant, error) {
+		return gensql.SelectIn[product.Variant](ts.Context(), SqlxTx(ts), query, list)
+	})
+}
+
+// FindVariantIDListByIDList id неудаленных вариантов из списка одним запросом
+func (r *productRepository) FindVariantIDListByIDList(ts transaction.Session, variantIDList []int) ([]int, error) {
+	query := `
+	select variant_id
+	from product_variants
+	where variant_id in (?)
+	and removed_at is null`
+
+	return gensql.SelectInOverLimit(variantIDList, func(list []int) ([]int, error) {
+		return gensql.SelectIn[int](ts.Context(), SqlxTx(ts), query, list)
+	})
+}
+
+// FindCurrentPriceListByVariantIDList получение актуальных цен списка вариантов одним запросом
+func (r *productRepository) FindCurrentPriceListByVariantIDList(ts transaction.Session, variantIDList []int) (priceList []product.VariantPrice, err error) {
+	query := `
+	select distinct on (variant_id) variant_id, price
+	from product_prices
+	where variant_id in (?)
+	and start_date < now()
+	and ( end_date is null or end_date > now() )
+	order by variant_id, start_date desc`
+
+	return gensql.SelectInOverLimit(variantIDList, func(list []int) ([]product.VariantPrice, error) {
+		return gensql.SelectIn[product.VariantPrice](ts.Context(), SqlxTx(ts), query, list)
+	})
+}
+
+// FindStorageListByVariantIDList получение складов, в которых находятся варианты, одним запросом
+func (r *productRepository) FindStorageListByVariantIDList(ts transaction.Session, variantIDList []int) (storageList []product.VariantStorage, err error) {
+	query := `
+	select pis.variant_id, s.storage_id, s.name, pis.quantity,
+		coalesce(r.reserved, 0) as reserved,
+		pis.quantity - coalesce(r.reserved, 0) as available
+	from products_in_storage pis
+	join storages s on pis.storage_id = s.storage_id
+	left join (
+		select variant_id, storage_id, sum(quantity) as reserved
+		from stock_reservations
+		where status = 'active' and expires_at > now()
+		group by variant_id, storage_id
+	) r on r.variant_id = pis.variant_id and r.storage_id = pis.storage_id
+	where pis.variant_id in (?)
+	order by pis.variant_id, s.storage_id`
+
+	return gensql.SelectInOverLimit(variantIDList, func(list []int) ([]product.VariantStorage, error) {
+		return gensql.SelectIn[product.VariantStorage](ts.Context(), SqlxTx(ts), query, list)
+	})
 }
 
 // FindProductListByTag  поиск информации о продукте по его тегу
 func (r *productRepository) FindProductListByTag(ts transaction.Session, tag string, limit int) (productList []product.ProductInfo, err error) {
 	query := `
-	select product_id, name, description
+	select product_id, name, description, category_id, attributes
 	from products 
 	where $1 = any ( string_to_array( tags,',' )) 
 	limit $2`
 
-	return gensql.Select[product.ProductInfo](SqlxTx(ts), query, tag, limit)
+	return gensql.Select[product.ProductInfo](ts.Context(), SqlxTx(ts), query, tag, limit)
 }
 
 func (r *productRepository) FindProductListByName(ts transaction.Session, name string, limit int) (productList []product.ProductInfo, err error) {
 	query := `
-	select product_id, name, description
+	select product_id, name, description, category_id, attributes
 	from products
 	where name = $1 
 	limit $2
 	`
-	return gensql.Select[product.ProductInfo](SqlxTx(ts), query, name, limit)
+	return gensql.Select[product.ProductInfo](ts.Context(), SqlxTx(ts), query, name, limit)
 }
 
 func (r *productRepository) FindProductListByTagAndName(ts transaction.Session, tag, name string, limit int) (productList []product.ProductInfo, err error) {
 	query := `
-	select product_id, name ,description
+	select product_id, name, description, category_id, attributes
 	from products
 	where name = $1
 	and $2 = any (string_to_array(tags,','))
 	limit $3
 	`
 
-	return gensql.Select[product.ProductInfo](SqlxTx(ts), query, name, tag, limit)
+	return gensql.Select[product.ProductInfo](ts.Context(), SqlxTx(ts), query, name, tag, limit)
+}
+
+// FindProductListByAttributes получение списка продуктов по категории и значениям атрибутов с лимитом,
+// пустые tag и name не ограничивают выборку; значения атрибутов сравниваются в текстовом виде
+func (r *productRepository) FindProductListByAttributes(ts transaction.Session, f attribute.Filter, tag, name string, limit int) (productList []product.ProductInfo, err error) {
+	values, err := jsonb.Marshal(f.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+	select product_id, name, description, category_id, attributes
+	from products p
+	where ($1 = 0 or p.category_id = $1)
+	and ($2 = '' or $2 = any (string_to_array(p.tags, ',')))
+	and ($3 = '' or p.name = $3)
+	and not exists (
+		select 1
+		from jsonb_each_text(coalesce($4::jsonb, '{}')) f
+		where p.attributes ->> f.key is distinct from f.value
+	)
+	order by p.product_id
+	limit $5`
+
+	return gensql.Select[product.ProductInfo](ts.Context(), SqlxTx(ts), query, f.CategoryID, tag, name, values, limit)
 }
 
 // LoadProductList получение списка продуктов с лимитом
 func (r *productRepository) LoadProductList(ts transaction.Session, limit int) (productList []product.ProductInfo, err error) {
 	query := `
-	select product_id, name, description
+	select product_id, name, description, category_id, attributes
 	from products
     limit $1`
 
-	return gensql.Select[product.ProductInfo](SqlxTx(ts), query, limit)
+	return gensql.Select[product.ProductInfo](ts.Context(), SqlxTx(ts), query, limit)
+}
+
+// storageColumns колонки склада с общим кол-вом продукта на нем
+const storageColumns = `
+	s.storage_id, s.name, s.address, s.contact, s.storage_type, s.capacity, s.removed_at,
+	coalesce((select sum(pis.quantity) from products_in_storage pis where pis.storage_id = s.storage_id), 0) as occupied`
+
+// LoadStockList получение информации о складах, удаленные склады выводятся только при withRemoved
+func (r *productRepository) LoadStockList(ts transaction.Session, withRemoved bool) (stockList []stock.Stock, err error) {
+	query := `
+	select ` + storageColumns + `
+	from storages s
+	where $1 or s.removed_at is null
+	order by s.storage_id`
+
+	return gensql.Select[stock.Stock](ts.Context(), SqlxTx(ts), query, withRemoved)
 }
 
-// LoadStockList получение информации о складах
-func (r *productRepository) LoadStockList(ts transaction.Session) (stockList []stock.Stock, err error) {
+// LoadStorage склад с блокировкой до конца транзакции
+func (r *productRepository) LoadStorage(ts transaction.Session, storageID int) (stock.Stock, error) {
 	query := `
-	select  storage_id, name
-	from storages`
+	select ` + storageColumns + `
+	from storages s
+	where s.storage_id = $1
+	for update`
 
-	return gensql.Select[stock.Stock](SqlxTx(ts), query)
+	return gensql.Get[stock.Stock](ts.Context(), SqlxTx(ts), query, storageID)
 }
 
 // FindStockListByProductId получение информации о складах где есть определенный продукт
@@ -214,39 +438,90 @@ func (r *productRepository) FindStockListByProductId(ts transaction.Session, pro
 	join products_in_storage pis ON (s.storage_id = pis.storage_id)
 	join product_variants pv ON (pis.variant_id = pv.variant_id)
 	join products p ON (pv.product_id = p.product_id)
-	where p.product_id = $1`
+	where p.product_id = $1 and s.removed_at is null`
 
-	return gensql.Select[stock.Stock](SqlxTx(ts), query, productID)
+	return gensql.Select[stock.Stock](ts.Context(), SqlxTx(ts), query, productID)
 }
 
-// FindStocksVariantList получение вариантов продукта на складе
+// FindStocksVariantList получение вариантов продукта на неудаленном складе
 func (r *productRepository) FindStocksVariantList(ts transaction.Session, storageID int) (variantList []stock.ProductInStockParams, err error) {
 	query := `
-	select variant_id, storage_id, added_at, quantity
-	from products_in_storage 
-	where storage_id = $1 `
+	select pis.variant_id, pis.storage_id, pis.added_at, pis.quantity,
+		coalesce(r.reserved, 0) as reserved,
+		pis.quantity - coalesce(r.reserved, 0) as available
+	from products_in_storage pis
+	join storages s on s.storage_id = pis.storage_id and s.removed_at is null
+	left join (
+		select variant_id, storage_id, sum(quantity) as reserved
+		from stock_reservations
+		where status = 'active' and expires_at > now()
+		group by variant_id, storage_id
+	) r on r.variant_id = pis.variant_id and r.storage_id = pis.storage_id
+	where pis.storage_id = $1`
+
+	return gensql.Select[stock.ProductInStockParams](ts.Context(), SqlxTx(ts), query, storageID)
+}
+
+// FindStocksVariantListByStorageIDList получение вариантов продуктов на списке неудаленных складов одним запросом
+func (r *productRepository) FindStocksVariantListByStorageIDList(ts transaction.Session, storageIDList []int) (variantList []stock.ProductInStockParams, err error) {
+	query := `
+	select pis.variant_id, pis.storage_id, pis.added_at, pis.quantity,
+		coalesce(r.reserved, 0) as reserved,
+		pis.quantity - coalesce(r.reserved, 0) as available
+	from products_in_storage pis
+	join storages s on s.storage_id = pis.storage_id and s.removed_at is null
+	left join (
+		select variant_id, storage_id, sum(quantity) as reserved
+		from stock_reservations
+		where status = 'active' and expires_at > now()
+		group by variant_id, storage_id
+	) r on r.variant_id = pis.variant_id and r.storage_id = pis.storage_id
+	where pis.storage_id in (?)
+	order by pis.storage_id, pis.variant_id`
+
+	return gensql.SelectInOverLimit(storageIDList, func(list []int) ([]stock.ProductInStockParams, error) {
+		return gensql.SelectIn[stock.ProductInStockParams](ts.Context(), SqlxTx(ts), query, list)
+	})
+}
+
+// FindStockLevelList кол-во вариантов продуктов на складах из списка, при пустом списке на всех складах
+func (r *productRepository) FindStockLevelList(ts transaction.Session, storageIDList []int) ([]stock.StockLevel, error) {
+	query := `
+	select variant_id, storage_id, quantity
+	from products_in_storage
+	where cardinality($1::int[]) = 0 or storage_id = any($1)
+	order by storage_id, variant_id`
+
+	if storageIDList == nil {
+		storageIDList = []int{}
+	}
 
-	return gensql.Select[stock.ProductInStockParams](SqlxTx(ts), query, storageID)
+	return gensql.Select[stock.StockLevel](ts.Context(), SqlxTx(ts), query, pq.Array(storageIDList))
 }
 
-// FindPrice получение цены
+// FindPrice получение действующей цены, из нескольких действующих цен выбирается начавшая действовать последней
 func (r *productRepository) FindPrice(ts transaction.Session, variantID int) (price float64, err error) {
 	query :=
 		`select price
 	 	 from product_prices
-	 	 where variant_id = $1`
+	 	 where variant_id = $1
+	 	 and start_date <= now()
+	 	 and ( end_date is null or end_date > now() )
+	 	 order by start_date desc
+	 	 limit 1`
 
-	return gensql.Get[float64](SqlxTx(ts), query, variantID)
+	return gensql.Get[float64](ts.Context(), SqlxTx(ts), query, variantID)
 }
 
 // SaveSale запись о покупке в базу
 func (r *productRepository) SaveSale(ts transaction.Session, sale product.SaleParams) (saleID int, err error) {
-	err = SqlxTx(ts).QueryRow(`
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
 	insert into sales
-	( variant_id, storage_id, sold_at, quantity, total_price )
-	values( $1, $2, $3, $4, $5 )
+	( variant_id, storage_id, sold_at, quantity, total_price, cost_of_goods, promotion_id, discount, customer_id )
+	values( $1, $2, $3, $4, $5, $6, $7, $8, nullif($9, 0) )
 	returning sales_id`,
-		sale.VariantID, sale.StorageID, sale.SoldAt, sale.Quantity, sale.TotalPrice).Scan(&saleID)
+		sale.VariantID, sale.StorageID, sale.SoldAt, sale.Quantity, sale.TotalPrice, sale.CostOfGoods,
+		sale.PromotionID, sale.Discount, sale.CustomerID).Scan(&saleID)
 
 	return saleID, err
 }
@@ -254,20 +529,20 @@ func (r *productRepository) SaveSale(ts transaction.Session, sale product.SalePa
 // FindSaleListOnlyBySoldDate получение списка всех продаж
 func (r *productRepository) FindSaleListOnlyBySoldDate(ts transaction.Session, saleFilters product.SaleQueryOnlyBySoldDateParam) (saleList []product.Sale, err error) {
 	query := `
-	SELECT s.sales_id, s.variant_id, s.storage_id, s.sold_at, s.quantity, s.total_price, p.name 
+	SELECT s.sales_id, s.variant_id, s.storage_id, s.sold_at, s.quantity, s.total_price, s.cost_of_goods, s.promotion_id, s.discount, s.customer_id, p.name 
 	FROM sales s
 	JOIN product_variants  pv ON ( pv.variant_id = s.variant_id )
 	JOIN products  p ON ( p.product_id = pv.product_id )
 	WHERE s.sold_at >= $1 AND s.sold_at <= $2
 	LIMIT $3`
 
-	return gensql.Select[product.Sale](SqlxTx(ts), query, saleFilters.StartDate, saleFilters.EndDate, saleFilters.Limit)
+	return gensql.Select[product.Sale](ts.Context(), SqlxTx(ts), query, saleFilters.StartDate, saleFilters.EndDate, saleFilters.Limit)
 }
 
 // FindSaleListByFilters получение списка продаж по фильтрам
 func (r *productRepository) FindSaleListByFilters(ts transaction.Session, saleFilters product.SaleQueryParam) (saleList []product.Sale, err error) {
 	query := `
-	SELECT s.sales_id, s.variant_id, s.storage_id, s.sold_at, s.quantity, s.total_price, p.name 
+	SELECT s.sales_id, s.variant_id, s.storage_id, s.sold_at, s.quantity, s.total_price, s.cost_of_goods, s.promotion_id, s.discount, s.customer_id, p.name 
 	FROM sales s
 	JOIN product_variants pv ON (pv.variant_id = s.variant_id)
 	JOIN products p ON (p.product_id = pv.product_id)
@@ -284,27 +559,44 @@ func (r *productRepository) FindSaleListByFilters(ts transaction.Session, saleFi
 		"storage_id":   saleFilters.StorageID,
 	}
 
-	return gensql.SelectNamed[product.Sale](SqlxTx(ts), query, params)
+	return gensql.SelectNamed[product.Sale](ts.Context(), SqlxTx(ts), query, params)
 }
 
 func (r *productRepository) AddStock(ts transaction.Session, storage stock.StockParams) (stockID int, err error) {
 	query := `
 	insert into storages
-	(name, added_at)
-	values ($1, $2)
+	(name, added_at, address, contact, storage_type, capacity)
+	values ($1, $2, $3, $4, $5, $6)
 	returning storage_id
 	`
-	err = SqlxTx(ts).QueryRow(query, storage.StorageName, storage.Added_at).Scan(&stockID)
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), query, storage.StorageName, storage.Added_at,
+		storage.Address, storage.Contact, storage.StorageType, storage.Capacity).Scan(&stockID)
 	return stockID, err
 }
 
-func (r *productRepository) DeleteStock(ts transaction.Session, storage stock.StockParams) error {
-	log.Println(storage)
+// UpdateStock изменение названия, адреса, контакта, типа и вместимости склада
+func (r *productRepository) UpdateStock(ts transaction.Session, storage stock.StockParams) error {
+	query := `
+	update storages
+	set name = $2,
+		address = $3,
+		contact = $4,
+		storage_type = $5,
+		capacity = $6
+	where storage_id = $1
+	`
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), query, storage.StorageID, storage.StorageName,
+		storage.Address, storage.Contact, storage.StorageType, storage.Capacity)
+	return err
+}
 
+// DeleteStock удаление склада с сохранением истории: склад помечается удаленным
+func (r *productRepository) DeleteStock(ts transaction.Session, storageID int) error {
 	query := `
-	delete from storages
-	where name = $1 and storage_id = $2
+	update storages
+	set removed_at = now()
+	where storage_id = $1 and removed_at is null
 	`
-	_, err := SqlxTx(ts).Exec(query, storage.StorageName, storage.StorageID)
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), query, storageID)
 	return err
 }
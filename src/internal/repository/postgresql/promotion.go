@@ -0,0 +1,82 @@
+package postgresql
+
+import (
+	"product_storage/internal/entity/promotion"
+	"product_storage/internal/repository"
+	"product_storage/internal/transaction"
+	"product_storage/tools/gensql"
+	"time"
+)
+
+type promotionRepository struct{}
+
+func NewPromotion() repository.Promotion {
+	return &promotionRepository{}
+}
+
+// promotionColumns поля акции
+const promotionColumns = `pr.promotion_id, pr.name, pr.kind, pr.value, pr.buy_quantity, pr.free_quantity,
+	pr.variant_id, pr.tag, pr.category_id, pr.storage_id, pr.min_amount, pr.starts_at, pr.ends_at, pr.created_at`
+
+// AddPromotion создание акции
+func (r *promotionRepository) AddPromotion(ts transaction.Session, p promotion.Promotion) (promotionID int, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into promotions
+	( name, kind, value, buy_quantity, free_quantity, variant_id, tag, category_id, storage_id, min_amount, starts_at, ends_at )
+	values ( $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12 )
+	returning promotion_id`,
+		p.Name, p.Kind, p.Value, p.BuyQuantity, p.FreeQuantity, p.VariantID, p.Tag, p.CategoryID, p.StorageID,
+		p.MinAmount, p.StartsAt, p.EndsAt).Scan(&promotionID)
+
+	return promotionID, err
+}
+
+// FindPromotionList акции, при activeAt отличном от нуля только действующие в этот момент
+func (r *promotionRepository) FindPromotionList(ts transaction.Session, activeAt time.Time) ([]promotion.Promotion, error) {
+	query := `
+	select ` + promotionColumns + `
+	from promotions pr
+	where $1::timestamptz is null
+	or ( pr.starts_at <= $1 and ( pr.ends_at is null or pr.ends_at > $1 ) )
+	order by pr.starts_at desc, pr.promotion_id desc`
+
+	var at interface{}
+	if !activeAt.IsZero() {
+		at = activeAt
+	}
+
+	return gensql.Select[promotion.Promotion](ts.Context(), SqlxTx(ts), query, at)
+}
+
+// FindApplicablePromotionList акции, действующие в момент at на продажу варианта со склада:
+// по варианту, тегу или категории его продукта, либо на все продукты
+func (r *promotionRepository) FindApplicablePromotionList(ts transaction.Session, variantID, storageID int, at time.Time) ([]promotion.Promotion, error) {
+	query := `
+	select ` + promotionColumns + `
+	from promotions pr
+	join product_variants v on v.variant_id = $1
+	join products p on p.product_id = v.product_id
+	where pr.starts_at <= $3
+	and ( pr.ends_at is null or pr.ends_at > $3 )
+	and ( pr.storage_id is null or pr.storage_id = $2 )
+	and ( pr.variant_id is null or pr.variant_id = v.variant_id )
+	and ( pr.tag is null or pr.tag = any (string_to_array(p.tags, ',')) )
+	and ( pr.category_id is null or pr.category_id = p.category_id )
+	order by pr.promotion_id`
+
+	return gensql.Select[promotion.Promotion](ts.Context(), SqlxTx(ts), query, variantID, storageID, at)
+}
+
+// EndPromotion завершение действующей или будущей акции в момент at; будущая акция
+// завершается сразу после своего начала и не действует ни одного момента
+func (r *promotionRepository) EndPromotion(ts transaction.Session, promotionID int, at time.Time) error {
+	query := `
+	update promotions
+	set ends_at = greatest($2, starts_at + interval '1 microsecond')
+	where promotion_id = $1
+	and ( ends_at is null or ends_at > $2 )
+	returning promotion_id`
+
+	_, err := gensql.Get[int](ts.Context(), SqlxTx(ts), query, promotionID, at)
+	return err
+}
@@ -0,0 +1,155 @@
+package postgresql
+
+import (
+	"product_storage/internal/entity/purchase"
+	"product_storage/internal/repository"
+	"product_storage/internal/transaction"
+	"product_storage/tools/gensql"
+)
+
+type purchaseRepository struct{}
+
+func NewPurchase() repository.Purchase {
+	return &purchaseRepository{}
+}
+
+// AddSupplier добавление поставщика
+func (r *purchaseRepository) AddSupplier(ts transaction.Session, p purchase.SupplierParams) (supplierID int, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into suppliers
+	( name, contact, phone, email )
+	values ( $1, $2, $3, $4 )
+	returning supplier_id`,
+		p.Name, p.Contact, p.Phone, p.Email).Scan(&supplierID)
+
+	return supplierID, err
+}
+
+// FindSupplierList действующие поставщики
+func (r *purchaseRepository) FindSupplierList(ts transaction.Session) ([]purchase.Supplier, error) {
+	query := `
+	select supplier_id, name, contact, phone, email, added_at
+	from suppliers
+	where removed_at is null
+	order by name`
+
+	return gensql.Select[purchase.Supplier](ts.Context(), SqlxTx(ts), query)
+}
+
+// LoadSupplier действующий поставщик
+func (r *purchaseRepository) LoadSupplier(ts transaction.Session, supplierID int) (purchase.Supplier, error) {
+	query := `
+	select supplier_id, name, contact, phone, email, added_at
+	from suppliers
+	where supplier_id = $1
+	and removed_at is null`
+
+	return gensql.Get[purchase.Supplier](ts.Context(), SqlxTx(ts), query, supplierID)
+}
+
+// AddPurchaseOrder создание заказа поставщику в статусе черновика
+func (r *purchaseRepository) AddPurchaseOrder(ts transaction.Session, p purchase.OrderParams) (orderID int, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into purchase_orders
+	( supplier_id, storage_id, status, comment )
+	values ( $1, $2, $3, $4 )
+	returning order_id`,
+		p.SupplierID, p.StorageID, purchase.StatusDraft, p.Comment).Scan(&orderID)
+
+	return orderID, err
+}
+
+// AddPurchaseOrderLine добавление строки заказа поставщику
+func (r *purchaseRepository) AddPurchaseOrderLine(ts transaction.Session, orderID int, l purchase.OrderLineParams) (lineID int, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into purchase_order_lines
+	( order_id, variant_id, quantity, purchase_price )
+	values ( $1, $2, $3, $4 )
+	returning line_id`,
+		orderID, l.VariantID, l.Quantity, l.PurchasePrice).Scan(&lineID)
+
+	return lineID, err
+}
+
+// LoadPurchaseOrder заказ поставщику без строк
+func (r *purchaseRepository) LoadPurchaseOrder(ts transaction.Session, orderID int) (purchase.Order, error) {
+	query := `
+	select order_id, supplier_id, storage_id, status, comment, created_at, sent_at, closed_at
+	from purchase_orders
+	where order_id = $1`
+
+	return gensql.Get[purchase.Order](ts.Context(), SqlxTx(ts), query, orderID)
+}
+
+// FindPurchaseOrderLineList строки заказа поставщику
+func (r *purchaseRepository) FindPurchaseOrderLineList(ts transaction.Session, orderID int) ([]purchase.OrderLine, error) {
+	query := `
+	select line_id, order_id, variant_id, quantity, received_quantity, purchase_price, closed_at
+	from purchase_order_lines
+	where order_id = $1
+	order by line_id`
+
+	return gensql.Select[purchase.OrderLine](ts.Context(), SqlxTx(ts), query, orderID)
+}
+
+// FindPurchaseOrderList заказы поставщикам, пустой status и supplierID = 0 не ограничивают выборку
+func (r *purchaseRepository) FindPurchaseOrderList(ts transaction.Session, status string, supplierID int) ([]purchase.Order, error) {
+	query := `
+	select order_id, supplier_id, storage_id, status, comment, created_at, sent_at, closed_at
+	from purchase_orders
+	where ($1 = '' or status = $1)
+	and ($2 = 0 or supplier_id = $2)
+	order by order_id desc`
+
+	return gensql.Select[purchase.Order](ts.Context(), SqlxTx(ts), query, status, supplierID)
+}
+
+// UpdatePurchaseOrderStatus смена статуса заказа, при отправке фиксируется дата отправки,
+// при полном приеме или отмене дата закрытия
+func (r *purchaseRepository) UpdatePurchaseOrderStatus(ts transaction.Session, orderID int, status string) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	update purchase_orders
+	set status = $2,
+		sent_at = case when $2 = $3 then now() else sent_at end,
+		closed_at = case when $2 in ($4, $5) then now() else closed_at end
+	where order_id = $1`,
+		orderID, status, purchase.StatusSent, purchase.StatusReceived, purchase.StatusCancelled)
+
+	return err
+}
+
+// AddGoodsReceipt создание документа приема товара по заказу
+func (r *purchaseRepository) AddGoodsReceipt(ts transaction.Session, orderID, storageID int) (receiptID int, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into goods_receipts
+	( order_id, storage_id )
+	values ( $1, $2 )
+	returning receipt_id`,
+		orderID, storageID).Scan(&receiptID)
+
+	return receiptID, err
+}
+
+// AddGoodsReceiptLine добавление строки приема товара
+func (r *purchaseRepository) AddGoodsReceiptLine(ts transaction.Session, l purchase.ReceiptLine) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	insert into goods_receipt_lines
+	( receipt_id, line_id, variant_id, quantity, purchase_price )
+	values ( $1, $2, $3, $4, $5 )`,
+		l.ReceiptID, l.LineID, l.VariantID, l.Quantity, l.PurchasePrice)
+
+	return err
+}
+
+// ReceivePurchaseOrderLine учет принятого кол-ва по строке заказа,
+// строка закрывается, когда принято все заказанное кол-во
+func (r *purchaseRepository) ReceivePurchaseOrderLine(ts transaction.Session, lineID, quantity int) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	update purchase_order_lines
+	set received_quantity = received_quantity + $2,
+		closed_at = case when received_quantity + $2 = quantity then now() else closed_at end
+	where line_id = $1`,
+		lineID, quantity)
+
+	return err
+}
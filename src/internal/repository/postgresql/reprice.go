@@ -0,0 +1,73 @@
+package postgresql
+
+import (
+	"product_storage/internal/entity/reprice"
+	"product_storage/internal/repository"
+	"product_storage/internal/transaction"
+	"product_storage/tools/gensql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type repriceRepository struct{}
+
+func NewReprice() repository.Reprice {
+	return &repriceRepository{}
+}
+
+// FindLineList варианты продуктов, выбранных по тегу, категории или списку id, с ценой, действующей
+// в момент at, и началом ближайшей цены, запланированной после at. У вариантов без действующей цены old_price равна 0
+func (r *repriceRepository) FindLineList(ts transaction.Session, p reprice.Params, at time.Time) ([]reprice.Line, error) {
+	query := `
+	select v.variant_id, p.product_id, p.name as product_name, coalesce(cp.price, 0) as price,
+		( select min(sp.start_date)
+		from product_prices sp
+		where sp.variant_id = v.variant_id
+		and sp.start_date > $4 ) as scheduled_at
+	from product_variants v
+	join products p on p.product_id = v.product_id
+	left join lateral (
+		select pp.price
+		from product_prices pp
+		where pp.variant_id = v.variant_id
+		and pp.start_date <= $4
+		and ( pp.end_date is null or pp.end_date > $4 )
+		order by pp.start_date desc
+		limit 1
+	) cp on true
+	where ( $1 = '' or $1 = any (string_to_array(p.tags, ',')) )
+	and ( $2 = 0 or p.category_id = $2 )
+	and ( cardinality($3::int[]) = 0 or p.product_id = any ($3) )
+	order by p.name, v.variant_id`
+
+	return gensql.Select[reprice.Line](ts.Context(), SqlxTx(ts), query,
+		p.Tag, p.CategoryID, pq.Array(p.ProductIDList), at)
+}
+
+// ReplacePrice новая цена варианта с момента at, действующие в этот момент цены закрываются.
+// Если после at уже запланирована другая цена, новая цена действует до ее начала
+func (r *repriceRepository) ReplacePrice(ts transaction.Session, variantID int, price float64, at time.Time) (priceID int, err error) {
+	_, err = SqlxTx(ts).ExecContext(ts.Context(), `
+	update product_prices
+	set end_date = $2
+	where variant_id = $1
+	and start_date < $2
+	and ( end_date is null or end_date > $2 )`,
+		variantID, at)
+	if err != nil {
+		return 0, err
+	}
+
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into product_prices
+	( variant_id, price, start_date, end_date )
+	select $1, $2, $3, min(start_date)
+	from product_prices
+	where variant_id = $1
+	and start_date > $3
+	returning price_id`,
+		variantID, price, at).Scan(&priceID)
+
+	return priceID, err
+}
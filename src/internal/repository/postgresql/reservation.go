@@ -0,0 +1,110 @@
+package postgresql
+
+import (
+	"product_storage/internal/entity/reservation"
+	"product_storage/internal/repository"
+	"product_storage/internal/transaction"
+	"product_storage/tools/gensql"
+)
+
+type reservationRepository struct{}
+
+func NewReservation() repository.Reservation {
+	return &reservationRepository{}
+}
+
+// AddReservation создание активного резерва
+func (r *reservationRepository) AddReservation(ts transaction.Session, res reservation.Reservation) (reservationID int, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into stock_reservations
+	( variant_id, storage_id, quantity, status, order_ref, expires_at, customer_id )
+	values ( $1, $2, $3, $4, $5, $6, $7 )
+	returning reservation_id`,
+		res.VariantID, res.StorageID, res.Quantity, reservation.StatusActive, res.OrderRef, res.ExpiresAt,
+		res.CustomerID).Scan(&reservationID)
+
+	return reservationID, err
+}
+
+// LoadStockQuantity остаток варианта на складе с блокировкой строки до конца транзакции,
+// чтобы параллельные резервы и продажи не распределили один и тот же остаток
+func (r *reservationRepository) LoadStockQuantity(ts transaction.Session, variantID, storageID int) (int, error) {
+	query := `
+	select quantity
+	from products_in_storage
+	where variant_id = $1 and storage_id = $2
+	for update`
+
+	return gensql.Get[int](ts.Context(), SqlxTx(ts), query, variantID, storageID)
+}
+
+// FindReservedQuantity кол-во варианта, зарезервированное на складе активными неистекшими резервами
+func (r *reservationRepository) FindReservedQuantity(ts transaction.Session, variantID, storageID int) (int, error) {
+	query := `
+	select coalesce(sum(quantity), 0)
+	from stock_reservations
+	where variant_id = $1 and storage_id = $2
+	and status = 'active' and expires_at > now()`
+
+	return gensql.Get[int](ts.Context(), SqlxTx(ts), query, variantID, storageID)
+}
+
+// LoadReservation резерв с блокировкой до конца транзакции
+func (r *reservationRepository) LoadReservation(ts transaction.Session, reservationID int) (reservation.Reservation, error) {
+	query := `
+	select reservation_id, variant_id, storage_id, quantity, status, order_ref,
+		created_at, expires_at, closed_at, sale_id, customer_id
+	from stock_reservations
+	where reservation_id = $1
+	for update`
+
+	return gensql.Get[reservation.Reservation](ts.Context(), SqlxTx(ts), query, reservationID)
+}
+
+// FindReservationList резервы, нулевые variantID, storageID и пустой status не ограничивают выборку
+func (r *reservationRepository) FindReservationList(ts transaction.Session, variantID, storageID int, status string) ([]reservation.Reservation, error) {
+	query := `
+	select reservation_id, variant_id, storage_id, quantity, status, order_ref,
+		created_at, expires_at, closed_at, sale_id, customer_id
+	from stock_reservations
+	where ($1 = 0 or variant_id = $1)
+	and ($2 = 0 or storage_id = $2)
+	and ($3 = '' or status = $3)
+	order by reservation_id desc`
+
+	return gensql.Select[reservation.Reservation](ts.Context(), SqlxTx(ts), query, variantID, storageID, status)
+}
+
+// CloseReservation закрытие резерва продажей или снятием, saleID = 0 если резерв снят
+func (r *reservationRepository) CloseReservation(ts transaction.Session, reservationID int, status string, saleID int) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	update stock_reservations
+	set status = $2,
+		closed_at = now(),
+		sale_id = nullif($3, 0)
+	where reservation_id = $1`,
+		reservationID, status, saleID)
+
+	return err
+}
+
+// ExpireReservationList снятие не более limit активных резервов с истекшим сроком.
+// Резервы, заблокированные другими транзакциями, пропускаются и снимаются при следующем запуске
+func (r *reservationRepository) ExpireReservationList(ts transaction.Session, limit int) ([]reservation.Reservation, error) {
+	query := `
+	update stock_reservations
+	set status = 'expired',
+		closed_at = now()
+	where reservation_id in (
+		select reservation_id
+		from stock_reservations
+		where status = 'active' and expires_at <= now()
+		order by expires_at
+		limit $1
+		for update skip locked
+	)
+	returning reservation_id, variant_id, storage_id, quantity, status, order_ref,
+		created_at, expires_at, closed_at, sale_id, customer_id`
+
+	return gensql.Select[reservation.Reservation](ts.Context(), SqlxTx(ts), query, limit)
+}
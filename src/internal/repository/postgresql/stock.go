@@ -0,0 +1,219 @@
+package postgresql
+
+import (
+	"product_storage/internal/entity/stock"
+	"product_storage/internal/repository"
+	"product_storage/internal/transaction"
+	"product_storage/tools/gensql"
+)
+
+type stockRepository struct{}
+
+func NewStock() repository.Stock {
+	return &stockRepository{}
+}
+
+// DecreaseProductInStock уменьшение кол-ва продукта на складе, если его достаточно;
+// при нехватке продукта возвращается global.ErrNoData
+func (r *stockRepository) DecreaseProductInStock(ts transaction.Session, variantID, storageID, quantity int) (remaining int, err error) {
+	query := `
+	update products_in_storage
+	set quantity = quantity - $3
+	where variant_id = $1
+	and storage_id = $2
+	and quantity >= $3
+	returning quantity`
+
+	return gensql.Get[int](ts.Context(), SqlxTx(ts), query, variantID, storageID, quantity)
+}
+
+// SaveWriteOff запись о списании продукта
+func (r *stockRepository) SaveWriteOff(ts transaction.Session, w stock.WriteOffParams) (writeOffID int, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into stock_write_offs
+	( variant_id, storage_id, quantity, reason, written_off_at, batch_id, stocktake_id )
+	values ( $1, $2, $3, $4, $5, nullif($6, 0), nullif($7, 0) )
+	returning write_off_id`,
+		w.VariantID, w.StorageID, w.Quantity, w.Reason, w.WrittenOffAt, w.BatchID, w.StocktakeID).Scan(&writeOffID)
+
+	return writeOffID, err
+}
+
+// SaveThreshold установка порогов остатка варианта продукта на складе
+func (r *stockRepository) SaveThreshold(ts transaction.Session, t stock.ThresholdParams) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	insert into stock_thresholds
+	( variant_id, storage_id, min_quantity, max_quantity )
+	values ( $1, $2, $3, $4 )
+	on conflict (variant_id, storage_id) do update
+	set min_quantity = excluded.min_quantity,
+		max_quantity = excluded.max_quantity,
+		updated_at = now()`,
+		t.VariantID, t.StorageID, t.MinQuantity, t.MaxQuantity)
+
+	return err
+}
+
+// FindThreshold пороги остатка варианта продукта на складе
+func (r *stockRepository) FindThreshold(ts transaction.Session, variantID, storageID int) (stock.ThresholdParams, error) {
+	query := `
+	select variant_id, storage_id, min_quantity, max_quantity
+	from stock_thresholds
+	where variant_id = $1
+	and storage_id = $2`
+
+	return gensql.Get[stock.ThresholdParams](ts.Context(), SqlxTx(ts), query, variantID, storageID)
+}
+
+// FindLowStockList варианты продуктов с остатком ниже минимального, при storageID = 0 на всех складах.
+// Вариант, отсутствующий на складе, считается с нулевым остатком
+func (r *stockRepository) FindLowStockList(ts transaction.Session, storageID int) ([]stock.LowStock, error) {
+	query := `
+	select t.variant_id, t.storage_id, p.name as product_name, v.weight, v.unit,
+		coalesce(pis.quantity, 0) as quantity,
+		t.min_quantity, t.max_quantity,
+		t.max_quantity - coalesce(pis.quantity, 0) as reorder_quantity
+	from stock_thresholds t
+	join product_variants v on v.variant_id = t.variant_id
+	join products p on p.product_id = v.product_id
+	left join products_in_storage pis on pis.variant_id = t.variant_id and pis.storage_id = t.storage_id
+	where coalesce(pis.quantity, 0) < t.min_quantity
+	and ($1 = 0 or t.storage_id = $1)
+	order by t.storage_id, reorder_quantity desc`
+
+	return gensql.Select[stock.LowStock](ts.Context(), SqlxTx(ts), query, storageID)
+}
+
+// IncreaseProductInStock увеличение кол-ва продукта на складе,
+// если продукта на складе еще нет, он добавляется
+func (r *stockRepository) IncreaseProductInStock(ts transaction.Session, variantID, storageID, quantity int) (total int, err error) {
+	query := `
+	with updated as (
+		update products_in_storage
+		set quantity = quantity + $3
+		where variant_id = $1
+		and storage_id = $2
+		returning quantity
+	), inserted as (
+		insert into products_in_storage
+		( variant_id, storage_id, quantity )
+		select $1, $2, $3
+		where not exists (select 1 from updated)
+		returning quantity
+	)
+	select quantity from updated
+	union all
+	select quantity from inserted`
+
+	return gensql.Get[int](ts.Context(), SqlxTx(ts), query, variantID, storageID, quantity)
+}
+
+// AddBatch добавление партии продукта на склад
+func (r *stockRepository) AddBatch(ts transaction.Session, b stock.Batch) (batchID int, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into stock_batches
+	( variant_id, storage_id, batch_number, produced_at, expires_at, quantity, receipt_id )
+	values ( $1, $2, $3, $4, $5, $6, nullif($7, 0) )
+	returning batch_id`,
+		b.VariantID, b.StorageID, b.BatchNumber, b.ProducedAt, b.ExpiresAt, b.Quantity, b.ReceiptID).Scan(&batchID)
+
+	return batchID, err
+}
+
+// LoadBatchList непустые партии варианта продукта на складе в порядке FEFO, партии блокируются до конца транзакции
+func (r *stockRepository) LoadBatchList(ts transaction.Session, variantID, storageID int) ([]stock.Batch, error) {
+	query := `
+	select batch_id, variant_id, storage_id, batch_number, produced_at, expires_at,
+		quantity, coalesce(receipt_id, 0) as receipt_id, received_at
+	from stock_batches
+	where variant_id = $1
+	and storage_id = $2
+	and quantity > 0
+	order by expires_at nulls last, batch_id
+	for update`
+
+	return gensql.Select[stock.Batch](ts.Context(), SqlxTx(ts), query, variantID, storageID)
+}
+
+// FindBatchList непустые партии варианта продукта, при storageID = 0 на всех складах
+func (r *stockRepository) FindBatchList(ts transaction.Session, variantID, storageID int) ([]stock.Batch, error) {
+	query := `
+	select batch_id, variant_id, storage_id, batch_number, produced_at, expires_at,
+		quantity, coalesce(receipt_id, 0) as receipt_id, received_at
+	from stock_batches
+	where variant_id = $1
+	and ($2 = 0 or storage_id = $2)
+	and quantity > 0
+	order by storage_id, expires_at nulls last, batch_id`
+
+	return gensql.Select[stock.Batch](ts.Context(), SqlxTx(ts), query, variantID, storageID)
+}
+
+// ConsumeBatch уменьшение остатка партии, если его достаточно; при нехватке возвращается global.ErrNoData
+func (r *stockRepository) ConsumeBatch(ts transaction.Session, batchID, quantity int) error {
+	query := `
+	update stock_batches
+	set quantity = quantity - $2
+	where batch_id = $1
+	and quantity >= $2
+	returning batch_id`
+
+	_, err := gensql.Get[int](ts.Context(), SqlxTx(ts), query, batchID, quantity)
+	return err
+}
+
+// TrimBatches уменьшение остатка партий варианта на складе так, чтобы в сумме он не превышал
+// общий остаток total. Остаток партий уменьшается в порядке FEFO
+func (r *stockRepository) TrimBatches(ts transaction.Session, variantID, storageID, total int) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	with batched as (
+		select batch_id, quantity,
+			sum(quantity) over (order by expires_at nulls last, batch_id) - quantity as before,
+			sum(quantity) over () - $3 as excess
+		from stock_batches
+		where variant_id = $1 and storage_id = $2 and quantity > 0
+	)
+	update stock_batches b
+	set quantity = b.quantity - least(x.quantity, x.excess - x.before)
+	from batched x
+	where b.batch_id = x.batch_id
+	and x.excess > x.before`,
+		variantID, storageID, total)
+
+	return err
+}
+
+// FindExpiringBatchList непустые партии, срок годности которых истекает в ближайшие days дней или уже истек,
+// при storageID = 0 на всех складах
+func (r *stockRepository) FindExpiringBatchList(ts transaction.Session, storageID, days int) ([]stock.ExpiringBatch, error) {
+	query := `
+	select b.batch_id, b.variant_id, b.storage_id, b.batch_number, b.produced_at, b.expires_at,
+		b.quantity, coalesce(b.receipt_id, 0) as receipt_id, b.received_at,
+		p.name as product_name, v.weight, v.unit,
+		b.expires_at - current_date as days_left
+	from stock_batches b
+	join product_variants v on v.variant_id = b.variant_id
+	join products p on p.product_id = v.product_id
+	where b.quantity > 0
+	and b.expires_at <= current_date + $2::int
+	and ($1 = 0 or b.storage_id = $1)
+	order by b.expires_at, b.storage_id, b.batch_id`
+
+	return gensql.Select[stock.ExpiringBatch](ts.Context(), SqlxTx(ts), query, storageID, days)
+}
+
+// LoadExpiredBatchList непустые партии с истекшим сроком годности, при storageID = 0 на всех складах;
+// партии блокируются до конца транзакции
+func (r *stockRepository) LoadExpiredBatchList(ts transaction.Session, storageID int) ([]stock.Batch, error) {
+	query := `
+	select batch_id, variant_id, storage_id, batch_number, produced_at, expires_at,
+		quantity, coalesce(receipt_id, 0) as receipt_id, received_at
+	from stock_batches
+	where quantity > 0
+	and expires_at < current_date
+	and ($1 = 0 or storage_id = $1)
+	order by storage_id, variant_id, batch_id
+	for update`
+
+	return gensql.Select[stock.Batch](ts.Context(), SqlxTx(ts), query, storageID)
+}
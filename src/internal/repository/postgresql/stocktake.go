@@ -0,0 +1,115 @@
+package postgresql
+
+import (
+	"product_storage/internal/entity/stocktake"
+	"product_storage/internal/repository"
+	"product_storage/internal/transaction"
+	"product_storage/tools/gensql"
+)
+
+type stocktakeRepository struct{}
+
+func NewStocktake() repository.Stocktake {
+	return &stocktakeRepository{}
+}
+
+// AddStocktake создание открытой инвентаризации склада
+func (r *stocktakeRepository) AddStocktake(ts transaction.Session, p stocktake.Params) (stocktakeID int, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into stocktakes
+	( storage_id, status, comment )
+	values ( $1, $2, $3 )
+	returning stocktake_id`,
+		p.StorageID, stocktake.StatusOpen, p.Comment).Scan(&stocktakeID)
+
+	return stocktakeID, err
+}
+
+// SnapshotStocktakeLines фиксация учетных остатков склада на момент начала инвентаризации
+func (r *stocktakeRepository) SnapshotStocktakeLines(ts transaction.Session, stocktakeID, storageID int) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	insert into stocktake_lines
+	( stocktake_id, variant_id, expected_quantity )
+	select $1, variant_id, sum(quantity)
+	from products_in_storage
+	where storage_id = $2
+	group by variant_id`,
+		stocktakeID, storageID)
+
+	return err
+}
+
+// LoadStocktake документ инвентаризации
+func (r *stocktakeRepository) LoadStocktake(ts transaction.Session, stocktakeID int) (stocktake.Stocktake, error) {
+	query := `
+	select stocktake_id, storage_id, status, comment, created_at, closed_at
+	from stocktakes
+	where stocktake_id = $1`
+
+	return gensql.Get[stocktake.Stocktake](ts.Context(), SqlxTx(ts), query, stocktakeID)
+}
+
+// FindStocktakeList инвентаризации, storageID = 0 и пустой status не ограничивают выборку
+func (r *stocktakeRepository) FindStocktakeList(ts transaction.Session, storageID int, status string) ([]stocktake.Stocktake, error) {
+	query := `
+	select stocktake_id, storage_id, status, comment, created_at, closed_at
+	from stocktakes
+	where ($1 = 0 or storage_id = $1)
+	and ($2 = '' or status = $2)
+	order by stocktake_id desc`
+
+	return gensql.Select[stocktake.Stocktake](ts.Context(), SqlxTx(ts), query, storageID, status)
+}
+
+// FindStocktakeLineList строки инвентаризации с названиями продуктов
+func (r *stocktakeRepository) FindStocktakeLineList(ts transaction.Session, stocktakeID int) ([]stocktake.Line, error) {
+	query := `
+	select l.line_id, l.stocktake_id, l.variant_id, p.name as product_name,
+		l.expected_quantity, l.counted_quantity, l.counted_at, l.adjustment
+	from stocktake_lines l
+	join product_variants v on v.variant_id = l.variant_id
+	join products p on p.product_id = v.product_id
+	where l.stocktake_id = $1
+	order by l.variant_id`
+
+	return gensql.Select[stocktake.Line](ts.Context(), SqlxTx(ts), query, stocktakeID)
+}
+
+// SaveCount запись посчитанного кол-ва варианта. Вариант, которого не было в учетных остатках,
+// добавляется с нулевым ожидаемым кол-вом
+func (r *stocktakeRepository) SaveCount(ts transaction.Session, stocktakeID int, c stocktake.CountParams, accumulate bool) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	insert into stocktake_lines
+	( stocktake_id, variant_id, expected_quantity, counted_quantity, counted_at )
+	values ( $1, $2, 0, $3, now() )
+	on conflict (stocktake_id, variant_id) do update
+	set counted_quantity = case when $4 then coalesce(stocktake_lines.counted_quantity, 0) + excluded.counted_quantity
+			else excluded.counted_quantity end,
+		counted_at = now()`,
+		stocktakeID, c.VariantID, c.CountedQuantity, accumulate)
+
+	return err
+}
+
+// SetLineAdjustment запись проведенной корректировки остатка по строке
+func (r *stocktakeRepository) SetLineAdjustment(ts transaction.Session, lineID, adjustment int) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	update stocktake_lines
+	set adjustment = $2
+	where line_id = $1`,
+		lineID, adjustment)
+
+	return err
+}
+
+// UpdateStocktakeStatus закрытие инвентаризации проведением или отменой
+func (r *stocktakeRepository) UpdateStocktakeStatus(ts transaction.Session, stocktakeID int, status string) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	update stocktakes
+	set status = $2,
+		closed_at = case when $2 = $3 then closed_at else now() end
+	where stocktake_id = $1`,
+		stocktakeID, status, stocktake.StatusOpen)
+
+	return err
+}
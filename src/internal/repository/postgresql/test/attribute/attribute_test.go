@@ -0,0 +1,61 @@
+package attribute_test
+
+import (
+	"context"
+	"product_storage/internal/entity/attribute"
+	"product_storage/internal/entity/product"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/pgdb"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProductListByAttributes(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	categoryID, err := repo.Repository.Attribute.AddCategory(ts, "Тестовая категория")
+	r.NoError(err)
+	r.NoError(repo.Repository.Attribute.AddDefinition(ts, attribute.Definition{
+		CategoryID: categoryID, Code: "country", Name: "Страна", Type: attribute.TypeEnum, Options: []string{"Россия", "Беларусь"},
+	}))
+
+	definitionList, err := repo.Repository.Attribute.FindDefinitionList(ts, categoryID)
+	r.NoError(err)
+	r.Len(definitionList, 1)
+	r.Equal([]string{"Россия", "Беларусь"}, definitionList[0].Options)
+
+	for i, fat := range []float64{3.2, 1.5} {
+		_, err = repo.Repository.Product.AddProduct(ts, product.ProductParams{
+			Name:       "Тестовое молоко " + string(rune('A'+i)),
+			AddetAt:    time.Now(),
+			Tags:       "молоко",
+			CategoryID: categoryID,
+			Attributes: attribute.Values{"country": "Россия", "fat": fat},
+		})
+		r.NoError(err)
+	}
+
+	// числа сравниваются в текстовом виде
+	productList, err := repo.Repository.Product.FindProductListByAttributes(ts,
+		attribute.Filter{CategoryID: categoryID, Values: map[string]string{"fat": "3.2"}}, "молоко", "", 10)
+	r.NoError(err)
+	r.Len(productList, 1)
+	r.Equal("Тестовое молоко A", productList[0].Name)
+
+	productList, err = repo.Repository.Product.FindProductListByAttributes(ts,
+		attribute.Filter{CategoryID: categoryID, Values: map[string]string{"country": "Россия"}}, "", "", 10)
+	r.NoError(err)
+	r.Len(productList, 2)
+}
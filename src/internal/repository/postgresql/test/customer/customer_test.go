@@ -0,0 +1,127 @@
+package customer_test
+
+import (
+	"context"
+	"product_storage/internal/entity/customer"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/product"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/pgdb"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindCustomerList(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	retailID, err := repo.Repository.Customer.AddCustomer(ts, customer.Params{Name: "Тестовый Иван", Phone: "+79001112233", Type: customer.TypeRetail})
+	r.NoError(err)
+	wholesaleID, err := repo.Repository.Customer.AddCustomer(ts, customer.Params{Name: "Тестовый опт", Type: customer.TypeWholesale})
+	r.NoError(err)
+
+	customerList, err := repo.Repository.Customer.FindCustomerList(ts, "тестовый", "", 10)
+	r.NoError(err)
+	r.Len(customerList, 2)
+
+	// поиск по части телефона в любом написании
+	customerList, err = repo.Repository.Customer.FindCustomerList(ts, "111-22", "", 10)
+	r.NoError(err)
+	r.Len(customerList, 1)
+	r.Equal(retailID, customerList[0].CustomerID)
+
+	customerList, err = repo.Repository.Customer.FindCustomerList(ts, "тестовый", customer.TypeWholesale, 10)
+	r.NoError(err)
+	r.Len(customerList, 1)
+	r.Equal(wholesaleID, customerList[0].CustomerID)
+
+	// удаленный покупатель не находится и освобождает телефон
+	r.NoError(repo.Repository.Customer.RemoveCustomer(ts, retailID))
+	_, err = repo.Repository.Customer.LoadCustomer(ts, retailID)
+	r.Equal(global.ErrNoData, err)
+	_, err = repo.Repository.Customer.FindCustomerIDByPhone(ts, "+79001112233")
+	r.Equal(global.ErrNoData, err)
+	r.Equal(global.ErrNoData, repo.Repository.Customer.RemoveCustomer(ts, retailID))
+}
+
+func TestSetGroupPrice(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	variantID, err := repo.Repository.Product.AddProductVariantList(ts, 1, product.Variant{Weight: 1, Unit: "кг"})
+	r.NoError(err)
+
+	first := time.Now().Add(-time.Hour)
+	second := time.Now()
+
+	_, err = repo.Repository.Customer.SetGroupPrice(ts, customer.GroupPriceParams{VariantID: variantID, CustomerType: customer.TypeWholesale, Price: 90}, first)
+	r.NoError(err)
+	_, err = repo.Repository.Customer.SetGroupPrice(ts, customer.GroupPriceParams{VariantID: variantID, CustomerType: customer.TypeWholesale, Price: 85}, second)
+	r.NoError(err)
+
+	// новая цена закрывает интервал предыдущей
+	price, err := repo.Repository.Customer.FindGroupPrice(ts, variantID, customer.TypeWholesale, first.Add(time.Minute))
+	r.NoError(err)
+	r.Equal(90.0, price)
+
+	price, err = repo.Repository.Customer.FindGroupPrice(ts, variantID, customer.TypeWholesale, second.Add(time.Minute))
+	r.NoError(err)
+	r.Equal(85.0, price)
+
+	_, err = repo.Repository.Customer.FindGroupPrice(ts, variantID, customer.TypeRetail, second)
+	r.Equal(global.ErrNoData, err)
+
+	priceList, err := repo.Repository.Customer.FindGroupPriceList(ts, customer.TypeWholesale)
+	r.NoError(err)
+	r.Len(priceList, 1)
+}
+
+func TestLoadCustomerSaleTotal(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	customerID, err := repo.Repository.Customer.AddCustomer(ts, customer.Params{Name: "Тестовый опт", Type: customer.TypeWholesale})
+	r.NoError(err)
+
+	for _, total := range []float64{10.5, 20, 30} {
+		_, err = repo.Repository.Product.SaveSale(ts, product.SaleParams{VariantID: 1, StorageID: 3, Quantity: 1, TotalPrice: total, SoldAt: time.Now(), CustomerID: customerID})
+		r.NoError(err)
+	}
+
+	// итоги считаются по всем продажам, независимо от кол-ва выведенных последних
+	saleList, err := repo.Repository.Customer.FindCustomerSaleList(ts, customerID, 2)
+	r.NoError(err)
+	r.Len(saleList, 2)
+
+	total, err := repo.Repository.Customer.LoadCustomerSaleTotal(ts, customerID)
+	r.NoError(err)
+	r.Equal(3, total.SaleCount)
+	r.Equal(60.5, total.TotalSpent)
+}
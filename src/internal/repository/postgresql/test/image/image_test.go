@@ -0,0 +1,61 @@
+package image_test
+
+import (
+	"context"
+	"product_storage/internal/entity/image"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/pgdb"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrimaryImage(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	var imageIDList []int
+	for _, name := range []string{"a", "b"} {
+		position, err := repo.Repository.Image.NextImagePosition(ts, 1)
+		r.NoError(err)
+
+		imageID, err := repo.Repository.Image.AddImage(ts, image.Image{
+			ProductID:   1,
+			BlobKey:     "test/" + name + ".png",
+			ThumbKey:    "test/" + name + "_thumb.png",
+			ContentType: image.ContentTypePNG,
+			Width:       10,
+			Height:      10,
+			Position:    position,
+			IsPrimary:   position == 0,
+		})
+		r.NoError(err)
+		imageIDList = append(imageIDList, imageID)
+	}
+
+	// основным может быть только одно изображение продукта
+	r.NoError(repo.Repository.Image.SetPrimaryImage(ts, 1, imageIDList[1]))
+
+	imageList, err := repo.Repository.Image.FindImageList(ts, 1)
+	r.NoError(err)
+	r.Equal(imageIDList[1], imageList[0].ImageID)
+	r.True(imageList[0].IsPrimary)
+	r.False(imageList[1].IsPrimary)
+
+	// после удаления основного изображения основным становится первое по порядку
+	r.NoError(repo.Repository.Image.DeleteImage(ts, imageIDList[1]))
+	r.NoError(repo.Repository.Image.PromoteFirstImage(ts, 1))
+
+	img, err := repo.Repository.Image.LoadImage(ts, imageIDList[0])
+	r.NoError(err)
+	r.True(img.IsPrimary)
+}
@@ -0,0 +1,61 @@
+package location_test
+
+import (
+	"context"
+	"product_storage/internal/entity/location"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/pgdb"
+	"product_storage/tools/sqlnull"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrimLocationStock(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	zoneID, err := repo.Repository.Location.AddLocation(ts, location.Location{StorageID: 1, Kind: location.KindZone, Code: "T", Path: "T"})
+	r.NoError(err)
+
+	var binIDList []int
+	for _, code := range []string{"1", "2"} {
+		binID, err := repo.Repository.Location.AddLocation(ts, location.Location{
+			StorageID: 1, ParentID: sqlnull.NewInt64(zoneID), Kind: location.KindBin, Code: code, Path: "T/" + code,
+		})
+		r.NoError(err)
+		binIDList = append(binIDList, binID)
+	}
+
+	// вариант 3 на складе 1 в количестве 5, по 2 единицы в каждой ячейке
+	for _, binID := range binIDList {
+		r.NoError(repo.Repository.Location.PutLocationStock(ts, binID, 3, 2))
+	}
+
+	unplaced, err := repo.Repository.Location.LoadUnplacedQuantity(ts, 3, 1)
+	r.NoError(err)
+	r.Equal(1, unplaced)
+
+	// остаток снизился до 1, продукт забирается из ячеек в порядке адресов
+	r.NoError(repo.Repository.Location.TrimLocationStock(ts, 3, 1, 1))
+
+	stockList, err := repo.Repository.Location.FindLocationStockList(ts, 1)
+	r.NoError(err)
+
+	located := map[int]int{}
+	for _, s := range stockList {
+		if s.VariantID == 3 {
+			located[s.LocationID] = s.Quantity
+		}
+	}
+	r.Equal(map[int]int{binIDList[1]: 1}, located)
+}
@@ -1,6 +1,7 @@
 package logger_test
 
 import (
+	"context"
 	"product_storage/internal/entity/log"
 	"product_storage/internal/repository/postgresql"
 	"product_storage/internal/transaction"
@@ -21,7 +22,7 @@ func TestSaveLog(t *testing.T) {
 	repo := postgresql.NewLoggerRepository()
 
 	ts := transaction.NewSQLSession(db)
-	err := ts.Start()
+	err := ts.Start(context.Background())
 	r.NoError(err)
 	defer ts.Rollback()
 
@@ -46,7 +47,7 @@ func TestSaveLogWithReturnID(t *testing.T) {
 	repo := postgresql.NewLoggerRepository()
 
 	ts := transaction.NewSQLSession(db)
-	err := ts.Start()
+	err := ts.Start(context.Background())
 	r.NoError(err)
 	defer ts.Rollback()
 
@@ -73,7 +74,7 @@ func TestSaveLogDetails(t *testing.T) {
 	repo := postgresql.NewLoggerRepository()
 
 	ts := transaction.NewSQLSession(db)
-	err := ts.Start()
+	err := ts.Start(context.Background())
 	r.NoError(err)
 	defer ts.Rollback()
 
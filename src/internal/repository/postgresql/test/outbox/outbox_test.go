@@ -0,0 +1,64 @@
+package outbox_test
+
+import (
+	"context"
+	"product_storage/internal/entity/event"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/pgdb"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutbox(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	e, err := event.NewEvent(event.ProductCreated, 1, event.ProductCreatedPayload{ProductID: 1, Name: "test"})
+	r.NoError(err)
+
+	eventID, err := repo.Repository.Outbox.SaveEvent(ts, e)
+	r.NoError(err)
+	r.NotEmpty(eventID)
+
+	eventList, err := repo.Repository.Outbox.LoadPendingEventList(ts, 1000)
+	r.NoError(err)
+
+	var saved event.Event
+	for _, v := range eventList {
+		if v.EventID == eventID {
+			saved = v
+		}
+	}
+	r.Equal(event.ProductCreated, saved.EventType)
+	r.JSONEq(`{"product_id":1,"name":"test","tags":""}`, string(saved.Payload))
+
+	// после неудачной публикации событие откладывается до следующей попытки
+	err = repo.Repository.Outbox.MarkFailed(ts, eventID, time.Now().Add(time.Hour), "connection refused")
+	r.NoError(err)
+
+	eventList, _ = repo.Repository.Outbox.LoadPendingEventList(ts, 1000)
+	for _, v := range eventList {
+		r.NotEqual(eventID, v.EventID)
+	}
+
+	// опубликованное событие больше не загружается
+	eventID, err = repo.Repository.Outbox.SaveEvent(ts, e)
+	r.NoError(err)
+	r.NoError(repo.Repository.Outbox.MarkPublished(ts, eventID))
+
+	eventList, _ = repo.Repository.Outbox.LoadPendingEventList(ts, 1000)
+	for _, v := range eventList {
+		r.NotEqual(eventID, v.EventID)
+	}
+}
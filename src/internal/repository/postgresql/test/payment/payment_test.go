@@ -0,0 +1,60 @@
+package payment_test
+
+import (
+	"context"
+	"product_storage/internal/entity/payment"
+	"product_storage/internal/entity/product"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/pgdb"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindZTotalList(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	start := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+
+	splitID, err := repo.Repository.Product.SaveSale(ts, product.SaleParams{VariantID: 1, StorageID: 3, Quantity: 2, TotalPrice: 19.99, SoldAt: start.Add(time.Hour)})
+	r.NoError(err)
+	_, err = repo.Repository.Payment.AddPayment(ts, splitID, payment.Params{Method: payment.MethodCard, Amount: 10})
+	r.NoError(err)
+	_, err = repo.Repository.Payment.AddPayment(ts, splitID, payment.Params{Method: payment.MethodCash, Amount: 9.99})
+	r.NoError(err)
+
+	// продажа следующего дня в отчет не попадает
+	nextID, err := repo.Repository.Product.SaveSale(ts, product.SaleParams{VariantID: 1, StorageID: 3, Quantity: 1, TotalPrice: 5, SoldAt: end})
+	r.NoError(err)
+	_, err = repo.Repository.Payment.AddPayment(ts, nextID, payment.Params{Method: payment.MethodCash, Amount: 5})
+	r.NoError(err)
+
+	paymentList, err := repo.Repository.Payment.FindPaymentList(ts, splitID)
+	r.NoError(err)
+	r.Len(paymentList, 2)
+	r.Equal(payment.MethodCard, paymentList[0].Method)
+
+	storageList, err := repo.Repository.Payment.FindZStorageTotalList(ts, start, end, 3)
+	r.NoError(err)
+	r.Len(storageList, 1)
+	r.Equal(1, storageList[0].SaleCount)
+	r.Equal(19.99, storageList[0].Revenue)
+
+	methodList, err := repo.Repository.Payment.FindZMethodTotalList(ts, start, end, 3)
+	r.NoError(err)
+	r.Len(methodList, 2)
+	r.Equal(payment.MethodCard, methodList[0].Method)
+	r.Equal(10.0, methodList[0].Amount)
+}
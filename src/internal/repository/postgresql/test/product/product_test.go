@@ -1,6 +1,7 @@
 package product_test
 
 import (
+	"context"
 	"product_storage/internal/entity/product"
 	"product_storage/internal/entity/stock"
 	"product_storage/internal/repository/postgresql"
@@ -23,7 +24,7 @@ func TestAddProduct(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	// Первый тестовый случай - успешное добавление продукта
@@ -61,7 +62,7 @@ func TestAddProductVariantList(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	id := 1
@@ -69,7 +70,7 @@ func TestAddProductVariantList(t *testing.T) {
 		Weight: 200,
 		Unit:   "г",
 	}
-	err := repo.Repository.Product.AddProductVariantList(ts, id, variant)
+	_, err := repo.Repository.Product.AddProductVariantList(ts, id, variant)
 	r.NoError(err)
 
 	id = -1
@@ -77,7 +78,7 @@ func TestAddProductVariantList(t *testing.T) {
 		Weight: 300,
 		Unit:   "кг",
 	}
-	err = repo.Repository.Product.AddProductVariantList(ts, id, variant)
+	_, err = repo.Repository.Product.AddProductVariantList(ts, id, variant)
 	r.Error(err)
 }
 
@@ -89,7 +90,7 @@ func TestCheckExists(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	productPrice := product.ProductPriceParams{
@@ -123,7 +124,7 @@ func TestUpdateProductPrice(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	expectedProductPrice := product.ProductPriceParams{
@@ -154,7 +155,7 @@ func TestAddProductPrice(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	startDate, err := time.Parse("02.01.2006", "01.07.2023")
@@ -192,7 +193,7 @@ func TestCheckProductsInStock(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	productInStock := stock.ProductInStockParams{
@@ -221,7 +222,7 @@ func TestUpdateProductsnStock(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	productInStock := stock.ProductInStockParams{
@@ -254,7 +255,7 @@ func TestAddProductInStock(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	expectedProduct := stock.ProductInStockParams{
@@ -289,7 +290,7 @@ func TestLoadProductInfo(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	product := product.ProductParams{
@@ -319,7 +320,7 @@ func TestFindProductVariantList(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	varquery := product.Variant{
@@ -328,7 +329,7 @@ func TestFindProductVariantList(t *testing.T) {
 	}
 
 	id := 2
-	err := repo.Repository.Product.AddProductVariantList(ts, id, varquery)
+	_, err := repo.Repository.Product.AddProductVariantList(ts, id, varquery)
 	r.NoError(err)
 
 	variants, err := repo.Repository.Product.FindProductVariantList(ts, id)
@@ -360,7 +361,7 @@ func TestFindCurrentPrice(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	pp := product.ProductPriceParams{
@@ -392,7 +393,7 @@ func TestInStorages(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	var id int
@@ -416,6 +417,51 @@ func TestInStorages(t *testing.T) {
 	r.NotEmpty(inStorages)
 }
 
+func TestFindVariantTreeByIDList(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	// варианты продуктов из начальных данных: у продукта 1 и 2 по три варианта
+	variantList, err := repo.Repository.Product.FindVariantListByProductIDList(ts, []int{1, 2})
+	r.NoError(err)
+	r.Len(variantList, 6)
+
+	variantIDList := make([]int, 0, len(variantList))
+	for _, v := range variantList {
+		r.Contains([]int{1, 2}, v.ProductID)
+		variantIDList = append(variantIDList, v.VariantID)
+	}
+
+	_, err = postgresql.SqlxTx(ts).Exec(
+		`insert into product_prices
+		 ( variant_id, price, start_date )
+		 values( $1, $2, $3 )`,
+		variantIDList[0], 14.99, time.Now().Add(-time.Minute))
+	r.NoError(err)
+
+	priceList, err := repo.Repository.Product.FindCurrentPriceListByVariantIDList(ts, variantIDList)
+	r.NoError(err)
+	r.NotEmpty(priceList)
+
+	storageList, err := repo.Repository.Product.FindStorageListByVariantIDList(ts, variantIDList)
+	r.NoError(err)
+	r.NotEmpty(storageList)
+	for _, s := range storageList {
+		r.Contains(variantIDList, s.VariantID)
+	}
+
+	_, err = repo.Repository.Product.FindVariantListByProductIDList(ts, []int{-1})
+	r.Error(err)
+}
+
 func TestFindProductListByTag(t *testing.T) {
 	r := require.New(t)
 
@@ -425,7 +471,7 @@ func TestFindProductListByTag(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	p1 := product.ProductParams{
@@ -480,7 +526,7 @@ func TestFindStockVariantList(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	storageId := 1
@@ -503,7 +549,7 @@ func TestFindPrice(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	var variantID int
@@ -535,7 +581,7 @@ func TestSaveSale(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	saleQuery := product.SaleParams{
@@ -569,7 +615,7 @@ func TestFindSaleList(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	startDate, err := time.Parse("02.01.2006", "01.07.2023")
@@ -595,7 +641,7 @@ func TestFindSaleListByFilters(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	startDate, err := time.Parse("02.01.2006", "01.07.2023")
@@ -637,10 +683,10 @@ func TestLoadStockList(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
-	stockList, err := repo.Repository.Product.LoadStockList(ts)
+	stockList, err := repo.Repository.Product.LoadStockList(ts, false)
 	r.NoError(err)
 	r.NotEmpty(stockList)
 }
@@ -654,11 +700,12 @@ func TestAddStock(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
 	stockParams := stock.StockParams{
 		StorageName: "sasa",
+		StorageType: stock.StorageTypeWarehouse,
 		Added_at:    sqlnull.NewNullTime(time.Now()),
 	}
 
@@ -676,14 +723,27 @@ func TestDelete(t *testing.T) {
 	repo := rimport.NewRepositoryImports(sm)
 
 	ts := sm.CreateSession()
-	ts.Start()
+	ts.Start(context.Background())
 	defer ts.Rollback()
 
-	stockParams := stock.StockParams{
+	stockID, err := repo.Repository.Product.AddStock(ts, stock.StockParams{
 		StorageName: "sasa",
-		StorageID:   1,
-	}
+		StorageType: stock.StorageTypeWarehouse,
+		Added_at:    sqlnull.NewNullTime(time.Now()),
+	})
+	r.NoError(err)
 
-	err := repo.Repository.Product.DeleteStock(ts, stockParams)
+	err = repo.Repository.Product.DeleteStock(ts, stockID)
 	r.NoError(err)
+
+	// удаленный склад сохраняется, но не выводится в списке по умолчанию
+	s, err := repo.Repository.Product.LoadStorage(ts, stockID)
+	r.NoError(err)
+	r.True(s.RemovedAt.Valid)
+
+	stockList, err := repo.Repository.Product.LoadStockList(ts, false)
+	r.NoError(err)
+	for _, s := range stockList {
+		r.NotEqual(stockID, s.StorageID)
+	}
 }
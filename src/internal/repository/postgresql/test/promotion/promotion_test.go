@@ -0,0 +1,75 @@
+package promotion_test
+
+import (
+	"context"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/product"
+	"product_storage/internal/entity/promotion"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/pgdb"
+	"product_storage/tools/sqlnull"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindApplicablePromotionList(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	productID, err := repo.Repository.Product.AddProduct(ts, product.ProductParams{
+		Name:    "Тестовый йогурт",
+		AddetAt: time.Now(),
+		Tags:    "йогурт,молочное",
+	})
+	r.NoError(err)
+
+	variantID, err := repo.Repository.Product.AddProductVariantList(ts, productID, product.Variant{Weight: 200, Unit: "г"})
+	r.NoError(err)
+
+	now := time.Now()
+	byTag := promotion.Promotion{Name: "Молочная неделя", Kind: promotion.KindPercent, Value: 10,
+		Tag: sqlnull.NewString("молочное"), StartsAt: now.Add(-time.Hour)}
+	otherStorage := promotion.Promotion{Name: "Только на складе 2", Kind: promotion.KindPercent, Value: 20,
+		VariantID: sqlnull.NewInt64(variantID), StorageID: sqlnull.NewInt64(2), StartsAt: now.Add(-time.Hour)}
+	future := promotion.Promotion{Name: "Будущая", Kind: promotion.KindFixed, Value: 5,
+		VariantID: sqlnull.NewInt64(variantID), StartsAt: now.Add(time.Hour)}
+
+	byTag.PromotionID, err = repo.Repository.Promotion.AddPromotion(ts, byTag)
+	r.NoError(err)
+	_, err = repo.Repository.Promotion.AddPromotion(ts, otherStorage)
+	r.NoError(err)
+	futureID, err := repo.Repository.Promotion.AddPromotion(ts, future)
+	r.NoError(err)
+
+	// акция по тегу подходит, акция другого склада и будущая акция нет
+	promotionList, err := repo.Repository.Promotion.FindApplicablePromotionList(ts, variantID, 1, now)
+	r.NoError(err)
+	r.Len(promotionList, 1)
+	r.Equal(byTag.PromotionID, promotionList[0].PromotionID)
+
+	promotionList, err = repo.Repository.Promotion.FindApplicablePromotionList(ts, variantID, 2, now)
+	r.NoError(err)
+	r.Len(promotionList, 2)
+
+	// завершенная акция больше не применяется, повторно ее завершить нельзя
+	r.NoError(repo.Repository.Promotion.EndPromotion(ts, byTag.PromotionID, now))
+	_, err = repo.Repository.Promotion.FindApplicablePromotionList(ts, variantID, 1, now)
+	r.Equal(global.ErrNoData, err)
+	r.Equal(global.ErrNoData, repo.Repository.Promotion.EndPromotion(ts, byTag.PromotionID, now))
+
+	// будущая акция завершается до начала и не начинает действовать
+	r.NoError(repo.Repository.Promotion.EndPromotion(ts, futureID, now))
+	_, err = repo.Repository.Promotion.FindApplicablePromotionList(ts, variantID, 1, now.Add(2*time.Hour))
+	r.Equal(global.ErrNoData, err)
+}
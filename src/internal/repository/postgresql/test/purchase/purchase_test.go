@@ -0,0 +1,54 @@
+package purchase_test
+
+import (
+	"context"
+	"product_storage/internal/entity/purchase"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/pgdb"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurchaseOrder(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	supplierID, err := repo.Repository.Purchase.AddSupplier(ts, purchase.SupplierParams{Name: "test supplier"})
+	r.NoError(err)
+
+	orderID, err := repo.Repository.Purchase.AddPurchaseOrder(ts, purchase.OrderParams{SupplierID: supplierID, StorageID: 1})
+	r.NoError(err)
+
+	lineID, err := repo.Repository.Purchase.AddPurchaseOrderLine(ts, orderID, purchase.OrderLineParams{VariantID: 3, Quantity: 4, PurchasePrice: 10})
+	r.NoError(err)
+
+	r.NoError(repo.Repository.Purchase.UpdatePurchaseOrderStatus(ts, orderID, purchase.StatusSent))
+
+	order, err := repo.Repository.Purchase.LoadPurchaseOrder(ts, orderID)
+	r.NoError(err)
+	r.Equal(purchase.StatusSent, order.Status)
+	r.True(order.SentAt.Valid)
+
+	r.NoError(repo.Repository.Purchase.ReceivePurchaseOrderLine(ts, lineID, 4))
+
+	lines, err := repo.Repository.Purchase.FindPurchaseOrderLineList(ts, orderID)
+	r.NoError(err)
+	r.Len(lines, 1)
+	r.Equal(4, lines[0].ReceivedQuantity)
+	r.True(lines[0].ClosedAt.Valid)
+
+	// вариант 3 на складе 1 в количестве 5
+	total, err := repo.Repository.Stock.IncreaseProductInStock(ts, 3, 1, 4)
+	r.NoError(err)
+	r.Equal(9, total)
+}
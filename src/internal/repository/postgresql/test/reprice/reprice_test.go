@@ -0,0 +1,118 @@
+package reprice_test
+
+import (
+	"context"
+	"product_storage/internal/entity/product"
+	"product_storage/internal/entity/reprice"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/pgdb"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplacePrice(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	productID, err := repo.Repository.Product.AddProduct(ts, product.ProductParams{
+		Name:    "Тестовый сыр",
+		AddetAt: time.Now(),
+		Tags:    "сыр_для_переоценки",
+	})
+	r.NoError(err)
+
+	pricedID, err := repo.Repository.Product.AddProductVariantList(ts, productID, product.Variant{Weight: 200, Unit: "г"})
+	r.NoError(err)
+	unpricedID, err := repo.Repository.Product.AddProductVariantList(ts, productID, product.Variant{Weight: 500, Unit: "г"})
+	r.NoError(err)
+
+	start := time.Now().Add(-time.Hour)
+	_, err = repo.Repository.Product.AddProductPrice(ts, product.ProductPriceParams{VariantID: pricedID, Price: 300, StartDate: start})
+	r.NoError(err)
+
+	params := reprice.Params{Tag: "сыр_для_переоценки", Operation: reprice.OpPercent, Value: 10}
+	lineList, err := repo.Repository.Reprice.FindLineList(ts, params, time.Now())
+	r.NoError(err)
+	r.Len(lineList, 2)
+
+	prices := map[int]float64{}
+	for _, l := range lineList {
+		prices[l.VariantID] = l.OldPrice
+	}
+	r.Equal(map[int]float64{pricedID: 300, unpricedID: 0}, prices)
+
+	// старая цена закрывается моментом начала новой
+	at := time.Now()
+	_, err = repo.Repository.Reprice.ReplacePrice(ts, pricedID, 330, at)
+	r.NoError(err)
+
+	lineList, err = repo.Repository.Reprice.FindLineList(ts, reprice.Params{ProductIDList: []int{productID}}, at.Add(time.Second))
+	r.NoError(err)
+	for _, l := range lineList {
+		if l.VariantID == pricedID {
+			r.Equal(330.0, l.OldPrice)
+		}
+	}
+
+	lineList, err = repo.Repository.Reprice.FindLineList(ts, reprice.Params{ProductIDList: []int{productID}}, at.Add(-time.Minute))
+	r.NoError(err)
+	for _, l := range lineList {
+		if l.VariantID == pricedID {
+			r.Equal(300.0, l.OldPrice)
+		}
+	}
+}
+
+func TestReplacePriceBeforeScheduled(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	productID, err := repo.Repository.Product.AddProduct(ts, product.ProductParams{Name: "Тестовый творог", AddetAt: time.Now()})
+	r.NoError(err)
+	variantID, err := repo.Repository.Product.AddProductVariantList(ts, productID, product.Variant{Weight: 200, Unit: "г"})
+	r.NoError(err)
+
+	at := time.Now()
+	scheduledAt := at.Add(24 * time.Hour)
+	_, err = repo.Repository.Product.AddProductPrice(ts, product.ProductPriceParams{VariantID: variantID, Price: 100, StartDate: at.Add(-time.Hour)})
+	r.NoError(err)
+	_, err = repo.Repository.Product.AddProductPrice(ts, product.ProductPriceParams{VariantID: variantID, Price: 120, StartDate: scheduledAt})
+	r.NoError(err)
+
+	lineList, err := repo.Repository.Reprice.FindLineList(ts, reprice.Params{ProductIDList: []int{productID}}, at)
+	r.NoError(err)
+	r.Len(lineList, 1)
+	r.True(lineList[0].ScheduledAt.Valid)
+
+	// новая цена действует до начала запланированной, после нее действует запланированная
+	_, err = repo.Repository.Reprice.ReplacePrice(ts, variantID, 90, at)
+	r.NoError(err)
+
+	lineList, err = repo.Repository.Reprice.FindLineList(ts, reprice.Params{ProductIDList: []int{productID}}, at.Add(time.Hour))
+	r.NoError(err)
+	r.Equal(90.0, lineList[0].OldPrice)
+
+	lineList, err = repo.Repository.Reprice.FindLineList(ts, reprice.Params{ProductIDList: []int{productID}}, scheduledAt.Add(time.Hour))
+	r.NoError(err)
+	r.Equal(120.0, lineList[0].OldPrice)
+	r.False(lineList[0].ScheduledAt.Valid)
+}
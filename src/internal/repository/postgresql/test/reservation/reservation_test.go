@@ -0,0 +1,61 @@
+package reservation_test
+
+import (
+	"context"
+	"product_storage/internal/entity/reservation"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/pgdb"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReservedQuantity(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	// вариант 3 на складе 1, один резерв действует, второй истек
+	_, err := repo.Repository.Reservation.AddReservation(ts, reservation.Reservation{
+		VariantID: 3, StorageID: 1, Quantity: 2, ExpiresAt: time.Now().Add(time.Hour),
+	})
+	r.NoError(err)
+	expiredID, err := repo.Repository.Reservation.AddReservation(ts, reservation.Reservation{
+		VariantID: 3, StorageID: 1, Quantity: 1, ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	r.NoError(err)
+
+	reserved, err := repo.Repository.Reservation.FindReservedQuantity(ts, 3, 1)
+	r.NoError(err)
+	r.Equal(2, reserved)
+
+	expiredList, err := repo.Repository.Reservation.ExpireReservationList(ts, 100)
+	r.NoError(err)
+
+	var found bool
+	for _, e := range expiredList {
+		if e.ReservationID == expiredID {
+			found = true
+			r.Equal(reservation.StatusExpired, e.Status)
+		}
+	}
+	r.True(found)
+
+	storageList, err := repo.Repository.Product.InStorages(ts, 3)
+	r.NoError(err)
+	for _, s := range storageList {
+		if s.StorageID == 1 {
+			r.Equal(2, s.Reserved)
+			r.Equal(s.Quantity-2, s.Available)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package stock_test
+
+import (
+	"context"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/stock"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/pgdb"
+	"product_storage/tools/sqlnull"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecreaseProductInStock(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	// вариант 3 на складе 1 в количестве 5
+	remaining, err := repo.Repository.Stock.DecreaseProductInStock(ts, 3, 1, 2)
+	r.NoError(err)
+	r.Equal(3, remaining)
+
+	_, err = repo.Repository.Stock.DecreaseProductInStock(ts, 3, 1, 4)
+	r.Equal(global.ErrNoData, err)
+}
+
+func TestLowStockList(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	threshold := stock.ThresholdParams{VariantID: 1, StorageID: 1, MinQuantity: 5, MaxQuantity: 12}
+	r.NoError(repo.Repository.Stock.SaveThreshold(ts, threshold))
+
+	saved, err := repo.Repository.Stock.FindThreshold(ts, 1, 1)
+	r.NoError(err)
+	r.Equal(threshold, saved)
+
+	// вариант 1 на складе 1 в количестве 2
+	lowStockList, err := repo.Repository.Stock.FindLowStockList(ts, 1)
+	r.NoError(err)
+
+	var found bool
+	for _, v := range lowStockList {
+		if v.VariantID == 1 {
+			found = true
+			r.Equal(2, v.Quantity)
+			r.Equal(10, v.ReorderQuantity)
+		}
+	}
+	r.True(found)
+}
+
+func TestBatchList(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	now := time.Now()
+
+	expiredID, err := repo.Repository.Stock.AddBatch(ts, stock.Batch{VariantID: 3, StorageID: 1, Quantity: 2,
+		BatchParams: stock.BatchParams{BatchNumber: "A1", ExpiresAt: sqlnull.NewNullTime(now.AddDate(0, 0, -1))}})
+	r.NoError(err)
+
+	freshID, err := repo.Repository.Stock.AddBatch(ts, stock.Batch{VariantID: 3, StorageID: 1, Quantity: 3,
+		BatchParams: stock.BatchParams{BatchNumber: "A2", ExpiresAt: sqlnull.NewNullTime(now.AddDate(0, 0, 5))}})
+	r.NoError(err)
+
+	batchList, err := repo.Repository.Stock.LoadBatchList(ts, 3, 1)
+	r.NoError(err)
+	r.Len(batchList, 2)
+	r.Equal(expiredID, batchList[0].BatchID)
+
+	expiring, err := repo.Repository.Stock.FindExpiringBatchList(ts, 1, 7)
+	r.NoError(err)
+	r.Len(expiring, 2)
+	r.Equal(-1, expiring[0].DaysLeft)
+
+	expired, err := repo.Repository.Stock.LoadExpiredBatchList(ts, 1)
+	r.NoError(err)
+	r.Len(expired, 1)
+
+	r.NoError(repo.Repository.Stock.ConsumeBatch(ts, freshID, 3))
+	r.Equal(global.ErrNoData, repo.Repository.Stock.ConsumeBatch(ts, freshID, 1))
+}
@@ -0,0 +1,50 @@
+package stocktake_test
+
+import (
+	"context"
+	"product_storage/internal/entity/stocktake"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/pgdb"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStocktakeCount(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	stocktakeID, err := repo.Repository.Stocktake.AddStocktake(ts, stocktake.Params{StorageID: 1})
+	r.NoError(err)
+	r.NoError(repo.Repository.Stocktake.SnapshotStocktakeLines(ts, stocktakeID, 1))
+
+	// вариант 3 на складе 1 в количестве 5, пересчитан в двух местах склада
+	r.NoError(repo.Repository.Stocktake.SaveCount(ts, stocktakeID, stocktake.CountParams{VariantID: 3, CountedQuantity: 2}, false))
+	r.NoError(repo.Repository.Stocktake.SaveCount(ts, stocktakeID, stocktake.CountParams{VariantID: 3, CountedQuantity: 1}, true))
+
+	lineList, err := repo.Repository.Stocktake.FindStocktakeLineList(ts, stocktakeID)
+	r.NoError(err)
+
+	var found bool
+	for _, l := range lineList {
+		if l.VariantID == 3 {
+			found = true
+			r.Equal(5, l.ExpectedQuantity)
+			r.Equal(-2, l.Variance())
+		}
+	}
+	r.True(found)
+
+	openList, err := repo.Repository.Stocktake.FindStocktakeList(ts, 1, stocktake.StatusOpen)
+	r.NoError(err)
+	r.Len(openList, 1)
+}
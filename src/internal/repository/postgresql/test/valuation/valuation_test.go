@@ -0,0 +1,76 @@
+package valuation_test
+
+import (
+	"context"
+	"product_storage/internal/entity/valuation"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/pgdb"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostLayers(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	r.NoError(repo.Repository.Valuation.AddCostLayer(ts, valuation.CostLayer{VariantID: 2, StorageID: 1, Quantity: 2, UnitCost: 10}))
+	r.NoError(repo.Repository.Valuation.AddCostLayer(ts, valuation.CostLayer{VariantID: 2, StorageID: 1, Quantity: 2, UnitCost: 20}))
+	r.NoError(repo.Repository.Valuation.AddAverageCost(ts, 2, 1, 2, 10))
+	r.NoError(repo.Repository.Valuation.AddAverageCost(ts, 2, 1, 2, 20))
+
+	average, err := repo.Repository.Valuation.LoadAverageCost(ts, 2, 1)
+	r.NoError(err)
+	r.Equal(4, average.Quantity)
+	r.Equal(15.0, average.AverageCost)
+
+	layerList, err := repo.Repository.Valuation.LoadCostLayerList(ts, 2, 1)
+	r.NoError(err)
+	r.Len(layerList, 2)
+	r.NoError(repo.Repository.Valuation.ConsumeCostLayer(ts, layerList[0].LayerID, 2))
+
+	valuationList, err := repo.Repository.Valuation.FindFIFOValuationList(ts, 1)
+	r.NoError(err)
+	for _, v := range valuationList {
+		if v.VariantID == 2 {
+			r.Equal(40.0, v.Value)
+		}
+	}
+}
+
+func TestMarginList(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	q := valuation.MarginQuery{
+		StartDate: time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2023, 7, 31, 0, 0, 0, 0, time.UTC),
+		GroupBy:   valuation.GroupByVariant,
+	}
+
+	// в тестовых данных 3 продажи за июль 2023 без себестоимости
+	marginList, err := repo.Repository.Valuation.FindMarginList(ts, q)
+	r.NoError(err)
+	r.Len(marginList, 3)
+	for _, m := range marginList {
+		r.Equal(m.Revenue, m.GrossMargin)
+	}
+}
@@ -0,0 +1,73 @@
+package webhook_test
+
+import (
+	"context"
+	"product_storage/internal/entity/event"
+	"product_storage/internal/entity/webhook"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/pgdb"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDelivery(t *testing.T) {
+	r := require.New(t)
+
+	db := pgdb.SqlxDB("dbname=test_db user=test_db password=test_db host=127.0.0.1 port=5432 sslmode=disable")
+	defer db.Close()
+	sm := transaction.NewSQLSessionManager(db)
+	repo := rimport.NewRepositoryImports(sm)
+
+	ts := sm.CreateSession()
+	ts.Start(context.Background())
+	defer ts.Rollback()
+
+	subscriptionID, err := repo.Repository.Webhook.AddSubscription(ts, webhook.SubscriptionParams{
+		URL:        "https://shop.example.com/hooks",
+		Secret:     "0123456789abcdef",
+		EventTypes: []string{event.PriceChanged},
+	})
+	r.NoError(err)
+
+	// доставка создается только для событий из подписки
+	for _, eventType := range []string{event.PriceChanged, event.SaleRecorded} {
+		e, err := event.NewEvent(eventType, 10, map[string]int{"variant_id": 10})
+		r.NoError(err)
+
+		e.EventID, err = repo.Repository.Outbox.SaveEvent(ts, e)
+		r.NoError(err)
+		r.NoError(repo.Repository.Webhook.CreateDeliveryList(ts, e))
+	}
+
+	deliveryList, err := repo.Repository.Webhook.FindDeliveryList(ts, subscriptionID, 10)
+	r.NoError(err)
+	r.Len(deliveryList, 1)
+	r.Equal(event.PriceChanged, deliveryList[0].EventType)
+	r.Equal(webhook.StatusPending, deliveryList[0].Status)
+
+	pending, err := repo.Repository.Webhook.LoadPendingDeliveryList(ts, 1000)
+	r.NoError(err)
+
+	var loaded webhook.Delivery
+	for _, d := range pending {
+		if d.DeliveryID == deliveryList[0].DeliveryID {
+			loaded = d
+		}
+	}
+	r.Equal("https://shop.example.com/hooks", loaded.URL)
+	r.Equal("0123456789abcdef", loaded.Secret)
+
+	r.NoError(repo.Repository.Webhook.MarkFailed(ts, loaded.DeliveryID, webhook.StatusPending, time.Now().Add(time.Hour), 503, "unavailable"))
+
+	deliveryList, err = repo.Repository.Webhook.FindDeliveryList(ts, subscriptionID, 10)
+	r.NoError(err)
+	r.Equal(1, deliveryList[0].Attempts)
+	r.Equal(503, deliveryList[0].LastStatusCode.GetInt())
+
+	removed, err := repo.Repository.Webhook.RemoveSubscription(ts, subscriptionID)
+	r.NoError(err)
+	r.True(removed)
+}
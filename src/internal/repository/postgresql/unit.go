@@ -0,0 +1,45 @@
+package postgresql
+
+import (
+	"product_storage/internal/entity/unit"
+	"product_storage/internal/repository"
+	"product_storage/internal/transaction"
+	"product_storage/tools/gensql"
+)
+
+type unitRepository struct{}
+
+func NewUnit() repository.Unit {
+	return &unitRepository{}
+}
+
+// AddUnit добавление единицы измерения в справочник
+func (r *unitRepository) AddUnit(ts transaction.Session, u unit.Unit) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	insert into units
+	( code, name, dimension, factor )
+	values ( $1, $2, $3, $4 )`,
+		u.Code, u.Name, u.Dimension, u.Factor)
+
+	return err
+}
+
+// LoadUnit единица измерения по обозначению
+func (r *unitRepository) LoadUnit(ts transaction.Session, code string) (unit.Unit, error) {
+	query := `
+	select code, name, dimension, factor
+	from units
+	where code = $1`
+
+	return gensql.Get[unit.Unit](ts.Context(), SqlxTx(ts), query, code)
+}
+
+// FindUnitList справочник единиц измерения
+func (r *unitRepository) FindUnitList(ts transaction.Session) ([]unit.Unit, error) {
+	query := `
+	select code, name, dimension, factor
+	from units
+	order by dimension, factor`
+
+	return gensql.Select[unit.Unit](ts.Context(), SqlxTx(ts), query)
+}
@@ -0,0 +1,149 @@
+package postgresql
+
+import (
+	"product_storage/internal/entity/valuation"
+	"product_storage/internal/repository"
+	"product_storage/internal/transaction"
+	"product_storage/tools/gensql"
+)
+
+type valuationRepository struct{}
+
+func NewValuation() repository.Valuation {
+	return &valuationRepository{}
+}
+
+// AddCostLayer добавление слоя себестоимости поступившего продукта
+func (r *valuationRepository) AddCostLayer(ts transaction.Session, l valuation.CostLayer) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	insert into cost_layers
+	( variant_id, storage_id, quantity, remaining, unit_cost, receipt_id )
+	values ( $1, $2, $3, $3, $4, nullif($5, 0) )`,
+		l.VariantID, l.StorageID, l.Quantity, l.UnitCost, l.ReceiptID)
+
+	return err
+}
+
+// LoadCostLayerList неисчерпанные слои себестоимости в порядке поступления, слои блокируются до конца транзакции
+func (r *valuationRepository) LoadCostLayerList(ts transaction.Session, variantID, storageID int) ([]valuation.CostLayer, error) {
+	query := `
+	select layer_id, variant_id, storage_id, quantity, remaining, unit_cost, coalesce(receipt_id, 0) as receipt_id
+	from cost_layers
+	where variant_id = $1
+	and storage_id = $2
+	and remaining > 0
+	order by layer_id
+	for update`
+
+	return gensql.Select[valuation.CostLayer](ts.Context(), SqlxTx(ts), query, variantID, storageID)
+}
+
+// ConsumeCostLayer списание кол-ва со слоя себестоимости
+func (r *valuationRepository) ConsumeCostLayer(ts transaction.Session, layerID, quantity int) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	update cost_layers
+	set remaining = remaining - $2
+	where layer_id = $1`,
+		layerID, quantity)
+
+	return err
+}
+
+// AddAverageCost пересчет средневзвешенной себестоимости при поступлении продукта
+func (r *valuationRepository) AddAverageCost(ts transaction.Session, variantID, storageID, quantity int, unitCost float64) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	insert into stock_costs
+	( variant_id, storage_id, quantity, average_cost )
+	values ( $1, $2, $3, $4 )
+	on conflict (variant_id, storage_id) do update
+	set average_cost = (stock_costs.quantity * stock_costs.average_cost + excluded.quantity * excluded.average_cost)
+			/ (stock_costs.quantity + excluded.quantity),
+		quantity = stock_costs.quantity + excluded.quantity,
+		updated_at = now()`,
+		variantID, storageID, quantity, unitCost)
+
+	return err
+}
+
+// LoadAverageCost средневзвешенная себестоимость варианта продукта на складе, запись блокируется до конца транзакции
+func (r *valuationRepository) LoadAverageCost(ts transaction.Session, variantID, storageID int) (valuation.AverageCost, error) {
+	query := `
+	select variant_id, storage_id, quantity, average_cost
+	from stock_costs
+	where variant_id = $1
+	and storage_id = $2
+	for update`
+
+	return gensql.Get[valuation.AverageCost](ts.Context(), SqlxTx(ts), query, variantID, storageID)
+}
+
+// ConsumeAverageCost уменьшение кол-ва продукта с известной средней себестоимостью, средняя цена не меняется
+func (r *valuationRepository) ConsumeAverageCost(ts transaction.Session, variantID, storageID, quantity int) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	update stock_costs
+	set quantity = greatest(quantity - $3, 0),
+		updated_at = now()
+	where variant_id = $1
+	and storage_id = $2`,
+		variantID, storageID, quantity)
+
+	return err
+}
+
+// FindFIFOValuationList стоимость остатков по неисчерпанным слоям себестоимости, при storageID = 0 по всем складам
+func (r *valuationRepository) FindFIFOValuationList(ts transaction.Session, storageID int) ([]valuation.Valuation, error) {
+	query := `
+	select l.variant_id, l.storage_id, p.name as product_name, v.weight, v.unit,
+		sum(l.remaining) as quantity,
+		round(sum(l.remaining * l.unit_cost) / sum(l.remaining), 4) as unit_cost,
+		round(sum(l.remaining * l.unit_cost), 2) as value
+	from cost_layers l
+	join product_variants v on v.variant_id = l.variant_id
+	join products p on p.product_id = v.product_id
+	where l.remaining > 0
+	and ($1 = 0 or l.storage_id = $1)
+	group by l.variant_id, l.storage_id, p.name, v.weight, v.unit
+	order by l.storage_id, l.variant_id`
+
+	return gensql.Select[valuation.Valuation](ts.Context(), SqlxTx(ts), query, storageID)
+}
+
+// FindAverageValuationList стоимость остатков по средневзвешенной себестоимости, при storageID = 0 по всем складам
+func (r *valuationRepository) FindAverageValuationList(ts transaction.Session, storageID int) ([]valuation.Valuation, error) {
+	query := `
+	select c.variant_id, c.storage_id, p.name as product_name, v.weight, v.unit,
+		c.quantity,
+		c.average_cost as unit_cost,
+		round(c.quantity * c.average_cost, 2) as value
+	from stock_costs c
+	join product_variants v on v.variant_id = c.variant_id
+	join products p on p.product_id = v.product_id
+	where c.quantity > 0
+	and ($1 = 0 or c.storage_id = $1)
+	order by c.storage_id, c.variant_id`
+
+	return gensql.Select[valuation.Valuation](ts.Context(), SqlxTx(ts), query, storageID)
+}
+
+// FindMarginList выручка, себестоимость и валовая маржа продаж за период по продуктам или вариантам
+func (r *valuationRepository) FindMarginList(ts transaction.Session, q valuation.MarginQuery) ([]valuation.Margin, error) {
+	query := `
+	select p.product_id, p.name as product_name,
+		case when $4 = 'variant' then s.variant_id else 0 end as variant_id,
+		sum(s.quantity) as quantity,
+		sum(s.total_price) as revenue,
+		sum(s.cost_of_goods) as cost_of_goods,
+		sum(s.total_price) - sum(s.cost_of_goods) as gross_margin,
+		case when sum(s.total_price) > 0
+			then round((sum(s.total_price) - sum(s.cost_of_goods)) / sum(s.total_price) * 100, 2)
+			else 0 end as margin_percent
+	from sales s
+	join product_variants v on v.variant_id = s.variant_id
+	join products p on p.product_id = v.product_id
+	where s.sold_at >= $1 and s.sold_at <= $2
+	and ($3 = 0 or s.storage_id = $3)
+	group by 1, 2, 3
+	order by gross_margin desc`
+
+	return gensql.Select[valuation.Margin](ts.Context(), SqlxTx(ts), query, q.StartDate, q.EndDate, q.StorageID, q.GroupBy)
+}
@@ -0,0 +1,151 @@
+package postgresql
+
+import (
+	"product_storage/internal/entity/event"
+	"product_storage/internal/entity/webhook"
+	"product_storage/internal/repository"
+	"product_storage/internal/transaction"
+	"product_storage/tools/gensql"
+	"product_storage/tools/sqlnull"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type webhookRepository struct{}
+
+func NewWebhook() repository.Webhook {
+	return &webhookRepository{}
+}
+
+// AddSubscription добавление подписки на события
+func (r *webhookRepository) AddSubscription(ts transaction.Session, p webhook.SubscriptionParams) (subscriptionID int, err error) {
+	err = SqlxTx(ts).QueryRowContext(ts.Context(), `
+	insert into webhook_subscriptions
+	( url, secret, event_types )
+	values ( $1, $2, $3 )
+	returning subscription_id`,
+		p.URL, p.Secret, pq.StringArray(p.EventTypes)).Scan(&subscriptionID)
+
+	return subscriptionID, err
+}
+
+// FindSubscriptionList действующие подписки
+func (r *webhookRepository) FindSubscriptionList(ts transaction.Session) ([]webhook.Subscription, error) {
+	query := `
+	select subscription_id, url, event_types, added_at
+	from webhook_subscriptions
+	where removed_at is null
+	order by subscription_id`
+
+	return gensql.Select[webhook.Subscription](ts.Context(), SqlxTx(ts), query)
+}
+
+// RemoveSubscription отключение подписки, журнал доставок сохраняется
+func (r *webhookRepository) RemoveSubscription(ts transaction.Session, subscriptionID int) (bool, error) {
+	res, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	update webhook_subscriptions
+	set removed_at = now()
+	where subscription_id = $1
+	and removed_at is null`,
+		subscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// CreateDeliveryList создание доставок события всем действующим подписчикам на его тип
+func (r *webhookRepository) CreateDeliveryList(ts transaction.Session, e event.Event) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	insert into webhook_deliveries
+	( subscription_id, event_id, event_type, payload, occurred_at )
+	select subscription_id, $1, $2, $3, $4
+	from webhook_subscriptions
+	where removed_at is null
+	and $2 = any(event_types)`,
+		e.EventID, e.EventType, e.Payload, e.CreatedAt)
+
+	return err
+}
+
+// FindDeliveryList журнал доставок подписки, начиная с последних
+func (r *webhookRepository) FindDeliveryList(ts transaction.Session, subscriptionID, limit int) ([]webhook.Delivery, error) {
+	query := `
+	select delivery_id, subscription_id, event_id, event_type, payload, occurred_at,
+		status, attempts, next_attempt_at, last_status_code, last_error, delivered_at
+	from webhook_deliveries
+	where subscription_id = $1
+	order by delivery_id desc
+	limit $2`
+
+	return gensql.Select[webhook.Delivery](ts.Context(), SqlxTx(ts), query, subscriptionID, limit)
+}
+
+// LoadPendingDeliveryList доставки действующих подписок, время попытки которых наступило.
+// Строки блокируются до конца транзакции, параллельные обработчики пропускают их
+func (r *webhookRepository) LoadPendingDeliveryList(ts transaction.Session, limit int) ([]webhook.Delivery, error) {
+	query := `
+	select d.delivery_id, d.subscription_id, d.event_id, d.event_type, d.payload, d.occurred_at,
+		d.status, d.attempts, d.next_attempt_at, d.last_status_code, d.last_error, d.delivered_at,
+		s.url, s.secret
+	from webhook_deliveries d
+	join webhook_subscriptions s on s.subscription_id = d.subscription_id
+	where d.status = 'pending'
+	and d.next_attempt_at <= now()
+	and s.removed_at is null
+	order by d.delivery_id
+	limit $1
+	for update of d skip locked`
+
+	return gensql.Select[webhook.Delivery](ts.Context(), SqlxTx(ts), query, limit)
+}
+
+// ClaimDeliveryList перенос следующей попытки доставок на время until на время их отправки,
+// до этого времени другие обработчики их не загружают
+func (r *webhookRepository) ClaimDeliveryList(ts transaction.Session, deliveryIDList []int64, until time.Time) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	update webhook_deliveries
+	set next_attempt_at = $2
+	where delivery_id = any($1)`,
+		pq.Array(deliveryIDList), until)
+
+	return err
+}
+
+// MarkDelivered отметка об успешной доставке
+func (r *webhookRepository) MarkDelivered(ts transaction.Session, deliveryID int64, statusCode int) error {
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	update webhook_deliveries
+	set status = 'delivered',
+		attempts = attempts + 1,
+		last_status_code = $2,
+		last_error = null,
+		delivered_at = now()
+	where delivery_id = $1`,
+		deliveryID, statusCode)
+
+	return err
+}
+
+// MarkFailed отметка о неудачной попытке доставки со статусом status и временем следующей попытки
+func (r *webhookRepository) MarkFailed(ts transaction.Session, deliveryID int64, status string, nextAttemptAt time.Time, statusCode int, lastError string) error {
+	code := sqlnull.NullInt64{}
+	if statusCode > 0 {
+		code = sqlnull.NewInt64(statusCode)
+	}
+
+	_, err := SqlxTx(ts).ExecContext(ts.Context(), `
+	update webhook_deliveries
+	set status = $2,
+		attempts = attempts + 1,
+		next_attempt_at = $3,
+		last_status_code = $4,
+		last_error = $5
+	where delivery_id = $1`,
+		deliveryID, status, nextAttemptAt, code, lastError)
+
+	return err
+}
@@ -1,14 +1,25 @@
 package transaction
 
+import "context"
+
+// Session транзакция; повторный вызов Start при активной транзакции открывает вложенную транзакцию,
+// каждому Start должен соответствовать один вызов Rollback (обычно через defer), Commit фиксирует текущий уровень
 type Session interface {
-	Start() error
+	Start(ctx context.Context) error
 	Rollback() error
 	Commit() error
 	Tx() interface{}
 	TxIsActive() bool
+	Context() context.Context
+	// OnCommit регистрирует функцию, выполняемую после фиксации транзакции верхнего уровня;
+	// при откате транзакции или вложенной транзакции функция не выполняется
+	OnCommit(f func())
+	// OnRollback регистрирует функцию, выполняемую после отката транзакции верхнего уровня или вложенной
+	// транзакции, в которой она зарегистрирована; после фиксации транзакции функция не выполняется
+	OnRollback(f func())
 	CreateNewSession() Session
 }
 
 type SessionManager interface {
-	CreateSession() Session
+	CreateSession(opts ...Option) Session
 }
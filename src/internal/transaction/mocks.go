@@ -5,6 +5,7 @@
 package transaction
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -47,6 +48,20 @@ func (mr *MockSessionMockRecorder) Commit() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockSession)(nil).Commit))
 }
 
+// Context mocks base method.
+func (m *MockSession) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockSessionMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockSession)(nil).Context))
+}
+
 // CreateNewSession mocks base method.
 func (m *MockSession) CreateNewSession() Session {
 	m.ctrl.T.Helper()
@@ -61,6 +76,30 @@ func (mr *MockSessionMockRecorder) CreateNewSession() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNewSession", reflect.TypeOf((*MockSession)(nil).CreateNewSession))
 }
 
+// OnCommit mocks base method.
+func (m *MockSession) OnCommit(f func()) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnCommit", f)
+}
+
+// OnCommit indicates an expected call of OnCommit.
+func (mr *MockSessionMockRecorder) OnCommit(f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnCommit", reflect.TypeOf((*MockSession)(nil).OnCommit), f)
+}
+
+// OnRollback mocks base method.
+func (m *MockSession) OnRollback(f func()) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnRollback", f)
+}
+
+// OnRollback indicates an expected call of OnRollback.
+func (mr *MockSessionMockRecorder) OnRollback(f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnRollback", reflect.TypeOf((*MockSession)(nil).OnRollback), f)
+}
+
 // Rollback mocks base method.
 func (m *MockSession) Rollback() error {
 	m.ctrl.T.Helper()
@@ -76,17 +115,17 @@ func (mr *MockSessionMockRecorder) Rollback() *gomock.Call {
 }
 
 // Start mocks base method.
-func (m *MockSession) Start() error {
+func (m *MockSession) Start(ctx context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Start")
+	ret := m.ctrl.Call(m, "Start", ctx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Start indicates an expected call of Start.
-func (mr *MockSessionMockRecorder) Start() *gomock.Call {
+func (mr *MockSessionMockRecorder) Start(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockSession)(nil).Start))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockSession)(nil).Start), ctx)
 }
 
 // Tx mocks base method.
@@ -141,15 +180,19 @@ func (m *MockSessionManager) EXPECT() *MockSessionManagerMockRecorder {
 }
 
 // CreateSession mocks base method.
-func (m *MockSessionManager) CreateSession() Session {
+func (m *MockSessionManager) CreateSession(opts ...Option) Session {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSession")
+	varargs := []interface{}{}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateSession", varargs...)
 	ret0, _ := ret[0].(Session)
 	return ret0
 }
 
 // CreateSession indicates an expected call of CreateSession.
-func (mr *MockSessionManagerMockRecorder) CreateSession() *gomock.Call {
+func (mr *MockSessionManagerMockRecorder) CreateSession(opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessionManager)(nil).CreateSession))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessionManager)(nil).CreateSession), opts...)
 }
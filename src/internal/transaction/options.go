@@ -0,0 +1,49 @@
+package transaction
+
+import "database/sql"
+
+// Options параметры открываемой транзакции
+type Options struct {
+	Isolation sql.IsolationLevel // уровень изоляции, по умолчанию уровень изоляции базы
+	ReadOnly  bool               // транзакция только для чтения
+}
+
+// Option функция настройки параметров транзакции
+type Option func(o *Options)
+
+// ReadOnly транзакция только для чтения
+func ReadOnly() Option {
+	return func(o *Options) {
+		o.ReadOnly = true
+	}
+}
+
+// Isolation транзакция с указанным уровнем изоляции
+func Isolation(level sql.IsolationLevel) Option {
+	return func(o *Options) {
+		o.Isolation = level
+	}
+}
+
+// Serializable транзакция с уровнем изоляции serializable
+func Serializable() Option {
+	return Isolation(sql.LevelSerializable)
+}
+
+// NewOptions сборка параметров транзакции из функций настройки
+func NewOptions(opts ...Option) Options {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// TxOptions параметры транзакции для database/sql
+func (o Options) TxOptions() *sql.TxOptions {
+	return &sql.TxOptions{
+		Isolation: o.Isolation,
+		ReadOnly:  o.ReadOnly,
+	}
+}
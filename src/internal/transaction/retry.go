@@ -0,0 +1,203 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// sqlStateSerializationFailure конфликт сериализации транзакций
+	sqlStateSerializationFailure = "40001"
+	// sqlStateDeadlockDetected обнаружена взаимоблокировка
+	sqlStateDeadlockDetected = "40P01"
+)
+
+// IsRetryable ошибка конфликта сериализации или deadlock, после которой транзакцию можно выполнить повторно
+func IsRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	return pqErr.Code == sqlStateSerializationFailure || pqErr.Code == sqlStateDeadlockDetected
+}
+
+// RetryPolicy параметры повтора транзакций
+type RetryPolicy struct {
+	MaxAttempts int           // максимальное кол-во попыток выполнения, включая первую
+	BaseDelay   time.Duration // задержка перед первым повтором, удваивается с каждой попыткой
+	MaxDelay    time.Duration // максимальная задержка между попытками
+}
+
+// DefaultRetryPolicy параметры повтора по умолчанию
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   20 * time.Millisecond,
+	MaxDelay:    time.Second,
+}
+
+// delay задержка перед попыткой attempt (начиная со второй), половина задержки случайна,
+// чтобы конкурирующие транзакции не повторялись одновременно
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay << (attempt - 2)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+// RetryStats статистика повторов транзакций
+type RetryStats struct {
+	Retries   int64 `json:"retries"`   // кол-во повторных запусков транзакций
+	Recovered int64 `json:"recovered"` // кол-во транзакций, выполненных успешно после повтора
+	Exhausted int64 `json:"exhausted"` // кол-во транзакций, не выполненных после всех попыток
+}
+
+// UnitOfWork действия, выполняемые в рамках одной транзакции
+type UnitOfWork func(ts Session) error
+
+// Retrier выполняет unit of work в транзакции и повторяет его целиком
+// при конфликтах сериализации и deadlock
+type Retrier struct {
+	sm     SessionManager
+	policy RetryPolicy
+	log    *logrus.Logger
+
+	retries   atomic.Int64
+	recovered atomic.Int64
+	exhausted atomic.Int64
+}
+
+func NewRetrier(sm SessionManager, policy RetryPolicy, log *logrus.Logger) *Retrier {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 1
+	}
+
+	return &Retrier{
+		sm:     sm,
+		policy: policy,
+		log:    log,
+	}
+}
+
+// Run открывает транзакцию с параметрами opts, выполняет в ней f и фиксирует ее.
+// Если f или фиксация завершились конфликтом сериализации или deadlock, транзакция откатывается
+// и f выполняется заново в новой транзакции, не более policy.MaxAttempts раз.
+// Ошибка конфликта определяется и в том случае, когда f вернул другую ошибку вместо исходной,
+// если база открыта через NewConnector
+func (r *Retrier) Run(ctx context.Context, f UnitOfWork, opts ...Option) (err error) {
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(r.policy.delay(attempt)):
+			}
+
+			r.retries.Add(1)
+		}
+
+		var conflict error
+		conflict, err = r.runOnce(ctx, f, opts...)
+		if conflict == nil {
+			if attempt > 1 && err == nil {
+				r.recovered.Add(1)
+				r.log.WithField("attempt", attempt).Info("транзакция выполнена после повтора")
+			}
+			return err
+		}
+
+		lf := logrus.Fields{"attempt": attempt, "max_attempts": r.policy.MaxAttempts}
+		if attempt >= r.policy.MaxAttempts {
+			r.exhausted.Add(1)
+			r.log.WithFields(lf).Error("транзакция не выполнена после всех попыток ", conflict)
+			return err
+		}
+
+		r.log.WithFields(lf).Warn("конфликт транзакций, транзакция будет выполнена повторно ", conflict)
+	}
+}
+
+// runOnce одна попытка выполнения f, возвращает ошибку конфликта, если попытку можно повторить
+func (r *Retrier) runOnce(ctx context.Context, f UnitOfWork, opts ...Option) (conflict, err error) {
+	ctx, rec := withFailureRecorder(ctx)
+
+	ts := r.sm.CreateSession(opts...)
+	if err = ts.Start(ctx); err != nil {
+		return rec.retryable(err), err
+	}
+	defer ts.Rollback()
+
+	if err = f(ts); err != nil {
+		return rec.retryable(err), err
+	}
+
+	if err = ts.Commit(); err != nil {
+		return rec.retryable(err), err
+	}
+
+	return nil, nil
+}
+
+// Stats статистика повторов транзакций
+func (r *Retrier) Stats() RetryStats {
+	return RetryStats{
+		Retries:   r.retries.Load(),
+		Recovered: r.recovered.Load(),
+		Exhausted: r.exhausted.Load(),
+	}
+}
+
+// failureRecorder запоминает ошибку конфликта, произошедшую в транзакции
+type failureRecorder struct {
+	m   sync.Mutex
+	err error
+}
+
+type failureRecorderKey struct{}
+
+func withFailureRecorder(ctx context.Context) (context.Context, *failureRecorder) {
+	rec := &failureRecorder{}
+	return context.WithValue(ctx, failureRecorderKey{}, rec), rec
+}
+
+// recordFailure сохраняет ошибку конфликта в контексте транзакции, открытой через Retrier.Run
+func recordFailure(ctx context.Context, err error) {
+	if !IsRetryable(err) {
+		return
+	}
+
+	rec, ok := ctx.Value(failureRecorderKey{}).(*failureRecorder)
+	if !ok {
+		return
+	}
+
+	rec.m.Lock()
+	rec.err = err
+	rec.m.Unlock()
+}
+
+// retryable ошибка конфликта: err, если он сам является конфликтом, иначе записанная в транзакции
+func (rec *failureRecorder) retryable(err error) error {
+	if IsRetryable(err) {
+		return err
+	}
+
+	rec.m.Lock()
+	defer rec.m.Unlock()
+
+	return rec.err
+}
@@ -0,0 +1,103 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    5 * time.Millisecond,
+}
+
+func TestRetrierRun(t *testing.T) {
+	serializationErr := &pq.Error{Code: sqlStateSerializationFailure}
+	deadlockErr := &pq.Error{Code: sqlStateDeadlockDetected}
+	internalErr := errors.New("внутренняя ошибка")
+
+	tests := []struct {
+		name          string
+		results       []error // результаты f по попыткам
+		recordFailure bool    // конфликт записывается драйвером, а f возвращает другую ошибку
+		expectedCalls int
+		expectedErr   error
+		expectedStats RetryStats
+	}{
+		{
+			name:          "успешно с первой попытки",
+			results:       []error{nil},
+			expectedCalls: 1,
+		},
+		{
+			name:          "успешно после конфликта сериализации",
+			results:       []error{serializationErr, nil},
+			expectedCalls: 2,
+			expectedStats: RetryStats{Retries: 1, Recovered: 1},
+		},
+		{
+			name:          "успешно после deadlock, скрытого usecase",
+			results:       []error{internalErr, nil},
+			recordFailure: true,
+			expectedCalls: 2,
+			expectedStats: RetryStats{Retries: 1, Recovered: 1},
+		},
+		{
+			name:          "попытки исчерпаны",
+			results:       []error{deadlockErr, deadlockErr, deadlockErr},
+			expectedCalls: 3,
+			expectedErr:   deadlockErr,
+			expectedStats: RetryStats{Retries: 2, Exhausted: 1},
+		},
+		{
+			name:          "ошибка без повтора",
+			results:       []error{internalErr},
+			expectedCalls: 1,
+			expectedErr:   internalErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sm := NewMockSessionManager(ctrl)
+			ts := NewMockSession(ctrl)
+
+			var txCtx context.Context
+			sm.EXPECT().CreateSession().Return(ts).Times(tt.expectedCalls)
+			ts.EXPECT().Start(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+				txCtx = ctx
+				return nil
+			}).Times(tt.expectedCalls)
+			ts.EXPECT().Rollback().Return(nil).Times(tt.expectedCalls)
+			ts.EXPECT().Commit().Return(nil).MaxTimes(1)
+
+			retrier := NewRetrier(sm, testPolicy, logrus.New())
+
+			calls := 0
+			err := retrier.Run(context.Background(), func(ts Session) error {
+				err := tt.results[calls]
+				calls++
+
+				if err != nil && tt.recordFailure {
+					recordFailure(txCtx, deadlockErr)
+				}
+				return err
+			})
+
+			r.Equal(tt.expectedErr, err)
+			r.Equal(tt.expectedCalls, calls)
+			r.Equal(tt.expectedStats, retrier.Stats())
+		})
+	}
+}
@@ -1,7 +1,8 @@
 package transaction
 
 import (
-	"log"
+	"context"
+	"fmt"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -10,29 +11,128 @@ type sqlSession struct {
 	db *sqlx.DB
 	//
 	currentTx *sqlx.Tx
+	// ctx контекст запроса, в рамках которого открыта транзакция
+	ctx context.Context
+	// opts параметры транзакции
+	opts Options
+	// savepoints вложенные транзакции
+	savepoints []savepoint
+	// afterCommit функции, выполняемые после фиксации транзакции
+	afterCommit []func()
+	// afterRollback функции, выполняемые после отката транзакции
+	afterRollback []func()
 }
 
-func NewSQLSession(db *sqlx.DB) Session {
-	return &sqlSession{db: db}
+// savepoint вложенная транзакция
+type savepoint struct {
+	name          string
+	released      bool // точка сохранения зафиксирована через Commit
+	afterCommit   int  // кол-во функций afterCommit на момент создания точки сохранения
+	afterRollback int  // кол-во функций afterRollback на момент создания точки сохранения
 }
 
-func (t *sqlSession) Start() (err error) {
+func NewSQLSession(db *sqlx.DB, opts ...Option) Session {
+	return &sqlSession{db: db, opts: NewOptions(opts...)}
+}
+
+// Start открывает транзакцию, привязанную к контексту запроса;
+// при отмене контекста или истечении его таймаута транзакция откатывается.
+// Если транзакция уже открыта, создается точка сохранения
+func (t *sqlSession) Start(ctx context.Context) (err error) {
 	if t.currentTx != nil {
-		log.Fatalln("открытие транзакции при активной транзакции")
+		return t.startSavepoint()
 	}
-	t.currentTx, err = t.db.Beginx()
+
+	t.ctx = ctx
+	t.currentTx, err = t.db.BeginTxx(ctx, t.opts.TxOptions())
 	return
 }
 
+func (t *sqlSession) startSavepoint() error {
+	sp := savepoint{
+		name:          fmt.Sprintf("sp_%d", len(t.savepoints)+1),
+		afterCommit:   len(t.afterCommit),
+		afterRollback: len(t.afterRollback),
+	}
+
+	if _, err := t.currentTx.ExecContext(t.Context(), "savepoint "+sp.name); err != nil {
+		return err
+	}
+
+	t.savepoints = append(t.savepoints, sp)
+	return nil
+}
+
+// Rollback откатывает текущий уровень транзакции;
+// для вложенной транзакции, уже зафиксированной через Commit, ничего не делает
 func (t *sqlSession) Rollback() error {
+	if len(t.savepoints) > 0 {
+		sp := t.savepoints[len(t.savepoints)-1]
+		t.savepoints = t.savepoints[:len(t.savepoints)-1]
+
+		if sp.released {
+			return nil
+		}
+
+		t.afterCommit = t.afterCommit[:sp.afterCommit]
+		afterRollback := t.afterRollback[sp.afterRollback:]
+		t.afterRollback = t.afterRollback[:sp.afterRollback]
+
+		_, err := t.currentTx.ExecContext(t.Context(), "rollback to savepoint "+sp.name)
+		runAll(afterRollback)
+		return err
+	}
+
+	t.afterCommit = nil
+	afterRollback := t.afterRollback
+	t.afterRollback = nil
+
 	err := t.currentTx.Rollback()
 	t.currentTx = nil
+	runAll(afterRollback)
 	return err
 }
 
+// Commit фиксирует текущий уровень транзакции
 func (t *sqlSession) Commit() error {
+	if len(t.savepoints) > 0 {
+		sp := &t.savepoints[len(t.savepoints)-1]
+		if _, err := t.currentTx.ExecContext(t.Context(), "release savepoint "+sp.name); err != nil {
+			return err
+		}
+
+		sp.released = true
+		return nil
+	}
+
 	err := t.currentTx.Commit()
-	return err
+	if err != nil {
+		return err
+	}
+
+	afterCommit := t.afterCommit
+	t.afterCommit = nil
+	t.afterRollback = nil
+	runAll(afterCommit)
+
+	return nil
+}
+
+// OnCommit регистрирует функцию, выполняемую после фиксации транзакции
+func (t *sqlSession) OnCommit(f func()) {
+	t.afterCommit = append(t.afterCommit, f)
+}
+
+// OnRollback регистрирует функцию, выполняемую после отката транзакции
+func (t *sqlSession) OnRollback(f func()) {
+	t.afterRollback = append(t.afterRollback, f)
+}
+
+// runAll выполнение зарегистрированных функций в порядке регистрации
+func runAll(list []func()) {
+	for _, f := range list {
+		f()
+	}
 }
 
 func (t *sqlSession) Tx() interface{} {
@@ -43,6 +143,14 @@ func (t *sqlSession) TxIsActive() bool {
 	return t.currentTx != nil
 }
 
+// Context контекст, с которым была открыта транзакция
+func (t *sqlSession) Context() context.Context {
+	if t.ctx == nil {
+		return context.Background()
+	}
+	return t.ctx
+}
+
 func (t *sqlSession) CreateNewSession() Session {
 	return NewSQLSession(t.db)
 }
@@ -55,6 +163,7 @@ func NewSQLSessionManager(db *sqlx.DB) SessionManager {
 	return &sqlSessionManager{db: db}
 }
 
-func (s *sqlSessionManager) CreateSession() Session {
-	return NewSQLSession(s.db)
+// CreateSession создает сессию с указанными параметрами транзакции
+func (s *sqlSessionManager) CreateSession(opts ...Option) Session {
+	return NewSQLSession(s.db, opts...)
 }
@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"errors"
+	"product_storage/internal/entity/attribute"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/product"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/jsonb"
+
+	"github.com/sirupsen/logrus"
+)
+
+// loadCategoryDefinitionList описания атрибутов существующей категории
+func (u *ProductUseCase) loadCategoryDefinitionList(ts transaction.Session, lf logrus.Fields, categoryID int) ([]attribute.Definition, error) {
+	_, err := u.Repository.Attribute.LoadCategory(ts, categoryID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return nil, attribute.ErrCategoryNotFound
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить категорию ", err)
+		return nil, global.ErrInternalError
+	}
+
+	definitionList, err := u.Repository.Attribute.FindDefinitionList(ts, categoryID)
+	switch err {
+	case nil, global.ErrNoData:
+		return definitionList, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить атрибуты категории ", err)
+		return nil, global.ErrInternalError
+	}
+}
+
+// checkAttributes проверка значений атрибутов продукта по описаниям атрибутов его категории
+func (u *ProductUseCase) checkAttributes(ts transaction.Session, lf logrus.Fields, categoryID int, values attribute.Values) error {
+	if categoryID < 0 {
+		return errors.New("id категории не может быть отрицательным")
+	}
+
+	if categoryID == 0 {
+		if len(values) > 0 {
+			return attribute.ErrNoCategory
+		}
+		return nil
+	}
+
+	definitionList, err := u.loadCategoryDefinitionList(ts, lf, categoryID)
+	if err != nil {
+		return err
+	}
+
+	return attribute.Validate(definitionList, values)
+}
+
+// decodeAttributes разбор значений атрибутов продуктов, загруженных из базы в виде jsonb
+func (u *ProductUseCase) decodeAttributes(productList []product.ProductInfo) error {
+	for i := range productList {
+		values, err := jsonb.Unmarshal[attribute.Values](productList[i].RawAttributes)
+		if err != nil {
+			u.log.WithFields(logrus.Fields{"product_ID": productList[i].ProductID}).Error("не удалось разобрать атрибуты продукта ", err)
+			return global.ErrInternalError
+		}
+
+		productList[i].Attributes = values
+	}
+
+	return nil
+}
+
+// AttributeUseCase категории продуктов и описания их атрибутов. Значения атрибутов хранятся
+// в продукте и проверяются по описаниям категории при создании и изменении продукта
+type AttributeUseCase struct {
+	log     *logrus.Logger
+	product *ProductUseCase
+	rimport.RepositoryImports
+}
+
+func NewAttribute(log *logrus.Logger, ri rimport.RepositoryImports, product *ProductUseCase) *AttributeUseCase {
+	return &AttributeUseCase{
+		log:               log,
+		product:           product,
+		RepositoryImports: ri,
+	}
+}
+
+// AddCategory создание категории вместе с описаниями атрибутов ее продуктов
+func (u *AttributeUseCase) AddCategory(ts transaction.Session, p attribute.CategoryParams) (categoryID int, err error) {
+	lf := p.Log()
+
+	if err = p.Validate(); err != nil {
+		return 0, err
+	}
+
+	_, err = u.Repository.Attribute.FindCategoryIDByName(ts, p.Name)
+	switch err {
+	case nil:
+		return 0, errors.New("категория с таким названием уже существует")
+	case global.ErrNoData:
+	default:
+		u.log.WithFields(lf).Error("не удалось проверить название категории ", err)
+		return 0, global.ErrInternalError
+	}
+
+	categoryID, err = u.Repository.Attribute.AddCategory(ts, p.Name)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось создать категорию ", err)
+		return 0, global.ErrInternalError
+	}
+
+	lf["category_ID"] = categoryID
+
+	for _, d := range p.Definitions {
+		d.CategoryID = categoryID
+		if err = u.Repository.Attribute.AddDefinition(ts, d); err != nil {
+			u.log.WithFields(lf).Error("не удалось добавить атрибут категории ", err)
+			return 0, global.ErrInternalError
+		}
+	}
+
+	u.log.WithFields(lf).Info("категория создана")
+	return categoryID, nil
+}
+
+// AddDefinition добавление атрибута в существующую категорию. Обязательный атрибут можно добавить
+// только в категорию без продуктов, иначе у них не окажется обязательного значения
+func (u *AttributeUseCase) AddDefinition(ts transaction.Session, d attribute.Definition) error {
+	lf := logrus.Fields{"category_ID": d.CategoryID, "code": d.Code}
+
+	if err := d.Validate(); err != nil {
+		return err
+	}
+
+	definitionList, err := u.product.loadCategoryDefinitionList(ts, lf, d.CategoryID)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range definitionList {
+		if existing.Code == d.Code {
+			return errors.New("атрибут с таким кодом уже есть в категории")
+		}
+	}
+
+	if d.Required {
+		count, err := u.Repository.Attribute.CountCategoryProducts(ts, d.CategoryID)
+		if err != nil {
+			u.log.WithFields(lf).Error("не удалось получить кол-во продуктов категории ", err)
+			return global.ErrInternalError
+		}
+
+		if count > 0 {
+			return errors.New("обязательный атрибут нельзя добавить в категорию, в которой уже есть продукты")
+		}
+	}
+
+	if err = u.Repository.Attribute.AddDefinition(ts, d); err != nil {
+		u.log.WithFields(lf).Error("не удалось добавить атрибут категории ", err)
+		return global.ErrInternalError
+	}
+
+	u.log.WithFields(lf).Info("атрибут добавлен в категорию")
+	return nil
+}
+
+// FindCategoryList категории вместе с описаниями атрибутов
+func (u *AttributeUseCase) FindCategoryList(ts transaction.Session) ([]attribute.Category, error) {
+	categoryList, err := u.Repository.Attribute.FindCategoryList(ts)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return []attribute.Category{}, nil
+	default:
+		u.log.Error("не удалось найти категории ", err)
+		return nil, global.ErrInternalError
+	}
+
+	definitionList, err := u.Repository.Attribute.FindDefinitionList(ts, 0)
+	switch err {
+	case nil, global.ErrNoData:
+	default:
+		u.log.Error("не удалось найти атрибуты категорий ", err)
+		return nil, global.ErrInternalError
+	}
+
+	definitionsByCategory := make(map[int][]attribute.Definition)
+	for _, d := range definitionList {
+		definitionsByCategory[d.CategoryID] = append(definitionsByCategory[d.CategoryID], d)
+	}
+
+	for i := range categoryList {
+		categoryList[i].Definitions = definitionsByCategory[categoryList[i].CategoryID]
+	}
+
+	return categoryList, nil
+}
+
+// UpdateProductAttributes замена категории и значений атрибутов продукта
+func (u *AttributeUseCase) UpdateProductAttributes(ts transaction.Session, p attribute.ProductParams) error {
+	lf := p.Log()
+
+	if p.ProductID <= 0 {
+		return errors.New("id продукта не может быть меньше или равен 0")
+	}
+
+	_, err := u.Repository.Product.LoadProductInfo(ts, p.ProductID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return errors.New("продукт не найден")
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить продукт ", err)
+		return global.ErrInternalError
+	}
+
+	if err = u.product.checkAttributes(ts, lf, p.CategoryID, p.Attributes); err != nil {
+		return err
+	}
+
+	if err = u.Repository.Product.UpdateProductAttributes(ts, p.ProductID, p.CategoryID, p.Attributes); err != nil {
+		u.log.WithFields(lf).Error("не удалось изменить атрибуты продукта ", err)
+		return global.ErrInternalError
+	}
+
+	// продукт может попасть в списки, отобранные по новым значениям атрибутов
+	ts.OnCommit(func() { u.product.cache.invalidate(productCacheDep(p.ProductID), productListCacheDep) })
+
+	u.log.WithFields(lf).Info("атрибуты продукта изменены")
+	return nil
+}
@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"errors"
+	"product_storage/internal/entity/customer"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/product"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// customerHistoryLimit кол-во продаж в истории покупок по умолчанию
+const customerHistoryLimit = 50
+
+// loadCustomer неудаленный покупатель
+func (u *ProductUseCase) loadCustomer(ts transaction.Session, lf logrus.Fields, customerID int) (customer.Customer, error) {
+	if customerID <= 0 {
+		return customer.Customer{}, errors.New("id покупателя не может быть меньше или равен 0")
+	}
+
+	c, err := u.Repository.Customer.LoadCustomer(ts, customerID)
+	switch err {
+	case nil:
+		return c, nil
+	case global.ErrNoData:
+		return customer.Customer{}, customer.ErrNotFound
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить покупателя ", err)
+		return customer.Customer{}, global.ErrInternalError
+	}
+}
+
+// customerPrice цена варианта для покупателя: цена прайс-листа его группы, действующая в момент at,
+// а если для группы цена не задана, то общая цена price
+func (u *ProductUseCase) customerPrice(ts transaction.Session, lf logrus.Fields, customerID, variantID int, price float64, at time.Time) (float64, error) {
+	c, err := u.loadCustomer(ts, lf, customerID)
+	if err != nil {
+		return 0, err
+	}
+
+	groupPrice, err := u.Repository.Customer.FindGroupPrice(ts, variantID, c.Type, at)
+	switch err {
+	case nil:
+		lf["customer_type"] = c.Type
+		return groupPrice, nil
+	case global.ErrNoData:
+		return price, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти цену группы покупателя ", err)
+		return 0, global.ErrInternalError
+	}
+}
+
+// CustomerUseCase реестр покупателей, прайс-листы групп покупателей и история покупок
+type CustomerUseCase struct {
+	log     *logrus.Logger
+	product *ProductUseCase
+	rimport.RepositoryImports
+}
+
+func NewCustomer(log *logrus.Logger, ri rimport.RepositoryImports, product *ProductUseCase) *CustomerUseCase {
+	return &CustomerUseCase{
+		log:               log,
+		product:           product,
+		RepositoryImports: ri,
+	}
+}
+
+// checkPhone проверка, что телефон не указан у другого неудаленного покупателя
+func (u *CustomerUseCase) checkPhone(ts transaction.Session, lf logrus.Fields, phone string, customerID int) error {
+	if phone == "" {
+		return nil
+	}
+
+	ownerID, err := u.Repository.Customer.FindCustomerIDByPhone(ts, phone)
+	switch err {
+	case nil:
+		if ownerID != customerID {
+			return customer.ErrPhoneTaken
+		}
+		return nil
+	case global.ErrNoData:
+		return nil
+	default:
+		u.log.WithFields(lf).Error("не удалось проверить телефон покупателя ", err)
+		return global.ErrInternalError
+	}
+}
+
+// AddCustomer добавление покупателя
+func (u *CustomerUseCase) AddCustomer(ts transaction.Session, p customer.Params) (customerID int, err error) {
+	p = p.Normalize()
+	lf := p.Log()
+
+	if err = p.Validate(); err != nil {
+		return 0, err
+	}
+
+	if err = u.checkPhone(ts, lf, p.Phone, 0); err != nil {
+		return 0, err
+	}
+
+	customerID, err = u.Repository.Customer.AddCustomer(ts, p)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось добавить покупателя ", err)
+		return 0, global.ErrInternalError
+	}
+
+	lf["customer_ID"] = customerID
+	u.log.WithFields(lf).Info("покупатель добавлен")
+	return customerID, nil
+}
+
+// LoadCustomer покупатель по id
+func (u *CustomerUseCase) LoadCustomer(ts transaction.Session, customerID int) (customer.Customer, error) {
+	return u.product.loadCustomer(ts, logrus.Fields{"customer_ID": customerID}, customerID)
+}
+
+// FindCustomerList поиск покупателей по части имени или телефона и типу
+func (u *CustomerUseCase) FindCustomerList(ts transaction.Session, search, customerType string, limit int) ([]customer.Customer, error) {
+	lf := logrus.Fields{"search": search, "type": customerType}
+
+	if customerType != "" && !customer.IsValidType(customerType) {
+		return nil, errors.New("тип покупателя должен быть одним из: retail, wholesale")
+	}
+
+	if limit <= 0 {
+		limit = 20
+	}
+
+	customerList, err := u.Repository.Customer.FindCustomerList(ts, search, customerType, limit)
+	switch err {
+	case nil:
+		return customerList, nil
+	case global.ErrNoData:
+		return []customer.Customer{}, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти покупателей ", err)
+		return nil, global.ErrInternalError
+	}
+}
+
+// UpdateCustomer изменение имени, телефона и типа покупателя. Новый тип действует на следующие продажи
+func (u *CustomerUseCase) UpdateCustomer(ts transaction.Session, p customer.UpdateParams) error {
+	p.Params = p.Params.Normalize()
+	lf := p.Log()
+	lf["customer_ID"] = p.CustomerID
+
+	if _, err := u.product.loadCustomer(ts, lf, p.CustomerID); err != nil {
+		return err
+	}
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	if err := u.checkPhone(ts, lf, p.Phone, p.CustomerID); err != nil {
+		return err
+	}
+
+	if err := u.Repository.Customer.UpdateCustomer(ts, p.CustomerID, p.Params); err != nil {
+		u.log.WithFields(lf).Error("не удалось изменить покупателя ", err)
+		return global.ErrInternalError
+	}
+
+	u.log.WithFields(lf).Info("покупатель изменен")
+	return nil
+}
+
+// RemoveCustomer удаление покупателя, проведенные продажи сохраняют ссылку на него
+func (u *CustomerUseCase) RemoveCustomer(ts transaction.Session, customerID int) error {
+	lf := logrus.Fields{"customer_ID": customerID}
+
+	if customerID <= 0 {
+		return errors.New("id покупателя не может быть меньше или равен 0")
+	}
+
+	err := u.Repository.Customer.RemoveCustomer(ts, customerID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return customer.ErrNotFound
+	default:
+		u.log.WithFields(lf).Error("не удалось удалить покупателя ", err)
+		return global.ErrInternalError
+	}
+
+	u.log.WithFields(lf).Info("покупатель удален")
+	return nil
+}
+
+// SetGroupPrice установка цены варианта для группы покупателей, предыдущая цена группы закрывается
+func (u *CustomerUseCase) SetGroupPrice(ts transaction.Session, p customer.GroupPriceParams) (priceID int, err error) {
+	lf := p.Log()
+
+	if err = p.Validate(); err != nil {
+		return 0, err
+	}
+
+	// цена группы заменяет общую цену варианта, без общей цены вариант не продается
+	_, err = u.Repository.Product.FindPrice(ts, p.VariantID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return 0, errors.New("вариант не найден или у него нет цены")
+	default:
+		u.log.WithFields(lf).Error("не удалось найти цену варианта продукта ", err)
+		return 0, global.ErrInternalError
+	}
+
+	priceID, err = u.Repository.Customer.SetGroupPrice(ts, p, time.Now())
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось установить цену группы покупателей ", err)
+		return 0, global.ErrInternalError
+	}
+
+	lf["price_ID"] = priceID
+	u.log.WithFields(lf).Info("цена группы покупателей установлена")
+	return priceID, nil
+}
+
+// FindGroupPriceList действующие цены групп покупателей
+func (u *CustomerUseCase) FindGroupPriceList(ts transaction.Session, customerType string) ([]customer.GroupPrice, error) {
+	if customerType != "" && !customer.IsValidType(customerType) {
+		return nil, errors.New("группа покупателей должна быть одной из: retail, wholesale")
+	}
+
+	priceList, err := u.Repository.Customer.FindGroupPriceList(ts, customerType)
+	switch err {
+	case nil:
+		return priceList, nil
+	case global.ErrNoData:
+		return []customer.GroupPrice{}, nil
+	default:
+		u.log.WithFields(logrus.Fields{"customer_type": customerType}).Error("не удалось найти цены групп покупателей ", err)
+		return nil, global.ErrInternalError
+	}
+}
+
+// FindHistory последние покупки покупателя с кол-вом и суммой всех его покупок
+func (u *CustomerUseCase) FindHistory(ts transaction.Session, customerID, limit int) (customer.History, error) {
+	lf := logrus.Fields{"customer_ID": customerID}
+
+	c, err := u.product.loadCustomer(ts, lf, customerID)
+	if err != nil {
+		return customer.History{}, err
+	}
+
+	if limit <= 0 {
+		limit = customerHistoryLimit
+	}
+
+	saleList, err := u.Repository.Customer.FindCustomerSaleList(ts, customerID, limit)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		saleList = []product.Sale{}
+	default:
+		u.log.WithFields(lf).Error("не удалось найти продажи покупателя ", err)
+		return customer.History{}, global.ErrInternalError
+	}
+
+	// итоги считаются по всем продажам покупателя, а не только по выведенным последним
+	total, err := u.Repository.Customer.LoadCustomerSaleTotal(ts, customerID)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось получить итоги продаж покупателя ", err)
+		return customer.History{}, global.ErrInternalError
+	}
+
+	return customer.History{Customer: c, SaleTotal: total, Sales: saleList}, nil
+}
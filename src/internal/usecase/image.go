@@ -0,0 +1,391 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"product_storage/config"
+	"product_storage/internal/bridge"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/image"
+	"product_storage/internal/entity/product"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/localblob"
+	"product_storage/tools/sqlnull"
+	"product_storage/tools/thumbnail"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// blobDeleteTimeout таймаут удаления файлов изображения из хранилища
+const blobDeleteTimeout = 10 * time.Second
+
+// newBlobStorage хранилище изображений, заданное в конфиге; пока поддерживается только локальный каталог,
+// другие хранилища подключаются через SetBlobStorage
+func newBlobStorage(c config.Config) bridge.BlobStorage {
+	return localblob.NewStorage(c.Image.Root, c.Image.BaseURL)
+}
+
+// SetBlobStorage подключение другого хранилища изображений вместо заданного в конфиге
+func (u *ProductUseCase) SetBlobStorage(blob bridge.BlobStorage) {
+	u.blob = blob
+}
+
+// setImageURLs адреса исходного изображения и превью в хранилище
+func (u *ProductUseCase) setImageURLs(img *image.Image) {
+	img.URL = u.blob.URL(img.BlobKey)
+	img.ThumbURL = u.blob.URL(img.ThumbKey)
+}
+
+// loadImageList загрузка изображений списка продуктов одним запросом
+func (u *ProductUseCase) loadImageList(ts transaction.Session, productList []product.ProductInfo) error {
+	if len(productList) == 0 {
+		return nil
+	}
+
+	productIDList := make([]int, 0, len(productList))
+	for _, p := range productList {
+		productIDList = append(productIDList, p.ProductID)
+	}
+
+	imageList, err := u.Repository.Image.FindImageListByProductIDList(ts, productIDList)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return nil
+	default:
+		u.log.WithFields(logrus.Fields{"product_ID_list": productIDList}).Error("не удалось найти изображения продуктов ", err)
+		return global.ErrInternalError
+	}
+
+	imagesByProduct := make(map[int][]image.Image)
+	for _, img := range imageList {
+		u.setImageURLs(&img)
+		imagesByProduct[img.ProductID] = append(imagesByProduct[img.ProductID], img)
+	}
+
+	for i := range productList {
+		productList[i].Images = imagesByProduct[productList[i].ProductID]
+	}
+
+	return nil
+}
+
+// ImageUseCase изображения продуктов и их вариантов: файлы и превью лежат в хранилище ProductUseCase,
+// в базе хранятся ключи файлов, порядок в галерее и признак основного изображения
+type ImageUseCase struct {
+	log     *logrus.Logger
+	product *ProductUseCase
+	rimport.RepositoryImports
+}
+
+func NewImage(log *logrus.Logger, ri rimport.RepositoryImports, product *ProductUseCase) *ImageUseCase {
+	return &ImageUseCase{
+		log:               log,
+		product:           product,
+		RepositoryImports: ri,
+	}
+}
+
+// newBlobKey ключ нового файла изображения продукта, suffix добавляется к имени перед расширением
+func newBlobKey(productID int, name, suffix, contentType string) string {
+	return fmt.Sprintf("products/%d/%s%s%s", productID, name, suffix, image.Ext(contentType))
+}
+
+// randomName случайное имя файла, не повторяющееся между загрузками
+func randomName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Upload загрузка изображения продукта или варианта: исходный файл и превью сохраняются в хранилище,
+// первое изображение продукта становится основным. Файлы сохраняются до фиксации транзакции
+// и удаляются из хранилища при ее откате, в том числе перед повтором транзакции
+func (u *ImageUseCase) Upload(ts transaction.Session, p image.UploadParams, data []byte) (imageID int, err error) {
+	lf := p.Log()
+
+	if err = p.Validate(); err != nil {
+		return 0, err
+	}
+
+	if int64(len(data)) > u.Config.ImageMaxSize() {
+		return 0, image.ErrTooLarge
+	}
+
+	if err = u.checkOwner(ts, lf, p); err != nil {
+		return 0, err
+	}
+
+	res, err := thumbnail.Make(data, u.Config.Image.ThumbSize, u.Config.ImageMaxPixels())
+	switch err {
+	case nil:
+	case thumbnail.ErrTooManyPixels:
+		return 0, image.ErrTooLarge
+	default:
+		return 0, image.ErrUnsupportedImage
+	}
+
+	contentType := image.ContentType(res.Format)
+	if contentType == "" {
+		return 0, image.ErrUnsupportedImage
+	}
+
+	// одновременные загрузки изображений продукта выполняются по очереди, иначе обе получат один номер
+	// и обе станут основными
+	if err = u.Repository.Image.LockProductImages(ts, p.ProductID); err != nil {
+		u.log.WithFields(lf).Error("не удалось заблокировать изображения продукта ", err)
+		return 0, global.ErrInternalError
+	}
+
+	position, err := u.Repository.Image.NextImagePosition(ts, p.ProductID)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось получить порядковый номер изображения ", err)
+		return 0, global.ErrInternalError
+	}
+
+	name, err := randomName()
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось сгенерировать имя файла ", err)
+		return 0, global.ErrInternalError
+	}
+
+	img := image.Image{
+		ProductID:   p.ProductID,
+		BlobKey:     newBlobKey(p.ProductID, name, "", contentType),
+		ThumbKey:    newBlobKey(p.ProductID, name, "_thumb", contentType),
+		ContentType: contentType,
+		Width:       res.Width,
+		Height:      res.Height,
+		Position:    position,
+		IsPrimary:   position == 0,
+	}
+	if p.VariantID > 0 {
+		img.VariantID = sqlnull.NewInt64(p.VariantID)
+	}
+	lf["blob_key"] = img.BlobKey
+
+	ts.OnRollback(func() { u.deleteBlobs(lf, img) })
+
+	if err = u.product.blob.Put(ts.Context(), img.BlobKey, contentType, bytes.NewReader(data)); err != nil {
+		u.log.WithFields(lf).Error("не удалось сохранить изображение в хранилище ", err)
+		return 0, global.ErrInternalError
+	}
+
+	if err = u.product.blob.Put(ts.Context(), img.ThumbKey, contentType, bytes.NewReader(res.Thumb)); err != nil {
+		u.log.WithFields(lf).Error("не удалось сохранить превью в хранилище ", err)
+		return 0, global.ErrInternalError
+	}
+
+	imageID, err = u.Repository.Image.AddImage(ts, img)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось сохранить изображение ", err)
+		return 0, global.ErrInternalError
+	}
+
+	lf["image_ID"] = imageID
+
+	u.imagesChanged(ts, p.ProductID)
+
+	u.log.WithFields(lf).Info("изображение загружено")
+	return imageID, nil
+}
+
+// checkOwner проверка, что продукт существует, а вариант относится к продукту
+func (u *ImageUseCase) checkOwner(ts transaction.Session, lf logrus.Fields, p image.UploadParams) error {
+	_, err := u.Repository.Product.LoadProductInfo(ts, p.ProductID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return errors.New("продукт не найден")
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить продукт ", err)
+		return global.ErrInternalError
+	}
+
+	if p.VariantID == 0 {
+		return nil
+	}
+
+	variantList, err := u.Repository.Product.FindProductVariantList(ts, p.ProductID)
+	switch err {
+	case nil, global.ErrNoData:
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить варианты продукта ", err)
+		return global.ErrInternalError
+	}
+
+	for _, v := range variantList {
+		if v.VariantID == p.VariantID {
+			return nil
+		}
+	}
+
+	return errors.New("вариант не относится к продукту")
+}
+
+// loadImage изображение по id
+func (u *ImageUseCase) loadImage(ts transaction.Session, lf logrus.Fields, imageID int) (image.Image, error) {
+	if imageID <= 0 {
+		return image.Image{}, errors.New("id изображения не может быть меньше или равен 0")
+	}
+
+	img, err := u.Repository.Image.LoadImage(ts, imageID)
+	switch err {
+	case nil:
+		return img, nil
+	case global.ErrNoData:
+		return image.Image{}, image.ErrNotFound
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить изображение ", err)
+		return image.Image{}, global.ErrInternalError
+	}
+}
+
+// FindImageList изображения продукта с адресами файлов, основное первым
+func (u *ImageUseCase) FindImageList(ts transaction.Session, productID int) ([]image.Image, error) {
+	lf := logrus.Fields{"product_ID": productID}
+
+	if productID <= 0 {
+		return nil, errors.New("id продукта не может быть меньше или равен 0")
+	}
+
+	imageList, err := u.Repository.Image.FindImageList(ts, productID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return []image.Image{}, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти изображения продукта ", err)
+		return nil, global.ErrInternalError
+	}
+
+	for i := range imageList {
+		u.product.setImageURLs(&imageList[i])
+	}
+
+	return imageList, nil
+}
+
+// SetPrimary назначение основного изображения продукта
+func (u *ImageUseCase) SetPrimary(ts transaction.Session, imageID int) error {
+	lf := logrus.Fields{"image_ID": imageID}
+
+	img, err := u.loadImage(ts, lf, imageID)
+	if err != nil {
+		return err
+	}
+
+	if img.IsPrimary {
+		return nil
+	}
+
+	if err = u.Repository.Image.SetPrimaryImage(ts, img.ProductID, imageID); err != nil {
+		u.log.WithFields(img.Log()).Error("не удалось назначить основное изображение ", err)
+		return global.ErrInternalError
+	}
+
+	u.imagesChanged(ts, img.ProductID)
+
+	u.log.WithFields(img.Log()).Info("назначено основное изображение")
+	return nil
+}
+
+// Reorder новый порядок изображений продукта, в списке должны быть указаны все изображения продукта
+func (u *ImageUseCase) Reorder(ts transaction.Session, p image.OrderParams) error {
+	lf := logrus.Fields{"product_ID": p.ProductID, "image_ID_list": p.ImageIDList}
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	imageList, err := u.Repository.Image.FindImageList(ts, p.ProductID)
+	switch err {
+	case nil, global.ErrNoData:
+	default:
+		u.log.WithFields(lf).Error("не удалось найти изображения продукта ", err)
+		return global.ErrInternalError
+	}
+
+	current := make(map[int]bool, len(imageList))
+	for _, img := range imageList {
+		current[img.ImageID] = true
+	}
+
+	if len(current) != len(p.ImageIDList) {
+		return errors.New("в новом порядке должны быть указаны все изображения продукта")
+	}
+
+	for _, imageID := range p.ImageIDList {
+		if !current[imageID] {
+			return errors.New("изображение не относится к продукту")
+		}
+	}
+
+	for position, imageID := range p.ImageIDList {
+		if err = u.Repository.Image.SetImagePosition(ts, imageID, position); err != nil {
+			u.log.WithFields(lf).Error("не удалось изменить порядок изображений ", err)
+			return global.ErrInternalError
+		}
+	}
+
+	u.imagesChanged(ts, p.ProductID)
+
+	u.log.WithFields(lf).Info("порядок изображений изменен")
+	return nil
+}
+
+// Delete удаление изображения; файлы удаляются из хранилища после фиксации транзакции,
+// при удалении основного изображения основным становится первое из оставшихся
+func (u *ImageUseCase) Delete(ts transaction.Session, imageID int) error {
+	lf := logrus.Fields{"image_ID": imageID}
+
+	img, err := u.loadImage(ts, lf, imageID)
+	if err != nil {
+		return err
+	}
+	lf = img.Log()
+
+	if err = u.Repository.Image.DeleteImage(ts, imageID); err != nil {
+		u.log.WithFields(lf).Error("не удалось удалить изображение ", err)
+		return global.ErrInternalError
+	}
+
+	if img.IsPrimary {
+		if err = u.Repository.Image.PromoteFirstImage(ts, img.ProductID); err != nil {
+			u.log.WithFields(lf).Error("не удалось назначить новое основное изображение ", err)
+			return global.ErrInternalError
+		}
+	}
+
+	u.imagesChanged(ts, img.ProductID)
+	ts.OnCommit(func() { u.deleteBlobs(lf, img) })
+
+	u.log.WithFields(lf).Info("изображение удалено")
+	return nil
+}
+
+// deleteBlobs удаление файлов изображения из хранилища, ошибка только пишется в лог
+func (u *ImageUseCase) deleteBlobs(lf logrus.Fields, img image.Image) {
+	ctx, cancel := context.WithTimeout(context.Background(), blobDeleteTimeout)
+	defer cancel()
+
+	for _, key := range []string{img.BlobKey, img.ThumbKey} {
+		if err := u.product.blob.Delete(ctx, key); err != nil {
+			u.log.WithFields(lf).Warn("не удалось удалить файл изображения из хранилища ", key, " ", err)
+		}
+	}
+}
+
+// imagesChanged сброс кэша продукта после фиксации транзакции: изображения хранятся в информации о продукте
+func (u *ImageUseCase) imagesChanged(ts transaction.Session, productID int) {
+	ts.OnCommit(func() { u.product.cache.invalidate(productCacheDep(productID)) })
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"product_storage/internal/entity/attribute"
 	"product_storage/internal/entity/product"
 	"product_storage/internal/entity/stock"
 	"product_storage/internal/transaction"
@@ -11,11 +12,14 @@ type Product interface {
 	AddProductPrice(ts transaction.Session, pr product.ProductPriceParams) (int, error)
 	AddProductInStock(ts transaction.Session, p stock.ProductInStockParams) (int, error)
 	FindProductInfoById(ts transaction.Session, productID int) (product.ProductInfo, error)
-	FindProductList(ts transaction.Session, tag, name string, limit int) ([]product.ProductInfo, error)
-	FindProductsInStock(ts transaction.Session, productID int) ([]stock.Stock, error)
+	FindProductList(ts transaction.Session, tag, name string, limit int, f attribute.Filter) ([]product.ProductInfo, error)
+	FindProductsInStock(ts transaction.Session, productID int, byLocation bool) ([]stock.Stock, error)
 	SaveSale(ts transaction.Session, p product.SaleParams) (int, error)
+	FindVariantByBarcode(ts transaction.Session, barcode string) (product.BarcodeVariant, error)
+	AddVariantBarcode(ts transaction.Session, p product.BarcodeParams) error
 	FindSaleList(ts transaction.Session, sq product.SaleQueryParam) ([]product.Sale, error)
-	LoadStockList(ts transaction.Session) ([]stock.Stock, error)
+	LoadStockList(ts transaction.Session, withRemoved bool) ([]stock.Stock, error)
 	AddStock(ts transaction.Session, storage stock.StockParams) (stockID int, err error)
+	UpdateStock(ts transaction.Session, storage stock.StockParams) error
 	DeleteStock(ts transaction.Session, storage stock.StockParams) error
 }
@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"errors"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/location"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/sqlnull"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LocationUseCase места хранения внутри складов: зоны, ряды, стеллажи и ячейки,
+// размещение продукта склада по местам и перемещение между ними.
+// Общий остаток склада не меняется, продукт без места считается неразмещенным
+type LocationUseCase struct {
+	log     *logrus.Logger
+	product *ProductUseCase
+	rimport.RepositoryImports
+}
+
+func NewLocation(log *logrus.Logger, ri rimport.RepositoryImports, product *ProductUseCase) *LocationUseCase {
+	return &LocationUseCase{
+		log:               log,
+		product:           product,
+		RepositoryImports: ri,
+	}
+}
+
+// AddLocation создание места хранения на складе или внутри другого места
+func (u *LocationUseCase) AddLocation(ts transaction.Session, p location.Params) (locationID int, err error) {
+	lf := p.Log()
+
+	if err = p.Validate(); err != nil {
+		return 0, err
+	}
+
+	if _, err = u.product.loadStorage(ts, lf, p.StorageID); err != nil {
+		return 0, err
+	}
+
+	l := location.Location{
+		StorageID: p.StorageID,
+		Kind:      p.Kind,
+		Code:      p.Code,
+		Name:      p.Name,
+		Path:      p.Code,
+	}
+
+	if p.ParentID > 0 {
+		parent, err := u.loadLocation(ts, lf, p.ParentID)
+		if err != nil {
+			return 0, err
+		}
+
+		if parent.StorageID != p.StorageID {
+			return 0, errors.New("родительское место хранения находится на другом складе")
+		}
+
+		if !location.CanContain(parent.Kind, p.Kind) {
+			return 0, errors.New("место хранения может содержать только более мелкие места")
+		}
+
+		l.ParentID = sqlnull.NewInt64(parent.LocationID)
+		l.Path = parent.ChildPath(p.Code)
+	}
+
+	locationID, err = u.Repository.Location.AddLocation(ts, l)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось создать место хранения ", err)
+		return 0, global.ErrInternalError
+	}
+
+	lf["location_ID"] = locationID
+
+	u.log.WithFields(lf).Info("место хранения создано")
+	return locationID, nil
+}
+
+// loadLocation место хранения
+func (u *LocationUseCase) loadLocation(ts transaction.Session, lf logrus.Fields, locationID int) (location.Location, error) {
+	l, err := u.Repository.Location.LoadLocation(ts, locationID)
+	switch err {
+	case nil:
+		return l, nil
+	case global.ErrNoData:
+		return location.Location{}, errors.New("место хранения не найдено")
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить место хранения ", err)
+		return location.Location{}, global.ErrInternalError
+	}
+}
+
+// FindLocationList места хранения склада
+func (u *LocationUseCase) FindLocationList(ts transaction.Session, storageID int) ([]location.Location, error) {
+	lf := logrus.Fields{"storage_ID": storageID}
+
+	if storageID <= 0 {
+		return nil, errors.New("id склада не может быть меньше или равен 0")
+	}
+
+	locationList, err := u.Repository.Location.FindLocationList(ts, storageID)
+	switch err {
+	case nil:
+		return locationList, nil
+	case global.ErrNoData:
+		return []location.Location{}, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти места хранения ", err)
+		return nil, global.ErrInternalError
+	}
+}
+
+// Putaway размещение неразмещенного продукта склада в место хранения
+func (u *LocationUseCase) Putaway(ts transaction.Session, p location.PutawayParams) error {
+	lf := p.Log()
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	l, err := u.loadLocation(ts, lf, p.LocationID)
+	if err != nil {
+		return err
+	}
+
+	lf["storage_ID"] = l.StorageID
+
+	unplaced, err := u.Repository.Location.LoadUnplacedQuantity(ts, p.VariantID, l.StorageID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return location.ErrNotEnoughUnplaced
+	default:
+		u.log.WithFields(lf).Error("не удалось получить неразмещенное кол-во ", err)
+		return global.ErrInternalError
+	}
+
+	if unplaced < p.Quantity {
+		return location.ErrNotEnoughUnplaced
+	}
+
+	if err = u.Repository.Location.PutLocationStock(ts, p.LocationID, p.VariantID, p.Quantity); err != nil {
+		u.log.WithFields(lf).Error("не удалось разместить продукт ", err)
+		return global.ErrInternalError
+	}
+
+	u.log.WithFields(lf).Info("продукт размещен в место хранения")
+	return nil
+}
+
+// Move перемещение продукта между местами хранения одного склада
+func (u *LocationUseCase) Move(ts transaction.Session, p location.MoveParams) error {
+	lf := p.Log()
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	from, err := u.loadLocation(ts, lf, p.FromLocationID)
+	if err != nil {
+		return err
+	}
+
+	to, err := u.loadLocation(ts, lf, p.ToLocationID)
+	if err != nil {
+		return err
+	}
+
+	if from.StorageID != to.StorageID {
+		return errors.New("перемещение возможно только между местами хранения одного склада")
+	}
+
+	err = u.Repository.Location.TakeLocationStock(ts, p.FromLocationID, p.VariantID, p.Quantity)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return location.ErrNotEnoughInLocation
+	default:
+		u.log.WithFields(lf).Error("не удалось забрать продукт из места хранения ", err)
+		return global.ErrInternalError
+	}
+
+	if err = u.Repository.Location.PutLocationStock(ts, p.ToLocationID, p.VariantID, p.Quantity); err != nil {
+		u.log.WithFields(lf).Error("не удалось разместить продукт ", err)
+		return global.ErrInternalError
+	}
+
+	u.log.WithFields(lf).Info("продукт перемещен")
+	return nil
+}
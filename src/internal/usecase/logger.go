@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"context"
+
 	"github.com/sirupsen/logrus"
 	"product_storage/internal/entity/log"
 	"product_storage/rimport"
@@ -30,7 +32,7 @@ func (u *Logger) SpecialFields() []string {
 
 func (u *Logger) SaveLog(row log.Row) error {
 	ts := u.ri.SessionManager.CreateSession()
-	if err := ts.Start(); err != nil {
+	if err := ts.Start(context.Background()); err != nil {
 		u.log.Errorln(u.logPrefix(), "не удается стартовать транзакцию", err)
 		return err
 	}
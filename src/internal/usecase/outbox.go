@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"product_storage/internal/bridge"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OutboxStats статистика публикации событий
+type OutboxStats struct {
+	Published int64 `json:"published"` // кол-во опубликованных событий
+	Failed    int64 `json:"failed"`    // кол-во неудачных попыток публикации
+}
+
+// OutboxUseCase публикация событий из outbox в брокер сообщений.
+// Событие отмечается опубликованным только после подтверждения брокера,
+// поэтому при сбое между публикацией и фиксацией оно будет опубликовано повторно:
+// получатели должны отбрасывать дубликаты по event_id
+type OutboxUseCase struct {
+	log *logrus.Logger
+	rimport.RepositoryImports
+
+	published atomic.Int64
+	failed    atomic.Int64
+}
+
+func NewOutbox(log *logrus.Logger, ri rimport.RepositoryImports) *OutboxUseCase {
+	return &OutboxUseCase{
+		log:               log,
+		RepositoryImports: ri,
+	}
+}
+
+// Stats статистика публикации событий
+func (u *OutboxUseCase) Stats() OutboxStats {
+	return OutboxStats{
+		Published: u.published.Load(),
+		Failed:    u.failed.Load(),
+	}
+}
+
+// retryDelay задержка перед повтором после attempts неудачных попыток:
+// удваивается с каждой попыткой начиная с base, но не превышает max
+func retryDelay(base, max time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 0; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+
+	if delay > max {
+		return max
+	}
+
+	return delay
+}
+
+// PublishPending публикация пачки неопубликованных событий в порядке возникновения.
+// При ошибке публикации событию назначается время следующей попытки, а обработка пачки прекращается,
+// так как брокер скорее всего недоступен
+func (u *OutboxUseCase) PublishPending(ts transaction.Session, publisher bridge.Publisher) (published int, err error) {
+	eventList, err := u.Repository.Outbox.LoadPendingEventList(ts, u.Config.OutboxBatchSize())
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return 0, nil
+	default:
+		u.log.Error("не удалось загрузить неопубликованные события ", err)
+		return 0, global.ErrInternalError
+	}
+
+	for _, e := range eventList {
+		lf := e.Log()
+
+		if pubErr := publisher.Publish(ts.Context(), e); pubErr != nil {
+			u.failed.Add(1)
+
+			nextAttemptAt := time.Now().Add(retryDelay(u.Config.OutboxRetryBaseDelay(), u.Config.OutboxRetryMaxDelay(), e.Attempts))
+			lf["next_attempt_at"] = nextAttemptAt
+			u.log.WithFields(lf).Warn("не удалось опубликовать событие ", pubErr)
+
+			if err = u.Repository.Outbox.MarkFailed(ts, e.EventID, nextAttemptAt, pubErr.Error()); err != nil {
+				u.log.WithFields(lf).Error("не удалось записать ошибку публикации события ", err)
+				return published, global.ErrInternalError
+			}
+
+			return published, nil
+		}
+
+		if err = u.Repository.Outbox.MarkPublished(ts, e.EventID); err != nil {
+			u.log.WithFields(lf).Error("не удалось отметить событие опубликованным ", err)
+			return published, global.ErrInternalError
+		}
+
+		u.published.Add(1)
+		published++
+	}
+
+	return published, nil
+}
+
+// publishBatch публикация одной пачки событий в отдельной транзакции
+func (u *OutboxUseCase) publishBatch(ctx context.Context, publisher bridge.Publisher) (published int, err error) {
+	ts := u.SessionManager.CreateSession()
+	if err = ts.Start(ctx); err != nil {
+		u.log.Error("не удалось начать транзакцию публикации событий ", err)
+		return 0, err
+	}
+	defer ts.Rollback()
+
+	published, err = u.PublishPending(ts, publisher)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = ts.Commit(); err != nil {
+		u.log.Error("не удалось зафиксировать публикацию событий ", err)
+		return 0, err
+	}
+
+	return published, nil
+}
+
+// RunRelay периодическая публикация событий из outbox до отмены ctx.
+// Пока пачки заполнены полностью, следующая публикуется без ожидания
+func (u *OutboxUseCase) RunRelay(ctx context.Context, publisher bridge.Publisher) {
+	ticker := time.NewTicker(u.Config.OutboxInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for ctx.Err() == nil {
+			published, err := u.publishBatch(ctx, publisher)
+			if err != nil || published == 0 || published < u.Config.OutboxBatchSize() {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"errors"
+	"math"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/payment"
+	"product_storage/internal/entity/product"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+
+	"github.com/sirupsen/logrus"
+)
+
+// salePayments оплаты продажи с уже рассчитанной суммой. Без указанных оплат вся сумма оплачивается
+// способом по умолчанию из конфига, полностью оплаченная скидкой продажа не имеет оплат
+func (u *ProductUseCase) salePayments(p product.SaleParams) ([]payment.Params, error) {
+	total := math.Round(p.TotalPrice*100) / 100
+
+	if len(p.Payments) == 0 {
+		if total == 0 {
+			return nil, nil
+		}
+
+		method := u.Config.Payment.DefaultMethod
+		if method == "" {
+			method = payment.MethodCash
+		}
+
+		return []payment.Params{{Method: method, Amount: total}}, nil
+	}
+
+	if err := payment.ValidateList(p.Payments, total); err != nil {
+		return nil, err
+	}
+
+	return p.Payments, nil
+}
+
+// savePayments запись оплат проведенной продажи
+func (u *ProductUseCase) savePayments(ts transaction.Session, lf logrus.Fields, saleID int, paymentList []payment.Params) error {
+	for _, p := range paymentList {
+		if _, err := u.Repository.Payment.AddPayment(ts, saleID, p); err != nil {
+			u.log.WithFields(lf).Error("не удалось записать оплату продажи ", err)
+			return global.ErrInternalError
+		}
+	}
+
+	return nil
+}
+
+// PaymentUseCase оплаты продаж и кассовые отчеты
+type PaymentUseCase struct {
+	log *logrus.Logger
+	rimport.RepositoryImports
+}
+
+func NewPayment(log *logrus.Logger, ri rimport.RepositoryImports) *PaymentUseCase {
+	return &PaymentUseCase{
+		log:               log,
+		RepositoryImports: ri,
+	}
+}
+
+// FindPaymentList оплаты продажи
+func (u *PaymentUseCase) FindPaymentList(ts transaction.Session, saleID int) ([]payment.Payment, error) {
+	if saleID <= 0 {
+		return nil, errors.New("id продажи не может быть меньше или равен 0")
+	}
+
+	paymentList, err := u.Repository.Payment.FindPaymentList(ts, saleID)
+	switch err {
+	case nil:
+		return paymentList, nil
+	case global.ErrNoData:
+		return []payment.Payment{}, nil
+	default:
+		u.log.WithFields(logrus.Fields{"sale_ID": saleID}).Error("не удалось найти оплаты продажи ", err)
+		return nil, global.ErrInternalError
+	}
+}
+
+// ZReport кассовый отчет за день: продажи, скидки и оплаты по складам и способам оплаты
+func (u *PaymentUseCase) ZReport(ts transaction.Session, q payment.ZQuery) (payment.ZReport, error) {
+	if err := q.Validate(); err != nil {
+		return payment.ZReport{}, err
+	}
+	lf := q.Log()
+
+	start, end := q.Period()
+
+	storageList, err := u.Repository.Payment.FindZStorageTotalList(ts, start, end, q.StorageID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return payment.NewZReport(start, end, nil, nil), nil
+	default:
+		u.log.WithFields(lf).Error("не удалось получить итоги продаж складов ", err)
+		return payment.ZReport{}, global.ErrInternalError
+	}
+
+	methodList, err := u.Repository.Payment.FindZMethodTotalList(ts, start, end, q.StorageID)
+	switch err {
+	case nil, global.ErrNoData:
+	default:
+		u.log.WithFields(lf).Error("не удалось получить итоги оплат складов ", err)
+		return payment.ZReport{}, global.ErrInternalError
+	}
+
+	return payment.NewZReport(start, end, storageList, methodList), nil
+}
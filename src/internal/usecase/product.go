@@ -1,13 +1,21 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"product_storage/internal/bridge"
+	"product_storage/internal/entity/attribute"
+	"product_storage/internal/entity/event"
 	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/location"
 	"product_storage/internal/entity/product"
 	"product_storage/internal/entity/stock"
+	"product_storage/internal/entity/unit"
+	"product_storage/internal/entity/valuation"
 	"product_storage/internal/transaction"
 	"product_storage/rimport"
+	"product_storage/tools/broadcast"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -16,17 +24,39 @@ import (
 type ProductUseCase struct {
 	log   *logrus.Logger
 	dbLog *logrus.Logger
+	// cache кэш информации о продуктах и списков продуктов
+	cache *productCache
+	// stockHub рассылка изменений кол-ва продуктов на складах после фиксации транзакции
+	stockHub *broadcast.Hub[stock.StockLevel]
+	// notifier оповещение о снижении остатка ниже минимального
+	notifier bridge.LowStockNotifier
+	// blob хранилище изображений продуктов
+	blob bridge.BlobStorage
 	rimport.RepositoryImports
 }
 
 func NewProduct(log, dblog *logrus.Logger, ri rimport.RepositoryImports) *ProductUseCase {
 	return &ProductUseCase{
-		log:               log,
-		dbLog:             dblog,
+		log:   log,
+		dbLog: dblog,
+		cache: newProductCache(log, ri.Config.Cache.Backend, ri.Redis,
+			ri.Config.Redis.Prefix, ri.Config.RedisCacheLifetime()),
+		stockHub:          broadcast.NewHub[stock.StockLevel](ri.Config.Stream.HistorySize, ri.Config.Stream.BufferSize),
+		blob:              newBlobStorage(ri.Config),
 		RepositoryImports: ri,
 	}
 }
 
+// RunCacheJanitor периодическая очистка устаревших значений кэша продуктов до отмены ctx
+func (u *ProductUseCase) RunCacheJanitor(ctx context.Context) {
+	u.cache.runJanitor(ctx, cacheJanitorInterval)
+}
+
+// CacheStats статистика кэша продуктов
+func (u *ProductUseCase) CacheStats() CacheStats {
+	return u.cache.stats()
+}
+
 // AddProduct логика добавление продукта в базу
 func (u *ProductUseCase) AddProduct(ts transaction.Session, product product.ProductParams) (productID int, err error) {
 	lf := product.Log()
@@ -36,6 +66,11 @@ func (u *ProductUseCase) AddProduct(ts transaction.Session, product product.Prod
 		err = errors.New("имя продукта не может быть пустым")
 		return
 	}
+	// значения атрибутов проверяются по описаниям атрибутов категории
+	if err = u.checkAttributes(ts, lf, product.CategoryID, product.Attributes); err != nil {
+		return 0, err
+	}
+
 	product.AddetAt = time.Now()
 	// добавляется продукт в базу
 	productID, err = u.Repository.Product.AddProduct(ts, product)
@@ -47,19 +82,45 @@ func (u *ProductUseCase) AddProduct(ts transaction.Session, product product.Prod
 
 	lf["product_ID"] = productID
 
+	_, err = u.saveEvent(ts, lf, event.ProductCreated, productID, event.ProductCreatedPayload{
+		ProductID: productID,
+		Name:      product.Name,
+		Tags:      product.Tags,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// новый продукт может попасть в любой из закэшированных списков
+	ts.OnCommit(func() { u.cache.invalidate(productListCacheDep) })
+
 	// если пользователь не ввел варианты продукта то данные о продукте просто запишутся в базу
 	if product.VariantList == nil {
 		u.log.WithFields(lf).Info("продукт успешно добавлен в базу данных")
 
 		return productID, nil
 	} else {
-		// добавляются варианты продукта
+		// добавляются варианты продукта вместе с их штрихкодами
 		for _, v := range product.VariantList {
-			err = u.Repository.Product.AddProductVariantList(ts, productID, v)
+			if err = u.checkVariantUnit(ts, lf, v); err != nil {
+				return 0, err
+			}
+
+			if err = u.checkVariantCodes(ts, lf, v); err != nil {
+				return 0, err
+			}
+
+			variantID, err := u.Repository.Product.AddProductVariantList(ts, productID, v)
 			if err != nil {
 				u.log.WithFields(lf).Error("не удалось добавить варианты продукта", err)
-				err = global.ErrInternalError
-				return
+				return 0, global.ErrInternalError
+			}
+
+			for _, barcode := range v.Barcodes {
+				if err = u.Repository.Product.AddVariantBarcode(ts, variantID, barcode); err != nil {
+					u.log.WithFields(lf).Error("не удалось привязать штрихкод к варианту ", err)
+					return 0, global.ErrInternalError
+				}
 			}
 		}
 
@@ -106,6 +167,18 @@ func (u *ProductUseCase) AddProductPrice(ts transaction.Session, p product.Produ
 	}
 	lf["price_ID"] = priceID
 
+	_, err = u.saveEvent(ts, lf, event.PriceChanged, p.VariantID, event.PriceChangedPayload{
+		PriceID:   priceID,
+		VariantID: p.VariantID,
+		Price:     p.Price,
+		StartDate: p.StartDate,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	ts.OnCommit(func() { u.cache.invalidate(variantCacheDep(p.VariantID)) })
+
 	u.log.WithFields(lf).Info("цена продукта успешно добавлена в базу данных")
 	return priceID, err
 }
@@ -118,6 +191,16 @@ func (u *ProductUseCase) AddProductInStock(ts transaction.Session, p stock.Produ
 	if err := p.IsNullFields(); err != nil {
 		return 0, err
 	}
+	if p.Batch != nil {
+		if err := p.Batch.Validate(); err != nil {
+			return 0, err
+		}
+	}
+	// продукт нельзя добавить на удаленный склад
+	if _, err = u.loadStorage(ts, lf, p.StorageID); err != nil {
+		return 0, err
+	}
+
 	p.AddedAt = time.Now()
 	// проверка есть ли уже продукт на складе
 	isExist, err := u.Repository.Product.CheckProductInStock(ts, p)
@@ -127,15 +210,64 @@ func (u *ProductUseCase) AddProductInStock(ts transaction.Session, p stock.Produ
 		return
 	}
 
-	// если продукт уже имеется в базе обновляется его кол-во
-	if isExist {
+	// поступление партии или с себестоимостью добавляется к имеющемуся остатку,
+	// иначе партии и слои себестоимости в сумме не совпадут с остатком
+	quantity := p.Quantity
+	receipt := p.Batch != nil || p.UnitCost > 0
+
+	switch {
+	case isExist && receipt:
+		productStockID, quantity, err = u.Repository.Product.IncreaseProductInstock(ts, p)
+		if err != nil {
+			u.log.WithFields(lf).Error("не удалось увеличить кол-во продуктов на складе", err)
+			err = global.ErrInternalError
+			return
+		}
+	case isExist:
+		// если продукт уже имеется в базе обновляется его кол-во, но не ниже зарезервированного
+		var reserved int
+		if reserved, err = u.Repository.Reservation.FindReservedQuantity(ts, p.VariantID, p.StorageID); err != nil {
+			u.log.WithFields(lf).Error("не удалось получить зарезервированное кол-во ", err)
+			return 0, global.ErrInternalError
+		}
+
+		if p.Quantity < reserved {
+			return 0, stock.ErrBelowReserved
+		}
+
+		// прежний остаток нужен, чтобы списать разницу из себестоимости
+		var onHand int
+		if onHand, err = u.Repository.Reservation.LoadStockQuantity(ts, p.VariantID, p.StorageID); err != nil {
+			u.log.WithFields(lf).Error("не удалось получить остаток продукта на складе ", err)
+			return 0, global.ErrInternalError
+		}
+
 		productStockID, err = u.Repository.Product.UpdateProductInstock(ts, p)
 		if err != nil {
 			u.log.WithFields(lf).Error("не удалось обновить кол-во продуктов на складе", err)
 			err = global.ErrInternalError
 			return
 		}
-	} else {
+
+		// при уменьшении кол-ва продукт забирается из мест хранения, чтобы размещенного не было больше остатка
+		if err = u.Repository.Location.TrimLocationStock(ts, p.VariantID, p.StorageID, p.Quantity); err != nil {
+			u.log.WithFields(lf).Error("не удалось уменьшить кол-во продукта в местах хранения ", err)
+			return 0, global.ErrInternalError
+		}
+
+		// и из партий, чтобы в партиях не было больше остатка
+		if err = u.Repository.Stock.TrimBatches(ts, p.VariantID, p.StorageID, p.Quantity); err != nil {
+			u.log.WithFields(lf).Error("не удалось уменьшить остаток партий продукта ", err)
+			return 0, global.ErrInternalError
+		}
+
+		// и из себестоимости, чтобы слои и средняя себестоимость не превышали остаток
+		if p.Quantity < onHand {
+			if _, err = u.consumeCost(ts, lf, p.VariantID, p.StorageID, onHand-p.Quantity); err != nil {
+				return 0, err
+			}
+		}
+	default:
 		// если продукта нет на складе то он просто добавляется на склад
 		productStockID, err = u.Repository.Product.AddProductInStock(ts, p)
 		if err != nil {
@@ -146,10 +278,52 @@ func (u *ProductUseCase) AddProductInStock(ts transaction.Session, p stock.Produ
 	}
 	lf["product_in_stock_ID"] = productStockID
 
+	level := stock.StockLevel{VariantID: p.VariantID, StorageID: p.StorageID, Quantity: quantity}
+	if err = u.stockChanged(ts, lf, level); err != nil {
+		return 0, err
+	}
+
+	if p.Batch != nil {
+		batch := stock.Batch{VariantID: p.VariantID, StorageID: p.StorageID, Quantity: p.Quantity, BatchParams: *p.Batch}
+		if err = u.addBatch(ts, lf, batch); err != nil {
+			return 0, err
+		}
+	}
+
+	if p.UnitCost > 0 {
+		layer := valuation.CostLayer{VariantID: p.VariantID, StorageID: p.StorageID, Quantity: p.Quantity, UnitCost: p.UnitCost}
+		if err = u.receiveCost(ts, lf, layer); err != nil {
+			return 0, err
+		}
+	}
+
 	u.log.WithFields(lf).Info("продукт успешно добавлен на склад")
 	return productStockID, err
 }
 
+// saveEvent запись доменного события в outbox и доставок подписчикам в транзакции изменения,
+// событие будет опубликовано и доставлено только если транзакция зафиксируется
+func (u *ProductUseCase) saveEvent(ts transaction.Session, lf logrus.Fields, eventType string, aggregateID int, payload any) (eventID int64, err error) {
+	e, err := event.NewEvent(eventType, aggregateID, payload)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось сформировать событие ", eventType, err)
+		return 0, global.ErrInternalError
+	}
+
+	if e.EventID, err = u.Repository.Outbox.SaveEvent(ts, e); err != nil {
+		u.log.WithFields(lf).Error("не удалось записать событие ", eventType, err)
+		return 0, global.ErrInternalError
+	}
+
+	// доставки подписчикам создаются вместе с событием и отправляются только после фиксации
+	if err = u.Repository.Webhook.CreateDeliveryList(ts, e); err != nil {
+		u.log.WithFields(lf).Error("не удалось создать доставки события подписчикам ", eventType, err)
+		return 0, global.ErrInternalError
+	}
+
+	return e.EventID, nil
+}
+
 // FindProductInfoById логика получения всей информации о продукте и его вариантах по id
 func (u *ProductUseCase) FindProductInfoById(ts transaction.Session, productID int) (productInfo product.ProductInfo, err error) {
 	lf := logrus.Fields{"product_ID": productID}
@@ -159,6 +333,12 @@ func (u *ProductUseCase) FindProductInfoById(ts transaction.Session, productID i
 		return
 	}
 
+	key := productInfoCacheKey(productID)
+	if cached, exists := u.cache.get(key); exists {
+		return cached[0], nil
+	}
+	generation := u.cache.currentGeneration()
+
 	// поиск продукта по его id
 	productInfo, err = u.Repository.Product.LoadProductInfo(ts, productID)
 	if err != nil {
@@ -167,57 +347,51 @@ func (u *ProductUseCase) FindProductInfoById(ts transaction.Session, productID i
 		return
 	}
 
-	productInfo.VariantList, err = u.Repository.Product.FindProductVariantList(ts, productInfo.ProductID)
-	switch err {
-	case nil:
-	case global.ErrNoData:
-		return productInfo, nil
-	default:
-		u.log.WithFields(lf).Error("не удалось найти варианты продукта", err)
-		return product.ProductInfo{}, global.ErrInternalError
+	// загрузка вариантов продукта с актуальными ценами и складами
+	productList := []product.ProductInfo{productInfo}
+	if err = u.decodeAttributes(productList); err != nil {
+		return product.ProductInfo{}, err
 	}
 
-	for i, v := range productInfo.VariantList {
-		price, err := u.Repository.Product.FindCurrentPrice(ts, v.VariantID)
-		switch err {
-		case nil:
-		case global.ErrNoData:
-			continue
-		default:
-			u.log.WithFields(lf).Error("не удалось найти актуальную цену варианта продукта", err)
-			return product.ProductInfo{}, global.ErrInternalError
-		}
-
-		// получение актуальной цены для каждого варианта продукта
-		productInfo.VariantList[i].CurrentPrice = price
-
-		// получение id складов в которых есть этот продукт
-		inStorages, err := u.Repository.Product.InStorages(ts, v.VariantID)
-		switch err {
-		case nil:
-		case global.ErrNoData:
-			continue
-		default:
-			u.log.WithFields(lf).Error("не удалось найти склады в которых есть продукт", err)
-			return product.ProductInfo{}, global.ErrInternalError
-		}
+	if err = u.loadVariantList(ts, productList); err != nil {
+		return product.ProductInfo{}, err
+	}
 
-		productInfo.VariantList[i].InStorages = inStorages
+	if err = u.loadImageList(ts, productList); err != nil {
+		return product.ProductInfo{}, err
 	}
+	u.cache.add(generation, key, productList)
 
-	return productInfo, err
+	return productList[0], nil
 }
 
-// FindProductList логика получения списка продуктов по тегу и лимиту
-func (u *ProductUseCase) FindProductList(ts transaction.Session, tag, name string, limit int) (products []product.ProductInfo, err error) {
-	lf := logrus.Fields{"tag": tag, "limit": limit, "productName": name}
+// FindProductList логика получения списка продуктов по тегу, категории, значениям атрибутов и лимиту
+func (u *ProductUseCase) FindProductList(ts transaction.Session, tag, name string, limit int, f attribute.Filter) (products []product.ProductInfo, err error) {
+	lf := logrus.Fields{"tag": tag, "limit": limit, "productName": name, "filter": f.String()}
 	// если лимит не указан или некорректен то по умолчанию устанавливается 3
 	if limit == 0 || limit < 0 {
 		limit = 3
 	}
 
-	// если пользователь ввел тег продукта произойдет поиск продуктов по данному тегу
-	if tag != "" || name != "" {
+	key := productListCacheKey(tag, name, limit, f)
+	if cached, exists := u.cache.get(key); exists {
+		return cached, nil
+	}
+	generation := u.cache.currentGeneration()
+
+	// при отборе по категории или атрибутам тег и название проверяются тем же запросом
+	if !f.IsEmpty() {
+		products, err = u.Repository.Product.FindProductListByAttributes(ts, f, tag, name, limit)
+		switch err {
+		case nil:
+		case global.ErrNoData:
+			products = []product.ProductInfo{}
+		default:
+			u.log.WithFields(lf).Error("не удалось найти продукты по атрибутам ", err)
+			return nil, global.ErrInternalError
+		}
+	} else if tag != "" || name != "" {
+		// если пользователь ввел тег продукта произойдет поиск продуктов по данному тегу
 
 		if tag != "" && name == "" {
 			products, err = u.Repository.Product.FindProductListByTag(ts, tag, limit)
@@ -241,46 +415,6 @@ func (u *ProductUseCase) FindProductList(ts transaction.Session, tag, name strin
 				return
 			}
 		}
-
-		// поиск вариантов продука
-		for i := range products {
-			vars, err := u.Repository.Product.FindProductVariantList(ts, products[i].ProductID)
-			switch err {
-			case nil:
-			case global.ErrNoData:
-				return products, nil
-			default:
-				u.log.WithFields(lf).Error("не удалось найти варианты продукта", err)
-				return nil, global.ErrInternalError
-			}
-
-			products[i].VariantList = vars
-			variantList := products[i].VariantList
-			for j := range variantList {
-				price, err := u.Repository.Product.FindCurrentPrice(ts, variantList[j].VariantID)
-				switch err {
-				case nil:
-				case global.ErrNoData:
-					continue
-				default:
-					u.log.WithFields(lf).Error("не удалось найти актуальную цену продукта", err)
-					return nil, global.ErrInternalError
-				}
-
-				variantList[j].CurrentPrice = price
-				inStorages, err := u.Repository.Product.InStorages(ts, variantList[j].VariantID)
-				switch err {
-				case nil:
-				case global.ErrNoData:
-					continue
-				default:
-					u.log.Error("не удалось найти склады в которых есть продукт", err)
-					return nil, global.ErrInternalError
-				}
-
-				variantList[j].InStorages = inStorages
-			}
-		}
 	} else {
 		// если пользователь не ввел тег то просто прозойдет поиск всех продуктов с лимитом вывода
 		products, err = u.Repository.Product.LoadProductList(ts, limit)
@@ -289,103 +423,295 @@ func (u *ProductUseCase) FindProductList(ts transaction.Session, tag, name strin
 			err = global.ErrInternalError
 			return
 		}
+	}
 
-		for i := range products {
-			vars, err := u.Repository.Product.FindProductVariantList(ts, products[i].ProductID)
-			switch err {
-			case nil:
-			case global.ErrNoData:
-				return products, nil
-			default:
-				u.log.WithFields(lf).Error("не удалось найти варианты продукта", err)
-				return nil, global.ErrInternalError
-			}
+	if err = u.decodeAttributes(products); err != nil {
+		return nil, err
+	}
 
-			products[i].VariantList = vars
-			variants := products[i].VariantList
-			for j := range variants {
-				price, err := u.Repository.Product.FindCurrentPrice(ts, variants[j].VariantID)
-				switch err {
-				case nil:
-				case global.ErrNoData:
-					continue
-				default:
-					u.log.WithFields(lf).Error("не удалось найти актуальную цену продукта", err)
-					return nil, global.ErrInternalError
-				}
+	// загрузка вариантов продуктов с актуальными ценами и складами
+	if err = u.loadVariantList(ts, products); err != nil {
+		return nil, err
+	}
 
-				variants[j].CurrentPrice = price
-				inStorages, err := u.Repository.Product.InStorages(ts, variants[j].VariantID)
-				switch err {
-				case nil:
-				case global.ErrNoData:
-					continue
-				default:
-					u.log.WithFields(lf).Error("не удалось найти склады в которых есть продукт", err)
-					return nil, global.ErrInternalError
-				}
+	if err = u.loadImageList(ts, products); err != nil {
+		return nil, err
+	}
+	u.cache.add(generation, key, products, productListCacheDep)
 
-				variants[j].InStorages = inStorages
-			}
+	return products, nil
+}
+
+// loadVariantList загрузка вариантов списка продуктов вместе с актуальными ценами и складами,
+// выполняется постоянное кол-во запросов независимо от кол-ва продуктов и вариантов
+func (u *ProductUseCase) loadVariantList(ts transaction.Session, productList []product.ProductInfo) error {
+	if len(productList) == 0 {
+		return nil
+	}
+
+	productIDList := make([]int, 0, len(productList))
+	for _, p := range productList {
+		productIDList = append(productIDList, p.ProductID)
+	}
+	lf := logrus.Fields{"product_ID_list": productIDList}
+
+	variantList, err := u.Repository.Product.FindVariantListByProductIDList(ts, productIDList)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти варианты продуктов", err)
+		return global.ErrInternalError
+	}
+
+	variantIDList := make([]int, 0, len(variantList))
+	for _, v := range variantList {
+		variantIDList = append(variantIDList, v.VariantID)
+	}
+
+	// получение актуальных цен вариантов продуктов
+	priceList, err := u.Repository.Product.FindCurrentPriceListByVariantIDList(ts, variantIDList)
+	switch err {
+	case nil, global.ErrNoData:
+	default:
+		u.log.WithFields(lf).Error("не удалось найти актуальные цены вариантов продуктов", err)
+		return global.ErrInternalError
+	}
+
+	// получение складов в которых есть варианты продуктов
+	storageList, err := u.Repository.Product.FindStorageListByVariantIDList(ts, variantIDList)
+	switch err {
+	case nil, global.ErrNoData:
+	default:
+		u.log.WithFields(lf).Error("не удалось найти склады в которых есть продукты", err)
+		return global.ErrInternalError
+	}
+
+	priceByVariant := make(map[int]float64, len(priceList))
+	for _, p := range priceList {
+		priceByVariant[p.VariantID] = p.Price
+	}
+
+	storagesByVariant := make(map[int][]product.VarStorage)
+	for _, s := range storageList {
+		storagesByVariant[s.VariantID] = append(storagesByVariant[s.VariantID], s.VarStorage)
+	}
+
+	variantsByProduct := make(map[int][]product.Variant)
+	for _, v := range variantList {
+		v.CurrentPrice = priceByVariant[v.VariantID]
+		v.SetPricePerBaseUnit()
+		v.InStorages = storagesByVariant[v.VariantID]
+		variantsByProduct[v.ProductID] = append(variantsByProduct[v.ProductID], v)
+	}
+
+	for i := range productList {
+		productList[i].VariantList = variantsByProduct[productList[i].ProductID]
+	}
+
+	return nil
+}
+
+// checkVariantUnit проверка меры варианта и наличия его единицы измерения в справочнике
+func (u *ProductUseCase) checkVariantUnit(ts transaction.Session, lf logrus.Fields, v product.Variant) error {
+	if v.Weight <= 0 {
+		return errors.New("мера варианта должна быть больше 0")
+	}
+
+	_, err := u.Repository.Unit.LoadUnit(ts, v.Unit)
+	switch err {
+	case nil:
+		return nil
+	case global.ErrNoData:
+		return unit.ErrUnknownUnit
+	default:
+		u.log.WithFields(lf).Error("не удалось проверить единицу измерения ", err)
+		return global.ErrInternalError
+	}
+}
+
+// checkVariantCodes проверка, что артикул и штрихкоды нового варианта корректны и не заняты другими вариантами
+func (u *ProductUseCase) checkVariantCodes(ts transaction.Session, lf logrus.Fields, v product.Variant) error {
+	if v.SKU.Valid && v.SKU.String != "" {
+		_, err := u.Repository.Product.FindVariantIDBySKU(ts, v.SKU.String)
+		switch err {
+		case nil:
+			return product.ErrSKUExists
+		case global.ErrNoData:
+		default:
+			u.log.WithFields(lf).Error("не удалось проверить артикул ", err)
+			return global.ErrInternalError
+		}
+	}
+
+	seen := make(map[string]bool, len(v.Barcodes))
+	for _, barcode := range v.Barcodes {
+		if seen[barcode] {
+			return product.ErrBarcodeExists
+		}
+		seen[barcode] = true
+
+		if err := u.checkBarcodeFree(ts, lf, barcode); err != nil {
+			return err
 		}
 	}
 
-	return products, err
+	return nil
 }
 
-// FindProductsInStock логика получения всех складов и продуктов в ней или фильтрация по продукту
-func (u *ProductUseCase) FindProductsInStock(ts transaction.Session, productID int) (stockList []stock.Stock, err error) {
-	lf := logrus.Fields{"product_ID": productID}
+// checkBarcodeFree проверка, что штрихкод корректен и не привязан ни к одному варианту
+func (u *ProductUseCase) checkBarcodeFree(ts transaction.Session, lf logrus.Fields, barcode string) error {
+	if err := product.ValidateEAN13(barcode); err != nil {
+		return err
+	}
+
+	_, err := u.Repository.Product.FindVariantIDByBarcode(ts, barcode)
+	switch err {
+	case nil:
+		return product.ErrBarcodeExists
+	case global.ErrNoData:
+		return nil
+	default:
+		u.log.WithFields(lf).Error("не удалось проверить штрихкод ", err)
+		return global.ErrInternalError
+	}
+}
+
+// AddVariantBarcode привязка дополнительного штрихкода к варианту
+func (u *ProductUseCase) AddVariantBarcode(ts transaction.Session, p product.BarcodeParams) error {
+	lf := logrus.Fields{"variant_ID": p.VariantID, "barcode": p.Barcode}
+
+	if p.VariantID <= 0 {
+		return errors.New("id варианта не может быть меньше или равен 0")
+	}
+
+	if err := u.checkBarcodeFree(ts, lf, p.Barcode); err != nil {
+		return err
+	}
+
+	if err := u.Repository.Product.AddVariantBarcode(ts, p.VariantID, p.Barcode); err != nil {
+		u.log.WithFields(lf).Error("не удалось привязать штрихкод к варианту ", err)
+		return global.ErrInternalError
+	}
+
+	u.log.WithFields(lf).Info("штрихкод привязан к варианту")
+	return nil
+}
+
+// findVariantIDByBarcode id варианта по штрихкоду
+func (u *ProductUseCase) findVariantIDByBarcode(ts transaction.Session, lf logrus.Fields, barcode string) (int, error) {
+	if err := product.ValidateEAN13(barcode); err != nil {
+		return 0, err
+	}
+
+	variantID, err := u.Repository.Product.FindVariantIDByBarcode(ts, barcode)
+	switch err {
+	case nil:
+		return variantID, nil
+	case global.ErrNoData:
+		return 0, product.ErrBarcodeNotFound
+	default:
+		u.log.WithFields(lf).Error("не удалось найти вариант по штрихкоду ", err)
+		return 0, global.ErrInternalError
+	}
+}
+
+// FindVariantByBarcode вариант по штрихкоду с актуальной ценой и доступным для продажи кол-вом на складах
+func (u *ProductUseCase) FindVariantByBarcode(ts transaction.Session, barcode string) (v product.BarcodeVariant, err error) {
+	lf := logrus.Fields{"barcode": barcode}
+
+	if err = product.ValidateEAN13(barcode); err != nil {
+		return v, err
+	}
+
+	v, err = u.Repository.Product.FindVariantByBarcode(ts, barcode)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return v, product.ErrBarcodeNotFound
+	default:
+		u.log.WithFields(lf).Error("не удалось найти вариант по штрихкоду ", err)
+		return v, global.ErrInternalError
+	}
+
+	lf["variant_ID"] = v.VariantID
+
+	v.CurrentPrice, err = u.Repository.Product.FindCurrentPrice(ts, v.VariantID)
+	switch err {
+	case nil, global.ErrNoData:
+	default:
+		u.log.WithFields(lf).Error("не удалось найти цену варианта ", err)
+		return v, global.ErrInternalError
+	}
+
+	v.SetPricePerBaseUnit()
+
+	v.InStorages, err = u.Repository.Product.InStorages(ts, v.VariantID)
+	switch err {
+	case nil, global.ErrNoData:
+	default:
+		u.log.WithFields(lf).Error("не удалось найти склады варианта ", err)
+		return v, global.ErrInternalError
+	}
+
+	v.Barcodes, err = u.Repository.Product.FindVariantBarcodeList(ts, v.VariantID)
+	switch err {
+	case nil, global.ErrNoData:
+	default:
+		u.log.WithFields(lf).Error("не удалось найти штрихкоды варианта ", err)
+		return v, global.ErrInternalError
+	}
+
+	return v, nil
+}
+
+// FindProductsInStock логика получения всех складов и продуктов в ней или фильтрация по продукту,
+// при byLocation кол-во вариантов разбивается по местам хранения
+func (u *ProductUseCase) FindProductsInStock(ts transaction.Session, productID int, byLocation bool) (stockList []stock.Stock, err error) {
+	lf := logrus.Fields{"product_ID": productID, "by_location": byLocation}
 
 	if productID < 0 {
 		err = errors.New("id продукта не может быть меньше нуля")
 		return
 	}
 
-	// если пользователь не ввел id продукта то будет выполнен поиск всех складов
+	// если пользователь не ввел id продукта то будет выполнен поиск всех складов,
+	// иначе склады фильтруются по id продукта
 	if productID == 0 {
-		stockList, err = u.Repository.Product.LoadStockList(ts)
+		stockList, err = u.Repository.Product.LoadStockList(ts, false)
 		if err != nil {
 			u.log.WithFields(lf).Error("не удалось найти список складов", err)
 			err = global.ErrInternalError
 			return
 		}
-
-		for i, v := range stockList {
-			variants, err := u.Repository.Product.FindStocksVariantList(ts, v.StorageID)
-			switch err {
-			case nil:
-			case global.ErrNoData:
-				return stockList, nil
-			default:
-				u.log.WithFields(lf).Error("не удалось найти варианты продукта на складе", err)
-				return nil, global.ErrInternalError
-			}
-
-			stockList[i].ProductVariantList = variants
-		}
 	} else {
-
-		// если же пользователь ввел id продукта то произойдет фильтрация складов по id продукта
 		stockList, err = u.Repository.Product.FindStockListByProductId(ts, productID)
 		if err != nil {
 			u.log.WithFields(lf).Error("не удалось найти склады с продуктами по данному id", err)
 			err = global.ErrInternalError
 			return
 		}
-		for i, v := range stockList {
-			variants, err := u.Repository.Product.FindStocksVariantList(ts, v.StorageID)
-			switch err {
-			case nil:
-			case global.ErrNoData:
-				return stockList, nil
-			default:
-				u.log.WithFields(lf).Error("не удалось найти варианты продукта на складе", err)
-				return nil, global.ErrInternalError
-			}
+	}
+
+	byStorage, err := u.findStockVariants(ts, lf, stockList)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, v := range stockList {
+		variants, exists := byStorage[v.StorageID]
+		if !exists {
+			continue
+		}
 
-			stockList[i].ProductVariantList = variants
+		if byLocation {
+			if err := u.breakDownByLocation(ts, lf, v.StorageID, variants); err != nil {
+				return nil, err
+			}
 		}
+
+		stockList[i].ProductVariantList = variants
 	}
 	lf["stock_list"] = stockList
 
@@ -393,8 +719,93 @@ func (u *ProductUseCase) FindProductsInStock(ts transaction.Session, productID i
 	return stockList, err
 }
 
+// findStockVariants варианты продуктов складов списка одним запросом, сгруппированные по id склада
+func (u *ProductUseCase) findStockVariants(ts transaction.Session, lf logrus.Fields, stockList []stock.Stock) (map[int][]stock.ProductInStockParams, error) {
+	if len(stockList) == 0 {
+		return nil, nil
+	}
+
+	storageIDList := make([]int, 0, len(stockList))
+	for _, s := range stockList {
+		storageIDList = append(storageIDList, s.StorageID)
+	}
+
+	variantList, err := u.Repository.Product.FindStocksVariantListByStorageIDList(ts, storageIDList)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return nil, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти варианты продукта на складах", err)
+		return nil, global.ErrInternalError
+	}
+
+	byStorage := make(map[int][]stock.ProductInStockParams, len(stockList))
+	for _, v := range variantList {
+		byStorage[v.StorageID] = append(byStorage[v.StorageID], v)
+	}
+
+	return byStorage, nil
+}
+
+// breakDownByLocation разбивка кол-ва вариантов склада по местам хранения
+func (u *ProductUseCase) breakDownByLocation(ts transaction.Session, lf logrus.Fields, storageID int, variants []stock.ProductInStockParams) error {
+	locationStockList, err := u.Repository.Location.FindLocationStockList(ts, storageID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+	default:
+		u.log.WithFields(lf).Error("не удалось найти кол-во продукта по местам хранения ", err)
+		return global.ErrInternalError
+	}
+
+	byVariant := make(map[int][]location.Stock)
+	for _, ls := range locationStockList {
+		byVariant[ls.VariantID] = append(byVariant[ls.VariantID], ls)
+	}
+
+	for i, v := range variants {
+		variants[i].Locations = byVariant[v.VariantID]
+		variants[i].Unplaced = v.Quantity
+		for _, ls := range variants[i].Locations {
+			variants[i].Unplaced -= ls.Quantity
+		}
+	}
+
+	return nil
+}
+
+// SubscribeStockChanges подписка на изменения кол-ва продуктов на складах. id изменений совпадают с id событий outbox;
+// если lastEventID еще хранится в истории, пропущенные после него изменения возвращаются в Backlog
+func (u *ProductUseCase) SubscribeStockChanges(lastEventID int64, hasLastEventID bool) *broadcast.Subscription[stock.StockLevel] {
+	return u.stockHub.Subscribe(lastEventID, hasLastEventID)
+}
+
+// FindStockLevelList текущее кол-во продуктов на складах из списка, при пустом списке на всех складах
+func (u *ProductUseCase) FindStockLevelList(ts transaction.Session, storageIDList []int) ([]stock.StockLevel, error) {
+	lf := logrus.Fields{"storage_ID_list": storageIDList}
+
+	levelList, err := u.Repository.Product.FindStockLevelList(ts, storageIDList)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return []stock.StockLevel{}, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти кол-во продуктов на складах ", err)
+		return nil, global.ErrInternalError
+	}
+
+	return levelList, nil
+}
+
 // SaveSale логuка записи о покупке в базу
 func (u *ProductUseCase) SaveSale(ts transaction.Session, p product.SaleParams) (saleID int, err error) {
+	return u.saveSale(ts, p, 0)
+}
+
+// saveSale запись продажи, продается только свободный от резервов остаток.
+// ownReserved кол-во из резерва, который превращается в эту продажу
+func (u *ProductUseCase) saveSale(ts transaction.Session, p product.SaleParams, ownReserved int) (saleID int, err error) {
 	lf := p.Log()
 	lf["sale_params"] = p
 
@@ -403,6 +814,19 @@ func (u *ProductUseCase) SaveSale(ts transaction.Session, p product.SaleParams)
 		return 0, err
 	}
 
+	// кассир может указать вариант отсканированным штрихкодом
+	if p.VariantID == 0 {
+		if p.VariantID, err = u.findVariantIDByBarcode(ts, lf, p.Barcode); err != nil {
+			return 0, err
+		}
+		lf["variant_ID"] = p.VariantID
+	}
+
+	// на удаленном складе продавать нечего
+	if _, err = u.loadStorage(ts, lf, p.StorageID); err != nil {
+		return 0, err
+	}
+
 	// получение цены варианта
 	price, err := u.Repository.Product.FindPrice(ts, p.VariantID)
 	if err != nil {
@@ -411,13 +835,41 @@ func (u *ProductUseCase) SaveSale(ts transaction.Session, p product.SaleParams)
 		return
 	}
 
-	// подсчет общей цены продажи
-	p.TotalPrice = price * float64(p.Quantity)
-	if p.TotalPrice == 0 {
+	// покупателю продается по цене прайс-листа его группы, если она задана
+	if p.CustomerID != 0 {
+		lf["customer_ID"] = p.CustomerID
+		if price, err = u.customerPrice(ts, lf, p.CustomerID, p.VariantID, price, p.SoldAt); err != nil {
+			return 0, err
+		}
+	}
+
+	// подсчет общей цены продажи с учетом действующих акций
+	quote, err := u.priceSale(ts, lf, p.VariantID, p.StorageID, p.Quantity, price, p.SoldAt)
+	if err != nil {
+		return 0, err
+	}
+	if quote.Gross == 0 {
 		u.log.WithFields(lf).Error("общая цена не может быть равна 0")
 		err = global.ErrInternalError
 		return
 	}
+	p.TotalPrice, p.Discount, p.PromotionID = quote.Total, quote.Discount, quote.PromotionID
+
+	// оплаты проверяются до изменения остатков
+	paymentList, err := u.salePayments(p)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = u.checkAvailable(ts, lf, p.VariantID, p.StorageID, p.Quantity, ownReserved); err != nil {
+		return 0, err
+	}
+
+	// продажа уменьшает остаток на складе, себестоимость сохраняется вместе с продажей
+	if p.CostOfGoods, err = u.decreaseStock(ts, lf, p.VariantID, p.StorageID, p.Quantity, true); err != nil {
+		return 0, err
+	}
+
 	// запись продажи в базу
 	saleID, err = u.Repository.Product.SaveSale(ts, p)
 	if err != nil {
@@ -428,6 +880,23 @@ func (u *ProductUseCase) SaveSale(ts transaction.Session, p product.SaleParams)
 
 	lf["sale_ID"] = saleID
 
+	if err = u.savePayments(ts, lf, saleID, paymentList); err != nil {
+		return 0, err
+	}
+
+	_, err = u.saveEvent(ts, lf, event.SaleRecorded, saleID, event.SaleRecordedPayload{
+		SaleID:     saleID,
+		VariantID:  p.VariantID,
+		StorageID:  p.StorageID,
+		Quantity:   p.Quantity,
+		TotalPrice: p.TotalPrice,
+		SoldAt:     p.SoldAt,
+		CustomerID: p.CustomerID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
 	u.log.WithFields(lf).Info("продажа успешно добавлена в базу данных")
 	return saleID, err
 }
@@ -473,8 +942,8 @@ func (u *ProductUseCase) FindSaleList(ts transaction.Session, sq product.SaleQue
 	return saleList, err
 }
 
-func (u *ProductUseCase) LoadStockList(ts transaction.Session) (stockList []stock.Stock, err error) {
-	stockList, err = u.Repository.Product.LoadStockList(ts)
+func (u *ProductUseCase) LoadStockList(ts transaction.Session, withRemoved bool) (stockList []stock.Stock, err error) {
+	stockList, err = u.Repository.Product.LoadStockList(ts, withRemoved)
 	lf := logrus.Fields{"stockList": stockList}
 
 	switch err {
@@ -492,8 +961,7 @@ func (u *ProductUseCase) LoadStockList(ts transaction.Session) (stockList []stoc
 func (u *ProductUseCase) AddStock(ts transaction.Session, storage stock.StockParams) (stockID int, err error) {
 	lf := storage.Log()
 
-	if storage.StorageName == "" {
-		err = errors.New("название склада не может быть пустым")
+	if err = storage.Validate(); err != nil {
 		return
 	}
 
@@ -512,17 +980,79 @@ func (u *ProductUseCase) AddStock(ts transaction.Session, storage stock.StockPar
 	return stockID, err
 }
 
+// loadStorage неудаленный склад
+func (u *ProductUseCase) loadStorage(ts transaction.Session, lf logrus.Fields, storageID int) (stock.Stock, error) {
+	if storageID <= 0 {
+		return stock.Stock{}, errors.New("id склада не может быть меньше или равен 0")
+	}
+
+	s, err := u.Repository.Product.LoadStorage(ts, storageID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return stock.Stock{}, errors.New("склад не найден")
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить склад ", err)
+		return stock.Stock{}, global.ErrInternalError
+	}
+
+	if s.RemovedAt.Valid {
+		return stock.Stock{}, stock.ErrStorageRemoved
+	}
+
+	return s, nil
+}
+
+// UpdateStock изменение склада, вместимость не может быть меньше продукта, уже находящегося на складе
+func (u *ProductUseCase) UpdateStock(ts transaction.Session, storage stock.StockParams) (err error) {
+	lf := storage.Log()
+
+	if err = storage.Validate(); err != nil {
+		return err
+	}
+
+	s, err := u.loadStorage(ts, lf, storage.StorageID)
+	if err != nil {
+		return err
+	}
+
+	if storage.Capacity.Valid && int64(s.Occupied) > storage.Capacity.Int64 {
+		return fmt.Errorf("на складе уже находится %d ед. продукта, это больше новой вместимости", s.Occupied)
+	}
+
+	if err = u.Repository.Product.UpdateStock(ts, storage); err != nil {
+		u.log.WithFields(lf).Error("не удалось изменить склад ", err)
+		return global.ErrInternalError
+	}
+
+	ts.OnCommit(func() { u.cache.invalidate(storageCacheDep(storage.StorageID)) })
+
+	u.log.WithFields(lf).Info("склад успешно изменен")
+	return nil
+}
+
+// DeleteStock удаление пустого склада, история продаж и движений по складу сохраняется
 func (u *ProductUseCase) DeleteStock(ts transaction.Session, storage stock.StockParams) (err error) {
 	lf := storage.Log()
 
-	err = u.Repository.Product.DeleteStock(ts, storage)
+	s, err := u.loadStorage(ts, lf, storage.StorageID)
+	if err != nil {
+		return err
+	}
+
+	if s.Occupied != 0 {
+		return stock.ErrStorageNotEmpty
+	}
+
+	err = u.Repository.Product.DeleteStock(ts, storage.StorageID)
 	if err != nil {
-		fmt.Println(1111)
 		u.log.WithFields(lf).Error("не удалось удалить склад ", err)
 		err = global.ErrInternalError
 		return
 	}
 
+	ts.OnCommit(func() { u.cache.invalidate(storageCacheDep(storage.StorageID)) })
+
 	u.log.WithFields(lf).Info("склад успешно удален")
 	return err
 }
@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"product_storage/config"
+	"product_storage/internal/entity/attribute"
+	"product_storage/internal/entity/product"
+	"product_storage/tools/inmemorycache"
+	"product_storage/tools/rediscache"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// cacheJanitorInterval период удаления устаревших значений из кэша
+const cacheJanitorInterval = time.Minute
+
+// productListCacheDep зависимость всех закэшированных списков продуктов, сбрасывается при добавлении продукта
+const productListCacheDep = "product_list"
+
+// taggedCacheStorage хранилище кэша с тегами, реализуется в памяти процесса и в redis
+type taggedCacheStorage[T any] interface {
+	Get(key string) (t T, exists bool, err error)
+	Generation() (int64, error)
+	Add(generation int64, key string, data T, tags ...string) error
+	Invalidate(tags ...string) error
+	ClearExpired()
+}
+
+// CacheStats статистика кэша продуктов
+type CacheStats struct {
+	Backend string `json:"backend"` // используемое хранилище кэша
+	Hits    int64  `json:"hits"`    // кол-во найденных в кэше значений
+	Misses  int64  `json:"misses"`  // кол-во отсутствующих значений
+	Errors  int64  `json:"errors"`  // кол-во ошибок хранилища, при ошибке данные загружаются из базы
+}
+
+// productCache кэш информации о продуктах и списков продуктов.
+// Каждое значение помнит продукты, варианты и склады, из которых оно собрано,
+// и сбрасывается при изменении любого из них.
+// Ошибки хранилища не прерывают запрос: значение загружается из базы
+type productCache struct {
+	log     *logrus.Logger
+	enabled bool
+	backend string
+	storage taggedCacheStorage[[]product.ProductInfo]
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+// newProductCache кэш с временем жизни значений lifetime, при нулевом времени жизни кэш отключен.
+// При backend redis значения хранятся в общем для всех экземпляров сервиса redis
+func newProductCache(log *logrus.Logger, backend string, client redis.UniversalClient, prefix string, lifetime time.Duration) *productCache {
+	c := &productCache{
+		log:     log,
+		enabled: lifetime > 0,
+		backend: backend,
+	}
+
+	switch {
+	case backend == config.CacheBackendRedis && client != nil:
+		c.storage = rediscache.NewTaggedCache[[]product.ProductInfo](client, prefix, lifetime)
+	default:
+		c.backend = config.CacheBackendMemory
+		c.storage = inmemorycache.NewTaggedCache[[]product.ProductInfo](lifetime)
+	}
+
+	return c
+}
+
+func productInfoCacheKey(productID int) string {
+	return fmt.Sprintf("info:%d", productID)
+}
+
+func productListCacheKey(tag, name string, limit int, f attribute.Filter) string {
+	return fmt.Sprintf("list:%s|%s|%d|%s", tag, name, limit, f.String())
+}
+
+func productCacheDep(productID int) string {
+	return fmt.Sprintf("product:%d", productID)
+}
+
+func variantCacheDep(variantID int) string {
+	return fmt.Sprintf("variant:%d", variantID)
+}
+
+func storageCacheDep(storageID int) string {
+	return fmt.Sprintf("storage:%d", storageID)
+}
+
+// storageError учет и логирование ошибки хранилища
+func (c *productCache) storageError(operation string, err error) {
+	c.errors.Add(1)
+	c.log.Warnf("ошибка кэша %s при %s: %v", c.backend, operation, err)
+}
+
+// get значение кэша по ключу
+func (c *productCache) get(key string) ([]product.ProductInfo, bool) {
+	if !c.enabled {
+		return nil, false
+	}
+
+	productList, exists, err := c.storage.Get(key)
+	if err != nil {
+		c.storageError("чтении", err)
+	}
+
+	if !exists {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	return productList, true
+}
+
+// currentGeneration текущий счетчик сбросов, запоминается перед загрузкой значения из базы.
+// При ошибке возвращается -1, такое значение не будет сохранено
+func (c *productCache) currentGeneration() int64 {
+	if !c.enabled {
+		return 0
+	}
+
+	generation, err := c.storage.Generation()
+	if err != nil {
+		c.storageError("получении счетчика сбросов", err)
+		return -1
+	}
+
+	return generation
+}
+
+// add сохранение значения, загруженного из базы после получения счетчика generation;
+// если за время загрузки кэш сбрасывался, значение могло устареть и не сохраняется
+func (c *productCache) add(generation int64, key string, productList []product.ProductInfo, extraDeps ...string) {
+	if !c.enabled || generation < 0 {
+		return
+	}
+
+	deps := append([]string{}, extraDeps...)
+	for _, p := range productList {
+		deps = append(deps, productCacheDep(p.ProductID))
+		for _, v := range p.VariantList {
+			deps = append(deps, variantCacheDep(v.VariantID))
+			for _, s := range v.InStorages {
+				deps = append(deps, storageCacheDep(s.StorageID))
+			}
+		}
+	}
+
+	if err := c.storage.Add(generation, key, productList, deps...); err != nil {
+		c.storageError("сохранении", err)
+	}
+}
+
+// invalidate сброс значений, зависящих от deps
+func (c *productCache) invalidate(deps ...string) {
+	if !c.enabled {
+		return
+	}
+
+	if err := c.storage.Invalidate(deps...); err != nil {
+		c.storageError("сбросе", err)
+	}
+}
+
+// stats статистика попаданий и промахов
+func (c *productCache) stats() CacheStats {
+	return CacheStats{
+		Backend: c.backend,
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Errors:  c.errors.Load(),
+	}
+}
+
+// runJanitor периодическое удаление устаревших значений до отмены ctx
+func (c *productCache) runJanitor(ctx context.Context, interval time.Duration) {
+	if !c.enabled {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.storage.ClearExpired()
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"errors"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/product"
+	"product_storage/internal/entity/promotion"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/sqlnull"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// priceSale расчет суммы продажи quantity единиц варианта по цене price в момент at.
+// Из действующих на вариант и склад акций применяется одна, дающая наибольшую скидку
+func (u *ProductUseCase) priceSale(ts transaction.Session, lf logrus.Fields, variantID, storageID, quantity int, price float64, at time.Time) (promotion.Quote, error) {
+	quote := promotion.Quote{
+		VariantID: variantID,
+		StorageID: storageID,
+		Quantity:  quantity,
+		Price:     price,
+		Gross:     price * float64(quantity),
+	}
+	quote.Total = quote.Gross
+
+	promotionList, err := u.Repository.Promotion.FindApplicablePromotionList(ts, variantID, storageID, at)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return quote, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти действующие акции ", err)
+		return promotion.Quote{}, global.ErrInternalError
+	}
+
+	best, discount, found := promotion.Best(promotionList, price, quantity)
+	if !found {
+		return quote, nil
+	}
+
+	quote.Discount = discount
+	quote.Total = quote.Gross - discount
+	quote.PromotionID = sqlnull.NewInt64(best.PromotionID)
+	quote.PromotionName = sqlnull.NewString(best.Name)
+
+	lf["promotion_ID"] = best.PromotionID
+	return quote, nil
+}
+
+// PromotionUseCase акции и скидки. Акции применяются автоматически при расчете цены продажи
+type PromotionUseCase struct {
+	log     *logrus.Logger
+	product *ProductUseCase
+	rimport.RepositoryImports
+}
+
+func NewPromotion(log *logrus.Logger, ri rimport.RepositoryImports, product *ProductUseCase) *PromotionUseCase {
+	return &PromotionUseCase{
+		log:               log,
+		product:           product,
+		RepositoryImports: ri,
+	}
+}
+
+// AddPromotion создание акции, без даты начала акция действует сразу
+func (u *PromotionUseCase) AddPromotion(ts transaction.Session, p promotion.Params) (promotionID int, err error) {
+	lf := p.Log()
+
+	if p.StartsAt.IsZero() {
+		p.StartsAt = time.Now()
+	}
+
+	if err = p.Validate(); err != nil {
+		return 0, err
+	}
+
+	if err = u.checkTarget(ts, lf, p); err != nil {
+		return 0, err
+	}
+
+	pr := promotion.Promotion{
+		Name:         p.Name,
+		Kind:         p.Kind,
+		Value:        p.Value,
+		BuyQuantity:  p.BuyQuantity,
+		FreeQuantity: p.FreeQuantity,
+		MinAmount:    p.MinAmount,
+		StartsAt:     p.StartsAt,
+		EndsAt:       p.EndsAt,
+	}
+	if p.VariantID > 0 {
+		pr.VariantID = sqlnull.NewInt64(p.VariantID)
+	}
+	if p.Tag != "" {
+		pr.Tag = sqlnull.NewString(p.Tag)
+	}
+	if p.CategoryID > 0 {
+		pr.CategoryID = sqlnull.NewInt64(p.CategoryID)
+	}
+	if p.StorageID > 0 {
+		pr.StorageID = sqlnull.NewInt64(p.StorageID)
+	}
+
+	promotionID, err = u.Repository.Promotion.AddPromotion(ts, pr)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось создать акцию ", err)
+		return 0, global.ErrInternalError
+	}
+
+	lf["promotion_ID"] = promotionID
+	u.log.WithFields(lf).Info("акция создана")
+	return promotionID, nil
+}
+
+// checkTarget проверка существования варианта, категории и склада, на которые действует акция
+func (u *PromotionUseCase) checkTarget(ts transaction.Session, lf logrus.Fields, p promotion.Params) error {
+	if p.VariantID > 0 {
+		_, err := u.Repository.Product.FindPrice(ts, p.VariantID)
+		switch err {
+		case nil:
+		case global.ErrNoData:
+			return errors.New("вариант не найден или у него нет цены")
+		default:
+			u.log.WithFields(lf).Error("не удалось найти цену варианта продукта ", err)
+			return global.ErrInternalError
+		}
+	}
+
+	if p.CategoryID > 0 {
+		_, err := u.Repository.Attribute.LoadCategory(ts, p.CategoryID)
+		switch err {
+		case nil:
+		case global.ErrNoData:
+			return errors.New("категория не найдена")
+		default:
+			u.log.WithFields(lf).Error("не удалось загрузить категорию ", err)
+			return global.ErrInternalError
+		}
+	}
+
+	if p.StorageID > 0 {
+		if _, err := u.product.loadStorage(ts, lf, p.StorageID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FindPromotionList все акции или только действующие сейчас
+func (u *PromotionUseCase) FindPromotionList(ts transaction.Session, activeOnly bool) ([]promotion.Promotion, error) {
+	var at time.Time
+	if activeOnly {
+		at = time.Now()
+	}
+
+	promotionList, err := u.Repository.Promotion.FindPromotionList(ts, at)
+	switch err {
+	case nil:
+		return promotionList, nil
+	case global.ErrNoData:
+		return []promotion.Promotion{}, nil
+	default:
+		u.log.Error("не удалось найти акции ", err)
+		return nil, global.ErrInternalError
+	}
+}
+
+// EndPromotion досрочное завершение акции, проведенные продажи сохраняют примененную скидку
+func (u *PromotionUseCase) EndPromotion(ts transaction.Session, promotionID int) error {
+	lf := logrus.Fields{"promotion_ID": promotionID}
+
+	if promotionID <= 0 {
+		return errors.New("id акции не может быть меньше или равен 0")
+	}
+
+	err := u.Repository.Promotion.EndPromotion(ts, promotionID, time.Now())
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return errors.New("акция не найдена или уже завершена")
+	default:
+		u.log.WithFields(lf).Error("не удалось завершить акцию ", err)
+		return global.ErrInternalError
+	}
+
+	u.log.WithFields(lf).Info("акция завершена")
+	return nil
+}
+
+// Quote расчет суммы продажи с учетом цены группы покупателя и акций без записи продажи и изменения остатков
+func (u *PromotionUseCase) Quote(ts transaction.Session, p product.SaleParams) (promotion.Quote, error) {
+	lf := p.Log()
+
+	if err := p.IsNullFields(); err != nil {
+		return promotion.Quote{}, err
+	}
+
+	var err error
+	if p.VariantID == 0 {
+		if p.VariantID, err = u.product.findVariantIDByBarcode(ts, lf, p.Barcode); err != nil {
+			return promotion.Quote{}, err
+		}
+		lf["variant_ID"] = p.VariantID
+	}
+
+	price, err := u.Repository.Product.FindPrice(ts, p.VariantID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return promotion.Quote{}, errors.New("цена варианта не найдена")
+	default:
+		u.log.WithFields(lf).Error("не удалось найти цену варианта продукта ", err)
+		return promotion.Quote{}, global.ErrInternalError
+	}
+
+	now := time.Now()
+	if p.CustomerID != 0 {
+		if price, err = u.product.customerPrice(ts, lf, p.CustomerID, p.VariantID, price, now); err != nil {
+			return promotion.Quote{}, err
+		}
+	}
+
+	return u.product.priceSale(ts, lf, p.VariantID, p.StorageID, p.Quantity, price, now)
+}
@@ -0,0 +1,402 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/purchase"
+	"product_storage/internal/entity/stock"
+	"product_storage/internal/entity/valuation"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PurchaseUseCase поставщики, заказы поставщикам и прием товара по заказам.
+// Принятый товар зачисляется на склад через ProductUseCase,
+// чтобы изменения остатка попадали в outbox, кэш и поток склада
+type PurchaseUseCase struct {
+	log     *logrus.Logger
+	product *ProductUseCase
+	rimport.RepositoryImports
+}
+
+func NewPurchase(log *logrus.Logger, ri rimport.RepositoryImports, product *ProductUseCase) *PurchaseUseCase {
+	return &PurchaseUseCase{
+		log:               log,
+		product:           product,
+		RepositoryImports: ri,
+	}
+}
+
+// AddSupplier логика добавления поставщика
+func (u *PurchaseUseCase) AddSupplier(ts transaction.Session, p purchase.SupplierParams) (supplierID int, err error) {
+	lf := p.Log()
+
+	if err = p.Validate(); err != nil {
+		return 0, err
+	}
+
+	supplierID, err = u.Repository.Purchase.AddSupplier(ts, p)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось добавить поставщика ", err)
+		return 0, global.ErrInternalError
+	}
+
+	lf["supplier_ID"] = supplierID
+
+	u.log.WithFields(lf).Info("поставщик успешно добавлен")
+	return supplierID, nil
+}
+
+// FindSupplierList список действующих поставщиков
+func (u *PurchaseUseCase) FindSupplierList(ts transaction.Session) ([]purchase.Supplier, error) {
+	supplierList, err := u.Repository.Purchase.FindSupplierList(ts)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return []purchase.Supplier{}, nil
+	default:
+		u.log.Error("не удалось найти поставщиков ", err)
+		return nil, global.ErrInternalError
+	}
+
+	return supplierList, nil
+}
+
+// AddPurchaseOrder логика создания заказа поставщику в статусе черновика
+func (u *PurchaseUseCase) AddPurchaseOrder(ts transaction.Session, p purchase.OrderParams) (orderID int, err error) {
+	lf := p.Log()
+
+	if err = p.Validate(); err != nil {
+		return 0, err
+	}
+
+	if err = u.checkOrder(ts, lf, p); err != nil {
+		return 0, err
+	}
+
+	orderID, err = u.Repository.Purchase.AddPurchaseOrder(ts, p)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось создать заказ поставщику ", err)
+		return 0, global.ErrInternalError
+	}
+
+	lf["order_ID"] = orderID
+
+	for _, l := range p.Lines {
+		if _, err = u.Repository.Purchase.AddPurchaseOrderLine(ts, orderID, l); err != nil {
+			lf["variant_ID"] = l.VariantID
+			u.log.WithFields(lf).Error("не удалось добавить строку заказа поставщику ", err)
+			return 0, global.ErrInternalError
+		}
+	}
+
+	u.log.WithFields(lf).Info("заказ поставщику успешно создан")
+	return orderID, nil
+}
+
+// checkOrder проверка существования поставщика, склада и вариантов строк заказа;
+// товар заказывается только у действующего поставщика на действующий склад
+func (u *PurchaseUseCase) checkOrder(ts transaction.Session, lf logrus.Fields, p purchase.OrderParams) error {
+	_, err := u.Repository.Purchase.LoadSupplier(ts, p.SupplierID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return errors.New("поставщик не найден")
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить поставщика ", err)
+		return global.ErrInternalError
+	}
+
+	if _, err = u.product.loadStorage(ts, lf, p.StorageID); err != nil {
+		return err
+	}
+
+	variantIDList := make([]int, 0, len(p.Lines))
+	for _, l := range p.Lines {
+		variantIDList = append(variantIDList, l.VariantID)
+	}
+
+	foundList, err := u.Repository.Product.FindVariantIDListByIDList(ts, variantIDList)
+	switch err {
+	case nil, global.ErrNoData:
+	default:
+		u.log.WithFields(lf).Error("не удалось найти варианты строк заказа ", err)
+		return global.ErrInternalError
+	}
+
+	found := make(map[int]struct{}, len(foundList))
+	for _, id := range foundList {
+		found[id] = struct{}{}
+	}
+
+	for _, id := range variantIDList {
+		if _, exists := found[id]; !exists {
+			return fmt.Errorf("вариант %d не найден", id)
+		}
+	}
+
+	return nil
+}
+
+// loadOrder заказ поставщику со строками
+func (u *PurchaseUseCase) loadOrder(ts transaction.Session, lf logrus.Fields, orderID int) (purchase.Order, error) {
+	order, err := u.Repository.Purchase.LoadPurchaseOrder(ts, orderID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return purchase.Order{}, errors.New("заказ поставщику не найден")
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить заказ поставщику ", err)
+		return purchase.Order{}, global.ErrInternalError
+	}
+
+	order.Lines, err = u.Repository.Purchase.FindPurchaseOrderLineList(ts, orderID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		order.Lines = []purchase.OrderLine{}
+	default:
+		u.log.WithFields(lf).Error("не удалось найти строки заказа поставщику ", err)
+		return purchase.Order{}, global.ErrInternalError
+	}
+
+	return order, nil
+}
+
+// FindPurchaseOrder заказ поставщику со строками и принятым кол-вом
+func (u *PurchaseUseCase) FindPurchaseOrder(ts transaction.Session, orderID int) (purchase.Order, error) {
+	lf := logrus.Fields{"order_ID": orderID}
+
+	if orderID <= 0 {
+		return purchase.Order{}, errors.New("id заказа не может быть меньше или равен 0")
+	}
+
+	return u.loadOrder(ts, lf, orderID)
+}
+
+// FindPurchaseOrderList список заказов поставщикам с отбором по статусу и поставщику
+func (u *PurchaseUseCase) FindPurchaseOrderList(ts transaction.Session, status string, supplierID int) ([]purchase.Order, error) {
+	lf := logrus.Fields{"status": status, "supplier_ID": supplierID}
+
+	orderList, err := u.Repository.Purchase.FindPurchaseOrderList(ts, status, supplierID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return []purchase.Order{}, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти заказы поставщикам ", err)
+		return nil, global.ErrInternalError
+	}
+
+	return orderList, nil
+}
+
+// changeStatus перевод заказа в новый статус с проверкой допустимости перехода
+func (u *PurchaseUseCase) changeStatus(ts transaction.Session, orderID int, status string) error {
+	lf := logrus.Fields{"order_ID": orderID, "status": status}
+
+	if orderID <= 0 {
+		return errors.New("id заказа не может быть меньше или равен 0")
+	}
+
+	order, err := u.Repository.Purchase.LoadPurchaseOrder(ts, orderID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return errors.New("заказ поставщику не найден")
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить заказ поставщику ", err)
+		return global.ErrInternalError
+	}
+
+	if !purchase.CanTransition(order.Status, status) {
+		return purchase.ErrStatusTransition(order.Status, status)
+	}
+
+	if err = u.Repository.Purchase.UpdatePurchaseOrderStatus(ts, orderID, status); err != nil {
+		u.log.WithFields(lf).Error("не удалось изменить статус заказа поставщику ", err)
+		return global.ErrInternalError
+	}
+
+	u.log.WithFields(lf).Info("статус заказа поставщику изменен")
+	return nil
+}
+
+// SendPurchaseOrder отправка черновика заказа поставщику
+func (u *PurchaseUseCase) SendPurchaseOrder(ts transaction.Session, orderID int) error {
+	return u.changeStatus(ts, orderID, purchase.StatusSent)
+}
+
+// CancelPurchaseOrder отмена заказа, по которому еще не принят товар
+func (u *PurchaseUseCase) CancelPurchaseOrder(ts transaction.Session, orderID int) error {
+	return u.changeStatus(ts, orderID, purchase.StatusCancelled)
+}
+
+// ReceiveGoods прием товара по заказу поставщику: принятое кол-во зачисляется на склад заказа,
+// полностью принятые строки закрываются, заказ становится частично или полностью принятым.
+// Принять больше, чем осталось по строке, нельзя
+func (u *PurchaseUseCase) ReceiveGoods(ts transaction.Session, p purchase.ReceiptParams) (receiptID int, err error) {
+	lf := p.Log()
+
+	if p.OrderID <= 0 {
+		return 0, errors.New("id заказа не может быть меньше или равен 0")
+	}
+
+	order, err := u.loadOrder(ts, lf, p.OrderID)
+	if err != nil {
+		return 0, err
+	}
+
+	if order.Status != purchase.StatusSent && order.Status != purchase.StatusPartiallyReceived {
+		return 0, fmt.Errorf("по заказу в статусе %s нельзя принять товар", order.Status)
+	}
+
+	receiptLines, err := receiptLineList(order, p.Lines)
+	if err != nil {
+		return 0, err
+	}
+
+	// склад мог быть удален после создания заказа
+	if _, err = u.product.loadStorage(ts, lf, order.StorageID); err != nil {
+		return 0, err
+	}
+
+	receiptID, err = u.Repository.Purchase.AddGoodsReceipt(ts, order.OrderID, order.StorageID)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось создать прием товара ", err)
+		return 0, global.ErrInternalError
+	}
+
+	lf["receipt_ID"] = receiptID
+
+	received := make(map[int]int, len(receiptLines))
+	for _, l := range receiptLines {
+		l.ReceiptID = receiptID
+		received[l.LineID] = l.Quantity
+
+		if err = u.Repository.Purchase.AddGoodsReceiptLine(ts, l); err != nil {
+			u.log.WithFields(lf).Error("не удалось добавить строку приема товара ", err)
+			return 0, global.ErrInternalError
+		}
+
+		if err = u.Repository.Purchase.ReceivePurchaseOrderLine(ts, l.LineID, l.Quantity); err != nil {
+			u.log.WithFields(lf).Error("не удалось учесть принятое кол-во по строке заказа ", err)
+			return 0, global.ErrInternalError
+		}
+
+		if err = u.product.increaseStock(ts, lf, l.VariantID, order.StorageID, l.Quantity); err != nil {
+			return 0, err
+		}
+
+		layer := valuation.CostLayer{
+			VariantID: l.VariantID,
+			StorageID: order.StorageID,
+			Quantity:  l.Quantity,
+			UnitCost:  l.PurchasePrice,
+			ReceiptID: receiptID,
+		}
+		if err = u.product.receiveCost(ts, lf, layer); err != nil {
+			return 0, err
+		}
+
+		if l.Batch != nil {
+			batch := stock.Batch{
+				VariantID:   l.VariantID,
+				StorageID:   order.StorageID,
+				Quantity:    l.Quantity,
+				ReceiptID:   receiptID,
+				BatchParams: *l.Batch,
+			}
+			if err = u.product.addBatch(ts, lf, batch); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	status := purchase.StatusReceived
+	for _, l := range order.Lines {
+		if l.Remaining() > received[l.LineID] {
+			status = purchase.StatusPartiallyReceived
+			break
+		}
+	}
+	lf["status"] = status
+
+	if err = u.Repository.Purchase.UpdatePurchaseOrderStatus(ts, order.OrderID, status); err != nil {
+		u.log.WithFields(lf).Error("не удалось изменить статус заказа поставщику ", err)
+		return 0, global.ErrInternalError
+	}
+
+	u.log.WithFields(lf).Info("товар по заказу поставщику успешно принят")
+	return receiptID, nil
+}
+
+// receiptLineList строки приема по строкам заказа; без указанных строк принимается весь остаток заказа
+func receiptLineList(order purchase.Order, params []purchase.ReceiptLineParams) ([]purchase.ReceiptLine, error) {
+	lines := make(map[int]purchase.OrderLine, len(order.Lines))
+	for _, l := range order.Lines {
+		lines[l.LineID] = l
+	}
+
+	if len(params) == 0 {
+		for _, l := range order.Lines {
+			if l.Remaining() > 0 {
+				params = append(params, purchase.ReceiptLineParams{LineID: l.LineID, Quantity: l.Remaining()})
+			}
+		}
+	}
+
+	receiptLines := make([]purchase.ReceiptLine, 0, len(params))
+	seen := make(map[int]struct{}, len(params))
+	for _, p := range params {
+		l, exists := lines[p.LineID]
+		if !exists {
+			return nil, fmt.Errorf("строка %d не относится к заказу %d", p.LineID, order.OrderID)
+		}
+
+		if _, dup := seen[p.LineID]; dup {
+			return nil, fmt.Errorf("строка %d указана в приеме несколько раз", p.LineID)
+		}
+		seen[p.LineID] = struct{}{}
+
+		if p.Quantity <= 0 {
+			return nil, fmt.Errorf("принимаемое кол-во по строке %d должно быть больше 0", p.LineID)
+		}
+
+		if p.Quantity > l.Remaining() {
+			return nil, fmt.Errorf("по строке %d осталось принять %d, указано %d", p.LineID, l.Remaining(), p.Quantity)
+		}
+
+		if p.Batch != nil {
+			if err := p.Batch.Validate(); err != nil {
+				return nil, err
+			}
+		}
+
+		if p.UnitCost < 0 {
+			return nil, fmt.Errorf("закупочная цена по строке %d не может быть отрицательной", p.LineID)
+		}
+
+		price := l.PurchasePrice
+		if p.UnitCost > 0 {
+			price = p.UnitCost
+		}
+
+		receiptLines = append(receiptLines, purchase.ReceiptLine{
+			LineID:        l.LineID,
+			VariantID:     l.VariantID,
+			Quantity:      p.Quantity,
+			PurchasePrice: price,
+			Batch:         p.Batch,
+		})
+	}
+
+	if len(receiptLines) == 0 {
+		return nil, errors.New("по заказу не осталось товара для приема")
+	}
+
+	return receiptLines, nil
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"fmt"
+	"product_storage/internal/entity/attribute"
+	"product_storage/internal/entity/event"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/reprice"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RepriceUseCase массовое изменение цен вариантов, выбранных по тегу, категории или списку продуктов
+type RepriceUseCase struct {
+	log     *logrus.Logger
+	product *ProductUseCase
+	rimport.RepositoryImports
+}
+
+func NewReprice(log *logrus.Logger, ri rimport.RepositoryImports, product *ProductUseCase) *RepriceUseCase {
+	return &RepriceUseCase{
+		log:               log,
+		product:           product,
+		RepositoryImports: ri,
+	}
+}
+
+// calculate расчет новых цен вариантов, действующих в момент at
+func (u *RepriceUseCase) calculate(ts transaction.Session, lf logrus.Fields, p reprice.Params, at time.Time) (reprice.Preview, error) {
+	if err := p.Validate(); err != nil {
+		return reprice.Preview{}, err
+	}
+
+	if p.CategoryID > 0 {
+		_, err := u.Repository.Attribute.LoadCategory(ts, p.CategoryID)
+		switch err {
+		case nil:
+		case global.ErrNoData:
+			return reprice.Preview{}, attribute.ErrCategoryNotFound
+		default:
+			u.log.WithFields(lf).Error("не удалось загрузить категорию ", err)
+			return reprice.Preview{}, global.ErrInternalError
+		}
+	}
+
+	lineList, err := u.Repository.Reprice.FindLineList(ts, p, at)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return reprice.Preview{}, reprice.ErrNoVariants
+	default:
+		u.log.WithFields(lf).Error("не удалось найти варианты для изменения цен ", err)
+		return reprice.Preview{}, global.ErrInternalError
+	}
+
+	preview := reprice.Preview{
+		Lines:     []reprice.Line{},
+		Unchanged: []reprice.Line{},
+		Rejected:  []reprice.Line{},
+		NoPrice:   []int{},
+		Scheduled: []int{},
+	}
+	for _, l := range lineList {
+		if l.OldPrice <= 0 {
+			preview.NoPrice = append(preview.NoPrice, l.VariantID)
+			continue
+		}
+
+		l.NewPrice = p.NewPrice(l.OldPrice)
+		switch {
+		case l.NewPrice <= 0:
+			preview.Rejected = append(preview.Rejected, l)
+		case l.NewPrice == l.OldPrice:
+			preview.Unchanged = append(preview.Unchanged, l)
+		default:
+			preview.Lines = append(preview.Lines, l)
+
+			// запланированная цена не заменяется и вступит в силу после новой
+			if l.ScheduledAt.Valid {
+				preview.Scheduled = append(preview.Scheduled, l.VariantID)
+			}
+		}
+	}
+
+	if len(preview.Lines)+len(preview.Unchanged)+len(preview.Rejected) == 0 {
+		return reprice.Preview{}, reprice.ErrNoVariants
+	}
+
+	return preview, nil
+}
+
+// Preview расчет старых и новых цен без их изменения
+func (u *RepriceUseCase) Preview(ts transaction.Session, p reprice.Params) (reprice.Preview, error) {
+	return u.calculate(ts, p.Log(), p, time.Now())
+}
+
+// Apply изменение цен всех выбранных вариантов в одной транзакции: действующие цены закрываются,
+// новые начинают действовать с одного момента и у вариантов с запланированной ценой действуют до ее начала.
+// Если хотя бы одна новая цена получилась не больше 0, не изменяется ни одна цена
+func (u *RepriceUseCase) Apply(ts transaction.Session, p reprice.Params) (reprice.Preview, error) {
+	lf := p.Log()
+	at := time.Now()
+
+	preview, err := u.calculate(ts, lf, p, at)
+	if err != nil {
+		return reprice.Preview{}, err
+	}
+
+	if len(preview.Rejected) > 0 {
+		return reprice.Preview{}, fmt.Errorf("новая цена не больше 0 у %d вариантов, цены не изменены", len(preview.Rejected))
+	}
+
+	deps := make([]string, 0, len(preview.Lines))
+	for _, l := range preview.Lines {
+		priceID, err := u.Repository.Reprice.ReplacePrice(ts, l.VariantID, l.NewPrice, at)
+		if err != nil {
+			u.log.WithFields(lf).Error("не удалось изменить цену варианта ", l.VariantID, " ", err)
+			return reprice.Preview{}, global.ErrInternalError
+		}
+
+		_, err = u.product.saveEvent(ts, lf, event.PriceChanged, l.VariantID, event.PriceChangedPayload{
+			PriceID:   priceID,
+			VariantID: l.VariantID,
+			Price:     l.NewPrice,
+			StartDate: at,
+		})
+		if err != nil {
+			return reprice.Preview{}, err
+		}
+
+		deps = append(deps, variantCacheDep(l.VariantID))
+	}
+
+	ts.OnCommit(func() { u.product.cache.invalidate(deps...) })
+
+	lf["changed"] = len(preview.Lines)
+	u.log.WithFields(lf).Info("цены вариантов изменены")
+	return preview, nil
+}
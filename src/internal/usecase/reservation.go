@@ -0,0 +1,265 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/payment"
+	"product_storage/internal/entity/product"
+	"product_storage/internal/entity/reservation"
+	"product_storage/internal/entity/stock"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/sqlnull"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ReservationUseCase резервирование продукта на складе под заказы: резерв уменьшает
+// доступное для продажи кол-во, но не остаток, пока не превращен в продажу через ProductUseCase
+type ReservationUseCase struct {
+	log     *logrus.Logger
+	product *ProductUseCase
+	rimport.RepositoryImports
+}
+
+func NewReservation(log *logrus.Logger, ri rimport.RepositoryImports, product *ProductUseCase) *ReservationUseCase {
+	return &ReservationUseCase{
+		log:               log,
+		product:           product,
+		RepositoryImports: ri,
+	}
+}
+
+// Reserve резервирование свободного от других резервов кол-ва варианта на складе
+func (u *ReservationUseCase) Reserve(ts transaction.Session, p reservation.Params) (reservationID int, err error) {
+	lf := p.Log()
+
+	if err = p.Validate(); err != nil {
+		return 0, err
+	}
+
+	if _, err = u.product.loadStorage(ts, lf, p.StorageID); err != nil {
+		return 0, err
+	}
+
+	var customerID sqlnull.NullInt64
+	if p.CustomerID > 0 {
+		if _, err = u.product.loadCustomer(ts, lf, p.CustomerID); err != nil {
+			return 0, err
+		}
+		customerID = sqlnull.NewInt64(p.CustomerID)
+	}
+
+	onHand, err := u.Repository.Reservation.LoadStockQuantity(ts, p.VariantID, p.StorageID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return 0, stock.ErrNotEnoughStock
+	default:
+		u.log.WithFields(lf).Error("не удалось получить остаток на складе ", err)
+		return 0, global.ErrInternalError
+	}
+
+	reserved, err := u.Repository.Reservation.FindReservedQuantity(ts, p.VariantID, p.StorageID)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось получить зарезервированное кол-во ", err)
+		return 0, global.ErrInternalError
+	}
+
+	lf["on_hand"] = onHand
+	lf["reserved"] = reserved
+
+	if onHand < p.Quantity {
+		return 0, stock.ErrNotEnoughStock
+	}
+
+	if onHand-reserved < p.Quantity {
+		return 0, stock.ErrNotEnoughAvailable
+	}
+
+	reservationID, err = u.Repository.Reservation.AddReservation(ts, reservation.Reservation{
+		VariantID:  p.VariantID,
+		StorageID:  p.StorageID,
+		Quantity:   p.Quantity,
+		OrderRef:   p.OrderRef,
+		ExpiresAt:  time.Now().Add(u.Config.ReservationTTL(p.TTL)),
+		CustomerID: customerID,
+	})
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось создать резерв ", err)
+		return 0, global.ErrInternalError
+	}
+
+	lf["reservation_ID"] = reservationID
+
+	u.availableChanged(ts, p.VariantID)
+
+	u.log.WithFields(lf).Info("продукт зарезервирован")
+	return reservationID, nil
+}
+
+// loadActiveReservation резерв, который еще можно продать или снять
+func (u *ReservationUseCase) loadActiveReservation(ts transaction.Session, lf logrus.Fields, reservationID int) (reservation.Reservation, error) {
+	if reservationID <= 0 {
+		return reservation.Reservation{}, errors.New("id резерва не может быть меньше или равен 0")
+	}
+
+	r, err := u.Repository.Reservation.LoadReservation(ts, reservationID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return reservation.Reservation{}, errors.New("резерв не найден")
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить резерв ", err)
+		return reservation.Reservation{}, global.ErrInternalError
+	}
+
+	if r.Status != reservation.StatusActive {
+		return reservation.Reservation{}, reservation.ErrNotActive
+	}
+
+	return r, nil
+}
+
+// Release снятие резерва, кол-во снова доступно для продажи
+func (u *ReservationUseCase) Release(ts transaction.Session, reservationID int) error {
+	lf := logrus.Fields{"reservation_ID": reservationID}
+
+	r, err := u.loadActiveReservation(ts, lf, reservationID)
+	if err != nil {
+		return err
+	}
+
+	if err = u.Repository.Reservation.CloseReservation(ts, reservationID, reservation.StatusReleased, 0); err != nil {
+		u.log.WithFields(lf).Error("не удалось снять резерв ", err)
+		return global.ErrInternalError
+	}
+
+	u.availableChanged(ts, r.VariantID)
+
+	u.log.WithFields(r.Log()).Info("резерв снят")
+	return nil
+}
+
+// Convert превращение резерва в продажу всего зарезервированного кол-ва с указанными оплатами
+func (u *ReservationUseCase) Convert(ts transaction.Session, reservationID int, payments []payment.Params) (saleID int, err error) {
+	lf := logrus.Fields{"reservation_ID": reservationID}
+
+	r, err := u.loadActiveReservation(ts, lf, reservationID)
+	if err != nil {
+		return 0, err
+	}
+
+	if r.IsExpired(time.Now()) {
+		return 0, reservation.ErrExpired
+	}
+
+	saleID, err = u.product.saveSale(ts, product.SaleParams{
+		VariantID:  r.VariantID,
+		StorageID:  r.StorageID,
+		Quantity:   r.Quantity,
+		SoldAt:     time.Now(),
+		CustomerID: r.CustomerID.GetInt(),
+		Payments:   payments,
+	}, r.Quantity)
+	if err != nil {
+		return 0, err
+	}
+
+	lf["sale_ID"] = saleID
+
+	if err = u.Repository.Reservation.CloseReservation(ts, reservationID, reservation.StatusConverted, saleID); err != nil {
+		u.log.WithFields(lf).Error("не удалось закрыть резерв продажей ", err)
+		return 0, global.ErrInternalError
+	}
+
+	u.log.WithFields(lf).Info("резерв превращен в продажу")
+	return saleID, nil
+}
+
+// FindReservationList резервы, нулевые variantID, storageID и пустой status не ограничивают выборку
+func (u *ReservationUseCase) FindReservationList(ts transaction.Session, variantID, storageID int, status string) ([]reservation.Reservation, error) {
+	lf := logrus.Fields{"variant_ID": variantID, "storage_ID": storageID, "status": status}
+
+	reservationList, err := u.Repository.Reservation.FindReservationList(ts, variantID, storageID, status)
+	switch err {
+	case nil:
+		return reservationList, nil
+	case global.ErrNoData:
+		return []reservation.Reservation{}, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти резервы ", err)
+		return nil, global.ErrInternalError
+	}
+}
+
+// ReleaseExpired снятие одной пачки резервов с истекшим сроком
+func (u *ReservationUseCase) ReleaseExpired(ts transaction.Session) (released int, err error) {
+	expiredList, err := u.Repository.Reservation.ExpireReservationList(ts, u.Config.ReservationBatchSize())
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return 0, nil
+	default:
+		u.log.Error("не удалось снять истекшие резервы ", err)
+		return 0, global.ErrInternalError
+	}
+
+	for _, r := range expiredList {
+		u.availableChanged(ts, r.VariantID)
+		u.log.WithFields(r.Log()).Info("резерв снят по истечении срока")
+	}
+
+	return len(expiredList), nil
+}
+
+// releaseExpiredBatch снятие одной пачки истекших резервов в отдельной транзакции
+func (u *ReservationUseCase) releaseExpiredBatch(ctx context.Context) (released int, err error) {
+	ts := u.SessionManager.CreateSession()
+	if err = ts.Start(ctx); err != nil {
+		u.log.Error("не удалось начать транзакцию снятия истекших резервов ", err)
+		return 0, err
+	}
+	defer ts.Rollback()
+
+	released, err = u.ReleaseExpired(ts)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = ts.Commit(); err != nil {
+		u.log.Error("не удалось зафиксировать снятие истекших резервов ", err)
+		return 0, err
+	}
+
+	return released, nil
+}
+
+// RunExpiryRelease периодическое снятие истекших резервов до отмены ctx.
+// Пока пачки заполнены полностью, следующая снимается без ожидания
+func (u *ReservationUseCase) RunExpiryRelease(ctx context.Context) {
+	ticker := time.NewTicker(u.Config.ReservationInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for ctx.Err() == nil {
+			released, err := u.releaseExpiredBatch(ctx)
+			if err != nil || released == 0 || released < u.Config.ReservationBatchSize() {
+				break
+			}
+		}
+	}
+}
+
+// availableChanged сброс кэша варианта после фиксации транзакции: доступное кол-во хранится в информации о продукте
+func (u *ReservationUseCase) availableChanged(ts transaction.Session, variantID int) {
+	ts.OnCommit(func() { u.product.cache.invalidate(variantCacheDep(variantID)) })
+}
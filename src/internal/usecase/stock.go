@@ -0,0 +1,382 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"product_storage/internal/bridge"
+	"product_storage/internal/entity/event"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/stock"
+	"product_storage/internal/transaction"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// lowStockNotifyTimeout таймаут оповещения о низком остатке
+const lowStockNotifyTimeout = 5 * time.Second
+
+// SetLowStockNotifier подключение оповещения о снижении остатка ниже минимального,
+// без него оповещения только пишутся в лог и в outbox
+func (u *ProductUseCase) SetLowStockNotifier(notifier bridge.LowStockNotifier) {
+	u.notifier = notifier
+}
+
+// stockChanged запись события об изменении остатка; после фиксации транзакции
+// сбрасывается кэш варианта и изменение рассылается в поток склада
+func (u *ProductUseCase) stockChanged(ts transaction.Session, lf logrus.Fields, level stock.StockLevel) error {
+	eventID, err := u.saveEvent(ts, lf, event.StockChanged, level.VariantID, event.StockChangedPayload{
+		VariantID: level.VariantID,
+		StorageID: level.StorageID,
+		Quantity:  level.Quantity,
+	})
+	if err != nil {
+		return err
+	}
+
+	ts.OnCommit(func() {
+		u.cache.invalidate(variantCacheDep(level.VariantID))
+		u.stockHub.Publish(eventID, level)
+	})
+
+	return nil
+}
+
+// increaseStock увеличение остатка продукта на складе при поступлении товара
+func (u *ProductUseCase) increaseStock(ts transaction.Session, lf logrus.Fields, variantID, storageID, quantity int) error {
+	total, err := u.Repository.Stock.IncreaseProductInStock(ts, variantID, storageID, quantity)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось увеличить кол-во продукта на складе ", err)
+		return global.ErrInternalError
+	}
+
+	return u.stockChanged(ts, lf, stock.StockLevel{VariantID: variantID, StorageID: storageID, Quantity: total})
+}
+
+// decreaseStock уменьшение остатка продукта на складе при продаже или списании,
+// остаток партий уменьшается в порядке FEFO; при freshOnly, как при продаже, партии с истекшим сроком не расходуются.
+// Возвращается себестоимость ушедшего продукта
+func (u *ProductUseCase) decreaseStock(ts transaction.Session, lf logrus.Fields, variantID, storageID, quantity int, freshOnly bool) (cost float64, err error) {
+	remaining, cost, err := u.decreaseTotal(ts, lf, variantID, storageID, quantity)
+	if err != nil {
+		return 0, err
+	}
+
+	return cost, u.consumeBatches(ts, lf, variantID, storageID, quantity, remaining+quantity, freshOnly)
+}
+
+// checkAvailable проверка, что кол-во quantity не занято резервами других заказов.
+// ownReserved кол-во из резерва, под который выполняется операция
+func (u *ProductUseCase) checkAvailable(ts transaction.Session, lf logrus.Fields, variantID, storageID, quantity, ownReserved int) error {
+	reserved, err := u.Repository.Reservation.FindReservedQuantity(ts, variantID, storageID)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось получить зарезервированное кол-во ", err)
+		return global.ErrInternalError
+	}
+
+	reserved -= ownReserved
+	if reserved <= 0 {
+		return nil
+	}
+
+	onHand, err := u.Repository.Reservation.LoadStockQuantity(ts, variantID, storageID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return stock.ErrNotEnoughStock
+	default:
+		u.log.WithFields(lf).Error("не удалось получить остаток на складе ", err)
+		return global.ErrInternalError
+	}
+
+	if onHand-reserved < quantity {
+		return stock.ErrNotEnoughAvailable
+	}
+
+	return nil
+}
+
+// decreaseTotal уменьшение общего остатка продукта на складе и списание его себестоимости,
+// если остаток опустился ниже минимального, создается оповещение
+func (u *ProductUseCase) decreaseTotal(ts transaction.Session, lf logrus.Fields, variantID, storageID, quantity int) (remaining int, cost float64, err error) {
+	remaining, err = u.Repository.Stock.DecreaseProductInStock(ts, variantID, storageID, quantity)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return 0, 0, stock.ErrNotEnoughStock
+	default:
+		u.log.WithFields(lf).Error("не удалось уменьшить кол-во продукта на складе ", err)
+		return 0, 0, global.ErrInternalError
+	}
+
+	if cost, err = u.consumeCost(ts, lf, variantID, storageID, quantity); err != nil {
+		return 0, 0, err
+	}
+
+	// продукт, ушедший со склада, забирается из мест хранения, если неразмещенного не хватило
+	if err = u.Repository.Location.TrimLocationStock(ts, variantID, storageID, remaining); err != nil {
+		u.log.WithFields(lf).Error("не удалось уменьшить кол-во продукта в местах хранения ", err)
+		return 0, 0, global.ErrInternalError
+	}
+
+	level := stock.StockLevel{VariantID: variantID, StorageID: storageID, Quantity: remaining}
+	if err = u.stockChanged(ts, lf, level); err != nil {
+		return 0, 0, err
+	}
+
+	return remaining, cost, u.checkThreshold(ts, lf, level, remaining+quantity)
+}
+
+// consumeBatches списание кол-ва с партий в порядке FEFO. При freshOnly партии с истекшим сроком не расходуются,
+// недостающее кол-во берется из продукта без партии, а если его не хватает, возвращается ошибка
+func (u *ProductUseCase) consumeBatches(ts transaction.Session, lf logrus.Fields, variantID, storageID, quantity, total int, freshOnly bool) error {
+	batchList, err := u.Repository.Stock.LoadBatchList(ts, variantID, storageID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return nil
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить партии продукта ", err)
+		return global.ErrInternalError
+	}
+
+	// продукт без партии; если партий больше остатка, его нет совсем
+	untracked := total
+	for _, b := range batchList {
+		untracked -= b.Quantity
+	}
+	untracked = max(untracked, 0)
+
+	allocation, rest := stock.AllocateFEFO(batchList, quantity, time.Now(), freshOnly)
+	if rest > untracked {
+		return stock.ErrNotEnoughFreshStock
+	}
+
+	for _, c := range allocation {
+		if err = u.Repository.Stock.ConsumeBatch(ts, c.BatchID, c.Quantity); err != nil {
+			lf["batch_ID"] = c.BatchID
+			u.log.WithFields(lf).Error("не удалось уменьшить остаток партии ", err)
+			return global.ErrInternalError
+		}
+	}
+
+	return nil
+}
+
+// addBatch добавление партии поступившего на склад продукта, даты партии проверяются вызывающим
+func (u *ProductUseCase) addBatch(ts transaction.Session, lf logrus.Fields, b stock.Batch) error {
+	batchID, err := u.Repository.Stock.AddBatch(ts, b)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось добавить партию продукта ", err)
+		return global.ErrInternalError
+	}
+
+	lf["batch_ID"] = batchID
+	return nil
+}
+
+// checkThreshold оповещение, если остаток пересек минимальный порог: до изменения был не ниже, а стал ниже
+func (u *ProductUseCase) checkThreshold(ts transaction.Session, lf logrus.Fields, level stock.StockLevel, previous int) error {
+	threshold, err := u.Repository.Stock.FindThreshold(ts, level.VariantID, level.StorageID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти порог остатка ", err)
+		return global.ErrInternalError
+	}
+
+	if previous < threshold.MinQuantity || level.Quantity >= threshold.MinQuantity {
+		return nil
+	}
+
+	alert := stock.NewLowStockAlert(threshold, level.Quantity)
+	if _, err = u.saveEvent(ts, lf, event.LowStock, level.VariantID, alert); err != nil {
+		return err
+	}
+
+	// оповещение отправляется только о зафиксированном изменении и не задерживает ответ
+	ts.OnCommit(func() { go u.notifyLowStock(alert) })
+
+	return nil
+}
+
+// notifyLowStock запись оповещения в лог и отправка подключенному оповещателю
+func (u *ProductUseCase) notifyLowStock(alert stock.LowStockAlert) {
+	lf := alert.Log()
+	u.log.WithFields(lf).Warn("остаток продукта на складе ниже минимального")
+
+	if u.notifier == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lowStockNotifyTimeout)
+	defer cancel()
+
+	if err := u.notifier.NotifyLowStock(ctx, alert); err != nil {
+		u.log.WithFields(lf).Error("не удалось отправить оповещение о низком остатке ", err)
+	}
+}
+
+// SaveThreshold логика установки порогов остатка варианта продукта на складе
+func (u *ProductUseCase) SaveThreshold(ts transaction.Session, p stock.ThresholdParams) error {
+	lf := p.Log()
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	if err := u.Repository.Stock.SaveThreshold(ts, p); err != nil {
+		u.log.WithFields(lf).Error("не удалось сохранить порог остатка ", err)
+		return global.ErrInternalError
+	}
+
+	u.log.WithFields(lf).Info("порог остатка успешно сохранен")
+	return nil
+}
+
+// FindLowStockList отчет о вариантах продуктов с остатком ниже минимального и рекомендуемым дозаказом,
+// при storageID = 0 по всем складам
+func (u *ProductUseCase) FindLowStockList(ts transaction.Session, storageID int) ([]stock.LowStock, error) {
+	lf := logrus.Fields{"storage_ID": storageID}
+
+	if storageID < 0 {
+		return nil, errors.New("id склада не может быть меньше нуля")
+	}
+
+	lowStockList, err := u.Repository.Stock.FindLowStockList(ts, storageID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return []stock.LowStock{}, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти продукты с низким остатком ", err)
+		return nil, global.ErrInternalError
+	}
+
+	return lowStockList, nil
+}
+
+// WriteOff логика списания продукта со склада
+func (u *ProductUseCase) WriteOff(ts transaction.Session, p stock.WriteOffParams) (writeOffID int, err error) {
+	lf := p.Log()
+
+	if err = p.IsNullFields(); err != nil {
+		return 0, err
+	}
+	p.WrittenOffAt = time.Now()
+
+	if _, err = u.decreaseStock(ts, lf, p.VariantID, p.StorageID, p.Quantity, false); err != nil {
+		return 0, err
+	}
+
+	writeOffID, err = u.Repository.Stock.SaveWriteOff(ts, p)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось записать списание ", err)
+		return 0, global.ErrInternalError
+	}
+
+	lf["write_off_ID"] = writeOffID
+
+	u.log.WithFields(lf).Info("продукт успешно списан со склада")
+	return writeOffID, nil
+}
+
+// FindBatchList партии варианта продукта с остатком, при storageID = 0 на всех складах
+func (u *ProductUseCase) FindBatchList(ts transaction.Session, variantID, storageID int) ([]stock.Batch, error) {
+	lf := logrus.Fields{"variant_ID": variantID, "storage_ID": storageID}
+
+	if variantID <= 0 || storageID < 0 {
+		return nil, errors.New("id варианта должен быть больше 0, id склада не может быть меньше 0")
+	}
+
+	batchList, err := u.Repository.Stock.FindBatchList(ts, variantID, storageID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return []stock.Batch{}, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти партии продукта ", err)
+		return nil, global.ErrInternalError
+	}
+
+	return batchList, nil
+}
+
+// FindExpiringBatchList отчет о партиях, срок годности которых истекает в ближайшие days дней,
+// вместе с уже истекшими; при storageID = 0 по всем складам
+func (u *ProductUseCase) FindExpiringBatchList(ts transaction.Session, storageID, days int) ([]stock.ExpiringBatch, error) {
+	lf := logrus.Fields{"storage_ID": storageID, "days": days}
+
+	if storageID < 0 || days < 0 {
+		return nil, errors.New("id склада и кол-во дней не могут быть меньше нуля")
+	}
+
+	batchList, err := u.Repository.Stock.FindExpiringBatchList(ts, storageID, days)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return []stock.ExpiringBatch{}, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти партии с истекающим сроком годности ", err)
+		return nil, global.ErrInternalError
+	}
+
+	return batchList, nil
+}
+
+// WriteOffExpired списание всех партий с истекшим сроком годности, при storageID = 0 на всех складах.
+// Каждая партия списывается отдельной записью о списании со ссылкой на партию
+func (u *ProductUseCase) WriteOffExpired(ts transaction.Session, storageID int) (writeOffIDList []int, err error) {
+	lf := logrus.Fields{"storage_ID": storageID}
+
+	if storageID < 0 {
+		return nil, errors.New("id склада не может быть меньше нуля")
+	}
+
+	batchList, err := u.Repository.Stock.LoadExpiredBatchList(ts, storageID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return []int{}, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить партии с истекшим сроком годности ", err)
+		return nil, global.ErrInternalError
+	}
+
+	now := time.Now()
+	writeOffIDList = make([]int, 0, len(batchList))
+	for _, b := range batchList {
+		blf := b.Log()
+
+		if _, _, err = u.decreaseTotal(ts, blf, b.VariantID, b.StorageID, b.Quantity); err != nil {
+			return nil, err
+		}
+
+		if err = u.Repository.Stock.ConsumeBatch(ts, b.BatchID, b.Quantity); err != nil {
+			u.log.WithFields(blf).Error("не удалось уменьшить остаток партии ", err)
+			return nil, global.ErrInternalError
+		}
+
+		writeOffID, err := u.Repository.Stock.SaveWriteOff(ts, stock.WriteOffParams{
+			VariantID:    b.VariantID,
+			StorageID:    b.StorageID,
+			Quantity:     b.Quantity,
+			Reason:       stock.ReasonExpired,
+			WrittenOffAt: now,
+			BatchID:      b.BatchID,
+		})
+		if err != nil {
+			u.log.WithFields(blf).Error("не удалось записать списание партии ", err)
+			return nil, global.ErrInternalError
+		}
+
+		writeOffIDList = append(writeOffIDList, writeOffID)
+	}
+
+	lf["write_off_count"] = len(writeOffIDList)
+
+	u.log.WithFields(lf).Info("партии с истекшим сроком годности списаны")
+	return writeOffIDList, nil
+}
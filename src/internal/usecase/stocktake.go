@@ -0,0 +1,254 @@
+package usecase
+
+import (
+	"errors"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/stock"
+	"product_storage/internal/entity/stocktake"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StocktakeUseCase инвентаризация складов: фиксация учетных остатков, пересчеты,
+// отчет о расхождениях и проведение корректировок через ProductUseCase
+type StocktakeUseCase struct {
+	log     *logrus.Logger
+	product *ProductUseCase
+	rimport.RepositoryImports
+}
+
+func NewStocktake(log *logrus.Logger, ri rimport.RepositoryImports, product *ProductUseCase) *StocktakeUseCase {
+	return &StocktakeUseCase{
+		log:               log,
+		product:           product,
+		RepositoryImports: ri,
+	}
+}
+
+// StartStocktake начало инвентаризации склада с фиксацией учетных остатков,
+// по складу может идти только одна инвентаризация
+func (u *StocktakeUseCase) StartStocktake(ts transaction.Session, p stocktake.Params) (stocktakeID int, err error) {
+	lf := p.Log()
+
+	if p.StorageID <= 0 {
+		return 0, errors.New("id склада не может быть меньше или равен 0")
+	}
+
+	openList, err := u.Repository.Stocktake.FindStocktakeList(ts, p.StorageID, stocktake.StatusOpen)
+	switch err {
+	case nil:
+		if len(openList) > 0 {
+			return 0, errors.New("по складу уже идет инвентаризация")
+		}
+	case global.ErrNoData:
+	default:
+		u.log.WithFields(lf).Error("не удалось проверить открытые инвентаризации ", err)
+		return 0, global.ErrInternalError
+	}
+
+	stocktakeID, err = u.Repository.Stocktake.AddStocktake(ts, p)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось создать инвентаризацию ", err)
+		return 0, global.ErrInternalError
+	}
+
+	lf["stocktake_ID"] = stocktakeID
+
+	if err = u.Repository.Stocktake.SnapshotStocktakeLines(ts, stocktakeID, p.StorageID); err != nil {
+		u.log.WithFields(lf).Error("не удалось зафиксировать учетные остатки ", err)
+		return 0, global.ErrInternalError
+	}
+
+	u.log.WithFields(lf).Info("инвентаризация начата")
+	return stocktakeID, nil
+}
+
+// loadOpenStocktake открытая инвентаризация
+func (u *StocktakeUseCase) loadOpenStocktake(ts transaction.Session, lf logrus.Fields, stocktakeID int) (stocktake.Stocktake, error) {
+	s, err := u.loadStocktake(ts, lf, stocktakeID)
+	if err != nil {
+		return stocktake.Stocktake{}, err
+	}
+
+	if s.Status != stocktake.StatusOpen {
+		return stocktake.Stocktake{}, stocktake.ErrNotOpen
+	}
+
+	return s, nil
+}
+
+// loadStocktake инвентаризация по id
+func (u *StocktakeUseCase) loadStocktake(ts transaction.Session, lf logrus.Fields, stocktakeID int) (stocktake.Stocktake, error) {
+	if stocktakeID <= 0 {
+		return stocktake.Stocktake{}, errors.New("id инвентаризации не может быть меньше или равен 0")
+	}
+
+	s, err := u.Repository.Stocktake.LoadStocktake(ts, stocktakeID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return stocktake.Stocktake{}, errors.New("инвентаризация не найдена")
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить инвентаризацию ", err)
+		return stocktake.Stocktake{}, global.ErrInternalError
+	}
+
+	return s, nil
+}
+
+// findLineList строки инвентаризации
+func (u *StocktakeUseCase) findLineList(ts transaction.Session, lf logrus.Fields, stocktakeID int) ([]stocktake.Line, error) {
+	lineList, err := u.Repository.Stocktake.FindStocktakeLineList(ts, stocktakeID)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return []stocktake.Line{}, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти строки инвентаризации ", err)
+		return nil, global.ErrInternalError
+	}
+
+	return lineList, nil
+}
+
+// SaveCount запись результатов пересчета, пересчетов может быть несколько
+func (u *StocktakeUseCase) SaveCount(ts transaction.Session, p stocktake.CountListParams) error {
+	lf := p.Log()
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := u.loadOpenStocktake(ts, lf, p.StocktakeID); err != nil {
+		return err
+	}
+
+	for _, c := range p.Counts {
+		if err := u.Repository.Stocktake.SaveCount(ts, p.StocktakeID, c, p.Accumulate); err != nil {
+			lf["variant_ID"] = c.VariantID
+			u.log.WithFields(lf).Error("не удалось записать результат пересчета ", err)
+			return global.ErrInternalError
+		}
+	}
+
+	u.log.WithFields(lf).Info("результаты пересчета записаны")
+	return nil
+}
+
+// FindStocktakeList список инвентаризаций с отбором по складу и статусу
+func (u *StocktakeUseCase) FindStocktakeList(ts transaction.Session, storageID int, status string) ([]stocktake.Stocktake, error) {
+	lf := logrus.Fields{"storage_ID": storageID, "status": status}
+
+	stocktakeList, err := u.Repository.Stocktake.FindStocktakeList(ts, storageID, status)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return []stocktake.Stocktake{}, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти инвентаризации ", err)
+		return nil, global.ErrInternalError
+	}
+
+	return stocktakeList, nil
+}
+
+// VarianceReport отчет о расхождениях посчитанного кол-ва с учетным на момент начала инвентаризации
+func (u *StocktakeUseCase) VarianceReport(ts transaction.Session, stocktakeID int) (stocktake.VarianceReport, error) {
+	lf := logrus.Fields{"stocktake_ID": stocktakeID}
+
+	s, err := u.loadStocktake(ts, lf, stocktakeID)
+	if err != nil {
+		return stocktake.VarianceReport{}, err
+	}
+
+	lineList, err := u.findLineList(ts, lf, stocktakeID)
+	if err != nil {
+		return stocktake.VarianceReport{}, err
+	}
+
+	return stocktake.NewVarianceReport(s, lineList), nil
+}
+
+// PostStocktake проведение инвентаризации: расхождение каждой строки применяется к текущему остатку,
+// поэтому движения во время пересчета не теряются. Излишек зачисляется на склад,
+// недостача списывается с записью о списании
+func (u *StocktakeUseCase) PostStocktake(ts transaction.Session, p stocktake.PostParams) error {
+	lf := p.Log()
+
+	s, err := u.loadOpenStocktake(ts, lf, p.StocktakeID)
+	if err != nil {
+		return err
+	}
+
+	lineList, err := u.findLineList(ts, lf, p.StocktakeID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, l := range lineList {
+		llf := logrus.Fields{"stocktake_ID": s.StocktakeID, "variant_ID": l.VariantID}
+
+		adjustment := l.Variance()
+		if !l.Counted() && p.ZeroUncounted {
+			adjustment = -l.ExpectedQuantity
+		}
+
+		switch {
+		case adjustment > 0:
+			if err = u.product.increaseStock(ts, llf, l.VariantID, s.StorageID, adjustment); err != nil {
+				return err
+			}
+		case adjustment < 0:
+			if _, err = u.product.decreaseStock(ts, llf, l.VariantID, s.StorageID, -adjustment, false); err != nil {
+				return err
+			}
+
+			_, err = u.Repository.Stock.SaveWriteOff(ts, stock.WriteOffParams{
+				VariantID:    l.VariantID,
+				StorageID:    s.StorageID,
+				Quantity:     -adjustment,
+				Reason:       stocktake.ReasonShortage,
+				WrittenOffAt: now,
+				StocktakeID:  s.StocktakeID,
+			})
+			if err != nil {
+				u.log.WithFields(llf).Error("не удалось записать списание недостачи ", err)
+				return global.ErrInternalError
+			}
+		}
+
+		if err = u.Repository.Stocktake.SetLineAdjustment(ts, l.LineID, adjustment); err != nil {
+			u.log.WithFields(llf).Error("не удалось записать корректировку остатка ", err)
+			return global.ErrInternalError
+		}
+	}
+
+	if err = u.Repository.Stocktake.UpdateStocktakeStatus(ts, s.StocktakeID, stocktake.StatusPosted); err != nil {
+		u.log.WithFields(lf).Error("не удалось провести инвентаризацию ", err)
+		return global.ErrInternalError
+	}
+
+	u.log.WithFields(lf).Info("инвентаризация проведена")
+	return nil
+}
+
+// CancelStocktake отмена открытой инвентаризации без изменения остатков
+func (u *StocktakeUseCase) CancelStocktake(ts transaction.Session, stocktakeID int) error {
+	lf := logrus.Fields{"stocktake_ID": stocktakeID}
+
+	if _, err := u.loadOpenStocktake(ts, lf, stocktakeID); err != nil {
+		return err
+	}
+
+	if err := u.Repository.Stocktake.UpdateStocktakeStatus(ts, stocktakeID, stocktake.StatusCancelled); err != nil {
+		u.log.WithFields(lf).Error("не удалось отменить инвентаризацию ", err)
+		return global.ErrInternalError
+	}
+
+	u.log.WithFields(lf).Info("инвентаризация отменена")
+	return nil
+}
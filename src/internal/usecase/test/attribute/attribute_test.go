@@ -0,0 +1,156 @@
+package test
+
+import (
+	"product_storage/internal/entity/attribute"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/product"
+	"product_storage/rimport"
+	"product_storage/tools/logger"
+	"product_storage/tools/sqlnull"
+	"product_storage/uimport"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testLogger = logger.NewNoFileLogger("test")
+)
+
+// dairyDefinitionList атрибуты категории молочных продуктов
+var dairyDefinitionList = []attribute.Definition{
+	{CategoryID: 1, Code: "brand", Name: "Бренд", Type: attribute.TypeString, Required: true},
+	{CategoryID: 1, Code: "fat", Name: "Жирность, %", Type: attribute.TypeNumber},
+	{CategoryID: 1, Code: "lactose_free", Name: "Без лактозы", Type: attribute.TypeBool},
+	{CategoryID: 1, Code: "country", Name: "Страна", Type: attribute.TypeEnum, Options: []string{"Россия", "Беларусь"}},
+}
+
+func TestUpdateProductAttributes(t *testing.T) {
+	tests := []struct {
+		name       string
+		categoryID int
+		values     attribute.Values
+		wantErr    bool
+	}{
+		{
+			name:       "все значения корректны",
+			categoryID: 1,
+			values:     attribute.Values{"brand": "Простоквашино", "fat": 3.2, "lactose_free": false, "country": "Россия"},
+		},
+		{
+			name:       "нет обязательного значения",
+			categoryID: 1,
+			values:     attribute.Values{"fat": 3.2},
+			wantErr:    true,
+		},
+		{
+			name:       "атрибут не определен для категории",
+			categoryID: 1,
+			values:     attribute.Values{"brand": "Простоквашино", "color": "белый"},
+			wantErr:    true,
+		},
+		{
+			name:       "неверный тип значения",
+			categoryID: 1,
+			values:     attribute.Values{"brand": "Простоквашино", "fat": "3.2"},
+			wantErr:    true,
+		},
+		{
+			name:       "значение вне списка",
+			categoryID: 1,
+			values:     attribute.Values{"brand": "Простоквашино", "country": "Франция"},
+			wantErr:    true,
+		},
+		{
+			name:    "атрибуты без категории",
+			values:  attribute.Values{"brand": "Простоквашино"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ri := rimport.NewTestRepositoryImports(ctrl)
+			ts := ri.MockSession()
+
+			ri.MockRepository.Product.EXPECT().LoadProductInfo(ts, 7).Return(product.ProductInfo{ProductID: 7}, nil)
+			if tt.categoryID > 0 {
+				ri.MockRepository.Attribute.EXPECT().LoadCategory(ts, tt.categoryID).Return(attribute.Category{CategoryID: 1}, nil)
+				ri.MockRepository.Attribute.EXPECT().FindDefinitionList(ts, tt.categoryID).Return(dairyDefinitionList, nil)
+			}
+			if !tt.wantErr {
+				ri.MockRepository.Product.EXPECT().UpdateProductAttributes(ts, 7, tt.categoryID, tt.values).Return(nil)
+				ts.EXPECT().OnCommit(gomock.Any())
+			}
+
+			ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+			err := ui.Usecase.Attribute.UpdateProductAttributes(ts, attribute.ProductParams{ProductID: 7, CategoryID: tt.categoryID, Attributes: tt.values})
+			if tt.wantErr {
+				r.Error(err)
+				return
+			}
+			r.NoError(err)
+		})
+	}
+}
+
+func TestFindProductListByAttributes(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	filter := attribute.Filter{CategoryID: 1, Values: map[string]string{"fat": "3.2"}}
+	productList := []product.ProductInfo{
+		{ProductID: 7, Name: "Молоко", CategoryID: sqlnull.NewInt64(1), RawAttributes: types.JSONText(`{"brand": "Простоквашино", "fat": 3.2}`)},
+	}
+
+	// отбор по атрибутам выполняется одним запросом вместе с тегом
+	ri.MockRepository.Product.EXPECT().FindProductListByAttributes(ts, filter, "молоко", "", 3).Return(productList, nil)
+	ri.MockRepository.Product.EXPECT().FindVariantListByProductIDList(ts, []int{7}).Return(nil, global.ErrNoData)
+	ri.MockRepository.Image.EXPECT().FindImageListByProductIDList(ts, []int{7}).Return(nil, global.ErrNoData)
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	products, err := ui.Usecase.Product.FindProductList(ts, "молоко", "", 0, filter)
+	r.NoError(err)
+	r.Len(products, 1)
+	r.Equal(attribute.Values{"brand": "Простоквашино", "fat": 3.2}, products[0].Attributes)
+}
+
+func TestAddRequiredDefinition(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	ri.MockRepository.Attribute.EXPECT().LoadCategory(ts, 1).Return(attribute.Category{CategoryID: 1}, nil).Times(3)
+	ri.MockRepository.Attribute.EXPECT().FindDefinitionList(ts, 1).Return(dairyDefinitionList, nil).Times(3)
+	ri.MockRepository.Attribute.EXPECT().CountCategoryProducts(ts, 1).Return(5, nil)
+	ri.MockRepository.Attribute.EXPECT().AddDefinition(ts, gomock.Any()).Return(nil)
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	// в категории уже есть продукты без значения нового обязательного атрибута
+	err := ui.Usecase.Attribute.AddDefinition(ts, attribute.Definition{CategoryID: 1, Code: "volume", Name: "Объем", Type: attribute.TypeNumber, Required: true})
+	r.Error(err)
+
+	// необязательный атрибут добавляется
+	err = ui.Usecase.Attribute.AddDefinition(ts, attribute.Definition{CategoryID: 1, Code: "volume", Name: "Объем", Type: attribute.TypeNumber})
+	r.NoError(err)
+
+	// код атрибута уже занят
+	err = ui.Usecase.Attribute.AddDefinition(ts, attribute.Definition{CategoryID: 1, Code: "fat", Name: "Жирность", Type: attribute.TypeNumber})
+	r.Error(err)
+}
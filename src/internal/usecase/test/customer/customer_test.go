@@ -0,0 +1,157 @@
+package test
+
+import (
+	"product_storage/internal/entity/customer"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/product"
+	"product_storage/internal/entity/stock"
+	"product_storage/internal/entity/valuation"
+	"product_storage/rimport"
+	"product_storage/tools/logger"
+	"product_storage/uimport"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testLogger = logger.NewNoFileLogger("test")
+)
+
+func TestSaveSaleForCustomer(t *testing.T) {
+	soldAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		customerType  string
+		groupPrice    float64
+		noGroupPrice  bool
+		notFound      bool
+		expectedTotal float64
+		err           error
+	}{
+		{
+			name:          "оптовый покупатель по цене группы",
+			customerType:  customer.TypeWholesale,
+			groupPrice:    80,
+			expectedTotal: 240,
+		},
+		{
+			name:          "для группы нет цены",
+			customerType:  customer.TypeRetail,
+			noGroupPrice:  true,
+			expectedTotal: 300,
+		},
+		{
+			name:     "покупатель удален",
+			notFound: true,
+			err:      customer.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ri := rimport.NewTestRepositoryImports(ctrl)
+			ts := ri.MockSession()
+
+			ri.MockRepository.Product.EXPECT().LoadStorage(ts, 1).Return(stock.Stock{StorageID: 1}, nil)
+			ri.MockRepository.Product.EXPECT().FindPrice(ts, 1).Return(100.0, nil)
+			if tt.notFound {
+				ri.MockRepository.Customer.EXPECT().LoadCustomer(ts, 5).Return(customer.Customer{}, global.ErrNoData)
+			} else {
+				ri.MockRepository.Customer.EXPECT().LoadCustomer(ts, 5).
+					Return(customer.Customer{CustomerID: 5, Params: customer.Params{Name: "ООО Ромашка", Type: tt.customerType}}, nil)
+				if tt.noGroupPrice {
+					ri.MockRepository.Customer.EXPECT().FindGroupPrice(ts, 1, tt.customerType, soldAt).Return(0.0, global.ErrNoData)
+				} else {
+					ri.MockRepository.Customer.EXPECT().FindGroupPrice(ts, 1, tt.customerType, soldAt).Return(tt.groupPrice, nil)
+				}
+
+				ri.MockRepository.Promotion.EXPECT().FindApplicablePromotionList(ts, 1, 1, soldAt).Return(nil, global.ErrNoData)
+				ri.MockRepository.Reservation.EXPECT().FindReservedQuantity(ts, 1, 1).Return(0, nil)
+				ri.MockRepository.Stock.EXPECT().DecreaseProductInStock(ts, 1, 1, 3).Return(7, nil)
+				ri.MockRepository.Stock.EXPECT().FindThreshold(ts, 1, 1).Return(stock.ThresholdParams{}, global.ErrNoData)
+				ri.MockRepository.Stock.EXPECT().LoadBatchList(ts, 1, 1).Return(nil, global.ErrNoData)
+				ri.MockRepository.Valuation.EXPECT().LoadAverageCost(ts, 1, 1).Return(valuation.AverageCost{}, global.ErrNoData)
+				ri.MockRepository.Valuation.EXPECT().LoadCostLayerList(ts, 1, 1).Return(nil, global.ErrNoData)
+				ri.MockRepository.Location.EXPECT().TrimLocationStock(ts, 1, 1, 7).Return(nil)
+				ri.MockRepository.Outbox.EXPECT().SaveEvent(ts, gomock.Any()).Return(int64(1), nil).AnyTimes()
+				ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(ts, gomock.Any()).Return(nil).AnyTimes()
+				ts.EXPECT().OnCommit(gomock.Any()).AnyTimes()
+
+				ri.MockRepository.Payment.EXPECT().AddPayment(ts, gomock.Any(), gomock.Any()).Return(1, nil)
+				ri.MockRepository.Product.EXPECT().SaveSale(ts, gomock.Any()).
+					DoAndReturn(func(_ interface{}, s product.SaleParams) (int, error) {
+						r.Equal(5, s.CustomerID)
+						r.Equal(tt.expectedTotal, s.TotalPrice)
+						return 1, nil
+					})
+			}
+
+			ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+			_, err := ui.Usecase.Product.SaveSale(ts, product.SaleParams{VariantID: 1, StorageID: 1, Quantity: 3, SoldAt: soldAt, CustomerID: 5})
+			r.Equal(tt.err, err)
+		})
+	}
+}
+
+func TestAddCustomer(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	_, err := ui.Usecase.Customer.AddCustomer(ts, customer.Params{Name: "Иван", Phone: "123"})
+	r.Error(err)
+
+	_, err = ui.Usecase.Customer.AddCustomer(ts, customer.Params{Name: "Иван", Type: "vip"})
+	r.Error(err)
+
+	// телефон сравнивается в нормализованном виде
+	ri.MockRepository.Customer.EXPECT().FindCustomerIDByPhone(ts, "+79001234567").Return(2, nil)
+	_, err = ui.Usecase.Customer.AddCustomer(ts, customer.Params{Name: "Иван", Phone: "+7 (900) 123-45-67"})
+	r.Equal(customer.ErrPhoneTaken, err)
+
+	ri.MockRepository.Customer.EXPECT().FindCustomerIDByPhone(ts, "89001234568").Return(0, global.ErrNoData)
+	ri.MockRepository.Customer.EXPECT().AddCustomer(ts, customer.Params{Name: "Иван", Phone: "89001234568", Type: customer.TypeRetail}).Return(3, nil)
+	customerID, err := ui.Usecase.Customer.AddCustomer(ts, customer.Params{Name: " Иван ", Phone: "8 900 123 45 68"})
+	r.NoError(err)
+	r.Equal(3, customerID)
+}
+
+func TestFindHistory(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	ri.MockRepository.Customer.EXPECT().LoadCustomer(ts, 5).Return(customer.Customer{CustomerID: 5}, nil)
+	ri.MockRepository.Customer.EXPECT().FindCustomerSaleList(ts, 5, 50).Return([]product.Sale{
+		{SaleID: 2, TotalPrice: 120.5},
+		{SaleID: 1, TotalPrice: 79.5},
+	}, nil)
+	ri.MockRepository.Customer.EXPECT().LoadCustomerSaleTotal(ts, 5).Return(customer.SaleTotal{SaleCount: 120, TotalSpent: 15000}, nil)
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	history, err := ui.Usecase.Customer.FindHistory(ts, 5, 0)
+	r.NoError(err)
+	// итоги по всем продажам покупателя, а не по выведенным последним
+	r.Len(history.Sales, 2)
+	r.Equal(120, history.SaleCount)
+	r.Equal(15000.0, history.TotalSpent)
+	r.Equal(5, history.Customer.CustomerID)
+}
@@ -0,0 +1,193 @@
+package test
+
+import (
+	"bytes"
+	stdimage "image"
+	"image/color"
+	"image/png"
+	"product_storage/internal/bridge"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/image"
+	"product_storage/internal/entity/product"
+	"product_storage/rimport"
+	"product_storage/tools/logger"
+	"product_storage/tools/sqlnull"
+	"product_storage/uimport"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testLogger = logger.NewNoFileLogger("test")
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, width, height))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestUpload(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ri.Config.Image.MaxSize = 1
+	ri.Config.Image.ThumbSize = 50
+	ts := ri.MockSession()
+	blob := bridge.NewMockBlobStorage(ctrl)
+
+	data := encodePNG(t, 200, 100)
+
+	ri.MockRepository.Product.EXPECT().LoadProductInfo(ts, 1).Return(product.ProductInfo{ProductID: 1}, nil).AnyTimes()
+	ri.MockRepository.Product.EXPECT().FindProductVariantList(ts, 1).Return([]product.Variant{{ProductID: 1, VariantID: 10}}, nil).AnyTimes()
+	ri.MockRepository.Image.EXPECT().LockProductImages(ts, 1).Return(nil)
+	ri.MockRepository.Image.EXPECT().NextImagePosition(ts, 1).Return(0, nil)
+	ts.EXPECT().OnRollback(gomock.Any())
+
+	var thumb []byte
+	gomock.InOrder(
+		blob.EXPECT().Put(gomock.Any(), gomock.Any(), image.ContentTypePNG, gomock.Any()).Return(nil),
+		blob.EXPECT().Put(gomock.Any(), gomock.Any(), image.ContentTypePNG, gomock.Any()).
+			DoAndReturn(func(_ interface{}, key, _ string, rd *bytes.Reader) error {
+				r.True(strings.HasSuffix(key, "_thumb.png"))
+				thumb = make([]byte, rd.Len())
+				rd.Read(thumb)
+				return nil
+			}),
+	)
+
+	// первое изображение продукта становится основным
+	ri.MockRepository.Image.EXPECT().AddImage(ts, gomock.Any()).DoAndReturn(func(_ interface{}, img image.Image) (int, error) {
+		r.True(img.IsPrimary)
+		r.Equal(sqlnull.NewInt64(10), img.VariantID)
+		r.Equal(200, img.Width)
+		r.Equal(100, img.Height)
+		r.True(strings.HasPrefix(img.BlobKey, "products/1/"))
+		return 5, nil
+	})
+	ts.EXPECT().OnCommit(gomock.Any())
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+	ui.Usecase.Product.SetBlobStorage(blob)
+
+	imageID, err := ui.Usecase.Image.Upload(ts, image.UploadParams{ProductID: 1, VariantID: 10}, data)
+	r.NoError(err)
+	r.Equal(5, imageID)
+
+	// превью вписано в заданный размер
+	cfg, err := png.DecodeConfig(bytes.NewReader(thumb))
+	r.NoError(err)
+	r.Equal(50, cfg.Width)
+	r.Equal(25, cfg.Height)
+
+	// вариант другого продукта
+	_, err = ui.Usecase.Image.Upload(ts, image.UploadParams{ProductID: 1, VariantID: 20}, data)
+	r.Error(err)
+
+	// файл не является изображением
+	_, err = ui.Usecase.Image.Upload(ts, image.UploadParams{ProductID: 1}, []byte("текст"))
+	r.Equal(image.ErrUnsupportedImage, err)
+
+	// файл больше допустимого размера
+	_, err = ui.Usecase.Image.Upload(ts, image.UploadParams{ProductID: 1}, make([]byte, 1<<20+1))
+	r.Equal(image.ErrTooLarge, err)
+}
+
+func TestUploadRemovesBlobsOnError(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ri.Config.Image.MaxSize = 1
+	ts := ri.MockSession()
+	blob := bridge.NewMockBlobStorage(ctrl)
+
+	ri.MockRepository.Product.EXPECT().LoadProductInfo(ts, 1).Return(product.ProductInfo{ProductID: 1}, nil)
+	ri.MockRepository.Image.EXPECT().LockProductImages(ts, 1).Return(nil)
+	ri.MockRepository.Image.EXPECT().NextImagePosition(ts, 1).Return(3, nil)
+	ri.MockRepository.Image.EXPECT().AddImage(ts, gomock.Any()).Return(0, global.ErrInternalError)
+	blob.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	var afterRollback func()
+	ts.EXPECT().OnRollback(gomock.Any()).Do(func(f func()) { afterRollback = f })
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+	ui.Usecase.Product.SetBlobStorage(blob)
+
+	_, err := ui.Usecase.Image.Upload(ts, image.UploadParams{ProductID: 1}, encodePNG(t, 10, 10))
+	r.Equal(global.ErrInternalError, err)
+
+	// файлы удаляются из хранилища при откате транзакции
+	blob.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	afterRollback()
+}
+
+func TestDeletePrimary(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+	blob := bridge.NewMockBlobStorage(ctrl)
+
+	img := image.Image{ImageID: 5, ProductID: 1, BlobKey: "products/1/a.png", ThumbKey: "products/1/a_thumb.png", IsPrimary: true}
+
+	ri.MockRepository.Image.EXPECT().LoadImage(ts, 5).Return(img, nil)
+	ri.MockRepository.Image.EXPECT().DeleteImage(ts, 5).Return(nil)
+	ri.MockRepository.Image.EXPECT().PromoteFirstImage(ts, 1).Return(nil)
+
+	var afterCommit []func()
+	ts.EXPECT().OnCommit(gomock.Any()).Do(func(f func()) { afterCommit = append(afterCommit, f) }).Times(2)
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+	ui.Usecase.Product.SetBlobStorage(blob)
+
+	r.NoError(ui.Usecase.Image.Delete(ts, 5))
+
+	// файлы удаляются из хранилища только после фиксации транзакции
+	blob.EXPECT().Delete(gomock.Any(), "products/1/a.png").Return(nil)
+	blob.EXPECT().Delete(gomock.Any(), "products/1/a_thumb.png").Return(nil)
+	for _, f := range afterCommit {
+		f()
+	}
+}
+
+func TestReorder(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	imageList := []image.Image{{ImageID: 5, ProductID: 1}, {ImageID: 6, ProductID: 1}, {ImageID: 7, ProductID: 1}}
+
+	ri.MockRepository.Image.EXPECT().FindImageList(ts, 1).Return(imageList, nil).Times(3)
+	ri.MockRepository.Image.EXPECT().SetImagePosition(ts, 7, 0).Return(nil)
+	ri.MockRepository.Image.EXPECT().SetImagePosition(ts, 5, 1).Return(nil)
+	ri.MockRepository.Image.EXPECT().SetImagePosition(ts, 6, 2).Return(nil)
+	ts.EXPECT().OnCommit(gomock.Any())
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	r.NoError(ui.Usecase.Image.Reorder(ts, image.OrderParams{ProductID: 1, ImageIDList: []int{7, 5, 6}}))
+
+	// в новом порядке указаны не все изображения продукта
+	r.Error(ui.Usecase.Image.Reorder(ts, image.OrderParams{ProductID: 1, ImageIDList: []int{7, 5}}))
+
+	// изображение другого продукта
+	r.Error(ui.Usecase.Image.Reorder(ts, image.OrderParams{ProductID: 1, ImageIDList: []int{7, 5, 8}}))
+
+	// повтор изображения в списке
+	r.Error(ui.Usecase.Image.Reorder(ts, image.OrderParams{ProductID: 1, ImageIDList: []int{7, 7, 6}}))
+}
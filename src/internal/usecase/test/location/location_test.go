@@ -0,0 +1,117 @@
+package test
+
+import (
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/location"
+	"product_storage/internal/entity/stock"
+	"product_storage/rimport"
+	"product_storage/tools/logger"
+	"product_storage/tools/sqlnull"
+	"product_storage/uimport"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testLogger = logger.NewNoFileLogger("test")
+)
+
+func TestAddLocation(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	shelf := location.Location{LocationID: 3, StorageID: 1, Kind: location.KindShelf, Path: "A/01/3"}
+
+	ri.MockRepository.Product.EXPECT().LoadStorage(ts, 1).Return(stock.Stock{StorageID: 1}, nil).Times(2)
+	ri.MockRepository.Location.EXPECT().LoadLocation(ts, 3).Return(shelf, nil).Times(2)
+	ri.MockRepository.Location.EXPECT().AddLocation(ts, location.Location{
+		StorageID: 1,
+		ParentID:  sqlnull.NewInt64(3),
+		Kind:      location.KindBin,
+		Code:      "B",
+		Path:      "A/01/3/B",
+	}).Return(4, nil)
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	// адрес ячейки строится от адреса стеллажа
+	locationID, err := ui.Usecase.Location.AddLocation(ts, location.Params{StorageID: 1, ParentID: 3, Kind: location.KindBin, Code: "B"})
+	r.NoError(err)
+	r.Equal(4, locationID)
+
+	// зона не может находиться внутри стеллажа
+	_, err = ui.Usecase.Location.AddLocation(ts, location.Params{StorageID: 1, ParentID: 3, Kind: location.KindZone, Code: "Z"})
+	r.Error(err)
+}
+
+func TestPutaway(t *testing.T) {
+	r := require.New(t)
+
+	bin := location.Location{LocationID: 4, StorageID: 1, Kind: location.KindBin, Path: "A/01/3/B"}
+
+	tests := []struct {
+		name     string
+		unplaced int
+		err      error
+	}{
+		{
+			name:     "неразмещенного продукта достаточно",
+			unplaced: 5,
+		},
+		{
+			name:     "неразмещенного продукта не хватает",
+			unplaced: 2,
+			err:      location.ErrNotEnoughUnplaced,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ri := rimport.NewTestRepositoryImports(ctrl)
+			ts := ri.MockSession()
+
+			ri.MockRepository.Location.EXPECT().LoadLocation(ts, 4).Return(bin, nil)
+			ri.MockRepository.Location.EXPECT().LoadUnplacedQuantity(ts, 7, 1).Return(tt.unplaced, nil)
+			if tt.err == nil {
+				ri.MockRepository.Location.EXPECT().PutLocationStock(ts, 4, 7, 3).Return(nil)
+			}
+
+			ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+			err := ui.Usecase.Location.Putaway(ts, location.PutawayParams{VariantID: 7, LocationID: 4, Quantity: 3})
+			r.Equal(tt.err, err)
+		})
+	}
+}
+
+func TestMove(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	ri.MockRepository.Location.EXPECT().LoadLocation(ts, 4).Return(location.Location{LocationID: 4, StorageID: 1}, nil).Times(2)
+	ri.MockRepository.Location.EXPECT().LoadLocation(ts, 5).Return(location.Location{LocationID: 5, StorageID: 1}, nil)
+	ri.MockRepository.Location.EXPECT().LoadLocation(ts, 6).Return(location.Location{LocationID: 6, StorageID: 2}, nil)
+	ri.MockRepository.Location.EXPECT().TakeLocationStock(ts, 4, 7, 3).Return(global.ErrNoData)
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	err := ui.Usecase.Location.Move(ts, location.MoveParams{VariantID: 7, FromLocationID: 4, ToLocationID: 5, Quantity: 3})
+	r.Equal(location.ErrNotEnoughInLocation, err)
+
+	// между складами продукт перемещается только через их остатки, а не места хранения
+	err = ui.Usecase.Location.Move(ts, location.MoveParams{VariantID: 7, FromLocationID: 4, ToLocationID: 6, Quantity: 3})
+	r.Error(err)
+}
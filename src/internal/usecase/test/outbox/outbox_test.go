@@ -0,0 +1,115 @@
+package test
+
+import (
+	"errors"
+	"product_storage/internal/entity/event"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/logger"
+	"product_storage/tools/memorybroker"
+	"product_storage/uimport"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testLogger = logger.NewNoFileLogger("test")
+)
+
+func TestPublishPending(t *testing.T) {
+	r := require.New(t)
+
+	eventList := []event.Event{
+		{EventID: 1, EventType: event.ProductCreated, AggregateID: 1, Payload: []byte(`{"product_id":1}`)},
+		{EventID: 2, EventType: event.PriceChanged, AggregateID: 10, Payload: []byte(`{"variant_id":10}`)},
+		{EventID: 3, EventType: event.SaleRecorded, AggregateID: 5, Payload: []byte(`{"sale_id":5}`), Attempts: 2},
+	}
+
+	type fields struct {
+		ts *transaction.MockSession
+		ri rimport.TestRepositoryImports
+	}
+
+	tests := []struct {
+		name              string
+		prepare           func(f *fields)
+		brokerFail        error
+		expectedPublished int
+		expectedEventList []int64
+		err               error
+	}{
+		{
+			name: "все события опубликованы",
+			prepare: func(f *fields) {
+				f.ri.MockRepository.Outbox.EXPECT().LoadPendingEventList(f.ts, 100).Return(eventList, nil)
+				for _, e := range eventList {
+					f.ri.MockRepository.Outbox.EXPECT().MarkPublished(f.ts, e.EventID).Return(nil)
+				}
+			},
+			expectedPublished: 3,
+			expectedEventList: []int64{1, 2, 3},
+		},
+		{
+			name: "нет неопубликованных событий",
+			prepare: func(f *fields) {
+				f.ri.MockRepository.Outbox.EXPECT().LoadPendingEventList(f.ts, 100).Return(nil, global.ErrNoData)
+			},
+		},
+		{
+			name:       "брокер недоступен",
+			brokerFail: errors.New("connection refused"),
+			prepare: func(f *fields) {
+				f.ri.MockRepository.Outbox.EXPECT().LoadPendingEventList(f.ts, 100).Return(eventList, nil)
+				// после первой ошибки обработка пачки прекращается
+				f.ri.MockRepository.Outbox.EXPECT().MarkFailed(f.ts, int64(1), gomock.Any(), "connection refused").
+					DoAndReturn(func(_ transaction.Session, _ int64, nextAttemptAt time.Time, _ string) error {
+						r.True(nextAttemptAt.After(time.Now()))
+						return nil
+					})
+			},
+		},
+		{
+			name: "ошибка загрузки событий",
+			prepare: func(f *fields) {
+				f.ri.MockRepository.Outbox.EXPECT().LoadPendingEventList(f.ts, 100).Return(nil, errors.New("db error"))
+			},
+			err: global.ErrInternalError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			f := fields{
+				ri: rimport.NewTestRepositoryImports(ctrl),
+			}
+			f.ts = f.ri.MockSession()
+			f.ri.Config.Outbox.BatchSize = 100
+
+			if tt.prepare != nil {
+				tt.prepare(&f)
+			}
+
+			broker := memorybroker.NewBroker()
+			broker.SetFail(tt.brokerFail)
+
+			ui := uimport.NewUsecaseImports(testLogger, testLogger, f.ri.RepositoryImports(), f.ri.SessionManager)
+
+			published, err := ui.Usecase.Outbox.PublishPending(f.ts, broker)
+			r.Equal(tt.err, err)
+			r.Equal(tt.expectedPublished, published)
+
+			publishedIDList := make([]int64, 0)
+			for _, e := range broker.Events() {
+				publishedIDList = append(publishedIDList, e.EventID)
+			}
+			r.ElementsMatch(tt.expectedEventList, publishedIDList)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package test
+
+import (
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/payment"
+	"product_storage/internal/entity/product"
+	"product_storage/internal/entity/stock"
+	"product_storage/internal/entity/valuation"
+	"product_storage/rimport"
+	"product_storage/tools/logger"
+	"product_storage/uimport"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testLogger = logger.NewNoFileLogger("test")
+)
+
+func TestSaveSalePayments(t *testing.T) {
+	tests := []struct {
+		name     string
+		payments []payment.Params
+		expected []payment.Params
+		err      bool
+	}{
+		{
+			name:     "без оплат вся сумма оплачивается способом по умолчанию",
+			expected: []payment.Params{{Method: payment.MethodCash, Amount: 299.97}},
+		},
+		{
+			name: "оплата частями",
+			payments: []payment.Params{
+				{Method: payment.MethodCard, Amount: 200},
+				{Method: payment.MethodCash, Amount: 99.97},
+			},
+			expected: []payment.Params{
+				{Method: payment.MethodCard, Amount: 200},
+				{Method: payment.MethodCash, Amount: 99.97},
+			},
+		},
+		{
+			name: "сумма оплат не равна сумме продажи",
+			payments: []payment.Params{
+				{Method: payment.MethodCard, Amount: 200},
+				{Method: payment.MethodCash, Amount: 99.96},
+			},
+			err: true,
+		},
+		{
+			name:     "неизвестный способ оплаты",
+			payments: []payment.Params{{Method: "bonus", Amount: 299.97}},
+			err:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ri := rimport.NewTestRepositoryImports(ctrl)
+			ts := ri.MockSession()
+
+			ri.MockRepository.Product.EXPECT().LoadStorage(ts, 1).Return(stock.Stock{StorageID: 1}, nil)
+			ri.MockRepository.Product.EXPECT().FindPrice(ts, 1).Return(99.99, nil)
+			ri.MockRepository.Promotion.EXPECT().FindApplicablePromotionList(ts, 1, 1, gomock.Any()).Return(nil, global.ErrNoData)
+
+			// при ошибке в оплатах остатки не изменяются
+			if !tt.err {
+				ri.MockRepository.Reservation.EXPECT().FindReservedQuantity(ts, 1, 1).Return(0, nil)
+				ri.MockRepository.Stock.EXPECT().DecreaseProductInStock(ts, 1, 1, 3).Return(7, nil)
+				ri.MockRepository.Stock.EXPECT().FindThreshold(ts, 1, 1).Return(stock.ThresholdParams{}, global.ErrNoData)
+				ri.MockRepository.Stock.EXPECT().LoadBatchList(ts, 1, 1).Return(nil, global.ErrNoData)
+				ri.MockRepository.Valuation.EXPECT().LoadAverageCost(ts, 1, 1).Return(valuation.AverageCost{}, global.ErrNoData)
+				ri.MockRepository.Valuation.EXPECT().LoadCostLayerList(ts, 1, 1).Return(nil, global.ErrNoData)
+				ri.MockRepository.Location.EXPECT().TrimLocationStock(ts, 1, 1, 7).Return(nil)
+				ri.MockRepository.Outbox.EXPECT().SaveEvent(ts, gomock.Any()).Return(int64(1), nil).AnyTimes()
+				ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(ts, gomock.Any()).Return(nil).AnyTimes()
+				ts.EXPECT().OnCommit(gomock.Any()).AnyTimes()
+
+				ri.MockRepository.Product.EXPECT().SaveSale(ts, gomock.Any()).Return(21, nil)
+				for i, p := range tt.expected {
+					ri.MockRepository.Payment.EXPECT().AddPayment(ts, 21, p).Return(i+1, nil)
+				}
+			}
+
+			ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+			saleID, err := ui.Usecase.Product.SaveSale(ts, product.SaleParams{VariantID: 1, StorageID: 1, Quantity: 3, Payments: tt.payments})
+			if tt.err {
+				r.Error(err)
+				return
+			}
+			r.NoError(err)
+			r.Equal(21, saleID)
+		})
+	}
+}
+
+func TestZReport(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	_, err := ui.Usecase.Payment.ZReport(ts, payment.ZQuery{})
+	r.Error(err)
+
+	date := time.Date(2024, 3, 1, 15, 30, 0, 0, time.UTC)
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+
+	ri.MockRepository.Payment.EXPECT().FindZStorageTotalList(ts, start, end, 0).Return([]payment.ZStorageTotal{
+		{StorageID: 1, StorageName: "Центральный", SaleCount: 3, Revenue: 450.5, Discount: 20},
+		{StorageID: 2, StorageName: "Северный", SaleCount: 1, Revenue: 100},
+	}, nil)
+	ri.MockRepository.Payment.EXPECT().FindZMethodTotalList(ts, start, end, 0).Return([]payment.ZMethodTotal{
+		{StorageID: 1, Method: payment.MethodCard, PaymentCount: 2, Amount: 300.2},
+		{StorageID: 1, Method: payment.MethodCash, PaymentCount: 2, Amount: 150.3},
+		{StorageID: 2, Method: payment.MethodCash, PaymentCount: 1, Amount: 60},
+	}, nil)
+
+	report, err := ui.Usecase.Payment.ZReport(ts, payment.ZQuery{Date: date})
+	r.NoError(err)
+	r.Equal(start, report.Start)
+	r.Equal(4, report.SaleCount)
+	r.Equal(550.5, report.Revenue)
+	r.Equal(20.0, report.Discount)
+	r.Equal(map[string]float64{payment.MethodCash: 210.3, payment.MethodCard: 300.2, payment.MethodTransfer: 0}, report.Methods)
+
+	// у склада выводятся все способы оплаты, продажи до учета оплат попадают в отдельную сумму
+	r.Len(report.Storages, 2)
+	r.Len(report.Storages[1].Methods, len(payment.MethodList))
+	r.Equal(0.0, report.Storages[0].WithoutPayments)
+	r.Equal(40.0, report.Storages[1].WithoutPayments)
+
+	// за день без продаж отчет пустой
+	ri.MockRepository.Payment.EXPECT().FindZStorageTotalList(ts, start, end, 2).Return(nil, global.ErrNoData)
+	report, err = ui.Usecase.Payment.ZReport(ts, payment.ZQuery{Date: date, StorageID: 2})
+	r.NoError(err)
+	r.Equal(0, report.SaleCount)
+	r.Empty(report.Storages)
+}
@@ -1,11 +1,23 @@
 package test
 
 import (
+	"context"
+	"product_storage/config"
+	"product_storage/internal/bridge"
+	"product_storage/internal/entity/attribute"
+	"product_storage/internal/entity/event"
 	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/image"
+	"product_storage/internal/entity/location"
 	"product_storage/internal/entity/product"
+	"product_storage/internal/entity/stock"
+	"product_storage/internal/entity/unit"
+	"product_storage/internal/entity/valuation"
 	"product_storage/internal/transaction"
 	"product_storage/rimport"
+	"product_storage/tools/localblob"
 	"product_storage/tools/logger"
+	"product_storage/tools/sqlnull"
 	"product_storage/uimport"
 	"testing"
 	"time"
@@ -59,8 +71,32 @@ func TestSaveSale(t *testing.T) {
 					SoldAt:     fixedTime,
 					TotalPrice: price * float64(argSale.Quantity),
 				}
+				f.ri.MockRepository.Product.EXPECT().LoadStorage(f.ts, 1).Return(stock.Stock{StorageID: 1}, nil)
 				f.ri.MockRepository.Product.EXPECT().FindPrice(f.ts, sale.VariantID).Return(price, nil)
+				f.ri.MockRepository.Promotion.EXPECT().FindApplicablePromotionList(f.ts, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, global.ErrNoData)
+				f.ri.MockRepository.Reservation.EXPECT().FindReservedQuantity(f.ts, 1, 1).Return(0, nil)
+				f.ri.MockRepository.Stock.EXPECT().DecreaseProductInStock(f.ts, 1, 1, 2).Return(8, nil)
+				f.ri.MockRepository.Stock.EXPECT().FindThreshold(f.ts, 1, 1).Return(stock.ThresholdParams{}, global.ErrNoData)
+				f.ri.MockRepository.Stock.EXPECT().LoadBatchList(f.ts, 1, 1).Return(nil, global.ErrNoData)
+				f.ri.MockRepository.Valuation.EXPECT().LoadAverageCost(f.ts, 1, 1).Return(valuation.AverageCost{}, global.ErrNoData)
+				f.ri.MockRepository.Valuation.EXPECT().LoadCostLayerList(f.ts, 1, 1).Return(nil, global.ErrNoData)
+				f.ri.MockRepository.Location.EXPECT().TrimLocationStock(f.ts, 1, 1, 8).Return(nil)
+				f.ri.MockRepository.Payment.EXPECT().AddPayment(f.ts, gomock.Any(), gomock.Any()).Return(1, nil)
 				f.ri.MockRepository.Product.EXPECT().SaveSale(f.ts, sale).Return(saleID, nil)
+				f.ts.EXPECT().OnCommit(gomock.Any())
+
+				var eventTypes []string
+				f.ri.MockRepository.Outbox.EXPECT().SaveEvent(f.ts, gomock.Any()).
+					DoAndReturn(func(_ transaction.Session, e event.Event) (int64, error) {
+						eventTypes = append(eventTypes, e.EventType)
+						return int64(len(eventTypes)), nil
+					}).Times(2)
+				f.ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(f.ts, gomock.Any()).
+					DoAndReturn(func(_ transaction.Session, e event.Event) error {
+						// доставки ссылаются на записанное событие
+						r.Equal(eventTypes[e.EventID-1], e.EventType)
+						return nil
+					}).Times(2)
 			},
 			args: args{
 				sale: argSale,
@@ -69,10 +105,56 @@ func TestSaveSale(t *testing.T) {
 			expectedTotalPrice: 5.99,
 			err:                nil,
 		},
+		{
+			name: "недостаточно продукта на складе",
+			prepare: func(f *fields) {
+				f.ri.MockRepository.Product.EXPECT().LoadStorage(f.ts, 1).Return(stock.Stock{StorageID: 1}, nil)
+				f.ri.MockRepository.Product.EXPECT().FindPrice(f.ts, argSale.VariantID).Return(5.99, nil)
+				f.ri.MockRepository.Promotion.EXPECT().FindApplicablePromotionList(f.ts, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, global.ErrNoData)
+				f.ri.MockRepository.Reservation.EXPECT().FindReservedQuantity(f.ts, 1, 1).Return(0, nil)
+				f.ri.MockRepository.Stock.EXPECT().DecreaseProductInStock(f.ts, 1, 1, 2).Return(0, global.ErrNoData)
+			},
+			args: args{
+				argSale,
+			},
+			err: stock.ErrNotEnoughStock,
+		},
+		{
+			name: "продукт зарезервирован под другие заказы",
+			prepare: func(f *fields) {
+				f.ri.MockRepository.Product.EXPECT().LoadStorage(f.ts, 1).Return(stock.Stock{StorageID: 1}, nil)
+				f.ri.MockRepository.Product.EXPECT().FindPrice(f.ts, argSale.VariantID).Return(5.99, nil)
+				f.ri.MockRepository.Promotion.EXPECT().FindApplicablePromotionList(f.ts, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, global.ErrNoData)
+				f.ri.MockRepository.Reservation.EXPECT().FindReservedQuantity(f.ts, 1, 1).Return(3, nil)
+				f.ri.MockRepository.Reservation.EXPECT().LoadStockQuantity(f.ts, 1, 1).Return(4, nil)
+			},
+			args: args{
+				argSale,
+			},
+			err: stock.ErrNotEnoughAvailable,
+		},
+		{
+			name: "неверная контрольная цифра штрихкода",
+			args: args{
+				product.SaleParams{Barcode: "4006381333932", StorageID: 1, Quantity: 2},
+			},
+			err: product.ErrInvalidBarcode,
+		},
+		{
+			name: "штрихкод не привязан к варианту",
+			prepare: func(f *fields) {
+				f.ri.MockRepository.Product.EXPECT().FindVariantIDByBarcode(f.ts, "4006381333931").Return(0, global.ErrNoData)
+			},
+			args: args{
+				product.SaleParams{Barcode: "4006381333931", StorageID: 1, Quantity: 2},
+			},
+			err: product.ErrBarcodeNotFound,
+		},
 		{
 			name: "безуспешный результат",
 			prepare: func(f *fields) {
 				price := 0.0 // Assuming price is 0.0 in this case
+				f.ri.MockRepository.Product.EXPECT().LoadStorage(f.ts, 1).Return(stock.Stock{StorageID: 1}, nil)
 				f.ri.MockRepository.Product.EXPECT().FindPrice(f.ts, argSale.VariantID).Return(price, global.ErrNoData)
 			},
 			args: args{
@@ -108,3 +190,716 @@ func TestSaveSale(t *testing.T) {
 		})
 	}
 }
+
+func TestFindProductList(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := transaction.NewMockSession(ctrl)
+
+	productList := []product.ProductInfo{
+		{ProductID: 1, Name: "Чай"},
+		{ProductID: 2, Name: "Вода"},
+	}
+	variantList := []product.Variant{
+		{ProductID: 1, VariantID: 10, Weight: 100, Unit: "г", UnitDimension: unit.DimensionMass, UnitFactor: 0.001},
+		{ProductID: 1, VariantID: 11, Weight: 200, Unit: "г", UnitDimension: unit.DimensionMass, UnitFactor: 0.001},
+		{ProductID: 2, VariantID: 20, Weight: 1.5, Unit: "л", UnitDimension: unit.DimensionVolume, UnitFactor: 1},
+	}
+	priceList := []product.VariantPrice{
+		{VariantID: 10, Price: 2.99},
+		{VariantID: 20, Price: 1.49},
+	}
+	storageList := []product.VariantStorage{
+		{VariantID: 10, VarStorage: product.VarStorage{StorageID: 1, StorageName: "Склад 1"}},
+		{VariantID: 10, VarStorage: product.VarStorage{StorageID: 2, StorageName: "Склад 2"}},
+		{VariantID: 11, VarStorage: product.VarStorage{StorageID: 2, StorageName: "Склад 2"}},
+	}
+
+	// варианты, цены и склады загружаются одним запросом на весь список продуктов
+	ri.MockRepository.Product.EXPECT().LoadProductList(ts, 3).Return(productList, nil)
+	ri.MockRepository.Product.EXPECT().FindVariantListByProductIDList(ts, []int{1, 2}).Return(variantList, nil).Times(1)
+	ri.MockRepository.Product.EXPECT().FindCurrentPriceListByVariantIDList(ts, []int{10, 11, 20}).Return(priceList, nil).Times(1)
+	ri.MockRepository.Product.EXPECT().FindStorageListByVariantIDList(ts, []int{10, 11, 20}).Return(storageList, nil).Times(1)
+	ri.MockRepository.Image.EXPECT().FindImageListByProductIDList(ts, []int{1, 2}).Return([]image.Image{
+		{ImageID: 7, ProductID: 1, BlobKey: "products/1/a.jpg", ThumbKey: "products/1/a_thumb.jpg", IsPrimary: true},
+	}, nil).Times(1)
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), transaction.NewMockSessionManager(ctrl))
+	ui.Usecase.Product.SetBlobStorage(localblob.NewStorage(t.TempDir(), "/media"))
+
+	products, err := ui.Usecase.Product.FindProductList(ts, "", "", 0, attribute.Filter{})
+	r.NoError(err)
+	r.Len(products, 2)
+
+	r.Len(products[0].VariantList, 2)
+	r.Equal(2.99, products[0].VariantList[0].CurrentPrice)
+	r.Equal(29.9, products[0].VariantList[0].PricePerBaseUnit)
+	r.Equal("кг", products[0].VariantList[0].BaseUnit)
+	r.Len(products[0].VariantList[0].InStorages, 2)
+	r.Zero(products[0].VariantList[1].CurrentPrice)
+	r.Len(products[0].VariantList[1].InStorages, 1)
+
+	r.Len(products[1].VariantList, 1)
+	r.Equal(1.49, products[1].VariantList[0].CurrentPrice)
+	r.Equal(0.99, products[1].VariantList[0].PricePerBaseUnit)
+	r.Empty(products[1].VariantList[0].InStorages)
+
+	r.Len(products[0].Images, 1)
+	r.Equal("/media/products/1/a.jpg", products[0].Images[0].URL)
+	r.Equal("/media/products/1/a_thumb.jpg", products[0].Images[0].ThumbURL)
+	r.Empty(products[1].Images)
+}
+
+func TestFindProductsInStock(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	// варианты всех складов загружаются одним запросом, пустой склад не прерывает заполнение остальных
+	ri.MockRepository.Product.EXPECT().LoadStockList(ts, false).Return([]stock.Stock{
+		{StorageID: 1, StorageName: "Пустой"},
+		{StorageID: 2, StorageName: "Центральный"},
+	}, nil)
+	ri.MockRepository.Product.EXPECT().FindStocksVariantListByStorageIDList(ts, []int{1, 2}).Return([]stock.ProductInStockParams{
+		{VariantID: 10, StorageID: 2, Quantity: 5},
+		{VariantID: 11, StorageID: 2, Quantity: 3},
+	}, nil).Times(1)
+
+	stockList, err := ui.Usecase.Product.FindProductsInStock(ts, 0, false)
+	r.NoError(err)
+	r.Len(stockList, 2)
+	r.Empty(stockList[0].ProductVariantList)
+	r.Len(stockList[1].ProductVariantList, 2)
+
+	// склады продукта заполняются тем же циклом, с разбивкой по местам хранения
+	ri.MockRepository.Product.EXPECT().FindStockListByProductId(ts, 1).Return([]stock.Stock{{StorageID: 2, StorageName: "Центральный"}}, nil)
+	ri.MockRepository.Product.EXPECT().FindStocksVariantListByStorageIDList(ts, []int{2}).Return([]stock.ProductInStockParams{
+		{VariantID: 10, StorageID: 2, Quantity: 5},
+	}, nil)
+	ri.MockRepository.Location.EXPECT().FindLocationStockList(ts, 2).Return([]location.Stock{
+		{LocationID: 4, Path: "A-1", VariantID: 10, Quantity: 2},
+	}, nil)
+
+	stockList, err = ui.Usecase.Product.FindProductsInStock(ts, 1, true)
+	r.NoError(err)
+	r.Len(stockList, 1)
+	r.Len(stockList[0].ProductVariantList[0].Locations, 1)
+	r.Equal(3, stockList[0].ProductVariantList[0].Unplaced)
+}
+
+func TestFindProductInfoByIdCache(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ri.Config.Redis.CacheTime = 1
+	ts := transaction.NewMockSession(ctrl)
+
+	productInfo := product.ProductInfo{ProductID: 1, Name: "Чай"}
+	variantList := []product.Variant{{ProductID: 1, VariantID: 10, Weight: 100, Unit: "г"}}
+
+	expectLoad := func(price float64) {
+		ri.MockRepository.Product.EXPECT().LoadProductInfo(ts, 1).Return(productInfo, nil)
+		ri.MockRepository.Product.EXPECT().FindVariantListByProductIDList(ts, []int{1}).Return(variantList, nil)
+		ri.MockRepository.Product.EXPECT().FindCurrentPriceListByVariantIDList(ts, []int{10}).Return([]product.VariantPrice{{VariantID: 10, Price: price}}, nil)
+		ri.MockRepository.Product.EXPECT().FindStorageListByVariantIDList(ts, []int{10}).Return(nil, global.ErrNoData)
+		ri.MockRepository.Image.EXPECT().FindImageListByProductIDList(ts, []int{1}).Return(nil, global.ErrNoData)
+	}
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), transaction.NewMockSessionManager(ctrl))
+
+	// первый запрос загружается из базы, повторный берется из кэша
+	expectLoad(2.99)
+	for i := 0; i < 2; i++ {
+		info, err := ui.Usecase.Product.FindProductInfoById(ts, 1)
+		r.NoError(err)
+		r.Equal(2.99, info.VariantList[0].CurrentPrice)
+	}
+
+	// изменение цены варианта сбрасывает кэш после фиксации транзакции
+	price := product.ProductPriceParams{VariantID: 10, Price: 3.49}
+	ri.MockRepository.Product.EXPECT().CheckExists(ts, gomock.Any()).Return(0, global.ErrNoData)
+	ri.MockRepository.Product.EXPECT().AddProductPrice(ts, gomock.Any()).Return(5, nil)
+	ri.MockRepository.Outbox.EXPECT().SaveEvent(ts, gomock.Any()).Return(int64(1), nil)
+	ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(ts, gomock.Any()).Return(nil)
+
+	var afterCommit func()
+	ts.EXPECT().OnCommit(gomock.Any()).Do(func(f func()) { afterCommit = f })
+
+	_, err := ui.Usecase.Product.AddProductPrice(ts, price)
+	r.NoError(err)
+
+	// до фиксации в кэше остается прежнее значение
+	info, err := ui.Usecase.Product.FindProductInfoById(ts, 1)
+	r.NoError(err)
+	r.Equal(2.99, info.VariantList[0].CurrentPrice)
+
+	afterCommit()
+
+	expectLoad(3.49)
+	info, err = ui.Usecase.Product.FindProductInfoById(ts, 1)
+	r.NoError(err)
+	r.Equal(3.49, info.VariantList[0].CurrentPrice)
+
+	stats := ui.Usecase.Product.CacheStats()
+	r.Equal(int64(2), stats.Hits)
+	r.Equal(int64(2), stats.Misses)
+}
+
+func TestSubscribeStockChanges(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ri.Config.Stream.HistorySize = 10
+	ri.Config.Stream.BufferSize = 10
+	ts := transaction.NewMockSession(ctrl)
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), transaction.NewMockSessionManager(ctrl))
+
+	sub := ui.Usecase.Product.SubscribeStockChanges(0, false)
+	defer sub.Close()
+
+	p := stock.ProductInStockParams{VariantID: 7, StorageID: 2, Quantity: 15}
+	ri.MockRepository.Product.EXPECT().LoadStorage(ts, 2).Return(stock.Stock{StorageID: 2}, nil)
+	ri.MockRepository.Product.EXPECT().CheckProductInStock(ts, gomock.Any()).Return(true, nil)
+	ri.MockRepository.Reservation.EXPECT().FindReservedQuantity(ts, 7, 2).Return(0, nil)
+	ri.MockRepository.Reservation.EXPECT().LoadStockQuantity(ts, 7, 2).Return(10, nil)
+	ri.MockRepository.Product.EXPECT().UpdateProductInstock(ts, gomock.Any()).Return(4, nil)
+	ri.MockRepository.Location.EXPECT().TrimLocationStock(ts, 7, 2, 15).Return(nil)
+	ri.MockRepository.Stock.EXPECT().TrimBatches(ts, 7, 2, 15).Return(nil)
+	ri.MockRepository.Outbox.EXPECT().SaveEvent(ts, gomock.Any()).Return(int64(42), nil)
+	ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(ts, gomock.Any()).Return(nil)
+
+	var afterCommit func()
+	ts.EXPECT().OnCommit(gomock.Any()).Do(func(f func()) { afterCommit = f })
+
+	_, err := ui.Usecase.Product.AddProductInStock(ts, p)
+	r.NoError(err)
+
+	// до фиксации транзакции изменение не рассылается
+	r.Empty(sub.C)
+
+	afterCommit()
+
+	item := <-sub.C
+	r.Equal(int64(42), item.ID)
+	r.Equal(stock.StockLevel{VariantID: 7, StorageID: 2, Quantity: 15}, item.Data)
+
+	// переподключившийся клиент получает изменения после последнего полученного id
+	resumed := ui.Usecase.Product.SubscribeStockChanges(41, true)
+	defer resumed.Close()
+	r.False(resumed.Resumed)
+
+	resumed = ui.Usecase.Product.SubscribeStockChanges(42, true)
+	defer resumed.Close()
+	r.True(resumed.Resumed)
+	r.Empty(resumed.Backlog)
+}
+
+func TestAddProductInStockBelowReserved(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	// остаток нельзя установить ниже кол-ва, зарезервированного под заказы
+	p := stock.ProductInStockParams{VariantID: 7, StorageID: 2, Quantity: 3}
+	ri.MockRepository.Product.EXPECT().LoadStorage(ts, 2).Return(stock.Stock{StorageID: 2}, nil)
+	ri.MockRepository.Product.EXPECT().CheckProductInStock(ts, gomock.Any()).Return(true, nil)
+	ri.MockRepository.Reservation.EXPECT().FindReservedQuantity(ts, 7, 2).Return(5, nil)
+
+	_, err := ui.Usecase.Product.AddProductInStock(ts, p)
+	r.Equal(stock.ErrBelowReserved, err)
+}
+
+func TestOverwriteStockConsumesCost(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	ri.MockRepository.Outbox.EXPECT().SaveEvent(ts, gomock.Any()).Return(int64(1), nil).AnyTimes()
+	ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(ts, gomock.Any()).Return(nil).AnyTimes()
+	ts.EXPECT().OnCommit(gomock.Any()).AnyTimes()
+
+	ri.MockRepository.Product.EXPECT().LoadStorage(ts, 2).Return(stock.Stock{StorageID: 2}, nil)
+	ri.MockRepository.Product.EXPECT().CheckProductInStock(ts, gomock.Any()).Return(true, nil)
+	ri.MockRepository.Reservation.EXPECT().FindReservedQuantity(ts, 7, 2).Return(0, nil)
+	ri.MockRepository.Reservation.EXPECT().LoadStockQuantity(ts, 7, 2).Return(10, nil)
+	ri.MockRepository.Product.EXPECT().UpdateProductInstock(ts, gomock.Any()).Return(4, nil)
+	ri.MockRepository.Location.EXPECT().TrimLocationStock(ts, 7, 2, 6).Return(nil)
+	ri.MockRepository.Stock.EXPECT().TrimBatches(ts, 7, 2, 6).Return(nil)
+
+	// разница между прежним и новым остатком списывается из себестоимости по FIFO
+	ri.MockRepository.Valuation.EXPECT().LoadAverageCost(ts, 7, 2).Return(valuation.AverageCost{VariantID: 7, StorageID: 2, Quantity: 8, AverageCost: 11}, nil)
+	ri.MockRepository.Valuation.EXPECT().ConsumeAverageCost(ts, 7, 2, 4).Return(nil)
+	ri.MockRepository.Valuation.EXPECT().LoadCostLayerList(ts, 7, 2).Return([]valuation.CostLayer{
+		{LayerID: 1, VariantID: 7, StorageID: 2, Quantity: 5, Remaining: 3, UnitCost: 10},
+		{LayerID: 2, VariantID: 7, StorageID: 2, Quantity: 5, Remaining: 5, UnitCost: 12},
+	}, nil)
+	ri.MockRepository.Valuation.EXPECT().ConsumeCostLayer(ts, 1, 3).Return(nil)
+	ri.MockRepository.Valuation.EXPECT().ConsumeCostLayer(ts, 2, 1).Return(nil)
+
+	productStockID, err := ui.Usecase.Product.AddProductInStock(ts, stock.ProductInStockParams{VariantID: 7, StorageID: 2, Quantity: 6})
+	r.NoError(err)
+	r.Equal(4, productStockID)
+}
+
+func TestOverwriteStockBelowBatches(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	ri.MockRepository.Outbox.EXPECT().SaveEvent(ts, gomock.Any()).Return(int64(1), nil).AnyTimes()
+	ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(ts, gomock.Any()).Return(nil).AnyTimes()
+	ts.EXPECT().OnCommit(gomock.Any()).AnyTimes()
+
+	// остаток уменьшается ниже суммы партий, партии уменьшаются до остатка в порядке FEFO
+	ri.MockRepository.Product.EXPECT().LoadStorage(ts, 1).Return(stock.Stock{StorageID: 1}, nil)
+	ri.MockRepository.Product.EXPECT().CheckProductInStock(ts, gomock.Any()).Return(true, nil)
+	ri.MockRepository.Reservation.EXPECT().FindReservedQuantity(ts, 1, 1).Return(0, nil)
+	ri.MockRepository.Reservation.EXPECT().LoadStockQuantity(ts, 1, 1).Return(3, nil)
+	ri.MockRepository.Product.EXPECT().UpdateProductInstock(ts, gomock.Any()).Return(4, nil)
+	ri.MockRepository.Location.EXPECT().TrimLocationStock(ts, 1, 1, 3).Return(nil)
+	ri.MockRepository.Stock.EXPECT().TrimBatches(ts, 1, 1, 3).Return(nil)
+
+	_, err := ui.Usecase.Product.AddProductInStock(ts, stock.ProductInStockParams{VariantID: 1, StorageID: 1, Quantity: 3})
+	r.NoError(err)
+
+	// продажа расходует оставшиеся партии
+	ri.MockRepository.Product.EXPECT().LoadStorage(ts, 1).Return(stock.Stock{StorageID: 1}, nil)
+	ri.MockRepository.Product.EXPECT().FindPrice(ts, 1).Return(10.0, nil)
+	ri.MockRepository.Promotion.EXPECT().FindApplicablePromotionList(ts, 1, 1, gomock.Any()).Return(nil, global.ErrNoData)
+	ri.MockRepository.Reservation.EXPECT().FindReservedQuantity(ts, 1, 1).Return(0, nil)
+	ri.MockRepository.Stock.EXPECT().DecreaseProductInStock(ts, 1, 1, 2).Return(1, nil)
+	ri.MockRepository.Stock.EXPECT().FindThreshold(ts, 1, 1).Return(stock.ThresholdParams{}, global.ErrNoData)
+	ri.MockRepository.Valuation.EXPECT().LoadAverageCost(ts, 1, 1).Return(valuation.AverageCost{}, global.ErrNoData)
+	ri.MockRepository.Valuation.EXPECT().LoadCostLayerList(ts, 1, 1).Return(nil, global.ErrNoData)
+	ri.MockRepository.Location.EXPECT().TrimLocationStock(ts, 1, 1, 1).Return(nil)
+	ri.MockRepository.Stock.EXPECT().LoadBatchList(ts, 1, 1).Return([]stock.Batch{
+		{BatchID: 1, Quantity: 1, BatchParams: stock.BatchParams{ExpiresAt: sqlnull.NewNullTime(time.Now().Add(24 * time.Hour))}},
+		{BatchID: 2, Quantity: 2},
+	}, nil)
+	ri.MockRepository.Stock.EXPECT().ConsumeBatch(ts, 1, 1).Return(nil)
+	ri.MockRepository.Stock.EXPECT().ConsumeBatch(ts, 2, 1).Return(nil)
+	ri.MockRepository.Payment.EXPECT().AddPayment(ts, gomock.Any(), gomock.Any()).Return(1, nil)
+	ri.MockRepository.Product.EXPECT().SaveSale(ts, gomock.Any()).Return(9, nil)
+
+	saleID, err := ui.Usecase.Product.SaveSale(ts, product.SaleParams{VariantID: 1, StorageID: 1, Quantity: 2})
+	r.NoError(err)
+	r.Equal(9, saleID)
+}
+
+func TestAddProductInStockBatch(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	// партия, поступившая на склад с имеющимся остатком, добавляется к нему, а не заменяет его
+	batch := &stock.BatchParams{BatchNumber: "A-1"}
+	p := stock.ProductInStockParams{VariantID: 7, StorageID: 2, Quantity: 5, Batch: batch}
+	ri.MockRepository.Product.EXPECT().LoadStorage(ts, 2).Return(stock.Stock{StorageID: 2}, nil)
+	ri.MockRepository.Product.EXPECT().CheckProductInStock(ts, gomock.Any()).Return(true, nil)
+	ri.MockRepository.Product.EXPECT().IncreaseProductInstock(ts, gomock.Any()).Return(4, 15, nil)
+	ri.MockRepository.Outbox.EXPECT().SaveEvent(ts, gomock.Any()).
+		DoAndReturn(func(_ transaction.Session, e event.Event) (int64, error) {
+			r.Contains(string(e.Payload), `"quantity":15`)
+			return 1, nil
+		})
+	ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(ts, gomock.Any()).Return(nil)
+	ts.EXPECT().OnCommit(gomock.Any())
+	ri.MockRepository.Stock.EXPECT().AddBatch(ts, stock.Batch{VariantID: 7, StorageID: 2, Quantity: 5, BatchParams: *batch}).Return(3, nil)
+
+	productStockID, err := ui.Usecase.Product.AddProductInStock(ts, p)
+	r.NoError(err)
+	r.Equal(4, productStockID)
+}
+
+func TestAddProductInStockUnitCost(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	// слой себестоимости соответствует только поступившему кол-ву, поэтому оно добавляется к остатку
+	p := stock.ProductInStockParams{VariantID: 7, StorageID: 2, Quantity: 5, UnitCost: 12.5}
+	ri.MockRepository.Product.EXPECT().LoadStorage(ts, 2).Return(stock.Stock{StorageID: 2}, nil)
+	ri.MockRepository.Product.EXPECT().CheckProductInStock(ts, gomock.Any()).Return(true, nil)
+	ri.MockRepository.Product.EXPECT().IncreaseProductInstock(ts, gomock.Any()).Return(4, 15, nil)
+	ri.MockRepository.Outbox.EXPECT().SaveEvent(ts, gomock.Any()).Return(int64(1), nil)
+	ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(ts, gomock.Any()).Return(nil)
+	ts.EXPECT().OnCommit(gomock.Any())
+	ri.MockRepository.Valuation.EXPECT().AddCostLayer(ts, valuation.CostLayer{VariantID: 7, StorageID: 2, Quantity: 5, UnitCost: 12.5}).Return(nil)
+	ri.MockRepository.Valuation.EXPECT().AddAverageCost(ts, 7, 2, 5, 12.5).Return(nil)
+
+	_, err := ui.Usecase.Product.AddProductInStock(ts, p)
+	r.NoError(err)
+}
+
+func TestLowStockAlert(t *testing.T) {
+	r := require.New(t)
+
+	threshold := stock.ThresholdParams{VariantID: 1, StorageID: 1, MinQuantity: 5, MaxQuantity: 20}
+
+	tests := []struct {
+		name          string
+		remaining     int
+		quantity      int
+		expectedAlert bool
+	}{
+		{
+			name:          "остаток пересек минимальный порог",
+			remaining:     4,
+			quantity:      2,
+			expectedAlert: true,
+		},
+		{
+			name:      "остаток выше минимального",
+			remaining: 5,
+			quantity:  2,
+		},
+		{
+			name:      "остаток уже был ниже минимального",
+			remaining: 2,
+			quantity:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ri := rimport.NewTestRepositoryImports(ctrl)
+			ts := transaction.NewMockSession(ctrl)
+
+			ri.MockRepository.Stock.EXPECT().DecreaseProductInStock(ts, 1, 1, tt.quantity).Return(tt.remaining, nil)
+			ri.MockRepository.Stock.EXPECT().FindThreshold(ts, 1, 1).Return(threshold, nil)
+			ri.MockRepository.Stock.EXPECT().LoadBatchList(ts, 1, 1).Return(nil, global.ErrNoData)
+			ri.MockRepository.Valuation.EXPECT().LoadAverageCost(ts, 1, 1).Return(valuation.AverageCost{}, global.ErrNoData)
+			ri.MockRepository.Valuation.EXPECT().LoadCostLayerList(ts, 1, 1).Return(nil, global.ErrNoData)
+			ri.MockRepository.Location.EXPECT().TrimLocationStock(ts, 1, 1, tt.remaining).Return(nil)
+			ri.MockRepository.Stock.EXPECT().SaveWriteOff(ts, gomock.Any()).Return(3, nil)
+			ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(ts, gomock.Any()).Return(nil).AnyTimes()
+
+			var alertEvent *event.Event
+			ri.MockRepository.Outbox.EXPECT().SaveEvent(ts, gomock.Any()).
+				DoAndReturn(func(_ transaction.Session, e event.Event) (int64, error) {
+					if e.EventType == event.LowStock {
+						alertEvent = &e
+					}
+					return 1, nil
+				}).AnyTimes()
+
+			var afterCommit []func()
+			ts.EXPECT().OnCommit(gomock.Any()).Do(func(f func()) { afterCommit = append(afterCommit, f) }).AnyTimes()
+
+			notifier := bridge.NewMockLowStockNotifier(ctrl)
+			notified := make(chan stock.LowStockAlert, 1)
+			if tt.expectedAlert {
+				notifier.EXPECT().NotifyLowStock(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, alert stock.LowStockAlert) error {
+						notified <- alert
+						return nil
+					})
+			}
+
+			ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), transaction.NewMockSessionManager(ctrl))
+			ui.Usecase.Product.SetLowStockNotifier(notifier)
+
+			_, err := ui.Usecase.Product.WriteOff(ts, stock.WriteOffParams{VariantID: 1, StorageID: 1, Quantity: tt.quantity, Reason: "брак"})
+			r.NoError(err)
+
+			for _, f := range afterCommit {
+				f()
+			}
+
+			if !tt.expectedAlert {
+				r.Nil(alertEvent)
+				return
+			}
+
+			r.NotNil(alertEvent)
+			alert := <-notified
+			r.Equal(tt.remaining, alert.Quantity)
+			r.Equal(20-tt.remaining, alert.ReorderQuantity)
+		})
+	}
+}
+
+func TestSaveSaleFEFO(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Now()
+	day := 24 * time.Hour
+
+	batchList := []stock.Batch{
+		{BatchID: 1, Quantity: 3, BatchParams: stock.BatchParams{ExpiresAt: sqlnull.NewNullTime(now.Add(-2 * day))}},
+		{BatchID: 2, Quantity: 1, BatchParams: stock.BatchParams{ExpiresAt: sqlnull.NewNullTime(now.Add(3 * day))}},
+		{BatchID: 3, Quantity: 5, BatchParams: stock.BatchParams{ExpiresAt: sqlnull.NewNullTime(now.Add(30 * day))}},
+		{BatchID: 4, Quantity: 2},
+	}
+
+	tests := []struct {
+		name      string
+		quantity  int
+		remaining int
+		consumed  map[int]int
+		err       error
+	}{
+		{
+			name:      "первыми расходуются партии с ближайшим сроком",
+			quantity:  4,
+			remaining: 8,
+			consumed:  map[int]int{2: 1, 3: 3},
+		},
+		{
+			name:      "недостающее кол-во берется из продукта без партии",
+			quantity:  9,
+			remaining: 3,
+			consumed:  map[int]int{2: 1, 3: 5, 4: 2},
+		},
+		{
+			name:      "в партиях больше продукта, чем на складе",
+			quantity:  4,
+			remaining: 0,
+			consumed:  map[int]int{2: 1, 3: 3},
+		},
+		{
+			name:      "остался только продукт с истекшим сроком",
+			quantity:  10,
+			remaining: 2,
+			err:       stock.ErrNotEnoughFreshStock,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ri := rimport.NewTestRepositoryImports(ctrl)
+			ts := ri.MockSession()
+
+			ri.MockRepository.Product.EXPECT().LoadStorage(ts, 1).Return(stock.Stock{StorageID: 1}, nil)
+			ri.MockRepository.Product.EXPECT().FindPrice(ts, 1).Return(10.0, nil)
+			ri.MockRepository.Promotion.EXPECT().FindApplicablePromotionList(ts, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, global.ErrNoData)
+			ri.MockRepository.Reservation.EXPECT().FindReservedQuantity(ts, 1, 1).Return(0, nil)
+			ri.MockRepository.Stock.EXPECT().DecreaseProductInStock(ts, 1, 1, tt.quantity).Return(tt.remaining, nil)
+			ri.MockRepository.Stock.EXPECT().FindThreshold(ts, 1, 1).Return(stock.ThresholdParams{}, global.ErrNoData)
+			ri.MockRepository.Stock.EXPECT().LoadBatchList(ts, 1, 1).Return(batchList, nil)
+			ri.MockRepository.Valuation.EXPECT().LoadAverageCost(ts, 1, 1).Return(valuation.AverageCost{}, global.ErrNoData)
+			ri.MockRepository.Valuation.EXPECT().LoadCostLayerList(ts, 1, 1).Return(nil, global.ErrNoData)
+			ri.MockRepository.Location.EXPECT().TrimLocationStock(ts, 1, 1, tt.remaining).Return(nil)
+			ri.MockRepository.Outbox.EXPECT().SaveEvent(ts, gomock.Any()).Return(int64(1), nil).AnyTimes()
+			ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(ts, gomock.Any()).Return(nil).AnyTimes()
+			ts.EXPECT().OnCommit(gomock.Any()).AnyTimes()
+
+			consumed := make(map[int]int)
+			ri.MockRepository.Stock.EXPECT().ConsumeBatch(ts, gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ transaction.Session, batchID, quantity int) error {
+					consumed[batchID] += quantity
+					return nil
+				}).AnyTimes()
+
+			if tt.err == nil {
+				ri.MockRepository.Payment.EXPECT().AddPayment(ts, gomock.Any(), gomock.Any()).Return(1, nil)
+				ri.MockRepository.Product.EXPECT().SaveSale(ts, gomock.Any()).Return(1, nil)
+			}
+
+			ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+			_, err := ui.Usecase.Product.SaveSale(ts, product.SaleParams{VariantID: 1, StorageID: 1, Quantity: tt.quantity})
+			r.Equal(tt.err, err)
+			if tt.err == nil {
+				r.Equal(tt.consumed, consumed)
+			}
+		})
+	}
+}
+
+func TestSaleCostOfGoods(t *testing.T) {
+	r := require.New(t)
+
+	// 2 шт по 10 и 5 шт по 16, средняя цена (2*10 + 5*16) / 7
+	layerList := []valuation.CostLayer{
+		{LayerID: 1, Quantity: 2, Remaining: 2, UnitCost: 10},
+		{LayerID: 2, Quantity: 5, Remaining: 5, UnitCost: 16},
+	}
+	average := valuation.AverageCost{VariantID: 1, StorageID: 1, Quantity: 7, AverageCost: 100.0 / 7}
+
+	tests := []struct {
+		name         string
+		method       string
+		quantity     int
+		expectedCost float64
+	}{
+		{
+			name:         "FIFO: первыми списываются ранние поступления",
+			method:       config.CostingFIFO,
+			quantity:     3,
+			expectedCost: 36,
+		},
+		{
+			name:         "FIFO: продукт без себестоимости оценивается по средней цене",
+			method:       config.CostingFIFO,
+			quantity:     8,
+			expectedCost: 114.29,
+		},
+		{
+			name:         "средневзвешенная себестоимость",
+			method:       config.CostingAverage,
+			quantity:     3,
+			expectedCost: 42.86,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ri := rimport.NewTestRepositoryImports(ctrl)
+			ri.Config.Costing.Method = tt.method
+			ts := ri.MockSession()
+
+			ri.MockRepository.Product.EXPECT().LoadStorage(ts, 1).Return(stock.Stock{StorageID: 1}, nil)
+			ri.MockRepository.Product.EXPECT().FindPrice(ts, 1).Return(20.0, nil)
+			ri.MockRepository.Promotion.EXPECT().FindApplicablePromotionList(ts, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, global.ErrNoData)
+			ri.MockRepository.Reservation.EXPECT().FindReservedQuantity(ts, 1, 1).Return(0, nil)
+			ri.MockRepository.Stock.EXPECT().DecreaseProductInStock(ts, 1, 1, tt.quantity).Return(10, nil)
+			ri.MockRepository.Stock.EXPECT().FindThreshold(ts, 1, 1).Return(stock.ThresholdParams{}, global.ErrNoData)
+			ri.MockRepository.Stock.EXPECT().LoadBatchList(ts, 1, 1).Return(nil, global.ErrNoData)
+			ri.MockRepository.Valuation.EXPECT().LoadAverageCost(ts, 1, 1).Return(average, nil)
+			ri.MockRepository.Valuation.EXPECT().ConsumeAverageCost(ts, 1, 1, tt.quantity).Return(nil)
+			ri.MockRepository.Valuation.EXPECT().LoadCostLayerList(ts, 1, 1).Return(layerList, nil)
+			ri.MockRepository.Location.EXPECT().TrimLocationStock(ts, 1, 1, 10).Return(nil)
+			ri.MockRepository.Valuation.EXPECT().ConsumeCostLayer(ts, gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			ri.MockRepository.Outbox.EXPECT().SaveEvent(ts, gomock.Any()).Return(int64(1), nil).AnyTimes()
+			ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(ts, gomock.Any()).Return(nil).AnyTimes()
+			ts.EXPECT().OnCommit(gomock.Any()).AnyTimes()
+
+			var saved product.SaleParams
+			ri.MockRepository.Payment.EXPECT().AddPayment(ts, gomock.Any(), gomock.Any()).Return(1, nil)
+			ri.MockRepository.Product.EXPECT().SaveSale(ts, gomock.Any()).
+				DoAndReturn(func(_ transaction.Session, s product.SaleParams) (int, error) {
+					saved = s
+					return 1, nil
+				})
+
+			ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+			_, err := ui.Usecase.Product.SaveSale(ts, product.SaleParams{VariantID: 1, StorageID: 1, Quantity: tt.quantity})
+			r.NoError(err)
+			r.Equal(tt.expectedCost, saved.CostOfGoods)
+		})
+	}
+}
+
+func TestDeleteStock(t *testing.T) {
+	r := require.New(t)
+
+	tests := []struct {
+		name    string
+		storage stock.Stock
+		err     error
+	}{
+		{
+			name:    "пустой склад удаляется",
+			storage: stock.Stock{StorageID: 2},
+		},
+		{
+			name:    "склад с продуктом не удаляется",
+			storage: stock.Stock{StorageID: 2, Occupied: 5},
+			err:     stock.ErrStorageNotEmpty,
+		},
+		{
+			name:    "склад уже удален",
+			storage: stock.Stock{StorageID: 2, RemovedAt: sqlnull.NewNullTime(time.Now())},
+			err:     stock.ErrStorageRemoved,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ri := rimport.NewTestRepositoryImports(ctrl)
+			ts := ri.MockSession()
+
+			ri.MockRepository.Product.EXPECT().LoadStorage(ts, 2).Return(tt.storage, nil)
+			if tt.err == nil {
+				ri.MockRepository.Product.EXPECT().DeleteStock(ts, 2).Return(nil)
+				ts.EXPECT().OnCommit(gomock.Any())
+			}
+
+			ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+			err := ui.Usecase.Product.DeleteStock(ts, stock.StockParams{StorageID: 2})
+			r.Equal(tt.err, err)
+		})
+	}
+}
+
+func TestFindVariantByBarcode(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	found := product.BarcodeVariant{ProductName: "Чай", Variant: product.Variant{ProductID: 1, VariantID: 10}}
+	storageList := []product.VarStorage{{StorageID: 1, Quantity: 5, Reserved: 2, Available: 3}}
+
+	ri.MockRepository.Product.EXPECT().FindVariantByBarcode(ts, "4006381333931").Return(found, nil)
+	ri.MockRepository.Product.EXPECT().FindCurrentPrice(ts, 10).Return(2.99, nil)
+	ri.MockRepository.Product.EXPECT().InStorages(ts, 10).Return(storageList, nil)
+	ri.MockRepository.Product.EXPECT().FindVariantBarcodeList(ts, 10).Return([]string{"4006381333931"}, nil)
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	v, err := ui.Usecase.Product.FindVariantByBarcode(ts, "4006381333931")
+	r.NoError(err)
+	r.Equal("Чай", v.ProductName)
+	r.Equal(2.99, v.CurrentPrice)
+	r.Equal(3, v.InStorages[0].Available)
+
+	// штрихкод с неверной длиной отклоняется без обращения к базе
+	_, err = ui.Usecase.Product.FindVariantByBarcode(ts, "400638133393")
+	r.Equal(product.ErrInvalidBarcode, err)
+}
@@ -0,0 +1,183 @@
+package test
+
+import (
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/product"
+	"product_storage/internal/entity/promotion"
+	"product_storage/internal/entity/stock"
+	"product_storage/internal/entity/valuation"
+	"product_storage/rimport"
+	"product_storage/tools/logger"
+	"product_storage/tools/sqlnull"
+	"product_storage/uimport"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testLogger = logger.NewNoFileLogger("test")
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		name          string
+		quantity      int
+		promotionList []promotion.Promotion
+		total         float64
+		discount      float64
+		promotionID   int
+	}{
+		{
+			name:     "без акций",
+			quantity: 3,
+			total:    300,
+		},
+		{
+			name:     "применяется наибольшая скидка",
+			quantity: 3,
+			promotionList: []promotion.Promotion{
+				{PromotionID: 1, Kind: promotion.KindPercent, Value: 10},
+				{PromotionID: 2, Kind: promotion.KindFixed, Value: 15},
+			},
+			total:       255,
+			discount:    45,
+			promotionID: 2,
+		},
+		{
+			name:     "не набрана минимальная сумма",
+			quantity: 3,
+			promotionList: []promotion.Promotion{
+				{PromotionID: 1, Kind: promotion.KindPercent, Value: 10, MinAmount: 500},
+			},
+			total: 300,
+		},
+		{
+			name:     "два по цене одного",
+			quantity: 5,
+			promotionList: []promotion.Promotion{
+				{PromotionID: 3, Kind: promotion.KindBuyXGetY, BuyQuantity: 1, FreeQuantity: 1},
+			},
+			total:       300,
+			discount:    200,
+			promotionID: 3,
+		},
+		{
+			name:     "фиксированная скидка не больше цены",
+			quantity: 2,
+			promotionList: []promotion.Promotion{
+				{PromotionID: 4, Kind: promotion.KindFixed, Value: 150},
+			},
+			total:       0,
+			discount:    200,
+			promotionID: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ri := rimport.NewTestRepositoryImports(ctrl)
+			ts := ri.MockSession()
+
+			ri.MockRepository.Product.EXPECT().FindPrice(ts, 1).Return(100.0, nil)
+			if tt.promotionList != nil {
+				ri.MockRepository.Promotion.EXPECT().FindApplicablePromotionList(ts, 1, 2, gomock.Any()).Return(tt.promotionList, nil)
+			} else {
+				ri.MockRepository.Promotion.EXPECT().FindApplicablePromotionList(ts, 1, 2, gomock.Any()).Return(nil, global.ErrNoData)
+			}
+
+			ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+			quote, err := ui.Usecase.Promotion.Quote(ts, product.SaleParams{VariantID: 1, StorageID: 2, Quantity: tt.quantity})
+			r.NoError(err)
+			r.Equal(100*float64(tt.quantity), quote.Gross)
+			r.Equal(tt.total, quote.Total)
+			r.Equal(tt.discount, quote.Discount)
+			if tt.promotionID > 0 {
+				r.Equal(sqlnull.NewInt64(tt.promotionID), quote.PromotionID)
+			} else {
+				r.False(quote.PromotionID.Valid)
+			}
+		})
+	}
+}
+
+func TestSaveSaleWithPromotion(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	ri.MockRepository.Product.EXPECT().LoadStorage(ts, 1).Return(stock.Stock{StorageID: 1}, nil)
+	ri.MockRepository.Product.EXPECT().FindPrice(ts, 1).Return(50.0, nil)
+	ri.MockRepository.Promotion.EXPECT().FindApplicablePromotionList(ts, 1, 1, gomock.Any()).
+		Return([]promotion.Promotion{{PromotionID: 7, Name: "Скидка 20%", Kind: promotion.KindPercent, Value: 20}}, nil)
+	ri.MockRepository.Reservation.EXPECT().FindReservedQuantity(ts, 1, 1).Return(0, nil)
+	ri.MockRepository.Stock.EXPECT().DecreaseProductInStock(ts, 1, 1, 4).Return(6, nil)
+	ri.MockRepository.Stock.EXPECT().FindThreshold(ts, 1, 1).Return(stock.ThresholdParams{}, global.ErrNoData)
+	ri.MockRepository.Stock.EXPECT().LoadBatchList(ts, 1, 1).Return(nil, global.ErrNoData)
+	ri.MockRepository.Valuation.EXPECT().LoadAverageCost(ts, 1, 1).Return(valuation.AverageCost{}, global.ErrNoData)
+	ri.MockRepository.Valuation.EXPECT().LoadCostLayerList(ts, 1, 1).Return(nil, global.ErrNoData)
+	ri.MockRepository.Location.EXPECT().TrimLocationStock(ts, 1, 1, 6).Return(nil)
+	ri.MockRepository.Outbox.EXPECT().SaveEvent(ts, gomock.Any()).Return(int64(1), nil).AnyTimes()
+	ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(ts, gomock.Any()).Return(nil).AnyTimes()
+	ts.EXPECT().OnCommit(gomock.Any()).AnyTimes()
+
+	// скидка вычитается из суммы продажи, акция записывается вместе с продажей
+	var saved product.SaleParams
+	ri.MockRepository.Payment.EXPECT().AddPayment(ts, gomock.Any(), gomock.Any()).Return(1, nil)
+	ri.MockRepository.Product.EXPECT().SaveSale(ts, gomock.Any()).
+		DoAndReturn(func(_ interface{}, s product.SaleParams) (int, error) {
+			saved = s
+			return 11, nil
+		})
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	saleID, err := ui.Usecase.Product.SaveSale(ts, product.SaleParams{VariantID: 1, StorageID: 1, Quantity: 4})
+	r.NoError(err)
+	r.Equal(11, saleID)
+	r.Equal(160.0, saved.TotalPrice)
+	r.Equal(40.0, saved.Discount)
+	r.Equal(sqlnull.NewInt64(7), saved.PromotionID)
+}
+
+func TestAddPromotion(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	// акция действует только на одно из: вариант, тег или категорию
+	_, err := ui.Usecase.Promotion.AddPromotion(ts, promotion.Params{Name: "Акция", Kind: promotion.KindPercent, Value: 10, VariantID: 1, Tag: "молоко"})
+	r.Error(err)
+
+	_, err = ui.Usecase.Promotion.AddPromotion(ts, promotion.Params{Name: "Акция", Kind: promotion.KindPercent, Value: 100})
+	r.Error(err)
+
+	ri.MockRepository.Product.EXPECT().FindPrice(ts, 1).Return(0.0, global.ErrNoData)
+	_, err = ui.Usecase.Promotion.AddPromotion(ts, promotion.Params{Name: "Акция", Kind: promotion.KindFixed, Value: 10, VariantID: 1})
+	r.Error(err)
+
+	ri.MockRepository.Promotion.EXPECT().AddPromotion(ts, gomock.Any()).
+		DoAndReturn(func(_ interface{}, p promotion.Promotion) (int, error) {
+			r.Equal(sqlnull.NewString("молоко"), p.Tag)
+			r.False(p.VariantID.Valid)
+			r.False(p.StartsAt.IsZero())
+			return 3, nil
+		})
+	promotionID, err := ui.Usecase.Promotion.AddPromotion(ts, promotion.Params{Name: "Акция", Kind: promotion.KindBuyXGetY, BuyQuantity: 2, FreeQuantity: 1, Tag: "молоко"})
+	r.NoError(err)
+	r.Equal(3, promotionID)
+}
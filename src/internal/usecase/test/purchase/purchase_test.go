@@ -0,0 +1,233 @@
+package test
+
+import (
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/purchase"
+	"product_storage/internal/entity/stock"
+	"product_storage/internal/entity/valuation"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/logger"
+	"product_storage/tools/sqlnull"
+	"product_storage/uimport"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testLogger = logger.NewNoFileLogger("test")
+)
+
+func TestAddPurchaseOrder(t *testing.T) {
+	r := require.New(t)
+
+	params := purchase.OrderParams{
+		SupplierID: 1,
+		StorageID:  2,
+		Lines: []purchase.OrderLineParams{
+			{VariantID: 3, Quantity: 10, PurchasePrice: 50},
+			{VariantID: 5, Quantity: 6, PurchasePrice: 80},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		prepare    func(ri rimport.TestRepositoryImports, ts *transaction.MockSession)
+		expectedID int
+		hasErr     bool
+	}{
+		{
+			name: "успешный результат",
+			prepare: func(ri rimport.TestRepositoryImports, ts *transaction.MockSession) {
+				ri.MockRepository.Purchase.EXPECT().LoadSupplier(ts, 1).Return(purchase.Supplier{SupplierID: 1}, nil)
+				ri.MockRepository.Product.EXPECT().LoadStorage(ts, 2).Return(stock.Stock{StorageID: 2}, nil)
+				ri.MockRepository.Product.EXPECT().FindVariantIDListByIDList(ts, []int{3, 5}).Return([]int{3, 5}, nil)
+				ri.MockRepository.Purchase.EXPECT().AddPurchaseOrder(ts, params).Return(7, nil)
+				ri.MockRepository.Purchase.EXPECT().AddPurchaseOrderLine(ts, 7, params.Lines[0]).Return(11, nil)
+				ri.MockRepository.Purchase.EXPECT().AddPurchaseOrderLine(ts, 7, params.Lines[1]).Return(12, nil)
+			},
+			expectedID: 7,
+		},
+		{
+			name: "поставщик не найден",
+			prepare: func(ri rimport.TestRepositoryImports, ts *transaction.MockSession) {
+				ri.MockRepository.Purchase.EXPECT().LoadSupplier(ts, 1).Return(purchase.Supplier{}, global.ErrNoData)
+			},
+			hasErr: true,
+		},
+		{
+			name: "склад удален",
+			prepare: func(ri rimport.TestRepositoryImports, ts *transaction.MockSession) {
+				ri.MockRepository.Purchase.EXPECT().LoadSupplier(ts, 1).Return(purchase.Supplier{SupplierID: 1}, nil)
+				ri.MockRepository.Product.EXPECT().LoadStorage(ts, 2).
+					Return(stock.Stock{StorageID: 2, RemovedAt: sqlnull.NewNullTime(time.Now())}, nil)
+			},
+			hasErr: true,
+		},
+		{
+			name: "вариант строки не найден",
+			prepare: func(ri rimport.TestRepositoryImports, ts *transaction.MockSession) {
+				ri.MockRepository.Purchase.EXPECT().LoadSupplier(ts, 1).Return(purchase.Supplier{SupplierID: 1}, nil)
+				ri.MockRepository.Product.EXPECT().LoadStorage(ts, 2).Return(stock.Stock{StorageID: 2}, nil)
+				ri.MockRepository.Product.EXPECT().FindVariantIDListByIDList(ts, []int{3, 5}).Return([]int{3}, nil)
+			},
+			hasErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ri := rimport.NewTestRepositoryImports(ctrl)
+			ts := ri.MockSession()
+
+			tt.prepare(ri, ts)
+
+			ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+			// при неверных поставщике, складе или вариантах заказ не создается, ошибка возвращается клиенту
+			id, err := ui.Usecase.Purchase.AddPurchaseOrder(ts, params)
+			if tt.hasErr {
+				r.Error(err)
+				r.NotEqual(global.ErrInternalError, err)
+			} else {
+				r.NoError(err)
+			}
+			r.Equal(tt.expectedID, id)
+		})
+	}
+}
+
+func TestReceiveGoods(t *testing.T) {
+	r := require.New(t)
+
+	order := purchase.Order{OrderID: 7, SupplierID: 1, StorageID: 2, Status: purchase.StatusSent}
+	lines := []purchase.OrderLine{
+		{LineID: 11, OrderID: 7, OrderLineParams: purchase.OrderLineParams{VariantID: 3, Quantity: 10, PurchasePrice: 50}},
+		{LineID: 12, OrderID: 7, ReceivedQuantity: 4, OrderLineParams: purchase.OrderLineParams{VariantID: 5, Quantity: 6, PurchasePrice: 80}},
+	}
+
+	// expectReceive ожидания приема строки: учет по заказу, увеличение остатка и событие склада
+	expectReceive := func(ri rimport.TestRepositoryImports, ts *transaction.MockSession, l purchase.OrderLine, qty, total int) {
+		ri.MockRepository.Purchase.EXPECT().AddGoodsReceiptLine(ts, purchase.ReceiptLine{
+			ReceiptID: 100, LineID: l.LineID, VariantID: l.VariantID, Quantity: qty, PurchasePrice: l.PurchasePrice,
+		}).Return(nil)
+		ri.MockRepository.Purchase.EXPECT().ReceivePurchaseOrderLine(ts, l.LineID, qty).Return(nil)
+		ri.MockRepository.Stock.EXPECT().IncreaseProductInStock(ts, l.VariantID, order.StorageID, qty).Return(total, nil)
+		ri.MockRepository.Valuation.EXPECT().AddCostLayer(ts, valuation.CostLayer{
+			VariantID: l.VariantID, StorageID: order.StorageID, Quantity: qty, UnitCost: l.PurchasePrice, ReceiptID: 100,
+		}).Return(nil)
+		ri.MockRepository.Valuation.EXPECT().AddAverageCost(ts, l.VariantID, order.StorageID, qty, l.PurchasePrice).Return(nil)
+		ri.MockRepository.Outbox.EXPECT().SaveEvent(ts, gomock.Any()).Return(int64(1), nil)
+		ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(ts, gomock.Any()).Return(nil)
+		ts.EXPECT().OnCommit(gomock.Any())
+	}
+
+	tests := []struct {
+		name       string
+		status     string
+		params     purchase.ReceiptParams
+		prepare    func(ri rimport.TestRepositoryImports, ts *transaction.MockSession)
+		expectedID int
+		hasErr     bool
+	}{
+		{
+			name:   "частичный прием",
+			status: purchase.StatusSent,
+			params: purchase.ReceiptParams{OrderID: 7, Lines: []purchase.ReceiptLineParams{{LineID: 11, Quantity: 4}}},
+			prepare: func(ri rimport.TestRepositoryImports, ts *transaction.MockSession) {
+				ri.MockRepository.Product.EXPECT().LoadStorage(ts, 2).Return(stock.Stock{StorageID: 2}, nil)
+				ri.MockRepository.Purchase.EXPECT().AddGoodsReceipt(ts, 7, 2).Return(100, nil)
+				expectReceive(ri, ts, lines[0], 4, 9)
+				ri.MockRepository.Purchase.EXPECT().UpdatePurchaseOrderStatus(ts, 7, purchase.StatusPartiallyReceived).Return(nil)
+			},
+			expectedID: 100,
+		},
+		{
+			name:   "прием всего остатка",
+			status: purchase.StatusPartiallyReceived,
+			params: purchase.ReceiptParams{OrderID: 7},
+			prepare: func(ri rimport.TestRepositoryImports, ts *transaction.MockSession) {
+				ri.MockRepository.Product.EXPECT().LoadStorage(ts, 2).Return(stock.Stock{StorageID: 2}, nil)
+				ri.MockRepository.Purchase.EXPECT().AddGoodsReceipt(ts, 7, 2).Return(100, nil)
+				expectReceive(ri, ts, lines[0], 10, 15)
+				expectReceive(ri, ts, lines[1], 2, 2)
+				ri.MockRepository.Purchase.EXPECT().UpdatePurchaseOrderStatus(ts, 7, purchase.StatusReceived).Return(nil)
+			},
+			expectedID: 100,
+		},
+		{
+			name:   "прием больше остатка",
+			status: purchase.StatusSent,
+			params: purchase.ReceiptParams{OrderID: 7, Lines: []purchase.ReceiptLineParams{{LineID: 12, Quantity: 3}}},
+			hasErr: true,
+		},
+		{
+			name:   "строка другого заказа",
+			status: purchase.StatusSent,
+			params: purchase.ReceiptParams{OrderID: 7, Lines: []purchase.ReceiptLineParams{{LineID: 99, Quantity: 1}}},
+			hasErr: true,
+		},
+		{
+			name:   "заказ не отправлен",
+			status: purchase.StatusDraft,
+			params: purchase.ReceiptParams{OrderID: 7},
+			hasErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ri := rimport.NewTestRepositoryImports(ctrl)
+			ts := ri.MockSession()
+
+			loaded := order
+			loaded.Status = tt.status
+			ri.MockRepository.Purchase.EXPECT().LoadPurchaseOrder(ts, 7).Return(loaded, nil)
+			ri.MockRepository.Purchase.EXPECT().FindPurchaseOrderLineList(ts, 7).Return(lines, nil)
+			if tt.prepare != nil {
+				tt.prepare(ri, ts)
+			}
+
+			ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+			id, err := ui.Usecase.Purchase.ReceiveGoods(ts, tt.params)
+			if tt.hasErr {
+				r.Error(err)
+			} else {
+				r.NoError(err)
+			}
+			r.Equal(tt.expectedID, id)
+		})
+	}
+}
+
+func TestChangePurchaseOrderStatus(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	ri.MockRepository.Purchase.EXPECT().LoadPurchaseOrder(ts, 1).Return(purchase.Order{OrderID: 1, Status: purchase.StatusDraft}, nil)
+	ri.MockRepository.Purchase.EXPECT().UpdatePurchaseOrderStatus(ts, 1, purchase.StatusSent).Return(nil)
+	ri.MockRepository.Purchase.EXPECT().LoadPurchaseOrder(ts, 2).Return(purchase.Order{OrderID: 2, Status: purchase.StatusPartiallyReceived}, nil)
+	ri.MockRepository.Purchase.EXPECT().LoadPurchaseOrder(ts, 3).Return(purchase.Order{}, global.ErrNoData)
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	r.NoError(ui.Usecase.Purchase.SendPurchaseOrder(ts, 1))
+	// частично принятый заказ отменить нельзя
+	r.Error(ui.Usecase.Purchase.CancelPurchaseOrder(ts, 2))
+	r.Error(ui.Usecase.Purchase.SendPurchaseOrder(ts, 3))
+}
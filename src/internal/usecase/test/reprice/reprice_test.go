@@ -0,0 +1,181 @@
+package test
+
+import (
+	"product_storage/internal/entity/event"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/reprice"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/logger"
+	"product_storage/tools/sqlnull"
+	"product_storage/uimport"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testLogger = logger.NewNoFileLogger("test")
+)
+
+// lineList варианты продуктов с тегом молоко
+var lineList = []reprice.Line{
+	{VariantID: 1, ProductID: 1, ProductName: "Молоко", OldPrice: 89.9},
+	{VariantID: 2, ProductID: 1, ProductName: "Молоко", OldPrice: 150},
+	{VariantID: 3, ProductID: 2, ProductName: "Кефир", OldPrice: 0},
+}
+
+func TestNewPrice(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   reprice.Params
+		old      float64
+		expected float64
+	}{
+		{
+			name:     "повышение на процент до копеек",
+			params:   reprice.Params{Operation: reprice.OpPercent, Value: 10},
+			old:      89.9,
+			expected: 98.89,
+		},
+		{
+			name:     "повышение на процент с окончанием .99",
+			params:   reprice.Params{Operation: reprice.OpPercent, Value: 10, Rounding: reprice.Rounding{Step: 1, Mode: reprice.RoundUp, Ending: 0.99}},
+			old:      89.9,
+			expected: 98.99,
+		},
+		{
+			name:     "снижение на сумму с округлением вниз до 10",
+			params:   reprice.Params{Operation: reprice.OpAmount, Value: -15, Rounding: reprice.Rounding{Step: 10, Mode: reprice.RoundDown}},
+			old:      150,
+			expected: 130,
+		},
+		{
+			name:     "цена, уже кратная шагу, не меняется округлением",
+			params:   reprice.Params{Operation: reprice.OpSet, Value: 1.1, Rounding: reprice.Rounding{Step: 0.1, Mode: reprice.RoundUp}},
+			old:      5,
+			expected: 1.1,
+		},
+		{
+			name:     "снижение ниже нуля",
+			params:   reprice.Params{Operation: reprice.OpAmount, Value: -100},
+			old:      89.9,
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.params.NewPrice(tt.old))
+		})
+	}
+}
+
+func TestPreview(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	params := reprice.Params{Tag: "молоко", Operation: reprice.OpSet, Value: 150}
+	ri.MockRepository.Reprice.EXPECT().FindLineList(ts, params, gomock.Any()).Return(lineList, nil)
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	preview, err := ui.Usecase.Reprice.Preview(ts, params)
+	r.NoError(err)
+	r.Len(preview.Lines, 1)
+	r.Equal(89.9, preview.Lines[0].OldPrice)
+	r.Equal(150.0, preview.Lines[0].NewPrice)
+	r.Len(preview.Unchanged, 1)
+	r.Equal([]int{3}, preview.NoPrice)
+	r.Empty(preview.Scheduled)
+
+	// у варианта с запланированной ценой новая цена действует только до ее начала
+	scheduled := []reprice.Line{
+		{VariantID: 1, ProductID: 1, ProductName: "Молоко", OldPrice: 89.9, ScheduledAt: sqlnull.NewNullTime(time.Now().Add(24 * time.Hour))},
+		{VariantID: 2, ProductID: 1, ProductName: "Молоко", OldPrice: 120},
+	}
+	ri.MockRepository.Reprice.EXPECT().FindLineList(ts, params, gomock.Any()).Return(scheduled, nil)
+
+	preview, err = ui.Usecase.Reprice.Preview(ts, params)
+	r.NoError(err)
+	r.Len(preview.Lines, 2)
+	r.Equal([]int{1}, preview.Scheduled)
+
+	// ровно один способ выбора вариантов
+	_, err = ui.Usecase.Reprice.Preview(ts, reprice.Params{Tag: "молоко", CategoryID: 1, Operation: reprice.OpSet, Value: 150})
+	r.Error(err)
+}
+
+func TestApply(t *testing.T) {
+	t.Run("все цены изменяются", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ri := rimport.NewTestRepositoryImports(ctrl)
+		ts := ri.MockSession()
+
+		params := reprice.Params{ProductIDList: []int{1, 2}, Operation: reprice.OpPercent, Value: -10}
+		ri.MockRepository.Reprice.EXPECT().FindLineList(ts, params, gomock.Any()).Return(lineList, nil)
+		ri.MockRepository.Reprice.EXPECT().ReplacePrice(ts, 1, 80.91, gomock.Any()).Return(11, nil)
+		ri.MockRepository.Reprice.EXPECT().ReplacePrice(ts, 2, 135.0, gomock.Any()).Return(12, nil)
+
+		var variantIDList []int
+		ri.MockRepository.Outbox.EXPECT().SaveEvent(ts, gomock.Any()).
+			DoAndReturn(func(_ transaction.Session, e event.Event) (int64, error) {
+				r.Equal(event.PriceChanged, e.EventType)
+				variantIDList = append(variantIDList, e.AggregateID)
+				return int64(len(variantIDList)), nil
+			}).Times(2)
+		ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(ts, gomock.Any()).Return(nil).Times(2)
+		ts.EXPECT().OnCommit(gomock.Any()).AnyTimes()
+
+		ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+		result, err := ui.Usecase.Reprice.Apply(ts, params)
+		r.NoError(err)
+		r.Len(result.Lines, 2)
+		r.Equal([]int{1, 2}, variantIDList)
+	})
+
+	t.Run("цена одного варианта не больше 0", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ri := rimport.NewTestRepositoryImports(ctrl)
+		ts := ri.MockSession()
+
+		// ни одна цена не изменяется
+		params := reprice.Params{Tag: "молоко", Operation: reprice.OpAmount, Value: -100}
+		ri.MockRepository.Reprice.EXPECT().FindLineList(ts, params, gomock.Any()).Return(lineList, nil)
+
+		ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+		_, err := ui.Usecase.Reprice.Apply(ts, params)
+		r.Error(err)
+	})
+
+	t.Run("нет вариантов", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ri := rimport.NewTestRepositoryImports(ctrl)
+		ts := ri.MockSession()
+
+		params := reprice.Params{Tag: "нет такого", Operation: reprice.OpAmount, Value: 10}
+		ri.MockRepository.Reprice.EXPECT().FindLineList(ts, params, gomock.Any()).Return(nil, global.ErrNoData)
+
+		ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+		_, err := ui.Usecase.Reprice.Apply(ts, params)
+		r.Equal(reprice.ErrNoVariants, err)
+	})
+}
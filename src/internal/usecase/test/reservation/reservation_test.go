@@ -0,0 +1,180 @@
+package test
+
+import (
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/product"
+	"product_storage/internal/entity/reservation"
+	"product_storage/internal/entity/stock"
+	"product_storage/internal/entity/valuation"
+	"product_storage/rimport"
+	"product_storage/tools/logger"
+	"product_storage/tools/sqlnull"
+	"product_storage/uimport"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testLogger = logger.NewNoFileLogger("test")
+)
+
+func TestReserve(t *testing.T) {
+	r := require.New(t)
+
+	params := reservation.Params{VariantID: 1, StorageID: 2, Quantity: 3, OrderRef: "A-1"}
+
+	tests := []struct {
+		name     string
+		onHand   int
+		reserved int
+		removed  bool
+		err      error
+	}{
+		{
+			name:   "резерв свободного остатка",
+			onHand: 5,
+		},
+		{
+			name:     "остаток занят другими резервами",
+			onHand:   5,
+			reserved: 3,
+			err:      stock.ErrNotEnoughAvailable,
+		},
+		{
+			name:   "недостаточно продукта на складе",
+			onHand: 2,
+			err:    stock.ErrNotEnoughStock,
+		},
+		{
+			name:    "склад удален",
+			removed: true,
+			err:     stock.ErrStorageRemoved,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ri := rimport.NewTestRepositoryImports(ctrl)
+			ri.Config.Reservation.DefaultTTL = 30
+			ts := ri.MockSession()
+
+			if tt.removed {
+				ri.MockRepository.Product.EXPECT().LoadStorage(ts, 2).Return(stock.Stock{StorageID: 2, RemovedAt: sqlnull.NewNullTime(time.Now())}, nil)
+			} else {
+				ri.MockRepository.Product.EXPECT().LoadStorage(ts, 2).Return(stock.Stock{StorageID: 2}, nil)
+				ri.MockRepository.Reservation.EXPECT().LoadStockQuantity(ts, 1, 2).Return(tt.onHand, nil)
+				ri.MockRepository.Reservation.EXPECT().FindReservedQuantity(ts, 1, 2).Return(tt.reserved, nil)
+			}
+
+			if tt.err == nil {
+				ri.MockRepository.Reservation.EXPECT().AddReservation(ts, gomock.Any()).
+					DoAndReturn(func(_ interface{}, res reservation.Reservation) (int, error) {
+						// срок по умолчанию отсчитывается от момента резервирования
+						r.WithinDuration(time.Now().Add(30*time.Minute), res.ExpiresAt, time.Minute)
+						r.Equal(3, res.Quantity)
+						return 7, nil
+					})
+				ts.EXPECT().OnCommit(gomock.Any())
+			}
+
+			ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+			reservationID, err := ui.Usecase.Reservation.Reserve(ts, params)
+			r.Equal(tt.err, err)
+			if tt.err == nil {
+				r.Equal(7, reservationID)
+			}
+		})
+	}
+}
+
+func TestConvertReservation(t *testing.T) {
+	r := require.New(t)
+
+	active := reservation.Reservation{
+		ReservationID: 7,
+		VariantID:     1,
+		StorageID:     2,
+		Quantity:      3,
+		Status:        reservation.StatusActive,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	}
+
+	t.Run("резерв превращается в продажу", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ri := rimport.NewTestRepositoryImports(ctrl)
+		ts := ri.MockSession()
+
+		ri.MockRepository.Reservation.EXPECT().LoadReservation(ts, 7).Return(active, nil)
+		ri.MockRepository.Product.EXPECT().LoadStorage(ts, 2).Return(stock.Stock{StorageID: 2}, nil)
+		ri.MockRepository.Product.EXPECT().FindPrice(ts, 1).Return(10.0, nil)
+		ri.MockRepository.Promotion.EXPECT().FindApplicablePromotionList(ts, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, global.ErrNoData)
+		// собственный резерв не уменьшает доступное для продажи кол-во
+		ri.MockRepository.Reservation.EXPECT().FindReservedQuantity(ts, 1, 2).Return(3, nil)
+		ri.MockRepository.Stock.EXPECT().DecreaseProductInStock(ts, 1, 2, 3).Return(2, nil)
+		ri.MockRepository.Stock.EXPECT().FindThreshold(ts, 1, 2).Return(stock.ThresholdParams{}, global.ErrNoData)
+		ri.MockRepository.Stock.EXPECT().LoadBatchList(ts, 1, 2).Return(nil, global.ErrNoData)
+		ri.MockRepository.Valuation.EXPECT().LoadAverageCost(ts, 1, 2).Return(valuation.AverageCost{}, global.ErrNoData)
+		ri.MockRepository.Valuation.EXPECT().LoadCostLayerList(ts, 1, 2).Return(nil, global.ErrNoData)
+		ri.MockRepository.Location.EXPECT().TrimLocationStock(ts, 1, 2, 2).Return(nil)
+		ri.MockRepository.Outbox.EXPECT().SaveEvent(ts, gomock.Any()).Return(int64(1), nil).AnyTimes()
+		ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(ts, gomock.Any()).Return(nil).AnyTimes()
+		ts.EXPECT().OnCommit(gomock.Any()).AnyTimes()
+		ri.MockRepository.Payment.EXPECT().AddPayment(ts, gomock.Any(), gomock.Any()).Return(1, nil)
+		ri.MockRepository.Product.EXPECT().SaveSale(ts, gomock.Any()).
+			DoAndReturn(func(_ interface{}, p product.SaleParams) (int, error) {
+				r.Equal(3, p.Quantity)
+				r.Equal(30.0, p.TotalPrice)
+				return 11, nil
+			})
+		ri.MockRepository.Reservation.EXPECT().CloseReservation(ts, 7, reservation.StatusConverted, 11).Return(nil)
+
+		ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+		saleID, err := ui.Usecase.Reservation.Convert(ts, 7, nil)
+		r.NoError(err)
+		r.Equal(11, saleID)
+	})
+
+	t.Run("истекший резерв не продается", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ri := rimport.NewTestRepositoryImports(ctrl)
+		ts := ri.MockSession()
+
+		expired := active
+		expired.ExpiresAt = time.Now().Add(-time.Minute)
+		ri.MockRepository.Reservation.EXPECT().LoadReservation(ts, 7).Return(expired, nil)
+
+		ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+		_, err := ui.Usecase.Reservation.Convert(ts, 7, nil)
+		r.Equal(reservation.ErrExpired, err)
+	})
+
+	t.Run("снятый резерв не продается", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ri := rimport.NewTestRepositoryImports(ctrl)
+		ts := ri.MockSession()
+
+		released := active
+		released.Status = reservation.StatusReleased
+		ri.MockRepository.Reservation.EXPECT().LoadReservation(ts, 7).Return(released, nil)
+
+		ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+		_, err := ui.Usecase.Reservation.Convert(ts, 7, nil)
+		r.Equal(reservation.ErrNotActive, err)
+	})
+}
@@ -0,0 +1,137 @@
+package test
+
+import (
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/stock"
+	"product_storage/internal/entity/stocktake"
+	"product_storage/internal/entity/valuation"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/logger"
+	"product_storage/tools/sqlnull"
+	"product_storage/uimport"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testLogger = logger.NewNoFileLogger("test")
+)
+
+func TestPostStocktake(t *testing.T) {
+	r := require.New(t)
+
+	open := stocktake.Stocktake{StocktakeID: 5, StorageID: 2, Status: stocktake.StatusOpen}
+	lineList := []stocktake.Line{
+		{LineID: 1, VariantID: 1, ExpectedQuantity: 5, CountedQuantity: sqlnull.NewInt64(7)},
+		{LineID: 2, VariantID: 2, ExpectedQuantity: 4, CountedQuantity: sqlnull.NewInt64(1)},
+		{LineID: 3, VariantID: 3, ExpectedQuantity: 2},
+	}
+
+	tests := []struct {
+		name          string
+		zeroUncounted bool
+		adjustments   map[int]int
+	}{
+		{
+			name:        "непосчитанный вариант не меняется",
+			adjustments: map[int]int{1: 2, 2: -3, 3: 0},
+		},
+		{
+			name:          "непосчитанный вариант обнуляется",
+			zeroUncounted: true,
+			adjustments:   map[int]int{1: 2, 2: -3, 3: -2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ri := rimport.NewTestRepositoryImports(ctrl)
+			ts := ri.MockSession()
+
+			ri.MockRepository.Stocktake.EXPECT().LoadStocktake(ts, 5).Return(open, nil)
+			ri.MockRepository.Stocktake.EXPECT().FindStocktakeLineList(ts, 5).Return(lineList, nil)
+
+			// излишек зачисляется, недостача списывается
+			ri.MockRepository.Stock.EXPECT().IncreaseProductInStock(ts, 1, 2, 2).Return(9, nil)
+			ri.MockRepository.Stock.EXPECT().DecreaseProductInStock(ts, 2, 2, 3).Return(1, nil)
+			writeOffs := map[int]int{}
+			for variantID, adjustment := range tt.adjustments {
+				if adjustment >= 0 {
+					continue
+				}
+				if variantID != 2 {
+					ri.MockRepository.Stock.EXPECT().DecreaseProductInStock(ts, variantID, 2, -adjustment).Return(0, nil)
+				}
+				ri.MockRepository.Stock.EXPECT().FindThreshold(ts, variantID, 2).Return(stock.ThresholdParams{}, global.ErrNoData)
+				ri.MockRepository.Stock.EXPECT().LoadBatchList(ts, variantID, 2).Return(nil, global.ErrNoData)
+				ri.MockRepository.Valuation.EXPECT().LoadAverageCost(ts, variantID, 2).Return(valuation.AverageCost{}, global.ErrNoData)
+				ri.MockRepository.Valuation.EXPECT().LoadCostLayerList(ts, variantID, 2).Return(nil, global.ErrNoData)
+				ri.MockRepository.Location.EXPECT().TrimLocationStock(ts, variantID, 2, gomock.Any()).Return(nil)
+			}
+			ri.MockRepository.Stock.EXPECT().SaveWriteOff(ts, gomock.Any()).
+				DoAndReturn(func(_ transaction.Session, w stock.WriteOffParams) (int, error) {
+					r.Equal(stocktake.ReasonShortage, w.Reason)
+					r.Equal(5, w.StocktakeID)
+					writeOffs[w.VariantID] = w.Quantity
+					return 1, nil
+				}).AnyTimes()
+			ri.MockRepository.Outbox.EXPECT().SaveEvent(ts, gomock.Any()).Return(int64(1), nil).AnyTimes()
+			ri.MockRepository.Webhook.EXPECT().CreateDeliveryList(ts, gomock.Any()).Return(nil).AnyTimes()
+			ts.EXPECT().OnCommit(gomock.Any()).AnyTimes()
+
+			for _, l := range lineList {
+				ri.MockRepository.Stocktake.EXPECT().SetLineAdjustment(ts, l.LineID, tt.adjustments[l.VariantID]).Return(nil)
+			}
+			ri.MockRepository.Stocktake.EXPECT().UpdateStocktakeStatus(ts, 5, stocktake.StatusPosted).Return(nil)
+
+			ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+			err := ui.Usecase.Stocktake.PostStocktake(ts, stocktake.PostParams{StocktakeID: 5, ZeroUncounted: tt.zeroUncounted})
+			r.NoError(err)
+
+			for variantID, adjustment := range tt.adjustments {
+				if adjustment < 0 {
+					r.Equal(-adjustment, writeOffs[variantID])
+				}
+			}
+		})
+	}
+}
+
+func TestPostClosedStocktake(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ts := ri.MockSession()
+
+	ri.MockRepository.Stocktake.EXPECT().LoadStocktake(ts, 5).Return(stocktake.Stocktake{StocktakeID: 5, Status: stocktake.StatusPosted}, nil)
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	err := ui.Usecase.Stocktake.PostStocktake(ts, stocktake.PostParams{StocktakeID: 5})
+	r.Equal(stocktake.ErrNotOpen, err)
+}
+
+func TestVarianceReport(t *testing.T) {
+	r := require.New(t)
+
+	report := stocktake.NewVarianceReport(stocktake.Stocktake{StocktakeID: 1}, []stocktake.Line{
+		{VariantID: 1, ExpectedQuantity: 5, CountedQuantity: sqlnull.NewInt64(7)},
+		{VariantID: 2, ExpectedQuantity: 4, CountedQuantity: sqlnull.NewInt64(1)},
+		{VariantID: 3, ExpectedQuantity: 2},
+	})
+
+	r.Equal(2, report.Counted)
+	r.Equal(1, report.Uncounted)
+	r.Equal(2, report.Surplus)
+	r.Equal(3, report.Shortage)
+}
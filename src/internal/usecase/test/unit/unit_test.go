@@ -0,0 +1,88 @@
+package test
+
+import (
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/unit"
+	"product_storage/rimport"
+	"product_storage/tools/logger"
+	"product_storage/uimport"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testLogger = logger.NewNoFileLogger("test")
+)
+
+func TestConvert(t *testing.T) {
+	r := require.New(t)
+
+	units := map[string]unit.Unit{
+		"г":  {Code: "г", Dimension: unit.DimensionMass, Factor: 0.001},
+		"кг": {Code: "кг", Dimension: unit.DimensionMass, Factor: 1},
+		"л":  {Code: "л", Dimension: unit.DimensionVolume, Factor: 1},
+	}
+
+	tests := []struct {
+		name     string
+		value    float64
+		from, to string
+		expected float64
+		err      error
+	}{
+		{
+			name:     "граммы в килограммы",
+			value:    500,
+			from:     "г",
+			to:       "кг",
+			expected: 0.5,
+		},
+		{
+			name:     "килограммы в граммы",
+			value:    3,
+			from:     "кг",
+			to:       "г",
+			expected: 3000,
+		},
+		{
+			name:  "масса не переводится в объем",
+			value: 1,
+			from:  "кг",
+			to:    "л",
+			err:   unit.ErrDimensionMismatch,
+		},
+		{
+			name:  "неизвестная единица",
+			value: 1,
+			from:  "фунт",
+			to:    "кг",
+			err:   unit.ErrUnknownUnit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ri := rimport.NewTestRepositoryImports(ctrl)
+			ts := ri.MockSession()
+
+			ri.MockRepository.Unit.EXPECT().LoadUnit(ts, gomock.Any()).
+				DoAndReturn(func(_ interface{}, code string) (unit.Unit, error) {
+					if u, exists := units[code]; exists {
+						return u, nil
+					}
+					return unit.Unit{}, global.ErrNoData
+				}).AnyTimes()
+
+			ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+			value, err := ui.Usecase.Unit.Convert(ts, tt.value, tt.from, tt.to)
+			r.Equal(tt.err, err)
+			r.InDelta(tt.expected, value, 1e-9)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"product_storage/internal/bridge"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/webhook"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"product_storage/tools/logger"
+	"product_storage/uimport"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testLogger = logger.NewNoFileLogger("test")
+)
+
+func TestAddSubscription(t *testing.T) {
+	r := require.New(t)
+
+	valid := webhook.SubscriptionParams{
+		URL:        "https://shop.example.com/hooks/stock",
+		Secret:     "0123456789abcdef",
+		EventTypes: []string{"StockChanged", "PriceChanged"},
+	}
+
+	tests := []struct {
+		name       string
+		params     func(p webhook.SubscriptionParams) webhook.SubscriptionParams
+		prepare    func(ri rimport.TestRepositoryImports, ts transaction.Session)
+		expectedID int
+		hasErr     bool
+	}{
+		{
+			name:   "успешный результат",
+			params: func(p webhook.SubscriptionParams) webhook.SubscriptionParams { return p },
+			prepare: func(ri rimport.TestRepositoryImports, ts transaction.Session) {
+				ri.MockRepository.Webhook.EXPECT().AddSubscription(ts, valid).Return(3, nil)
+			},
+			expectedID: 3,
+		},
+		{
+			name: "неверный адрес",
+			params: func(p webhook.SubscriptionParams) webhook.SubscriptionParams {
+				p.URL = "ftp://shop.example.com"
+				return p
+			},
+			hasErr: true,
+		},
+		{
+			name: "короткий секрет",
+			params: func(p webhook.SubscriptionParams) webhook.SubscriptionParams {
+				p.Secret = "secret"
+				return p
+			},
+			hasErr: true,
+		},
+		{
+			name: "неизвестный тип события",
+			params: func(p webhook.SubscriptionParams) webhook.SubscriptionParams {
+				p.EventTypes = []string{"StockOut"}
+				return p
+			},
+			hasErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ri := rimport.NewTestRepositoryImports(ctrl)
+			ts := ri.MockSession()
+
+			if tt.prepare != nil {
+				tt.prepare(ri, ts)
+			}
+
+			ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+			id, err := ui.Usecase.Webhook.AddSubscription(ts, tt.params(valid))
+			if tt.hasErr {
+				r.Error(err)
+			} else {
+				r.NoError(err)
+			}
+			r.Equal(tt.expectedID, id)
+		})
+	}
+}
+
+func TestDeliverPending(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ri := rimport.NewTestRepositoryImports(ctrl)
+	ri.Config.Webhook.BatchSize = 50
+	ri.Config.Webhook.MaxAttempts = 3
+	ri.Config.Webhook.RetryBaseDelay = 5
+	ri.Config.Webhook.RetryMaxDelay = 3600
+	ri.Config.Webhook.Timeout = 5
+	ts := ri.MockSessionWithCommit()
+	ri.SessionManager.EXPECT().CreateSession().Return(ts).AnyTimes()
+
+	deliveryList := []webhook.Delivery{
+		{DeliveryID: 1, EventType: "PriceChanged", URL: "https://a.example.com"},
+		{DeliveryID: 2, EventType: "PriceChanged", URL: "https://b.example.com", Attempts: 0},
+		{DeliveryID: 3, EventType: "SaleRecorded", URL: "https://b.example.com", Attempts: 2},
+	}
+
+	// пачка забирается на срок ее отправки до запросов получателям
+	claim := ri.MockRepository.Webhook.EXPECT().LoadPendingDeliveryList(ts, 50).Return(deliveryList, nil)
+	claim = ri.MockRepository.Webhook.EXPECT().ClaimDeliveryList(ts, []int64{1, 2, 3}, gomock.Any()).
+		DoAndReturn(func(_ transaction.Session, _ []int64, until time.Time) error {
+			r.WithinDuration(time.Now().Add(50*5*time.Second), until, time.Second)
+			return nil
+		}).After(claim)
+
+	// запросы выполняются со сроком отправки пачки
+	sender := bridge.NewMockWebhookSender(ctrl)
+	sender.EXPECT().Send(gomock.Any(), deliveryList[0]).
+		DoAndReturn(func(ctx context.Context, _ webhook.Delivery) (int, error) {
+			_, ok := ctx.Deadline()
+			r.True(ok)
+			return 200, nil
+		}).After(claim)
+	sender.EXPECT().Send(gomock.Any(), deliveryList[1]).Return(503, errors.New("получатель ответил статусом 503")).After(claim)
+	sender.EXPECT().Send(gomock.Any(), deliveryList[2]).Return(0, errors.New("connection refused")).After(claim)
+
+	ri.MockRepository.Webhook.EXPECT().MarkDelivered(ts, int64(1), 200).Return(nil)
+
+	// ошибка получателя не мешает доставке остальным, доставка повторяется позже
+	ri.MockRepository.Webhook.EXPECT().MarkFailed(ts, int64(2), webhook.StatusPending, gomock.Any(), 503, gomock.Any()).
+		DoAndReturn(func(_ transaction.Session, _ int64, _ string, nextAttemptAt time.Time, _ int, _ string) error {
+			r.WithinDuration(time.Now().Add(5*time.Second), nextAttemptAt, time.Second)
+			return nil
+		})
+
+	// после исчерпания попыток доставка считается неудачной
+	ri.MockRepository.Webhook.EXPECT().MarkFailed(ts, int64(3), webhook.StatusFailed, gomock.Any(), 0, "connection refused").Return(nil)
+
+	ui := uimport.NewUsecaseImports(testLogger, testLogger, ri.RepositoryImports(), ri.SessionManager)
+
+	claimed, err := ui.Usecase.Webhook.DeliverPending(context.Background(), sender)
+	r.NoError(err)
+	r.Equal(3, claimed)
+
+	stats := ui.Usecase.Webhook.Stats()
+	r.Equal(int64(1), stats.Delivered)
+	r.Equal(int64(2), stats.Failed)
+
+	t.Run("нет недоставленных событий", func(t *testing.T) {
+		ri.MockRepository.Webhook.EXPECT().LoadPendingDeliveryList(ts, 50).Return(nil, global.ErrNoData)
+
+		claimed, err := ui.Usecase.Webhook.DeliverPending(context.Background(), sender)
+		r.NoError(err)
+		r.Zero(claimed)
+	})
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"errors"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/unit"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+
+	"github.com/sirupsen/logrus"
+)
+
+// UnitUseCase справочник единиц измерения и перевод значений между ними
+type UnitUseCase struct {
+	log *logrus.Logger
+	rimport.RepositoryImports
+}
+
+func NewUnit(log *logrus.Logger, ri rimport.RepositoryImports) *UnitUseCase {
+	return &UnitUseCase{
+		log:               log,
+		RepositoryImports: ri,
+	}
+}
+
+// AddUnit добавление единицы измерения в справочник
+func (u *UnitUseCase) AddUnit(ts transaction.Session, p unit.Unit) error {
+	lf := p.Log()
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	_, err := u.Repository.Unit.LoadUnit(ts, p.Code)
+	switch err {
+	case nil:
+		return errors.New("единица измерения уже есть в справочнике")
+	case global.ErrNoData:
+	default:
+		u.log.WithFields(lf).Error("не удалось проверить единицу измерения ", err)
+		return global.ErrInternalError
+	}
+
+	if err = u.Repository.Unit.AddUnit(ts, p); err != nil {
+		u.log.WithFields(lf).Error("не удалось добавить единицу измерения ", err)
+		return global.ErrInternalError
+	}
+
+	u.log.WithFields(lf).Info("единица измерения добавлена")
+	return nil
+}
+
+// FindUnitList справочник единиц измерения
+func (u *UnitUseCase) FindUnitList(ts transaction.Session) ([]unit.Unit, error) {
+	unitList, err := u.Repository.Unit.FindUnitList(ts)
+	switch err {
+	case nil:
+		return unitList, nil
+	case global.ErrNoData:
+		return []unit.Unit{}, nil
+	default:
+		u.log.Error("не удалось найти единицы измерения ", err)
+		return nil, global.ErrInternalError
+	}
+}
+
+// loadUnit единица измерения из справочника
+func (u *UnitUseCase) loadUnit(ts transaction.Session, lf logrus.Fields, code string) (unit.Unit, error) {
+	found, err := u.Repository.Unit.LoadUnit(ts, code)
+	switch err {
+	case nil:
+		return found, nil
+	case global.ErrNoData:
+		return unit.Unit{}, unit.ErrUnknownUnit
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить единицу измерения ", err)
+		return unit.Unit{}, global.ErrInternalError
+	}
+}
+
+// Convert перевод значения из одной единицы измерения в другую, например 500 г в кг
+func (u *UnitUseCase) Convert(ts transaction.Session, value float64, from, to string) (float64, error) {
+	lf := logrus.Fields{"value": value, "from": from, "to": to}
+
+	fromUnit, err := u.loadUnit(ts, lf, from)
+	if err != nil {
+		return 0, err
+	}
+
+	toUnit, err := u.loadUnit(ts, lf, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return unit.Convert(value, fromUnit, toUnit)
+}
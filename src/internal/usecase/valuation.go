@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"errors"
+	"product_storage/config"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/valuation"
+	"product_storage/internal/transaction"
+
+	"github.com/sirupsen/logrus"
+)
+
+// receiveCost учет себестоимости поступившего продукта: новый слой FIFO и пересчет средней цены
+func (u *ProductUseCase) receiveCost(ts transaction.Session, lf logrus.Fields, l valuation.CostLayer) error {
+	if err := u.Repository.Valuation.AddCostLayer(ts, l); err != nil {
+		u.log.WithFields(lf).Error("не удалось добавить слой себестоимости ", err)
+		return global.ErrInternalError
+	}
+
+	if err := u.Repository.Valuation.AddAverageCost(ts, l.VariantID, l.StorageID, l.Quantity, l.UnitCost); err != nil {
+		u.log.WithFields(lf).Error("не удалось пересчитать среднюю себестоимость ", err)
+		return global.ErrInternalError
+	}
+
+	return nil
+}
+
+// consumeCost себестоимость уходящего со склада продукта по методу из конфига.
+// Слои FIFO и средняя цена уменьшаются при любом методе, чтобы смена метода не искажала оценку.
+// Продукт, поступивший без себестоимости, оценивается по средней цене, а если ее нет, по нулевой
+func (u *ProductUseCase) consumeCost(ts transaction.Session, lf logrus.Fields, variantID, storageID, quantity int) (cost float64, err error) {
+	average, err := u.Repository.Valuation.LoadAverageCost(ts, variantID, storageID)
+	switch err {
+	case nil:
+		if err = u.Repository.Valuation.ConsumeAverageCost(ts, variantID, storageID, quantity); err != nil {
+			u.log.WithFields(lf).Error("не удалось уменьшить кол-во по средней себестоимости ", err)
+			return 0, global.ErrInternalError
+		}
+	case global.ErrNoData:
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить среднюю себестоимость ", err)
+		return 0, global.ErrInternalError
+	}
+
+	layerList, err := u.Repository.Valuation.LoadCostLayerList(ts, variantID, storageID)
+	switch err {
+	case nil, global.ErrNoData:
+	default:
+		u.log.WithFields(lf).Error("не удалось загрузить слои себестоимости ", err)
+		return 0, global.ErrInternalError
+	}
+
+	allocation, fifoCost, rest := valuation.AllocateFIFO(layerList, quantity)
+	for _, c := range allocation {
+		if err = u.Repository.Valuation.ConsumeCostLayer(ts, c.LayerID, c.Quantity); err != nil {
+			lf["layer_ID"] = c.LayerID
+			u.log.WithFields(lf).Error("не удалось списать слой себестоимости ", err)
+			return 0, global.ErrInternalError
+		}
+	}
+
+	if u.Config.Costing.Method == config.CostingAverage {
+		return valuation.RoundMoney(float64(quantity) * average.AverageCost), nil
+	}
+
+	return valuation.RoundMoney(fifoCost + float64(rest)*average.AverageCost), nil
+}
+
+// ValuationReport оценка остатков по методу себестоимости из конфига, при storageID = 0 по всем складам
+func (u *ProductUseCase) ValuationReport(ts transaction.Session, storageID int) (valuation.ValuationReport, error) {
+	lf := logrus.Fields{"storage_ID": storageID, "method": u.Config.Costing.Method}
+
+	if storageID < 0 {
+		return valuation.ValuationReport{}, errors.New("id склада не может быть меньше нуля")
+	}
+
+	var (
+		itemList []valuation.Valuation
+		err      error
+	)
+	if u.Config.Costing.Method == config.CostingAverage {
+		itemList, err = u.Repository.Valuation.FindAverageValuationList(ts, storageID)
+	} else {
+		itemList, err = u.Repository.Valuation.FindFIFOValuationList(ts, storageID)
+	}
+
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		itemList = []valuation.Valuation{}
+	default:
+		u.log.WithFields(lf).Error("не удалось оценить остатки ", err)
+		return valuation.ValuationReport{}, global.ErrInternalError
+	}
+
+	report := valuation.ValuationReport{Method: u.Config.Costing.Method, StorageID: storageID, Items: itemList}
+	for _, v := range itemList {
+		report.Total += v.Value
+	}
+	report.Total = valuation.RoundMoney(report.Total)
+
+	return report, nil
+}
+
+// MarginReport отчет о выручке, себестоимости и валовой марже продаж за период
+func (u *ProductUseCase) MarginReport(ts transaction.Session, q valuation.MarginQuery) (valuation.MarginReport, error) {
+	if err := q.Validate(); err != nil {
+		return valuation.MarginReport{}, err
+	}
+	lf := q.Log()
+
+	marginList, err := u.Repository.Valuation.FindMarginList(ts, q)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		marginList = []valuation.Margin{}
+	default:
+		u.log.WithFields(lf).Error("не удалось построить отчет о марже ", err)
+		return valuation.MarginReport{}, global.ErrInternalError
+	}
+
+	return valuation.NewMarginReport(q, marginList), nil
+}
@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"product_storage/internal/bridge"
+	"product_storage/internal/entity/global"
+	"product_storage/internal/entity/webhook"
+	"product_storage/internal/transaction"
+	"product_storage/rimport"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultDeliveryListLimit кол-во записей журнала доставок, если лимит не указан
+const defaultDeliveryListLimit = 50
+
+// WebhookStats статистика доставки событий получателям
+type WebhookStats struct {
+	Delivered int64 `json:"delivered"` // кол-во доставленных событий
+	Failed    int64 `json:"failed"`    // кол-во неудачных попыток доставки
+}
+
+// WebhookUseCase подписки на события и доставка событий получателям.
+// Доставки создаются в транзакции изменения вместе с событием outbox
+// и отправляются отдельным процессом с повторами при ошибках
+type WebhookUseCase struct {
+	log *logrus.Logger
+	rimport.RepositoryImports
+
+	delivered atomic.Int64
+	failed    atomic.Int64
+}
+
+func NewWebhook(log *logrus.Logger, ri rimport.RepositoryImports) *WebhookUseCase {
+	return &WebhookUseCase{
+		log:               log,
+		RepositoryImports: ri,
+	}
+}
+
+// Stats статистика доставки событий
+func (u *WebhookUseCase) Stats() WebhookStats {
+	return WebhookStats{
+		Delivered: u.delivered.Load(),
+		Failed:    u.failed.Load(),
+	}
+}
+
+// AddSubscription логика добавления подписки на события
+func (u *WebhookUseCase) AddSubscription(ts transaction.Session, p webhook.SubscriptionParams) (subscriptionID int, err error) {
+	lf := p.Log()
+
+	if err = p.Validate(); err != nil {
+		return 0, err
+	}
+
+	subscriptionID, err = u.Repository.Webhook.AddSubscription(ts, p)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось добавить подписку ", err)
+		return 0, global.ErrInternalError
+	}
+
+	lf["subscription_ID"] = subscriptionID
+
+	u.log.WithFields(lf).Info("подписка успешно добавлена")
+	return subscriptionID, nil
+}
+
+// FindSubscriptionList список действующих подписок
+func (u *WebhookUseCase) FindSubscriptionList(ts transaction.Session) ([]webhook.Subscription, error) {
+	subscriptionList, err := u.Repository.Webhook.FindSubscriptionList(ts)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return []webhook.Subscription{}, nil
+	default:
+		u.log.Error("не удалось найти подписки ", err)
+		return nil, global.ErrInternalError
+	}
+
+	return subscriptionList, nil
+}
+
+// RemoveSubscription отключение подписки, недоставленные события больше не отправляются
+func (u *WebhookUseCase) RemoveSubscription(ts transaction.Session, subscriptionID int) error {
+	lf := logrus.Fields{"subscription_ID": subscriptionID}
+
+	removed, err := u.Repository.Webhook.RemoveSubscription(ts, subscriptionID)
+	if err != nil {
+		u.log.WithFields(lf).Error("не удалось удалить подписку ", err)
+		return global.ErrInternalError
+	}
+
+	if !removed {
+		return errors.New("подписка не найдена")
+	}
+
+	u.log.WithFields(lf).Info("подписка успешно удалена")
+	return nil
+}
+
+// FindDeliveryList журнал доставок подписки, начиная с последних
+func (u *WebhookUseCase) FindDeliveryList(ts transaction.Session, subscriptionID, limit int) ([]webhook.Delivery, error) {
+	lf := logrus.Fields{"subscription_ID": subscriptionID, "limit": limit}
+
+	if subscriptionID <= 0 {
+		return nil, errors.New("id подписки не может быть меньше или равен 0")
+	}
+
+	if limit <= 0 {
+		limit = defaultDeliveryListLimit
+	}
+
+	deliveryList, err := u.Repository.Webhook.FindDeliveryList(ts, subscriptionID, limit)
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return []webhook.Delivery{}, nil
+	default:
+		u.log.WithFields(lf).Error("не удалось найти доставки подписки ", err)
+		return nil, global.ErrInternalError
+	}
+
+	return deliveryList, nil
+}
+
+// DeliverPending отправка пачки доставок, время попытки которых наступило, возвращает кол-во забранных доставок.
+// Пачка забирается в короткой транзакции, запросы получателям выполняются без открытой транзакции
+// за срок, на который пачка забрана, результат каждой доставки записывается в отдельной транзакции.
+// Неудачная доставка повторяется с растущей задержкой, после исчерпания попыток получает статус failed.
+// Ошибка одного получателя не мешает доставке остальным
+func (u *WebhookUseCase) DeliverPending(ctx context.Context, sender bridge.WebhookSender) (claimed int, err error) {
+	// пачка отправляется последовательно, каждый запрос ограничен таймаутом получателя
+	batchTimeout := u.Config.WebhookTimeout() * time.Duration(u.Config.WebhookBatchSize())
+
+	deliveryList, err := u.claimPending(ctx, time.Now().Add(batchTimeout))
+	if err != nil {
+		return 0, err
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, batchTimeout)
+	defer cancel()
+
+	for _, d := range deliveryList {
+		// неотправленные доставки забираются повторно после истечения срока пачки
+		if sendCtx.Err() != nil {
+			break
+		}
+
+		statusCode, sendErr := sender.Send(sendCtx, d)
+		if err = u.saveResult(ctx, d, statusCode, sendErr); err != nil {
+			return len(deliveryList), err
+		}
+	}
+
+	return len(deliveryList), nil
+}
+
+// claimPending загрузка пачки доставок с переносом их следующей попытки на until,
+// чтобы параллельные обработчики не отправили их повторно
+func (u *WebhookUseCase) claimPending(ctx context.Context, until time.Time) (deliveryList []webhook.Delivery, err error) {
+	ts := u.SessionManager.CreateSession()
+	if err = ts.Start(ctx); err != nil {
+		u.log.Error("не удалось начать транзакцию загрузки недоставленных событий ", err)
+		return nil, err
+	}
+	defer ts.Rollback()
+
+	deliveryList, err = u.Repository.Webhook.LoadPendingDeliveryList(ts, u.Config.WebhookBatchSize())
+	switch err {
+	case nil:
+	case global.ErrNoData:
+		return nil, nil
+	default:
+		u.log.Error("не удалось загрузить недоставленные события ", err)
+		return nil, global.ErrInternalError
+	}
+
+	deliveryIDList := make([]int64, 0, len(deliveryList))
+	for _, d := range deliveryList {
+		deliveryIDList = append(deliveryIDList, d.DeliveryID)
+	}
+
+	if err = u.Repository.Webhook.ClaimDeliveryList(ts, deliveryIDList, until); err != nil {
+		u.log.Error("не удалось забрать недоставленные события на отправку ", err)
+		return nil, global.ErrInternalError
+	}
+
+	if err = ts.Commit(); err != nil {
+		u.log.Error("не удалось зафиксировать загрузку недоставленных событий ", err)
+		return nil, err
+	}
+
+	return deliveryList, nil
+}
+
+// saveResult запись результата попытки доставки в отдельной транзакции
+func (u *WebhookUseCase) saveResult(ctx context.Context, d webhook.Delivery, statusCode int, sendErr error) (err error) {
+	lf := d.Log()
+	lf["status_code"] = statusCode
+
+	ts := u.SessionManager.CreateSession()
+	if err = ts.Start(ctx); err != nil {
+		u.log.WithFields(lf).Error("не удалось начать транзакцию записи доставки события ", err)
+		return err
+	}
+	defer ts.Rollback()
+
+	if sendErr == nil {
+		if err = u.Repository.Webhook.MarkDelivered(ts, d.DeliveryID, statusCode); err != nil {
+			u.log.WithFields(lf).Error("не удалось отметить событие доставленным ", err)
+			return global.ErrInternalError
+		}
+	} else {
+		status := webhook.StatusPending
+		nextAttemptAt := time.Now().Add(retryDelay(u.Config.WebhookRetryBaseDelay(), u.Config.WebhookRetryMaxDelay(), d.Attempts))
+		if d.Attempts+1 >= u.Config.WebhookMaxAttempts() {
+			status = webhook.StatusFailed
+		}
+		lf["status"] = status
+		u.log.WithFields(lf).Warn("не удалось доставить событие ", sendErr)
+
+		if err = u.Repository.Webhook.MarkFailed(ts, d.DeliveryID, status, nextAttemptAt, statusCode, sendErr.Error()); err != nil {
+			u.log.WithFields(lf).Error("не удалось записать ошибку доставки события ", err)
+			return global.ErrInternalError
+		}
+	}
+
+	if err = ts.Commit(); err != nil {
+		u.log.WithFields(lf).Error("не удалось зафиксировать доставку события ", err)
+		return err
+	}
+
+	if sendErr == nil {
+		u.delivered.Add(1)
+	} else {
+		u.failed.Add(1)
+	}
+
+	return nil
+}
+
+// RunDispatcher периодическая доставка событий получателям до отмены ctx.
+// Пока пачки заполнены полностью, следующая доставляется без ожидания
+func (u *WebhookUseCase) RunDispatcher(ctx context.Context, sender bridge.WebhookSender) {
+	ticker := time.NewTicker(u.Config.WebhookInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for ctx.Err() == nil {
+			claimed, err := u.DeliverPending(ctx, sender)
+			if err != nil {
+				u.log.Error("не удалось доставить пачку событий ", err)
+				break
+			}
+
+			if claimed == 0 || claimed < u.Config.WebhookBatchSize() {
+				break
+			}
+		}
+	}
+}
@@ -6,12 +6,17 @@ import (
 	"product_storage/config"
 	"product_storage/internal/repository/postgresql"
 	"product_storage/internal/transaction"
+	"product_storage/tools/rediscache"
+
+	"github.com/redis/go-redis/v9"
 )
 
 type RepositoryImports struct {
 	Config         config.Config
 	SessionManager transaction.SessionManager
 	Repository     Repository
+	// Redis клиент redis, создается только при хранении кэша в redis
+	Redis redis.UniversalClient
 }
 
 func NewRepositoryImports(
@@ -22,11 +27,36 @@ func NewRepositoryImports(
 		log.Fatalln(err)
 	}
 
-	return RepositoryImports{
+	ri := RepositoryImports{
 		Config:         config,
 		SessionManager: sessionManager,
 		Repository: Repository{
-			Product: postgresql.NewProduct(),
+			Product:     postgresql.NewProduct(),
+			Outbox:      postgresql.NewOutbox(),
+			Webhook:     postgresql.NewWebhook(),
+			Stock:       postgresql.NewStock(),
+			Purchase:    postgresql.NewPurchase(),
+			Valuation:   postgresql.NewValuation(),
+			Stocktake:   postgresql.NewStocktake(),
+			Reservation: postgresql.NewReservation(),
+			Location:    postgresql.NewLocation(),
+			Unit:        postgresql.NewUnit(),
+			Image:       postgresql.NewImage(),
+			Attribute:   postgresql.NewAttribute(),
+			Promotion:   postgresql.NewPromotion(),
+			Customer:    postgresql.NewCustomer(),
+			Reprice:     postgresql.NewReprice(),
+			Payment:     postgresql.NewPayment(),
 		},
 	}
+
+	if config.RedisCacheEnabled() {
+		client, err := rediscache.NewClient(config.RedisConnect())
+		if err != nil {
+			log.Fatalln(err)
+		}
+		ri.Redis = client
+	}
+
+	return ri
 }
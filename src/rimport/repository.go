@@ -3,11 +3,41 @@ package rimport
 import "product_storage/internal/repository"
 
 type Repository struct {
-	Logger  repository.Logger
-	Product repository.Product
+	Logger      repository.Logger
+	Product     repository.Product
+	Outbox      repository.Outbox
+	Webhook     repository.Webhook
+	Stock       repository.Stock
+	Purchase    repository.Purchase
+	Valuation   repository.Valuation
+	Stocktake   repository.Stocktake
+	Reservation repository.Reservation
+	Location    repository.Location
+	Unit        repository.Unit
+	Image       repository.Image
+	Attribute   repository.Attribute
+	Promotion   repository.Promotion
+	Customer    repository.Customer
+	Reprice     repository.Reprice
+	Payment     repository.Payment
 }
 
 type MockRepository struct {
-	Logger  *repository.MockLogger
-	Product *repository.MockProduct
+	Logger      *repository.MockLogger
+	Product     *repository.MockProduct
+	Outbox      *repository.MockOutbox
+	Webhook     *repository.MockWebhook
+	Stock       *repository.MockStock
+	Purchase    *repository.MockPurchase
+	Valuation   *repository.MockValuation
+	Stocktake   *repository.MockStocktake
+	Reservation *repository.MockReservation
+	Location    *repository.MockLocation
+	Unit        *repository.MockUnit
+	Image       *repository.MockImage
+	Attribute   *repository.MockAttribute
+	Promotion   *repository.MockPromotion
+	Customer    *repository.MockCustomer
+	Reprice     *repository.MockReprice
+	Payment     *repository.MockPayment
 }
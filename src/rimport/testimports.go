@@ -1,6 +1,7 @@
 package rimport
 
 import (
+	"context"
 	"log"
 	"os"
 	"product_storage/config"
@@ -30,8 +31,23 @@ func NewTestRepositoryImports(
 		Config:         config,
 		SessionManager: transaction.NewMockSessionManager(ctrl),
 		MockRepository: MockRepository{
-			Logger:  repository.NewMockLogger(ctrl),
-			Product: repository.NewMockProduct(ctrl),
+			Logger:      repository.NewMockLogger(ctrl),
+			Product:     repository.NewMockProduct(ctrl),
+			Outbox:      repository.NewMockOutbox(ctrl),
+			Webhook:     repository.NewMockWebhook(ctrl),
+			Stock:       repository.NewMockStock(ctrl),
+			Purchase:    repository.NewMockPurchase(ctrl),
+			Valuation:   repository.NewMockValuation(ctrl),
+			Stocktake:   repository.NewMockStocktake(ctrl),
+			Reservation: repository.NewMockReservation(ctrl),
+			Location:    repository.NewMockLocation(ctrl),
+			Unit:        repository.NewMockUnit(ctrl),
+			Image:       repository.NewMockImage(ctrl),
+			Attribute:   repository.NewMockAttribute(ctrl),
+			Promotion:   repository.NewMockPromotion(ctrl),
+			Customer:    repository.NewMockCustomer(ctrl),
+			Reprice:     repository.NewMockReprice(ctrl),
+			Payment:     repository.NewMockPayment(ctrl),
 		},
 	}
 }
@@ -39,8 +55,9 @@ func NewTestRepositoryImports(
 func (t *TestRepositoryImports) MockSession() *transaction.MockSession {
 	ts := transaction.NewMockSession(t.ctrl)
 
-	ts.EXPECT().Start().Return(nil).AnyTimes()
+	ts.EXPECT().Start(gomock.Any()).Return(nil).AnyTimes()
 	ts.EXPECT().Rollback().Return(nil).AnyTimes()
+	ts.EXPECT().Context().Return(context.Background()).AnyTimes()
 
 	return ts
 }
@@ -58,8 +75,23 @@ func (t *TestRepositoryImports) RepositoryImports() RepositoryImports {
 		SessionManager: t.SessionManager,
 		Config:         t.Config,
 		Repository: Repository{
-			Logger:  t.MockRepository.Logger,
-			Product: t.MockRepository.Product,
+			Logger:      t.MockRepository.Logger,
+			Product:     t.MockRepository.Product,
+			Outbox:      t.MockRepository.Outbox,
+			Webhook:     t.MockRepository.Webhook,
+			Stock:       t.MockRepository.Stock,
+			Purchase:    t.MockRepository.Purchase,
+			Valuation:   t.MockRepository.Valuation,
+			Stocktake:   t.MockRepository.Stocktake,
+			Reservation: t.MockRepository.Reservation,
+			Location:    t.MockRepository.Location,
+			Unit:        t.MockRepository.Unit,
+			Image:       t.MockRepository.Image,
+			Attribute:   t.MockRepository.Attribute,
+			Promotion:   t.MockRepository.Promotion,
+			Customer:    t.MockRepository.Customer,
+			Reprice:     t.MockRepository.Reprice,
+			Payment:     t.MockRepository.Payment,
 		},
 	}
 }
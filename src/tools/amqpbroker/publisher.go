@@ -0,0 +1,119 @@
+package amqpbroker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"product_storage/internal/entity/event"
+	"strconv"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ErrNotConfirmed брокер отказался принять сообщение
+var ErrNotConfirmed = errors.New("брокер не подтвердил получение сообщения")
+
+// Publisher публикация событий в topic exchange, ключ маршрутизации - тип события.
+// Каждое сообщение ожидает подтверждения брокера, при разрыве соединение
+// восстанавливается при следующей публикации
+type Publisher struct {
+	url      string
+	exchange string
+
+	m    sync.Mutex
+	conn *amqp.Connection
+	ch   *amqp.Channel
+}
+
+func NewPublisher(url, exchange string) *Publisher {
+	return &Publisher{
+		url:      url,
+		exchange: exchange,
+	}
+}
+
+// channel открытый канал в режиме подтверждений, при необходимости переподключается
+func (p *Publisher) channel() (*amqp.Channel, error) {
+	if p.ch != nil && !p.ch.IsClosed() {
+		return p.ch, nil
+	}
+
+	if p.conn == nil || p.conn.IsClosed() {
+		conn, err := amqp.Dial(p.url)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось подключиться к брокеру: %w", err)
+		}
+		p.conn = conn
+	}
+
+	ch, err := p.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть канал: %w", err)
+	}
+
+	if err = ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("не удалось включить подтверждения: %w", err)
+	}
+
+	if err = ch.ExchangeDeclare(p.exchange, amqp.ExchangeTopic, true, false, false, false, nil); err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("не удалось объявить exchange %s: %w", p.exchange, err)
+	}
+
+	p.ch = ch
+	return ch, nil
+}
+
+// Publish публикует событие и ждет подтверждения брокера
+func (p *Publisher) Publish(ctx context.Context, e event.Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	ch, err := p.channel()
+	if err != nil {
+		return err
+	}
+
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, p.exchange, e.EventType, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    strconv.FormatInt(e.EventID, 10),
+		Type:         e.EventType,
+		Timestamp:    e.CreatedAt,
+		Body:         body,
+	})
+	if err != nil {
+		return err
+	}
+
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !acked {
+		return ErrNotConfirmed
+	}
+
+	return nil
+}
+
+// Close закрытие соединения с брокером
+func (p *Publisher) Close() error {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if p.conn == nil {
+		return nil
+	}
+
+	return p.conn.Close()
+}
@@ -0,0 +1,132 @@
+package broadcast
+
+import (
+	"sync"
+)
+
+// Item сообщение с идентификатором, по которому подписчик может продолжить чтение после переподключения
+type Item[T any] struct {
+	ID   int64
+	Data T
+}
+
+// Subscription подписка на сообщения хаба
+type Subscription[T any] struct {
+	// C новые сообщения, канал закрывается при отписке или если подписчик не успевает читать
+	C <-chan Item[T]
+	// Backlog пропущенные сообщения после указанного при подписке id
+	Backlog []Item[T]
+	// Resumed удалось ли продолжить чтение с указанного id; если нет, подписчику нужно получить текущее состояние заново
+	Resumed bool
+	// LastID id последнего сообщения на момент подписки
+	LastID int64
+
+	hub *Hub[T]
+	ch  chan Item[T]
+}
+
+// Close отписка от сообщений
+func (s *Subscription[T]) Close() {
+	s.hub.unsubscribe(s.ch)
+}
+
+// Hub рассылка сообщений подписчикам в памяти процесса.
+// Последние сообщения хранятся в кольцевом буфере для продолжения чтения после переподключения
+type Hub[T any] struct {
+	m           sync.Mutex
+	history     []Item[T]
+	start       int // индекс самого старого сообщения в history
+	size        int
+	subscribers map[chan Item[T]]struct{}
+	bufferSize  int
+}
+
+// NewHub хаб, хранящий historySize последних сообщений, с очередью bufferSize сообщений на подписчика
+func NewHub[T any](historySize, bufferSize int) *Hub[T] {
+	return &Hub[T]{
+		history:     make([]Item[T], historySize),
+		subscribers: make(map[chan Item[T]]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
+
+// Publish рассылка сообщения всем подписчикам. Подписчик, очередь которого заполнена, отключается,
+// чтобы не задерживать остальных, и может переподключиться с последнего полученного id
+func (h *Hub[T]) Publish(id int64, data T) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	item := Item[T]{ID: id, Data: data}
+
+	if len(h.history) > 0 {
+		if h.size < len(h.history) {
+			h.history[(h.start+h.size)%len(h.history)] = item
+			h.size++
+		} else {
+			h.history[h.start] = item
+			h.start = (h.start + 1) % len(h.history)
+		}
+	}
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- item:
+		default:
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe подписка на новые сообщения. Если hasLastID, в Backlog возвращаются сообщения после lastID,
+// при условии что lastID еще хранится в буфере
+func (h *Hub[T]) Subscribe(lastID int64, hasLastID bool) *Subscription[T] {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	ch := make(chan Item[T], h.bufferSize)
+	h.subscribers[ch] = struct{}{}
+
+	s := &Subscription[T]{
+		C:   ch,
+		hub: h,
+		ch:  ch,
+	}
+
+	if h.size > 0 {
+		s.LastID = h.at(h.size - 1).ID
+	}
+
+	if !hasLastID {
+		return s
+	}
+
+	// сообщения хранятся в порядке публикации, id не обязаны возрастать
+	for i := h.size - 1; i >= 0; i-- {
+		if h.at(i).ID != lastID {
+			continue
+		}
+
+		s.Resumed = true
+		for j := i + 1; j < h.size; j++ {
+			s.Backlog = append(s.Backlog, h.at(j))
+		}
+		break
+	}
+
+	return s
+}
+
+func (h *Hub[T]) at(i int) Item[T] {
+	return h.history[(h.start+i)%len(h.history)]
+}
+
+func (h *Hub[T]) unsubscribe(ch chan Item[T]) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if _, exists := h.subscribers[ch]; exists {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+}
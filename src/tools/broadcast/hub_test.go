@@ -0,0 +1,66 @@
+package broadcast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHub(t *testing.T) {
+	r := require.New(t)
+
+	hub := NewHub[string](3, 10)
+
+	s := hub.Subscribe(0, false)
+	defer s.Close()
+	r.False(s.Resumed)
+
+	hub.Publish(1, "a")
+	hub.Publish(3, "b")
+	hub.Publish(2, "c")
+
+	for _, expected := range []int64{1, 3, 2} {
+		item := <-s.C
+		r.Equal(expected, item.ID)
+	}
+
+	t.Run("продолжение после переподключения", func(t *testing.T) {
+		resumed := hub.Subscribe(3, true)
+		defer resumed.Close()
+
+		r.True(resumed.Resumed)
+		r.Equal([]Item[string]{{ID: 2, Data: "c"}}, resumed.Backlog)
+		r.Equal(int64(2), resumed.LastID)
+	})
+
+	t.Run("id вытеснен из буфера", func(t *testing.T) {
+		hub.Publish(4, "d")
+
+		resumed := hub.Subscribe(1, true)
+		defer resumed.Close()
+
+		r.False(resumed.Resumed)
+		r.Empty(resumed.Backlog)
+		r.Equal(int64(4), resumed.LastID)
+	})
+}
+
+func TestHubSlowSubscriber(t *testing.T) {
+	r := require.New(t)
+
+	hub := NewHub[int](10, 1)
+
+	slow := hub.Subscribe(0, false)
+	hub.Publish(1, 1)
+	hub.Publish(2, 2)
+
+	// подписчик с заполненной очередью отключается
+	item, ok := <-slow.C
+	r.True(ok)
+	r.Equal(int64(1), item.ID)
+
+	_, ok = <-slow.C
+	r.False(ok)
+
+	slow.Close()
+}
@@ -22,7 +22,7 @@ func LoadData[T any](
 	needCommit bool,
 ) {
 	ts := sessionManager.CreateSession()
-	if err := ts.Start(); err != nil {
+	if err := ts.Start(rc.GinContext.Request.Context()); err != nil {
 		log.Errorln(fmt.Sprintf("ошибка открытия транзакции; ошибка: %v", err))
 		rc.ReturnError(global.ErrInternalError)
 		return
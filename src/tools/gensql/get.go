@@ -1,15 +1,16 @@
 package gensql
 
 import (
+	"context"
 	"database/sql"
 	"product_storage/internal/entity/global"
 	"github.com/jmoiron/sqlx"
 )
 
-func Get[T any](tx *sqlx.Tx, sqlQuery string, params ...interface{}) (t T, err error) {
+func Get[T any](ctx context.Context, tx *sqlx.Tx, sqlQuery string, params ...interface{}) (t T, err error) {
 	var data T
 
-	err = tx.Get(&data, sqlQuery, params...)
+	err = tx.GetContext(ctx, &data, sqlQuery, params...)
 
 	switch err {
 	case nil:
@@ -22,16 +23,16 @@ func Get[T any](tx *sqlx.Tx, sqlQuery string, params ...interface{}) (t T, err e
 	}
 }
 
-func GetNamed[T any](tx *sqlx.Tx, sqlQuery string, params map[string]interface{}) (t T, err error) {
+func GetNamed[T any](ctx context.Context, tx *sqlx.Tx, sqlQuery string, params map[string]interface{}) (t T, err error) {
 	var data T
 
-	stmt, err := tx.PrepareNamed(sqlQuery)
+	stmt, err := tx.PrepareNamedContext(ctx, sqlQuery)
 	if err != nil {
 		return
 	}
 	defer stmt.Close()
 
-	err = stmt.Get(&data, params)
+	err = stmt.GetContext(ctx, &data, params)
 	switch err {
 	case nil:
 		return data, nil
@@ -43,16 +44,16 @@ func GetNamed[T any](tx *sqlx.Tx, sqlQuery string, params map[string]interface{}
 	}
 }
 
-func GetNamedStruct[T any, S any](tx *sqlx.Tx, sqlQuery string, s S) (t T, err error) {
+func GetNamedStruct[T any, S any](ctx context.Context, tx *sqlx.Tx, sqlQuery string, s S) (t T, err error) {
 	var data T
 
-	stmt, err := tx.PrepareNamed(sqlQuery)
+	stmt, err := tx.PrepareNamedContext(ctx, sqlQuery)
 	if err != nil {
 		return
 	}
 	defer stmt.Close()
 
-	err = stmt.Get(&data, s)
+	err = stmt.GetContext(ctx, &data, s)
 	switch err {
 	case nil:
 		return data, nil
@@ -1,6 +1,7 @@
 package gensql
 
 import (
+	"context"
 	"database/sql"
 	"math"
 	"product_storage/internal/entity/global"
@@ -8,10 +9,10 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
-func Select[T any](tx *sqlx.Tx, sqlQuery string, params ...interface{}) ([]T, error) {
+func Select[T any](ctx context.Context, tx *sqlx.Tx, sqlQuery string, params ...interface{}) ([]T, error) {
 	data := make([]T, 0)
 
-	err := tx.Select(&data, sqlQuery, params...)
+	err := tx.SelectContext(ctx, &data, sqlQuery, params...)
 
 	if err == nil && len(data) == 0 {
 		err = sql.ErrNoRows
@@ -27,16 +28,16 @@ func Select[T any](tx *sqlx.Tx, sqlQuery string, params ...interface{}) ([]T, er
 	}
 }
 
-func SelectNamed[T any](tx *sqlx.Tx, sqlQuery string, params map[string]interface{}) ([]T, error) {
+func SelectNamed[T any](ctx context.Context, tx *sqlx.Tx, sqlQuery string, params map[string]interface{}) ([]T, error) {
 	data := make([]T, 0)
 
-	stmt, err := tx.PrepareNamed(sqlQuery)
+	stmt, err := tx.PrepareNamedContext(ctx, sqlQuery)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	err = stmt.Select(&data, params)
+	err = stmt.SelectContext(ctx, &data, params)
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +56,7 @@ func SelectNamed[T any](tx *sqlx.Tx, sqlQuery string, params map[string]interfac
 	}
 }
 
-func SelectIn[T any](tx *sqlx.Tx, sqlQuery string, params interface{}) ([]T, error) {
+func SelectIn[T any](ctx context.Context, tx *sqlx.Tx, sqlQuery string, params interface{}) ([]T, error) {
 	data := make([]T, 0)
 
 	q, args, err := sqlx.In(sqlQuery, params)
@@ -65,7 +66,7 @@ func SelectIn[T any](tx *sqlx.Tx, sqlQuery string, params interface{}) ([]T, err
 
 	q = tx.Rebind(q)
 
-	err = tx.Select(&data, q, args...)
+	err = tx.SelectContext(ctx, &data, q, args...)
 
 	if len(data) == 0 && err == nil {
 		err = sql.ErrNoRows
@@ -2,6 +2,7 @@ package inmemorycache
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -10,6 +11,15 @@ type inmemoryCacheRepository[T any, K comparable] struct {
 	cache      map[K]T
 	lifeTime   map[K]time.Time
 	expiration time.Duration
+	hits       atomic.Int64
+	misses     atomic.Int64
+}
+
+// Stats статистика использования кэша
+type Stats struct {
+	Hits   int64 `json:"hits"`   // кол-во найденных в кэше значений
+	Misses int64 `json:"misses"` // кол-во отсутствующих или устаревших значений
+	Size   int   `json:"size"`   // кол-во значений в кэше, включая устаревшие
 }
 
 func NewInmemoryCacheRepository[T any, K comparable](expiration time.Duration) *inmemoryCacheRepository[T, K] {
@@ -29,6 +39,17 @@ func (r *inmemoryCacheRepository[T, K]) Add(id K, data T) {
 }
 
 func (r *inmemoryCacheRepository[T, K]) Get(id K) (t T, exists bool) {
+	t, exists = r.get(id)
+	if exists {
+		r.hits.Add(1)
+	} else {
+		r.misses.Add(1)
+	}
+
+	return
+}
+
+func (r *inmemoryCacheRepository[T, K]) get(id K) (t T, exists bool) {
 	r.m.RLock()
 	defer r.m.RUnlock()
 
@@ -69,3 +90,16 @@ func (r *inmemoryCacheRepository[T, K]) ClearExpired() {
 	}
 	r.m.Unlock()
 }
+
+// Stats статистика попаданий и промахов кэша
+func (r *inmemoryCacheRepository[T, K]) Stats() Stats {
+	r.m.RLock()
+	size := len(r.cache)
+	r.m.RUnlock()
+
+	return Stats{
+		Hits:   r.hits.Load(),
+		Misses: r.misses.Load(),
+		Size:   size,
+	}
+}
@@ -79,6 +79,57 @@ func TestCacheExpiration(t *testing.T) {
 	}
 }
 
+func TestCacheStats(t *testing.T) {
+	r := require.New(t)
+
+	cacheStorate := NewInmemoryCacheRepository[*TestStruct, int](time.Second * 10)
+
+	cacheStorate.Add(1, &TestStruct{1})
+	cacheStorate.Add(2, &TestStruct{2})
+
+	_, exists := cacheStorate.Get(1)
+	r.True(exists)
+	_, exists = cacheStorate.Get(3)
+	r.False(exists)
+	_, exists = cacheStorate.Get(2)
+	r.True(exists)
+
+	r.Equal(Stats{Hits: 2, Misses: 1, Size: 2}, cacheStorate.Stats())
+}
+
+func TestTaggedCache(t *testing.T) {
+	r := require.New(t)
+
+	cacheStorate := NewTaggedCache[*TestStruct](time.Second * 10)
+
+	generation, err := cacheStorate.Generation()
+	r.NoError(err)
+
+	r.NoError(cacheStorate.Add(generation, "1", &TestStruct{1}, "a", "b"))
+	r.NoError(cacheStorate.Add(generation, "2", &TestStruct{2}, "b"))
+	r.NoError(cacheStorate.Add(generation, "3", &TestStruct{3}, "c"))
+
+	t.Run("сброс по тегу", func(t *testing.T) {
+		r.NoError(cacheStorate.Invalidate("b"))
+
+		_, exists, _ := cacheStorate.Get("1")
+		r.False(exists)
+		_, exists, _ = cacheStorate.Get("2")
+		r.False(exists)
+
+		ts, exists, _ := cacheStorate.Get("3")
+		r.True(exists)
+		r.Equal(3, ts.ID)
+	})
+
+	t.Run("значение загруженное до сброса не сохраняется", func(t *testing.T) {
+		r.NoError(cacheStorate.Add(generation, "4", &TestStruct{4}))
+
+		_, exists, _ := cacheStorate.Get("4")
+		r.False(exists)
+	})
+}
+
 func BenchmarkCache(t *testing.B) {
 	r := require.New(t)
 	expiration := time.Second * 1000
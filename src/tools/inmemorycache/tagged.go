@@ -0,0 +1,108 @@
+package inmemorycache
+
+import (
+	"sync"
+	"time"
+)
+
+// taggedCache кэш значений с тегами: значение удаляется при сбросе любого из своих тегов.
+// Счетчик generation увеличивается при каждом сбросе, значение загруженное до сброса не сохраняется
+type taggedCache[T any] struct {
+	cache      *inmemoryCacheRepository[T, string]
+	expiration time.Duration
+
+	m          sync.Mutex
+	tags       map[string]map[string]struct{} // ключи значений по тегам
+	expires    map[string]time.Time           // время устаревания ключей, по нему очищаются теги
+	generation int64
+}
+
+func NewTaggedCache[T any](expiration time.Duration) *taggedCache[T] {
+	return &taggedCache[T]{
+		cache:      NewInmemoryCacheRepository[T, string](expiration),
+		expiration: expiration,
+		tags:       make(map[string]map[string]struct{}),
+		expires:    make(map[string]time.Time),
+	}
+}
+
+func (c *taggedCache[T]) Get(key string) (t T, exists bool, err error) {
+	t, exists = c.cache.Get(key)
+	return t, exists, nil
+}
+
+// Generation текущий счетчик сбросов, запоминается перед загрузкой значения
+func (c *taggedCache[T]) Generation() (int64, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	return c.generation, nil
+}
+
+// Add сохраняет значение, загруженное после получения счетчика generation;
+// если с тех пор кэш сбрасывался, значение могло устареть и не сохраняется
+func (c *taggedCache[T]) Add(generation int64, key string, data T, tags ...string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if generation != c.generation {
+		return nil
+	}
+
+	c.cache.Add(key, data)
+	c.expires[key] = time.Now().Add(c.expiration)
+	for _, tag := range tags {
+		keys, exists := c.tags[tag]
+		if !exists {
+			keys = make(map[string]struct{})
+			c.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	return nil
+}
+
+// Invalidate удаляет значения с указанными тегами
+func (c *taggedCache[T]) Invalidate(tags ...string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.generation++
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			c.cache.Remove(key)
+			delete(c.expires, key)
+		}
+		delete(c.tags, tag)
+	}
+
+	return nil
+}
+
+// ClearExpired удаляет устаревшие значения и ссылки тегов на них
+func (c *taggedCache[T]) ClearExpired() {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.cache.ClearExpired()
+
+	now := time.Now()
+	for key, expires := range c.expires {
+		if expires.Before(now) {
+			delete(c.expires, key)
+		}
+	}
+
+	for tag, keys := range c.tags {
+		for key := range keys {
+			if _, exists := c.expires[key]; !exists {
+				delete(keys, key)
+			}
+		}
+
+		if len(keys) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
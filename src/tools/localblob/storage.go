@@ -0,0 +1,93 @@
+package localblob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidKey ключ пустой или выходит за пределы корневого каталога
+var ErrInvalidKey = errors.New("недопустимый ключ файла")
+
+// storage хранилище файлов в каталоге локальной файловой системы,
+// файлы раздаются http сервером по адресу baseURL
+type storage struct {
+	root    string
+	baseURL string
+}
+
+// NewStorage хранилище в каталоге root, раздаваемое по адресу baseURL
+func NewStorage(root, baseURL string) *storage {
+	return &storage{
+		root:    root,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// filePath путь к файлу с ключом key внутри корневого каталога
+func (s *storage) filePath(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean == "/" || clean != "/"+key {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(s.root, filepath.FromSlash(clean)), nil
+}
+
+// Put сохраняет файл; запись идет во временный файл, который переименовывается после успешной записи,
+// чтобы по адресу файла никогда не отдавался частично записанный файл
+func (s *storage) Put(ctx context.Context, key, contentType string, r io.Reader) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(filePath)
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// Delete удаляет файл, отсутствие файла не считается ошибкой
+func (s *storage) Delete(ctx context.Context, key string) error {
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// URL адрес файла относительно адреса раздачи хранилища
+func (s *storage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
@@ -0,0 +1,50 @@
+package localblob
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutDelete(t *testing.T) {
+	r := require.New(t)
+
+	root := t.TempDir()
+	s := NewStorage(root, "/media/")
+	ctx := context.Background()
+
+	r.NoError(s.Put(ctx, "products/1/a.jpg", "image/jpeg", strings.NewReader("data")))
+
+	data, err := os.ReadFile(filepath.Join(root, "products", "1", "a.jpg"))
+	r.NoError(err)
+	r.Equal("data", string(data))
+	r.Equal("/media/products/1/a.jpg", s.URL("products/1/a.jpg"))
+
+	// временные файлы не остаются в каталоге
+	entryList, err := os.ReadDir(filepath.Join(root, "products", "1"))
+	r.NoError(err)
+	r.Len(entryList, 1)
+
+	r.NoError(s.Delete(ctx, "products/1/a.jpg"))
+	_, err = os.Stat(filepath.Join(root, "products", "1", "a.jpg"))
+	r.True(os.IsNotExist(err))
+
+	// повторное удаление не является ошибкой
+	r.NoError(s.Delete(ctx, "products/1/a.jpg"))
+}
+
+func TestInvalidKey(t *testing.T) {
+	r := require.New(t)
+
+	s := NewStorage(t.TempDir(), "/media")
+	ctx := context.Background()
+
+	for _, key := range []string{"", "../a.jpg", "products/../../a.jpg", "/a.jpg", "products/"} {
+		r.ErrorIs(s.Put(ctx, key, "image/jpeg", strings.NewReader("data")), ErrInvalidKey, key)
+		r.ErrorIs(s.Delete(ctx, key), ErrInvalidKey, key)
+	}
+}
@@ -0,0 +1,72 @@
+package memorybroker
+
+import (
+	"context"
+	"product_storage/internal/entity/event"
+	"sync"
+)
+
+// broker брокер сообщений в памяти процесса, используется в тестах и при локальном запуске без брокера.
+// Опубликованные события сохраняются и рассылаются подписчикам
+type broker struct {
+	m           sync.Mutex
+	events      []event.Event
+	subscribers []chan event.Event
+	// fail ошибка, возвращаемая при публикации, имитирует недоступность брокера
+	fail error
+}
+
+func NewBroker() *broker {
+	return &broker{}
+}
+
+func (b *broker) Publish(ctx context.Context, e event.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if b.fail != nil {
+		return b.fail
+	}
+
+	b.events = append(b.events, e)
+	for _, s := range b.subscribers {
+		select {
+		case s <- e:
+		default:
+			// медленный подписчик не блокирует публикацию
+		}
+	}
+
+	return nil
+}
+
+// Subscribe канал опубликованных событий размером size
+func (b *broker) Subscribe(size int) <-chan event.Event {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	s := make(chan event.Event, size)
+	b.subscribers = append(b.subscribers, s)
+
+	return s
+}
+
+// Events опубликованные события в порядке публикации
+func (b *broker) Events() []event.Event {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	return append([]event.Event{}, b.events...)
+}
+
+// SetFail устанавливает ошибку публикации, nil восстанавливает работу брокера
+func (b *broker) SetFail(err error) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	b.fail = err
+}
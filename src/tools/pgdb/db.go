@@ -1,17 +1,25 @@
 package pgdb
 
 import (
+	"database/sql"
 	"log"
+	"product_storage/internal/transaction"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 // SqlxDB get db link
 func SqlxDB(URL string) *sqlx.DB {
-	db, err := sqlx.Connect("postgres", URL)
+	connector, err := pq.NewConnector(URL)
 	if err != nil {
 		log.Fatalln(err)
 	}
+
+	// ошибки конфликтов транзакций запоминаются для их повтора через transaction.Retrier
+	db := sqlx.NewDb(sql.OpenDB(transaction.NewConnector(connector)), "postgres")
+	if err := db.Ping(); err != nil {
+		log.Fatalln(err)
+	}
 	return db
 }
@@ -0,0 +1,154 @@
+package rediscache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// operationTimeout таймаут одной операции с redis
+const operationTimeout = time.Second
+
+// NewClient подключение к redis по url вида redis://host:port/db
+func NewClient(url string) (*redis.Client, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return redis.NewClient(opts), nil
+}
+
+// addScript сохраняет значение и его теги, если счетчик сбросов не изменился с момента загрузки значения
+var addScript = redis.NewScript(`
+local generation = redis.call('GET', KEYS[1]) or '0'
+if generation ~= ARGV[1] then
+	return 0
+end
+
+redis.call('SET', KEYS[2], ARGV[2], 'PX', ARGV[3])
+for i = 3, #KEYS do
+	redis.call('SADD', KEYS[i], KEYS[2])
+	redis.call('PEXPIRE', KEYS[i], ARGV[3])
+end
+
+return 1
+`)
+
+// invalidateScript увеличивает счетчик сбросов и удаляет значения с указанными тегами
+var invalidateScript = redis.NewScript(`
+redis.call('INCR', KEYS[1])
+for i = 2, #KEYS do
+	local keys = redis.call('SMEMBERS', KEYS[i])
+	for _, key in ipairs(keys) do
+		redis.call('DEL', key)
+	end
+	redis.call('DEL', KEYS[i])
+end
+
+return 1
+`)
+
+// taggedCache кэш значений с тегами в redis: значения, теги и счетчик сбросов общие для всех экземпляров сервиса,
+// поэтому сброс на одном экземпляре виден остальным
+type taggedCache[T any] struct {
+	client     redis.UniversalClient
+	prefix     string
+	expiration time.Duration
+}
+
+// NewTaggedCache кэш с ключами, начинающимися с prefix
+func NewTaggedCache[T any](client redis.UniversalClient, prefix string, expiration time.Duration) *taggedCache[T] {
+	return &taggedCache[T]{
+		client:     client,
+		prefix:     prefix,
+		expiration: expiration,
+	}
+}
+
+// ключи содержат общий hash tag, чтобы скрипты работали и в redis cluster
+func (c *taggedCache[T]) valueKey(key string) string {
+	return fmt.Sprintf("{%s}:value:%s", c.prefix, key)
+}
+
+func (c *taggedCache[T]) tagKey(tag string) string {
+	return fmt.Sprintf("{%s}:tag:%s", c.prefix, tag)
+}
+
+func (c *taggedCache[T]) generationKey() string {
+	return fmt.Sprintf("{%s}:generation", c.prefix)
+}
+
+func (c *taggedCache[T]) Get(key string) (t T, exists bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
+	defer cancel()
+
+	data, err := c.client.Get(ctx, c.valueKey(key)).Bytes()
+	switch {
+	case errors.Is(err, redis.Nil):
+		return t, false, nil
+	case err != nil:
+		return t, false, err
+	}
+
+	if err = json.Unmarshal(data, &t); err != nil {
+		return t, false, err
+	}
+
+	return t, true, nil
+}
+
+// Generation текущий счетчик сбросов, запоминается перед загрузкой значения
+func (c *taggedCache[T]) Generation() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
+	defer cancel()
+
+	generation, err := c.client.Get(ctx, c.generationKey()).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	return generation, err
+}
+
+// Add сохраняет значение, загруженное после получения счетчика generation;
+// если с тех пор кэш сбрасывался, значение могло устареть и не сохраняется
+func (c *taggedCache[T]) Add(generation int64, key string, data T, tags ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
+	defer cancel()
+
+	value, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(tags)+2)
+	keys = append(keys, c.generationKey(), c.valueKey(key))
+	for _, tag := range tags {
+		keys = append(keys, c.tagKey(tag))
+	}
+
+	return addScript.Run(ctx, c.client, keys, strconv.FormatInt(generation, 10), value, c.expiration.Milliseconds()).Err()
+}
+
+// Invalidate удаляет значения с указанными тегами
+func (c *taggedCache[T]) Invalidate(tags ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
+	defer cancel()
+
+	keys := make([]string, 0, len(tags)+1)
+	keys = append(keys, c.generationKey())
+	for _, tag := range tags {
+		keys = append(keys, c.tagKey(tag))
+	}
+
+	return invalidateScript.Run(ctx, c.client, keys).Err()
+}
+
+// ClearExpired значения удаляются самим redis по истечении времени жизни
+func (c *taggedCache[T]) ClearExpired() {}
@@ -0,0 +1,110 @@
+package rediscache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+type TestStruct struct {
+	ID int
+}
+
+func newTestClient(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	srv := miniredis.RunT(t)
+
+	client, err := NewClient("redis://" + srv.Addr() + "/")
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	return srv, client
+}
+
+func TestTaggedCache(t *testing.T) {
+	r := require.New(t)
+	_, client := newTestClient(t)
+
+	cacheStorate := NewTaggedCache[TestStruct](client, "test", time.Second*10)
+
+	_, exists, err := cacheStorate.Get("1")
+	r.NoError(err)
+	r.False(exists)
+
+	generation, err := cacheStorate.Generation()
+	r.NoError(err)
+
+	r.NoError(cacheStorate.Add(generation, "1", TestStruct{1}, "a", "b"))
+	r.NoError(cacheStorate.Add(generation, "2", TestStruct{2}, "b"))
+	r.NoError(cacheStorate.Add(generation, "3", TestStruct{3}, "c"))
+
+	ts, exists, err := cacheStorate.Get("1")
+	r.NoError(err)
+	r.True(exists)
+	r.Equal(1, ts.ID)
+
+	t.Run("сброс по тегу", func(t *testing.T) {
+		r.NoError(cacheStorate.Invalidate("b"))
+
+		_, exists, _ := cacheStorate.Get("1")
+		r.False(exists)
+		_, exists, _ = cacheStorate.Get("2")
+		r.False(exists)
+		_, exists, _ = cacheStorate.Get("3")
+		r.True(exists)
+	})
+
+	t.Run("значение загруженное до сброса не сохраняется", func(t *testing.T) {
+		r.NoError(cacheStorate.Add(generation, "4", TestStruct{4}))
+
+		_, exists, _ := cacheStorate.Get("4")
+		r.False(exists)
+	})
+}
+
+func TestTaggedCacheCrossInstance(t *testing.T) {
+	r := require.New(t)
+	_, client := newTestClient(t)
+
+	// два экземпляра сервиса с общим redis
+	first := NewTaggedCache[TestStruct](client, "test", time.Second*10)
+	second := NewTaggedCache[TestStruct](client, "test", time.Second*10)
+
+	generation, err := first.Generation()
+	r.NoError(err)
+	r.NoError(first.Add(generation, "1", TestStruct{1}, "a"))
+
+	ts, exists, err := second.Get("1")
+	r.NoError(err)
+	r.True(exists)
+	r.Equal(1, ts.ID)
+
+	// сброс на втором экземпляре виден первому
+	r.NoError(second.Invalidate("a"))
+
+	_, exists, err = first.Get("1")
+	r.NoError(err)
+	r.False(exists)
+
+	next, err := first.Generation()
+	r.NoError(err)
+	r.Greater(next, generation)
+}
+
+func TestTaggedCacheExpiration(t *testing.T) {
+	r := require.New(t)
+	srv, client := newTestClient(t)
+
+	cacheStorate := NewTaggedCache[TestStruct](client, "test", time.Second*3)
+
+	r.NoError(cacheStorate.Add(0, "1", TestStruct{1}, "a"))
+
+	srv.FastForward(time.Second * 3)
+
+	_, exists, err := cacheStorate.Get("1")
+	r.NoError(err)
+	r.False(exists)
+	r.False(srv.Exists("{test}:tag:a"))
+}
@@ -0,0 +1,118 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+)
+
+// jpegQuality качество сжатия превью в формате jpeg
+const jpegQuality = 85
+
+// ErrTooManyPixels изображение больше допустимого кол-ва пикселей
+var ErrTooManyPixels = errors.New("изображение превышает допустимое кол-во пикселей")
+
+// Result сведения об исходном изображении и его превью
+type Result struct {
+	Format string // формат исходного изображения: jpeg или png, превью кодируется в том же формате
+	Width  int    // ширина исходного изображения
+	Height int    // высота исходного изображения
+	Thumb  []byte // закодированное превью
+}
+
+// Make декодирует изображение data и уменьшает его так, чтобы большая сторона не превышала maxSide,
+// сохраняя пропорции; изображение меньше maxSide не увеличивается.
+// Размер проверяется по заголовку до декодирования: изображение больше maxPixels пикселей не декодируется,
+// так как память под него выделяется по заявленному в заголовке размеру; maxPixels = 0 снимает ограничение
+func Make(data []byte, maxSide int, maxPixels int64) (Result, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, err
+	}
+
+	if maxPixels > 0 && int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return Result{}, ErrTooManyPixels
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, err
+	}
+
+	b := src.Bounds()
+	res := Result{
+		Format: format,
+		Width:  b.Dx(),
+		Height: b.Dy(),
+	}
+
+	thumb := Resize(src, fitSize(b.Dx(), b.Dy(), maxSide))
+
+	var buf bytes.Buffer
+	switch format {
+	case "png":
+		err = png.Encode(&buf, thumb)
+	default:
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: jpegQuality})
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	res.Thumb = buf.Bytes()
+	return res, nil
+}
+
+// fitSize размер, вписанный в квадрат maxSide с сохранением пропорций
+func fitSize(width, height, maxSide int) image.Point {
+	if maxSide <= 0 || (width <= maxSide && height <= maxSide) {
+		return image.Pt(width, height)
+	}
+
+	if width >= height {
+		return image.Pt(maxSide, max(1, height*maxSide/width))
+	}
+
+	return image.Pt(max(1, width*maxSide/height), maxSide)
+}
+
+// Resize уменьшение изображения до размера size усреднением пикселей исходной области,
+// которая приходится на каждый пиксель результата
+func Resize(src image.Image, size image.Point) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, size.X, size.Y))
+
+	for y := 0; y < size.Y; y++ {
+		y0 := b.Min.Y + y*b.Dy()/size.Y
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/size.Y)
+
+		for x := 0; x < size.X; x++ {
+			x0 := b.Min.X + x*b.Dx()/size.X
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/size.X)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBA64Model.Convert(src.At(sx, sy)).(color.NRGBA64)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					bl += uint64(c.B)
+					a += uint64(c.A)
+					n++
+				}
+			}
+
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(bl / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,98 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestMake(t *testing.T) {
+	r := require.New(t)
+
+	res, err := Make(encodePNG(t, 400, 200), 100, 0)
+	r.NoError(err)
+	r.Equal("png", res.Format)
+	r.Equal(400, res.Width)
+	r.Equal(200, res.Height)
+
+	thumb, format, err := image.Decode(bytes.NewReader(res.Thumb))
+	r.NoError(err)
+	r.Equal("png", format)
+	r.Equal(image.Pt(100, 50), thumb.Bounds().Size())
+
+	// цвет однотонного изображения сохраняется при усреднении
+	c := color.NRGBAModel.Convert(thumb.At(10, 10)).(color.NRGBA)
+	r.Equal(color.NRGBA{R: 200, G: 100, B: 50, A: 255}, c)
+}
+
+func TestMakeSmallJPEG(t *testing.T) {
+	r := require.New(t)
+
+	var buf bytes.Buffer
+	r.NoError(jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 30, 60)), nil))
+
+	// изображение меньше превью не увеличивается
+	res, err := Make(buf.Bytes(), 100, 0)
+	r.NoError(err)
+	r.Equal("jpeg", res.Format)
+
+	thumb, format, err := image.Decode(bytes.NewReader(res.Thumb))
+	r.NoError(err)
+	r.Equal("jpeg", format)
+	r.Equal(image.Pt(30, 60), thumb.Bounds().Size())
+}
+
+func TestMakeTooManyPixels(t *testing.T) {
+	r := require.New(t)
+
+	data := encodePNG(t, 1, 1)
+
+	// в заголовке png заявлен размер 50000x50000, сами пиксели не передаются
+	binary.BigEndian.PutUint32(data[16:20], 50000)
+	binary.BigEndian.PutUint32(data[20:24], 50000)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	r.NoError(err)
+	r.Equal(50000, cfg.Width)
+
+	_, err = Make(data, 100, 40_000_000)
+	r.Equal(ErrTooManyPixels, err)
+
+	// изображение в пределах ограничения обрабатывается
+	_, err = Make(encodePNG(t, 400, 200), 100, 80_000)
+	r.NoError(err)
+}
+
+func TestMakeInvalid(t *testing.T) {
+	_, err := Make([]byte("не изображение"), 100, 0)
+	require.Error(t, err)
+}
+
+func TestFitSize(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(image.Pt(100, 1), fitSize(1000, 3, 100))
+	r.Equal(image.Pt(50, 100), fitSize(300, 600, 100))
+	r.Equal(image.Pt(300, 600), fitSize(300, 600, 0))
+}
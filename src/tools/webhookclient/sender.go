@@ -0,0 +1,85 @@
+package webhookclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"product_storage/internal/bridge"
+	"product_storage/internal/entity/webhook"
+	"strconv"
+	"time"
+)
+
+// заголовки запроса доставки
+const (
+	HeaderSignature = "X-Webhook-Signature" // подпись тела запроса: sha256=<hex hmac>
+	HeaderEvent     = "X-Webhook-Event"     // тип события
+	HeaderDelivery  = "X-Webhook-Delivery"  // id доставки, повторяется при повторных попытках
+)
+
+// signaturePrefix префикс подписи с названием алгоритма
+const signaturePrefix = "sha256="
+
+// Sign подпись тела запроса HMAC-SHA256 секретом подписки
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверка подписи тела запроса, используется получателями
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// sender отправка событий получателям по http
+type sender struct {
+	client *http.Client
+}
+
+// NewSender отправка с таймаутом одного запроса timeout
+func NewSender(timeout time.Duration) bridge.WebhookSender {
+	return &sender{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Send отправляет подписанное событие, ответ со статусом вне 2xx считается ошибкой
+func (s *sender) Send(ctx context.Context, d webhook.Delivery) (statusCode int, err error) {
+	body, err := json.Marshal(d.Message())
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderSignature, Sign(d.Secret, body))
+	req.Header.Set(HeaderEvent, d.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(d.DeliveryID, 10))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// тело ответа вычитывается, чтобы соединение можно было переиспользовать
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("получатель ответил статусом %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
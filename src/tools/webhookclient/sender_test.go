@@ -0,0 +1,83 @@
+package webhookclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"product_storage/internal/entity/webhook"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	r := require.New(t)
+
+	body := []byte(`{"event_id":1}`)
+	signature := Sign("0123456789abcdef", body)
+
+	r.True(Verify("0123456789abcdef", body, signature))
+	r.False(Verify("другой секрет 1234", body, signature))
+	r.False(Verify("0123456789abcdef", []byte(`{"event_id":2}`), signature))
+}
+
+func TestSend(t *testing.T) {
+	r := require.New(t)
+
+	const secret = "0123456789abcdef"
+	status := http.StatusOK
+
+	var received webhook.Message
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		if !Verify(secret, body, req.Header.Get(HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		r.Equal("PriceChanged", req.Header.Get(HeaderEvent))
+		r.Equal("7", req.Header.Get(HeaderDelivery))
+		r.NoError(json.Unmarshal(body, &received))
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	d := webhook.Delivery{
+		DeliveryID: 7,
+		EventID:    3,
+		EventType:  "PriceChanged",
+		Payload:    []byte(`{"variant_id":10}`),
+		URL:        srv.URL,
+		Secret:     secret,
+	}
+
+	s := NewSender(time.Second)
+
+	t.Run("успешная доставка", func(t *testing.T) {
+		code, err := s.Send(context.Background(), d)
+		r.NoError(err)
+		r.Equal(http.StatusOK, code)
+		r.Equal(int64(3), received.EventID)
+		r.JSONEq(`{"variant_id":10}`, string(received.Payload))
+	})
+
+	t.Run("неверная подпись", func(t *testing.T) {
+		d := d
+		d.Secret = "fedcba9876543210"
+
+		code, err := s.Send(context.Background(), d)
+		r.Error(err)
+		r.Equal(http.StatusUnauthorized, code)
+	})
+
+	t.Run("ошибка получателя", func(t *testing.T) {
+		status = http.StatusServiceUnavailable
+
+		code, err := s.Send(context.Background(), d)
+		r.Error(err)
+		r.Equal(http.StatusServiceUnavailable, code)
+	})
+}
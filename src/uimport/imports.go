@@ -28,13 +28,28 @@ func NewUsecaseImports(
 		log.Fatalln(err)
 	}
 
+	product := usecase.NewProduct(logger.NewUsecaseLogger(log, "product"), dblog, ri)
+
 	ui := UsecaseImports{
 		Config:         config,
 		SessionManager: sessionManager,
 
 		Usecase: Usecase{
-			Logger:  usecase.NewLogger(log, ri),
-			Product: usecase.NewProduct(logger.NewUsecaseLogger(log, "product"), dblog, ri),
+			Logger:      usecase.NewLogger(log, ri),
+			Product:     product,
+			Outbox:      usecase.NewOutbox(logger.NewUsecaseLogger(log, "outbox"), ri),
+			Webhook:     usecase.NewWebhook(logger.NewUsecaseLogger(log, "webhook"), ri),
+			Purchase:    usecase.NewPurchase(logger.NewUsecaseLogger(log, "purchase"), ri, product),
+			Stocktake:   usecase.NewStocktake(logger.NewUsecaseLogger(log, "stocktake"), ri, product),
+			Reservation: usecase.NewReservation(logger.NewUsecaseLogger(log, "reservation"), ri, product),
+			Location:    usecase.NewLocation(logger.NewUsecaseLogger(log, "location"), ri, product),
+			Unit:        usecase.NewUnit(logger.NewUsecaseLogger(log, "unit"), ri),
+			Image:       usecase.NewImage(logger.NewUsecaseLogger(log, "image"), ri, product),
+			Attribute:   usecase.NewAttribute(logger.NewUsecaseLogger(log, "attribute"), ri, product),
+			Promotion:   usecase.NewPromotion(logger.NewUsecaseLogger(log, "promotion"), ri, product),
+			Customer:    usecase.NewCustomer(logger.NewUsecaseLogger(log, "customer"), ri, product),
+			Reprice:     usecase.NewReprice(logger.NewUsecaseLogger(log, "reprice"), ri, product),
+			Payment:     usecase.NewPayment(logger.NewUsecaseLogger(log, "payment"), ri),
 		},
 	}
 
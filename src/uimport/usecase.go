@@ -5,6 +5,19 @@ import (
 )
 
 type Usecase struct {
-	Logger  *usecase.Logger
-	Product *usecase.ProductUseCase
+	Logger      *usecase.Logger
+	Product     *usecase.ProductUseCase
+	Outbox      *usecase.OutboxUseCase
+	Webhook     *usecase.WebhookUseCase
+	Purchase    *usecase.PurchaseUseCase
+	Stocktake   *usecase.StocktakeUseCase
+	Reservation *usecase.ReservationUseCase
+	Location    *usecase.LocationUseCase
+	Unit        *usecase.UnitUseCase
+	Image       *usecase.ImageUseCase
+	Attribute   *usecase.AttributeUseCase
+	Promotion   *usecase.PromotionUseCase
+	Customer    *usecase.CustomerUseCase
+	Reprice     *usecase.RepriceUseCase
+	Payment     *usecase.PaymentUseCase
 }